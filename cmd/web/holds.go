@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/porky256/course-project/internal/repository"
+	"time"
+)
+
+const holdSweepInterval = time.Minute
+
+// listenForExpiredHolds periodically releases rooms held by guests who didn't finish booking
+func listenForExpiredHolds(repo repository.DatabaseRepo) {
+	go func() {
+		ticker := time.NewTicker(holdSweepInterval)
+		for range ticker.C {
+			released, err := repo.DeleteExpiredRoomHolds()
+			if err != nil {
+				app.ErrorLog.Println(err)
+				continue
+			}
+			if released > 0 {
+				app.InfoLog.Printf("released %d expired room holds\n", released)
+			}
+		}
+	}()
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"github.com/porky256/course-project/internal/frontdesk"
+	"github.com/porky256/course-project/internal/repository"
+	"time"
+)
+
+const housekeepingInterval = time.Hour
+
+// listenForHousekeeping prepares each day's housekeeping: tasks for departures and stay-overs and out of
+// order status of rooms whose out of order period has begun. It runs once at start and then hourly,
+// the work is only done once a day
+func listenForHousekeeping(repo repository.DatabaseRepo) {
+	prepare := func() {
+		day := frontdesk.DateOf(time.Now())
+		added, err := repo.GenerateHousekeepingTasks(day)
+		if err != nil {
+			app.ErrorLog.Println(err)
+		} else if added > 0 {
+			app.InfoLog.Printf("added %d housekeeping tasks\n", added)
+		}
+		marked, err := repo.MarkOutOfOrderRooms(day)
+		if err != nil {
+			app.ErrorLog.Println(err)
+		} else if marked > 0 {
+			app.InfoLog.Printf("marked %d rooms as out of order\n", marked)
+		}
+	}
+
+	go func() {
+		prepare()
+		ticker := time.NewTicker(housekeepingInterval)
+		for range ticker.C {
+			prepare()
+		}
+	}()
+}
@@ -32,7 +32,7 @@ func sendMsg(m models.MailData) {
 		return
 	}
 	email := mail.NewMSG()
-	email.SetFrom(m.From).AddTo(m.From).SetSubject(m.Subject)
+	email.SetFrom(m.From).AddTo(m.To).SetSubject(m.Subject)
 	if m.Template == "" {
 		email.SetBody(mail.TextHTML, m.Content)
 	} else {
@@ -46,6 +46,9 @@ func sendMsg(m models.MailData) {
 		msgToSend := strings.Replace(mailTemplate, "[%body%]", m.Content, 1)
 		email.SetBody(mail.TextHTML, msgToSend)
 	}
+	for _, attachment := range m.Attachments {
+		email.Attach(&mail.File{Name: attachment.Name, MimeType: attachment.MimeType, Data: attachment.Data})
+	}
 	err = email.Send(client)
 	if err != nil {
 		app.ErrorLog.Println(err)
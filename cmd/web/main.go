@@ -2,13 +2,18 @@ package main
 
 import (
 	"encoding/gob"
+	"errors"
+	"fmt"
 	"github.com/alexedwards/scs/v2"
 	"github.com/joho/godotenv"
 	"github.com/porky256/course-project/internal/config"
+	"github.com/porky256/course-project/internal/currency"
 	"github.com/porky256/course-project/internal/driver"
+	"github.com/porky256/course-project/internal/frontdesk"
 	"github.com/porky256/course-project/internal/handlers"
 	"github.com/porky256/course-project/internal/helpers"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/payments"
 	"github.com/porky256/course-project/internal/render"
 	"log"
 	"net/http"
@@ -43,7 +48,20 @@ func main() {
 	listenForEmail()
 
 	newRender := render.NewRender(&app)
-	newHandler := handlers.NewHandlers(&app, newRender, db)
+	gateway, err := paymentGateway(os.Getenv("PAYMENT_GATEWAY"), os.Getenv("PAYMENT_WEBHOOK_SECRET"), app.IsProduction)
+	if err != nil {
+		app.ErrorLog.Fatal(err)
+		return
+	}
+	newHandler := handlers.NewHandlers(&app, newRender, db, gateway)
+	listenForExpiredHolds(newHandler.DB)
+	listenForNoShows(newHandler.DB)
+	listenForHousekeeping(newHandler.DB)
+	listenForTrashPurge(newHandler.DB)
+	err = newHandler.LoadCurrencies()
+	if err != nil {
+		app.ErrorLog.Println("can't load exchange rates:", err)
+	}
 
 	server := http.Server{
 		Addr:    host,
@@ -65,6 +83,9 @@ func run() error {
 	gob.Register(models.User{})
 	gob.Register(models.Restriction{})
 	gob.Register(models.RoomRestriction{})
+	gob.Register(models.ReservationGroup{})
+	gob.Register(models.Payment{})
+	gob.Register([]models.Reservation{})
 	gob.Register(map[string]int{})
 
 	godotenv.Load()
@@ -112,9 +133,74 @@ func run() error {
 	app.ErrorLog = log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
 	app.DateLayout = "2006-01-02"
 	app.MailChan = make(chan models.MailData)
+	app.HoldDuration = 15 * time.Minute
+	app.Property = models.Property{
+		Name:    "Fort Smythe Bed & Breakfast",
+		Address: []string{"100 Rocky Road", "Northbrook, Ontario", "Canada"},
+		Phone:   "(416) 555-1212",
+		Email:   "info@fsbb.ca",
+	}
+	app.Currencies = currency.NewTable(models.Currency{Code: "CAD", Symbol: "$"})
+	app.FrontDesk, err = frontDeskHours(
+		os.Getenv("CHECK_IN_TIME"),
+		os.Getenv("CHECK_OUT_TIME"),
+		os.Getenv("NO_SHOW_CUTOFF"),
+	)
+	if err != nil {
+		return err
+	}
+	app.TrashRetention = 30 * 24 * time.Hour
+	if retention := os.Getenv("TRASH_RETENTION"); retention != "" {
+		app.TrashRetention, err = time.ParseDuration(retention)
+		if err != nil {
+			return err
+		}
+	}
 
 	app.Session = session
 
 	helpers.NewHelpers(&app)
 	return nil
 }
+
+// frontDeskHours reads front desk times given as durations after midnight, like "15h", empty ones stay default
+func frontDeskHours(checkIn, checkOut, noShow string) (frontdesk.Hours, error) {
+	hours := frontdesk.Hours{CheckIn: 15 * time.Hour, CheckOut: 11 * time.Hour, NoShow: 26 * time.Hour}
+	for _, setting := range []struct {
+		value string
+		hour  *time.Duration
+	}{
+		{checkIn, &hours.CheckIn},
+		{checkOut, &hours.CheckOut},
+		{noShow, &hours.NoShow},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(setting.value)
+		if err != nil {
+			return hours, err
+		}
+		*setting.hour = duration
+	}
+	return hours, nil
+}
+
+// paymentGateway picks the payment gateway by name. The fake one takes no real money, so it only runs in development,
+// where callbacks are all rejected until a secret is set
+func paymentGateway(name, secret string, inProduction bool) (payments.PaymentGateway, error) {
+	switch name {
+	case "":
+		return nil, errors.New("PAYMENT_GATEWAY is not set")
+	case "fake":
+		if inProduction {
+			return nil, errors.New("the fake payment gateway takes no real money and can't run in production")
+		}
+		if secret == "" {
+			app.InfoLog.Println("PAYMENT_WEBHOOK_SECRET is not set, payment callbacks are rejected")
+		}
+		return payments.NewFake(secret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment gateway %q", name)
+	}
+}
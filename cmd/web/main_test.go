@@ -12,4 +12,21 @@ var _ = Describe("Main private functions", func() {
 			Expect(err).To(BeNil())
 		})
 	})
+
+	Context("paymentGateway()", func() {
+		It("runs the fake gateway in development only", func() {
+			gateway, err := paymentGateway("fake", "secret", false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gateway).ToNot(BeNil())
+			_, err = paymentGateway("fake", "secret", true)
+			Expect(err).To(MatchError("the fake payment gateway takes no real money and can't run in production"))
+		})
+
+		It("needs a known gateway", func() {
+			_, err := paymentGateway("", "secret", false)
+			Expect(err).To(MatchError("PAYMENT_GATEWAY is not set"))
+			_, err = paymentGateway("stripe", "secret", false)
+			Expect(err).To(MatchError(`unknown payment gateway "stripe"`))
+		})
+	})
 })
@@ -14,6 +14,8 @@ func NoSurf(next http.Handler) http.Handler {
 		Secure:   app.IsProduction,
 		SameSite: http.SameSiteLaxMode,
 	})
+	// the payment gateway signs its callbacks instead
+	csrfHandler.ExemptPath("/payments/webhook")
 	return csrfHandler
 }
 
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"github.com/porky256/course-project/internal/frontdesk"
+	"github.com/porky256/course-project/internal/repository"
+	"time"
+)
+
+const noShowSweepInterval = 10 * time.Minute
+
+// listenForNoShows periodically marks guests who haven't come by the no-show cutoff as no-shows
+func listenForNoShows(repo repository.DatabaseRepo) {
+	go func() {
+		ticker := time.NewTicker(noShowSweepInterval)
+		for range ticker.C {
+			marked, err := repo.MarkNoShows(frontdesk.NoShowDay(time.Now(), app.FrontDesk))
+			if err != nil {
+				app.ErrorLog.Println(err)
+				continue
+			}
+			if marked > 0 {
+				app.InfoLog.Printf("marked %d reservations as no-shows\n", marked)
+			}
+		}
+	}()
+}
@@ -11,6 +11,7 @@ import (
 func routes(app *config.AppConfig, handler *handlers.Handlers) http.Handler {
 	mux := chi.NewRouter()
 	mux.Use(middleware.Recoverer)
+	mux.Use(middleware.RequestID)
 	mux.Use(NoSurf)
 	mux.Use(SessionLoad)
 
@@ -31,8 +32,23 @@ func routes(app *config.AppConfig, handler *handlers.Handlers) http.Handler {
 	mux.Get("/choose-room/{id}", http.HandlerFunc(handler.ChooseRoom))
 	mux.Get("/book-room", http.HandlerFunc(handler.BookRoom))
 
+	mux.Post("/cart/add", http.HandlerFunc(handler.PostAddToCart))
+	mux.Post("/cart/remove/{index}", http.HandlerFunc(handler.PostRemoveFromCart))
+	mux.Post("/book-split-stay", http.HandlerFunc(handler.PostBookSplitStay))
+
+	mux.Get("/waitlist", http.HandlerFunc(handler.Waitlist))
+	mux.Post("/waitlist", http.HandlerFunc(handler.PostWaitlist))
+
 	mux.Get("/reservation-summary", http.HandlerFunc(handler.ReservationSummary))
 
+	mux.Get("/my-booking", http.HandlerFunc(handler.MyBooking))
+	mux.Post("/my-booking", http.HandlerFunc(handler.PostMyBooking))
+	mux.Get("/my-booking/{id}/invoice", http.HandlerFunc(handler.MyBookingInvoice))
+	mux.Get("/my-booking/{id}/receipt", http.HandlerFunc(handler.MyBookingReceipt))
+	mux.Post("/currency", http.HandlerFunc(handler.PostCurrency))
+
+	mux.Post("/payments/webhook", http.HandlerFunc(handler.PaymentWebhook))
+
 	mux.Get("/contact", http.HandlerFunc(handler.Contact))
 
 	mux.Route("/user", func(r chi.Router) {
@@ -46,15 +62,96 @@ func routes(app *config.AppConfig, handler *handlers.Handlers) http.Handler {
 		r.Get("/dashboard", http.HandlerFunc(handler.AdminDashboard))
 		r.Get("/new-reservations", http.HandlerFunc(handler.AdminNewReservations))
 		r.Get("/all-reservations", http.HandlerFunc(handler.AdminAllReservations))
+		r.Get("/new-reservations/export", http.HandlerFunc(handler.AdminExportReservations))
+		r.Get("/all-reservations/export", http.HandlerFunc(handler.AdminExportReservations))
+		r.Post("/new-reservations/bulk", http.HandlerFunc(handler.AdminPostBulkReservations))
+		r.Post("/all-reservations/bulk", http.HandlerFunc(handler.AdminPostBulkReservations))
 
 		r.Get("/reservation-calendar", http.HandlerFunc(handler.AdminReservationCalendar))
 		r.Post("/reservation-calendar", http.HandlerFunc(handler.AdminPostReservationCalendar))
 
+		r.Get("/create-reservation", http.HandlerFunc(handler.AdminCreateReservation))
+		r.Post("/create-reservation", http.HandlerFunc(handler.AdminPostCreateReservation))
+
 		r.Get("/reservations/{src}/{id}/show", http.HandlerFunc(handler.AdminSingleReservation))
 		r.Post("/reservations/{src}/{id}/show", http.HandlerFunc(handler.AdminPostSingleReservation))
+		r.Post("/reservations/{src}/{id}/stay", http.HandlerFunc(handler.AdminPostMoveReservation))
+		r.Post("/reservations/{src}/{id}/folio", http.HandlerFunc(handler.AdminPostFolioEntry))
+		r.Post("/reservations/{src}/{id}/add-ons", http.HandlerFunc(handler.AdminPostReservationAddOn))
+		r.Post("/remove-add-on/{src}/{id}/{item}/do", http.HandlerFunc(handler.AdminPostRemoveReservationAddOn))
+		r.Post("/reservations/{src}/{id}/notes", http.HandlerFunc(handler.AdminPostReservationNote))
+		r.Post("/reservations/{src}/{id}/tags", http.HandlerFunc(handler.AdminPostReservationTag))
+		r.Post("/remove-tag/{src}/{id}/{tag}/do", http.HandlerFunc(handler.AdminPostRemoveReservationTag))
+		r.Get("/reservations/{src}/{id}/invoice", http.HandlerFunc(handler.AdminInvoice))
+		r.Get("/reservations/{src}/{id}/receipt", http.HandlerFunc(handler.AdminReceipt))
+		r.Post("/email-invoice/{src}/{id}/do", http.HandlerFunc(handler.AdminPostEmailInvoice))
+		r.Post("/email-receipt/{src}/{id}/do", http.HandlerFunc(handler.AdminPostEmailReceipt))
 
 		r.Get("/process-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminProcessReservation))
-		r.Get("/delete-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminDeleteReservation))
+		r.Post("/delete-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminPostDeleteReservation))
+		r.Post("/cancel-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCancelReservation))
+		r.Post("/check-in/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCheckIn))
+		r.Post("/check-out/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCheckOut))
+
+		r.Post("/capture-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCapturePayment))
+		r.Post("/void-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostVoidPayment))
+		r.Post("/refund-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostRefundPayment))
+
+		r.Get("/reservation-groups/{id}/show", http.HandlerFunc(handler.AdminReservationGroup))
+		r.Post("/reservation-groups/{id}/show", http.HandlerFunc(handler.AdminPostReservationGroup))
+		r.Get("/process-reservation-group/{id}/do", http.HandlerFunc(handler.AdminProcessReservationGroup))
+		r.Post("/delete-reservation-group/{id}/do", http.HandlerFunc(handler.AdminPostDeleteReservationGroup))
+
+		r.Get("/guests", http.HandlerFunc(handler.AdminGuests))
+		r.Get("/guests/{id}/show", http.HandlerFunc(handler.AdminGuest))
+		r.Post("/guests/{id}/show", http.HandlerFunc(handler.AdminPostGuest))
+		r.Get("/guest-duplicates", http.HandlerFunc(handler.AdminGuestDuplicates))
+		r.Get("/merge-guests/{keep}/{merge}", http.HandlerFunc(handler.AdminMergeGuests))
+		r.Post("/merge-guests/{keep}/{merge}", http.HandlerFunc(handler.AdminPostMergeGuests))
+
+		r.Get("/waitlist", http.HandlerFunc(handler.AdminWaitlist))
+		r.Post("/notify-waitlist-entry/{id}/do", http.HandlerFunc(handler.AdminPostNotifyWaitlistEntry))
+		r.Post("/delete-waitlist-entry/{id}/do", http.HandlerFunc(handler.AdminPostDeleteWaitlistEntry))
+
+		r.Get("/cancellation-policies", http.HandlerFunc(handler.AdminCancellationPolicies))
+		r.Post("/cancellation-policies", http.HandlerFunc(handler.AdminPostCancellationPolicy))
+
+		r.Get("/taxes", http.HandlerFunc(handler.AdminTaxes))
+		r.Post("/taxes", http.HandlerFunc(handler.AdminPostTax))
+		r.Post("/delete-tax/{id}/do", http.HandlerFunc(handler.AdminPostDeleteTax))
+		r.Get("/add-ons", http.HandlerFunc(handler.AdminAddOns))
+		r.Post("/add-ons", http.HandlerFunc(handler.AdminPostAddOn))
+		r.Post("/delete-add-on/{id}/do", http.HandlerFunc(handler.AdminPostDeleteAddOn))
+		r.Get("/currencies", http.HandlerFunc(handler.AdminCurrencies))
+		r.Post("/currencies", http.HandlerFunc(handler.AdminPostCurrency))
+		r.Post("/delete-currency/{id}/do", http.HandlerFunc(handler.AdminPostDeleteCurrency))
+
+		r.Get("/housekeeping", http.HandlerFunc(handler.AdminHousekeeping))
+		r.Post("/housekeeping", http.HandlerFunc(handler.AdminPostHousekeeping))
+		r.Get("/out-of-order", http.HandlerFunc(handler.AdminOutOfOrder))
+		r.Post("/out-of-order", http.HandlerFunc(handler.AdminPostOutOfOrder))
+		r.Post("/delete-out-of-order/{id}/do", http.HandlerFunc(handler.AdminPostDeleteOutOfOrder))
+
+		r.Get("/rooms", http.HandlerFunc(handler.AdminRooms))
+		r.Post("/rooms/{id}", http.HandlerFunc(handler.AdminPostRoom))
+		r.Post("/rate-plans", http.HandlerFunc(handler.AdminPostRatePlan))
+		r.Post("/delete-rate-plan/{id}/do", http.HandlerFunc(handler.AdminPostDeleteRatePlan))
+
+		r.Get("/trash", http.HandlerFunc(handler.AdminTrash))
+		r.Post("/trash/{kind}/{id}/restore", http.HandlerFunc(handler.AdminPostRestoreFromTrash))
+		r.Post("/trash/{kind}/{id}/purge", http.HandlerFunc(handler.AdminPostPurgeFromTrash))
+
+		r.Get("/import", http.HandlerFunc(handler.AdminImport))
+		r.Post("/import", http.HandlerFunc(handler.AdminPostImport))
+		r.Post("/import/preview", http.HandlerFunc(handler.AdminPostImportPreview))
+		r.Post("/import/commit", http.HandlerFunc(handler.AdminPostImportCommit))
+
+		r.Get("/reports", http.HandlerFunc(handler.AdminReports))
+		r.Get("/reports/data", http.HandlerFunc(handler.AdminReportsData))
+		r.Get("/reports/export", http.HandlerFunc(handler.AdminExportReport))
+
+		r.Get("/audit-log", http.HandlerFunc(handler.AdminAuditLog))
+		r.Get("/history/{entity}/{id}", http.HandlerFunc(handler.AdminHistory))
 	})
 
 	return mux
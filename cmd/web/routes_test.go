@@ -7,6 +7,7 @@ import (
 	"github.com/porky256/course-project/internal/config"
 	"github.com/porky256/course-project/internal/driver"
 	"github.com/porky256/course-project/internal/handlers"
+	"github.com/porky256/course-project/internal/payments"
 	"github.com/porky256/course-project/internal/render"
 )
 
@@ -23,7 +24,7 @@ var _ = Describe("Routes private functions", func() {
 		BeforeEach(func() {
 			app = config.AppConfig{}
 			r = render.NewRender(&app)
-			h = handlers.NewHandlers(&app, r, &driver.DB{})
+			h = handlers.NewHandlers(&app, r, &driver.DB{}, payments.NewFake(""))
 		})
 
 		It("Check if routes added correctly", func() {
@@ -45,8 +46,17 @@ var _ = Describe("Routes private functions", func() {
 			Expect(routeExists(post, "/search-availability", routes)).To(Equal(true))
 			Expect(routeExists(post, "/search-availability-json", routes)).To(Equal(true))
 
+			Expect(routeExists(post, "/cart/add", routes)).To(Equal(true))
+			Expect(routeExists(post, "/cart/remove/{index}", routes)).To(Equal(true))
+			Expect(routeExists(post, "/book-split-stay", routes)).To(Equal(true))
+
+			Expect(routeExists(get, "/waitlist", routes)).To(Equal(true))
+			Expect(routeExists(post, "/waitlist", routes)).To(Equal(true))
+
 			Expect(routeExists(get, "/reservation-summary", routes)).To(Equal(true))
 
+			Expect(routeExists(post, "/payments/webhook", routes)).To(Equal(true))
+
 			Expect(routeExists(get, "/contact", routes)).To(Equal(true))
 		})
 	})
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"github.com/porky256/course-project/internal/repository"
+	"time"
+)
+
+const trashPurgeInterval = time.Hour
+
+// listenForTrashPurge periodically purges reservations and blocks which were in the trash longer than the retention
+func listenForTrashPurge(repo repository.DatabaseRepo) {
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		for range ticker.C {
+			purged, err := repo.PurgeTrash(time.Now().Add(-app.TrashRetention))
+			if err != nil {
+				app.ErrorLog.Println(err)
+				continue
+			}
+			if purged > 0 {
+				app.InfoLog.Printf("purged %d items from the trash\n", purged)
+			}
+		}
+	}()
+}
@@ -0,0 +1,15 @@
+DROP TRIGGER IF EXISTS row_mod_on_reservation_groups_trigger_ ON reservation_groups;
+
+DROP INDEX IF EXISTS reservations_confirmation_code_idx;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_group_id;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS group_id,
+    DROP COLUMN IF EXISTS confirmation_code;
+
+ALTER TABLE IF EXISTS reservations
+    ADD CONSTRAINT reservations_email_key UNIQUE (email);
+
+DROP TABLE IF EXISTS reservation_groups;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS reservation_groups (
+    id                SERIAL NOT NULL PRIMARY KEY,
+    confirmation_code VARCHAR(16) NOT NULL UNIQUE,
+    first_name        VARCHAR(256) NOT NULL DEFAULT '',
+    last_name         VARCHAR(256) NOT NULL DEFAULT '',
+    email             VARCHAR(256) NOT NULL DEFAULT '',
+    phone             VARCHAR(256) NOT NULL DEFAULT '',
+    is_processed      INTEGER NOT NULL DEFAULT 0,
+    created_at        TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS group_id INTEGER,
+    ADD COLUMN IF NOT EXISTS confirmation_code VARCHAR(16) NOT NULL DEFAULT '';
+
+-- rooms of one group share the guest contact, so email can't be unique anymore
+ALTER TABLE IF EXISTS reservations
+    DROP CONSTRAINT IF EXISTS reservations_email_key;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_group_id
+        FOREIGN KEY (group_id)
+            REFERENCES reservation_groups(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX reservations_confirmation_code_idx ON reservations (confirmation_code);
+
+CREATE TRIGGER row_mod_on_reservation_groups_trigger_ BEFORE UPDATE ON reservation_groups
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
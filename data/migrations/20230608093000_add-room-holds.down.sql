@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS room_restrictions_expires_at_idx;
+
+DELETE FROM room_restrictions WHERE restriction_id=3;
+
+ALTER TABLE IF EXISTS room_restrictions
+    DROP COLUMN IF EXISTS expires_at;
+
+DELETE FROM restrictions WHERE id=3;
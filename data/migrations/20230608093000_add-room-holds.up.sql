@@ -0,0 +1,8 @@
+INSERT INTO restrictions (id,restriction_name) VALUES
+                                     (3,'Hold')
+                                        ON CONFLICT (id) DO UPDATE SET restriction_name=EXCLUDED.restriction_name;
+
+ALTER TABLE IF EXISTS room_restrictions
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX room_restrictions_expires_at_idx ON room_restrictions (expires_at);
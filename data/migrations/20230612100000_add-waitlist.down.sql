@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS row_mod_on_waitlist_entries_trigger_ ON waitlist_entries;
+
+DROP INDEX IF EXISTS waitlist_entries_dates_idx;
+
+DROP TABLE IF EXISTS waitlist_entries;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS waitlist_entries (
+    id          SERIAL NOT NULL PRIMARY KEY,
+    first_name  VARCHAR(256) NOT NULL DEFAULT '',
+    last_name   VARCHAR(256) NOT NULL DEFAULT '',
+    email       VARCHAR(256) NOT NULL DEFAULT '',
+    phone       VARCHAR(256) NOT NULL DEFAULT '',
+    start_date  DATE NOT NULL,
+    end_date    DATE NOT NULL,
+    room_id     INTEGER,
+    notified_at TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE waitlist_entries
+    ADD CONSTRAINT fk_waitlist_entries_room_id
+        FOREIGN KEY (room_id)
+            REFERENCES rooms(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX waitlist_entries_dates_idx ON waitlist_entries (start_date, end_date);
+
+CREATE TRIGGER row_mod_on_waitlist_entries_trigger_ BEFORE UPDATE ON waitlist_entries
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
@@ -0,0 +1,29 @@
+DROP TRIGGER IF EXISTS row_mod_on_rate_plans_trigger_ ON rate_plans;
+DROP TRIGGER IF EXISTS row_mod_on_cancellation_policies_trigger_ ON cancellation_policies;
+
+DROP INDEX IF EXISTS reservations_status_idx;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_cancellation_policy_id;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_rate_plan_id;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS rate_plan_id,
+    DROP COLUMN IF EXISTS nightly_rate,
+    DROP COLUMN IF EXISTS cancellation_policy_id,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancellation_fee;
+
+DROP TABLE IF EXISTS rate_plans;
+
+ALTER TABLE rooms
+    DROP CONSTRAINT IF EXISTS fk_rooms_cancellation_policy_id;
+
+ALTER TABLE IF EXISTS rooms
+    DROP COLUMN IF EXISTS price,
+    DROP COLUMN IF EXISTS cancellation_policy_id;
+
+DROP TABLE IF EXISTS cancellation_policies;
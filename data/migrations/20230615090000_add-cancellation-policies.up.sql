@@ -0,0 +1,80 @@
+CREATE TABLE IF NOT EXISTS cancellation_policies (
+    id          SERIAL NOT NULL PRIMARY KEY,
+    policy_name VARCHAR(256) NOT NULL DEFAULT '',
+    free_days   INTEGER NOT NULL DEFAULT 0,
+    fee_type    VARCHAR(16) NOT NULL DEFAULT 'first_night',
+    fee_percent INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+INSERT INTO cancellation_policies (id,policy_name,free_days,fee_type,fee_percent) VALUES
+                                  (1,'Flexible',2,'first_night',0),
+                                  (2,'Moderate',7,'percent',50),
+                                  (3,'Strict',30,'percent',100)
+                                 ON CONFLICT (id) DO NOTHING;
+SELECT setval('cancellation_policies_id_seq', (SELECT max(id) FROM cancellation_policies));
+
+-- prices are kept in cents
+ALTER TABLE IF EXISTS rooms
+    ADD COLUMN IF NOT EXISTS price INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cancellation_policy_id INTEGER;
+
+UPDATE rooms SET price=12000, cancellation_policy_id=1 WHERE id=1;
+UPDATE rooms SET price=18000, cancellation_policy_id=1 WHERE id=2;
+
+ALTER TABLE rooms
+    ADD CONSTRAINT fk_rooms_cancellation_policy_id
+        FOREIGN KEY (cancellation_policy_id)
+            REFERENCES cancellation_policies(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE TABLE IF NOT EXISTS rate_plans (
+    id                     SERIAL NOT NULL PRIMARY KEY,
+    room_id                INTEGER NOT NULL,
+    plan_name              VARCHAR(256) NOT NULL DEFAULT '',
+    price                  INTEGER NOT NULL DEFAULT 0,
+    cancellation_policy_id INTEGER,
+    created_at             TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at             TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE rate_plans
+    ADD CONSTRAINT fk_rate_plans_room_id
+        FOREIGN KEY (room_id)
+            REFERENCES rooms(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE rate_plans
+    ADD CONSTRAINT fk_rate_plans_cancellation_policy_id
+        FOREIGN KEY (cancellation_policy_id)
+            REFERENCES cancellation_policies(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS rate_plan_id INTEGER,
+    ADD COLUMN IF NOT EXISTS nightly_rate INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS cancellation_policy_id INTEGER,
+    ADD COLUMN IF NOT EXISTS status VARCHAR(16) NOT NULL DEFAULT 'confirmed',
+    ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cancellation_fee INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_rate_plan_id
+        FOREIGN KEY (rate_plan_id)
+            REFERENCES rate_plans(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_cancellation_policy_id
+        FOREIGN KEY (cancellation_policy_id)
+            REFERENCES cancellation_policies(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservations_status_idx ON reservations (status);
+
+CREATE TRIGGER row_mod_on_cancellation_policies_trigger_ BEFORE UPDATE ON cancellation_policies
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
+
+CREATE TRIGGER row_mod_on_rate_plans_trigger_ BEFORE UPDATE ON rate_plans
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
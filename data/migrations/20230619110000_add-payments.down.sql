@@ -0,0 +1,13 @@
+DROP TRIGGER IF EXISTS row_mod_on_payments_trigger_ ON payments;
+
+DROP INDEX IF EXISTS payments_reference_idx;
+DROP INDEX IF EXISTS payments_reservation_id_idx;
+
+DROP TABLE IF EXISTS payments;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS payment_status;
+
+ALTER TABLE IF EXISTS rate_plans
+    DROP COLUMN IF EXISTS deposit_type,
+    DROP COLUMN IF EXISTS deposit_percent;
@@ -0,0 +1,30 @@
+ALTER TABLE IF EXISTS rate_plans
+    ADD COLUMN IF NOT EXISTS deposit_type VARCHAR(16) NOT NULL DEFAULT 'none',
+    ADD COLUMN IF NOT EXISTS deposit_percent INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS payment_status VARCHAR(16) NOT NULL DEFAULT 'unpaid';
+
+-- amounts are kept in cents, reference is the id of the payment in the gateway
+CREATE TABLE IF NOT EXISTS payments (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    amount         INTEGER NOT NULL DEFAULT 0,
+    refunded       INTEGER NOT NULL DEFAULT 0,
+    status         VARCHAR(16) NOT NULL DEFAULT 'authorized',
+    reference      VARCHAR(256) NOT NULL DEFAULT '',
+    created_at     TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE payments
+    ADD CONSTRAINT fk_payments_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX payments_reservation_id_idx ON payments (reservation_id);
+CREATE INDEX payments_reference_idx ON payments (reference);
+
+CREATE TRIGGER row_mod_on_payments_trigger_ BEFORE UPDATE ON payments
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
@@ -0,0 +1,6 @@
+DROP TRIGGER IF EXISTS folio_entries_append_only_trigger_ ON folio_entries;
+DROP FUNCTION IF EXISTS folio_entries_append_only_function_();
+
+DROP INDEX IF EXISTS folio_entries_reservation_id_idx;
+
+DROP TABLE IF EXISTS folio_entries;
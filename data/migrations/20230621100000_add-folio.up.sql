@@ -0,0 +1,58 @@
+-- amounts are kept in cents: charges are positive, payments and discounts are negative,
+-- so the balance due of a reservation is the sum of its entries
+CREATE TABLE IF NOT EXISTS folio_entries (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    entry_type     VARCHAR(32) NOT NULL DEFAULT '',
+    description    VARCHAR(256) NOT NULL DEFAULT '',
+    amount         INTEGER NOT NULL DEFAULT 0,
+    user_id        INTEGER,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE folio_entries
+    ADD CONSTRAINT fk_folio_entries_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE folio_entries
+    ADD CONSTRAINT fk_folio_entries_user_id
+        FOREIGN KEY (user_id)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX folio_entries_reservation_id_idx ON folio_entries (reservation_id);
+
+-- the ledger is append-only, mistakes are corrected with new entries
+CREATE OR REPLACE FUNCTION folio_entries_append_only_function_()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'folio entries can not be changed';
+END;
+$$
+    language PLPGSQL;
+
+CREATE TRIGGER folio_entries_append_only_trigger_ BEFORE UPDATE ON folio_entries
+    FOR EACH ROW EXECUTE PROCEDURE folio_entries_append_only_function_();
+
+-- ledger of reservations made before the folio
+INSERT INTO folio_entries (reservation_id, entry_type, description, amount, created_at)
+SELECT id, 'room', 'Room nights', nightly_rate * (end_date - start_date), created_at
+FROM reservations WHERE nightly_rate > 0;
+
+INSERT INTO folio_entries (reservation_id, entry_type, description, amount, created_at)
+SELECT id, 'room', 'Room nights cancelled', -nightly_rate * (end_date - start_date), cancelled_at
+FROM reservations WHERE nightly_rate > 0 AND status = 'cancelled';
+
+INSERT INTO folio_entries (reservation_id, entry_type, description, amount, created_at)
+SELECT id, 'cancellation_fee', 'Cancellation fee', cancellation_fee, cancelled_at
+FROM reservations WHERE cancellation_fee > 0;
+
+INSERT INTO folio_entries (reservation_id, entry_type, description, amount, created_at)
+SELECT reservation_id, 'payment', 'Deposit ' || reference, -amount, created_at
+FROM payments WHERE status IN ('captured', 'refunded');
+
+INSERT INTO folio_entries (reservation_id, entry_type, description, amount, created_at)
+SELECT reservation_id, 'refund', 'Refund ' || reference, refunded, updated_at
+FROM payments WHERE refunded > 0;
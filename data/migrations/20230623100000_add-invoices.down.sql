@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS invoices_reservation_id_kind_idx;
+
+DROP TABLE IF EXISTS invoices;
+
+DROP SEQUENCE IF EXISTS invoice_number_seq;
@@ -0,0 +1,19 @@
+-- invoice and receipt numbers share one sequence so no two documents get the same number
+CREATE SEQUENCE IF NOT EXISTS invoice_number_seq;
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    kind           VARCHAR(32) NOT NULL DEFAULT 'invoice',
+    number         VARCHAR(32) NOT NULL UNIQUE,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE invoices
+    ADD CONSTRAINT fk_invoices_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+-- a reservation has at most one invoice and one receipt, issuing again reuses the number
+CREATE UNIQUE INDEX invoices_reservation_id_kind_idx ON invoices (reservation_id, kind);
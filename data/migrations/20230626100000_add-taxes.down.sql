@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS reservation_taxes_reservation_id_idx;
+
+DROP TABLE IF EXISTS reservation_taxes;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS guests;
+
+DROP TRIGGER IF EXISTS row_mod_on_taxes_trigger_ ON taxes;
+
+DROP TABLE IF EXISTS taxes;
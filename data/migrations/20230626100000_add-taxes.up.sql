@@ -0,0 +1,45 @@
+-- rate of a percentage is in hundredths of a percent, amount of a flat tax is in cents
+CREATE TABLE IF NOT EXISTS taxes (
+    id         SERIAL NOT NULL PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL DEFAULT '',
+    tax_type   VARCHAR(32) NOT NULL DEFAULT 'percent',
+    rate       INTEGER NOT NULL DEFAULT 0,
+    amount     INTEGER NOT NULL DEFAULT 0,
+    basis      VARCHAR(32) NOT NULL DEFAULT 'stay',
+    inclusive  BOOLEAN NOT NULL DEFAULT false,
+    valid_from DATE,
+    valid_to   DATE,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER row_mod_on_taxes_trigger_ BEFORE UPDATE ON taxes
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
+
+ALTER TABLE reservations
+    ADD COLUMN guests INTEGER NOT NULL DEFAULT 1;
+
+-- taxes are worked out at booking and kept, so changing a definition doesn't change booked stays
+CREATE TABLE IF NOT EXISTS reservation_taxes (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    tax_id         INTEGER,
+    name           VARCHAR(255) NOT NULL DEFAULT '',
+    amount         INTEGER NOT NULL DEFAULT 0,
+    inclusive      BOOLEAN NOT NULL DEFAULT false,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE reservation_taxes
+    ADD CONSTRAINT fk_reservation_taxes_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE reservation_taxes
+    ADD CONSTRAINT fk_reservation_taxes_tax_id
+        FOREIGN KEY (tax_id)
+            REFERENCES taxes(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservation_taxes_reservation_id_idx ON reservation_taxes (reservation_id);
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS row_mod_on_currencies_trigger_ ON currencies;
+
+DROP INDEX IF EXISTS currencies_code_idx;
+
+DROP TABLE IF EXISTS currencies;
@@ -0,0 +1,14 @@
+-- rate is how much of the currency one unit of the base currency buys, charges are always in the base currency
+CREATE TABLE IF NOT EXISTS currencies (
+    id         SERIAL NOT NULL PRIMARY KEY,
+    code       VARCHAR(3) NOT NULL,
+    symbol     VARCHAR(8) NOT NULL DEFAULT '',
+    rate       NUMERIC(18, 6) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX currencies_code_idx ON currencies (code);
+
+CREATE TRIGGER row_mod_on_currencies_trigger_ BEFORE UPDATE ON currencies
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS reservation_add_ons_add_on_id_idx;
+
+DROP INDEX IF EXISTS reservation_add_ons_reservation_id_idx;
+
+DROP TABLE IF EXISTS reservation_add_ons;
+
+DROP TRIGGER IF EXISTS row_mod_on_add_ons_trigger_ ON add_ons;
+
+DROP TABLE IF EXISTS add_ons;
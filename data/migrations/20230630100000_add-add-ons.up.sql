@@ -0,0 +1,47 @@
+-- price is in cents, daily_limit is how many can be booked for any one night, 0 is no limit
+CREATE TABLE IF NOT EXISTS add_ons (
+    id          SERIAL NOT NULL PRIMARY KEY,
+    name        VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    price       INTEGER NOT NULL DEFAULT 0,
+    basis       VARCHAR(32) NOT NULL DEFAULT 'stay',
+    daily_limit INTEGER NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER row_mod_on_add_ons_trigger_ BEFORE UPDATE ON add_ons
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
+
+INSERT INTO add_ons (name, description, price, basis, daily_limit)
+VALUES ('Breakfast', 'Full breakfast served in the dining room', 1500, 'person_night', 0),
+       ('Parking', 'A space in the guest car park', 1000, 'night', 4),
+       ('Late checkout', 'Keep the room until 2 pm on the day you leave', 2500, 'stay', 2),
+       ('Airport pickup', 'A ride from the airport on the day you arrive', 4500, 'stay', 1);
+
+-- add-ons are kept as they were sold, so changing the catalog doesn't change booked stays
+CREATE TABLE IF NOT EXISTS reservation_add_ons (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    add_on_id      INTEGER,
+    name           VARCHAR(255) NOT NULL DEFAULT '',
+    quantity       INTEGER NOT NULL DEFAULT 1,
+    amount         INTEGER NOT NULL DEFAULT 0,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE reservation_add_ons
+    ADD CONSTRAINT fk_reservation_add_ons_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE reservation_add_ons
+    ADD CONSTRAINT fk_reservation_add_ons_add_on_id
+        FOREIGN KEY (add_on_id)
+            REFERENCES add_ons(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservation_add_ons_reservation_id_idx ON reservation_add_ons (reservation_id);
+
+CREATE INDEX reservation_add_ons_add_on_id_idx ON reservation_add_ons (add_on_id);
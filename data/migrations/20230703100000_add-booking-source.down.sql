@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS reservations_source_idx;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_created_by;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS override_reason,
+    DROP COLUMN IF EXISTS created_by;
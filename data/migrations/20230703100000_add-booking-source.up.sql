@@ -0,0 +1,14 @@
+-- source tells how the booking came in, bookings made by guests on the site are 'website'.
+-- override_reason is why staff booked off the room rate or past the stay rules
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'website',
+    ADD COLUMN IF NOT EXISTS override_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS created_by INTEGER;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_created_by
+        FOREIGN KEY (created_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservations_source_idx ON reservations (source);
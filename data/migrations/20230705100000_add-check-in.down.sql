@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS reservations_end_date_idx;
+DROP INDEX IF EXISTS reservations_start_date_idx;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_checked_out_by;
+
+ALTER TABLE reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_checked_in_by;
+
+UPDATE reservations SET status = 'cancelled' WHERE status = 'no_show';
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS checked_in_by,
+    DROP COLUMN IF EXISTS checked_out_at,
+    DROP COLUMN IF EXISTS checked_out_by;
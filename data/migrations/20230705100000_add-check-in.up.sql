@@ -0,0 +1,34 @@
+-- times guests actually came and left and the staff who checked them in and out.
+-- Guests who don't come by the no-show cutoff get the 'no_show' status
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_in_by INTEGER,
+    ADD COLUMN IF NOT EXISTS checked_out_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS checked_out_by INTEGER;
+
+-- stays which started before are taken as checked in and the ones which ended as checked out,
+-- so the no-show sweep and the front desk boards leave them alone
+UPDATE reservations
+SET checked_in_at = start_date
+WHERE status = 'confirmed'
+  AND start_date < CURRENT_DATE;
+
+UPDATE reservations
+SET checked_out_at = end_date
+WHERE status = 'confirmed'
+  AND end_date <= CURRENT_DATE;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_checked_in_by
+        FOREIGN KEY (checked_in_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_checked_out_by
+        FOREIGN KEY (checked_out_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservations_start_date_idx ON reservations (start_date);
+CREATE INDEX reservations_end_date_idx ON reservations (end_date);
@@ -0,0 +1,27 @@
+DELETE FROM room_restrictions WHERE restriction_id=4;
+
+DELETE FROM restrictions WHERE id=4;
+
+ALTER TABLE IF EXISTS room_restrictions
+    DROP CONSTRAINT IF EXISTS fk_room_restrictions_out_of_order_id;
+
+ALTER TABLE IF EXISTS room_restrictions
+    DROP COLUMN IF EXISTS out_of_order_id;
+
+DROP INDEX IF EXISTS out_of_order_periods_room_id_idx;
+
+DROP TRIGGER IF EXISTS row_mod_on_out_of_order_periods_trigger_ ON out_of_order_periods;
+
+DROP TABLE IF EXISTS out_of_order_periods;
+
+DROP INDEX IF EXISTS housekeeping_tasks_task_date_idx;
+
+DROP TABLE IF EXISTS housekeeping_tasks;
+
+ALTER TABLE IF EXISTS rooms
+    DROP CONSTRAINT IF EXISTS fk_rooms_housekeeping_updated_by;
+
+ALTER TABLE IF EXISTS rooms
+    DROP COLUMN IF EXISTS housekeeping_status,
+    DROP COLUMN IF EXISTS housekeeping_updated_at,
+    DROP COLUMN IF EXISTS housekeeping_updated_by;
@@ -0,0 +1,86 @@
+-- housekeeping status of rooms: clean, dirty, inspected or out_of_order
+ALTER TABLE IF EXISTS rooms
+    ADD COLUMN IF NOT EXISTS housekeeping_status VARCHAR(16) NOT NULL DEFAULT 'clean',
+    ADD COLUMN IF NOT EXISTS housekeeping_updated_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS housekeeping_updated_by INTEGER;
+
+ALTER TABLE rooms
+    ADD CONSTRAINT fk_rooms_housekeeping_updated_by
+        FOREIGN KEY (housekeeping_updated_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- one cleaning task per room and day, generated from departures and stay-overs
+CREATE TABLE IF NOT EXISTS housekeeping_tasks (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    room_id        INTEGER NOT NULL,
+    task_date      DATE NOT NULL,
+    task_type      VARCHAR(16) NOT NULL DEFAULT 'departure',
+    reservation_id INTEGER,
+    completed_at   TIMESTAMP,
+    completed_by   INTEGER,
+    created_at     TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (room_id, task_date)
+);
+
+ALTER TABLE housekeeping_tasks
+    ADD CONSTRAINT fk_housekeeping_tasks_room_id
+        FOREIGN KEY (room_id)
+            REFERENCES rooms(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE housekeeping_tasks
+    ADD CONSTRAINT fk_housekeeping_tasks_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+ALTER TABLE housekeeping_tasks
+    ADD CONSTRAINT fk_housekeeping_tasks_completed_by
+        FOREIGN KEY (completed_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX housekeeping_tasks_task_date_idx ON housekeeping_tasks (task_date);
+
+-- rooms taken out of service for repairs, each period blocks the room with a room restriction
+CREATE TABLE IF NOT EXISTS out_of_order_periods (
+    id         SERIAL NOT NULL PRIMARY KEY,
+    room_id    INTEGER NOT NULL,
+    start_date DATE NOT NULL,
+    end_date   DATE NOT NULL,
+    reason     TEXT NOT NULL DEFAULT '',
+    created_by INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER row_mod_on_out_of_order_periods_trigger_ BEFORE UPDATE ON out_of_order_periods
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
+
+ALTER TABLE out_of_order_periods
+    ADD CONSTRAINT fk_out_of_order_periods_room_id
+        FOREIGN KEY (room_id)
+            REFERENCES rooms(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE out_of_order_periods
+    ADD CONSTRAINT fk_out_of_order_periods_created_by
+        FOREIGN KEY (created_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX out_of_order_periods_room_id_idx ON out_of_order_periods (room_id);
+
+ALTER TABLE IF EXISTS room_restrictions
+    ADD COLUMN IF NOT EXISTS out_of_order_id INTEGER;
+
+ALTER TABLE room_restrictions
+    ADD CONSTRAINT fk_room_restrictions_out_of_order_id
+        FOREIGN KEY (out_of_order_id)
+            REFERENCES out_of_order_periods(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+INSERT INTO restrictions (id,restriction_name) VALUES
+                                     (4,'Out of Order')
+                                        ON CONFLICT (id) DO UPDATE SET restriction_name=EXCLUDED.restriction_name;
@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS reservations_guest_id_idx;
+
+ALTER TABLE IF EXISTS reservations
+    DROP CONSTRAINT IF EXISTS fk_reservations_guest_id;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS guest_id;
+
+DROP INDEX IF EXISTS guests_phone_idx;
+
+DROP INDEX IF EXISTS guests_email_idx;
+
+DROP TRIGGER IF EXISTS row_mod_on_guests_trigger_ ON guests;
+
+DROP TABLE IF EXISTS guests;
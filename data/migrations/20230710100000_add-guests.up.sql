@@ -0,0 +1,43 @@
+-- guest profiles, reservations keep the contact they were booked with and point to the guest
+CREATE TABLE IF NOT EXISTS guests (
+    id          SERIAL NOT NULL PRIMARY KEY,
+    first_name  VARCHAR(256) NOT NULL DEFAULT '',
+    last_name   VARCHAR(256) NOT NULL DEFAULT '',
+    email       VARCHAR(256) NOT NULL DEFAULT '',
+    phone       VARCHAR(256) NOT NULL DEFAULT '',
+    preferences TEXT NOT NULL DEFAULT '',
+    notes       TEXT NOT NULL DEFAULT '',
+    vip         BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE TRIGGER row_mod_on_guests_trigger_ BEFORE UPDATE ON guests
+    FOR EACH ROW EXECUTE PROCEDURE update_row_modified_function_();
+
+CREATE INDEX guests_email_idx ON guests (lower(email));
+
+CREATE INDEX guests_phone_idx ON guests (phone);
+
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS guest_id INTEGER;
+
+ALTER TABLE reservations
+    ADD CONSTRAINT fk_reservations_guest_id
+        FOREIGN KEY (guest_id)
+            REFERENCES guests(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservations_guest_id_idx ON reservations (guest_id);
+
+-- a profile for every email booked so far, with the contact of the latest booking
+INSERT INTO guests (first_name, last_name, email, phone, created_at)
+SELECT DISTINCT ON (lower(email)) first_name, last_name, email, phone, created_at
+FROM reservations
+WHERE email <> ''
+ORDER BY lower(email), created_at DESC;
+
+UPDATE reservations
+SET guest_id = guests.id
+FROM guests
+WHERE lower(reservations.email) = lower(guests.email);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS guest_merges_guest_id_idx;
+
+DROP TABLE IF EXISTS guest_merges;
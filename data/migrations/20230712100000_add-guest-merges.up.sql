@@ -0,0 +1,26 @@
+-- merges of duplicate guest profiles, the merged profile is gone so its contact is kept here
+CREATE TABLE IF NOT EXISTS guest_merges (
+    id              SERIAL NOT NULL PRIMARY KEY,
+    guest_id        INTEGER NOT NULL,
+    merged_guest_id INTEGER NOT NULL,
+    merged_name     VARCHAR(512) NOT NULL DEFAULT '',
+    merged_email    VARCHAR(256) NOT NULL DEFAULT '',
+    merged_phone    VARCHAR(256) NOT NULL DEFAULT '',
+    reservations    INTEGER NOT NULL DEFAULT 0,
+    merged_by       INTEGER,
+    created_at      TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE guest_merges
+    ADD CONSTRAINT fk_guest_merges_guest_id
+        FOREIGN KEY (guest_id)
+            REFERENCES guests(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE guest_merges
+    ADD CONSTRAINT fk_guest_merges_merged_by
+        FOREIGN KEY (merged_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX guest_merges_guest_id_idx ON guest_merges (guest_id);
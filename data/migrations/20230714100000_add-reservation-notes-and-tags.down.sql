@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS reservation_tags_tag_idx;
+
+DROP TABLE IF EXISTS reservation_tags;
+
+DROP INDEX IF EXISTS reservation_notes_reservation_id_idx;
+
+DROP TABLE IF EXISTS reservation_notes;
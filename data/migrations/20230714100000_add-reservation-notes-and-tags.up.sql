@@ -0,0 +1,37 @@
+-- internal notes staff leave on reservations
+CREATE TABLE IF NOT EXISTS reservation_notes (
+    id             SERIAL NOT NULL PRIMARY KEY,
+    reservation_id INTEGER NOT NULL,
+    body           TEXT NOT NULL,
+    created_by     INTEGER,
+    created_at     TIMESTAMP NOT NULL DEFAULT now()
+);
+
+ALTER TABLE reservation_notes
+    ADD CONSTRAINT fk_reservation_notes_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE reservation_notes
+    ADD CONSTRAINT fk_reservation_notes_created_by
+        FOREIGN KEY (created_by)
+            REFERENCES users(id)
+            ON DELETE SET NULL ON UPDATE CASCADE;
+
+CREATE INDEX reservation_notes_reservation_id_idx ON reservation_notes (reservation_id);
+
+-- free-form tags, stored lowercase
+CREATE TABLE IF NOT EXISTS reservation_tags (
+    reservation_id INTEGER NOT NULL,
+    tag            VARCHAR(64) NOT NULL,
+    PRIMARY KEY (reservation_id, tag)
+);
+
+ALTER TABLE reservation_tags
+    ADD CONSTRAINT fk_reservation_tags_reservation_id
+        FOREIGN KEY (reservation_id)
+            REFERENCES reservations(id)
+            ON DELETE CASCADE ON UPDATE CASCADE;
+
+CREATE INDEX reservation_tags_tag_idx ON reservation_tags (tag);
@@ -0,0 +1,32 @@
+DROP TRIGGER IF EXISTS audit_room_restrictions_delete_trigger_ ON room_restrictions;
+DROP TRIGGER IF EXISTS audit_room_restrictions_update_trigger_ ON room_restrictions;
+DROP TRIGGER IF EXISTS audit_room_restrictions_insert_trigger_ ON room_restrictions;
+DROP TRIGGER IF EXISTS audit_users_trigger_ ON users;
+DROP TRIGGER IF EXISTS audit_waitlist_entries_trigger_ ON waitlist_entries;
+DROP TRIGGER IF EXISTS audit_currencies_trigger_ ON currencies;
+DROP TRIGGER IF EXISTS audit_add_ons_trigger_ ON add_ons;
+DROP TRIGGER IF EXISTS audit_taxes_trigger_ ON taxes;
+DROP TRIGGER IF EXISTS audit_cancellation_policies_trigger_ ON cancellation_policies;
+DROP TRIGGER IF EXISTS audit_housekeeping_tasks_trigger_ ON housekeeping_tasks;
+DROP TRIGGER IF EXISTS audit_out_of_order_periods_trigger_ ON out_of_order_periods;
+DROP TRIGGER IF EXISTS audit_rate_plans_trigger_ ON rate_plans;
+DROP TRIGGER IF EXISTS audit_rooms_trigger_ ON rooms;
+DROP TRIGGER IF EXISTS audit_guests_trigger_ ON guests;
+DROP TRIGGER IF EXISTS audit_invoices_trigger_ ON invoices;
+DROP TRIGGER IF EXISTS audit_payments_trigger_ ON payments;
+DROP TRIGGER IF EXISTS audit_folio_entries_trigger_ ON folio_entries;
+DROP TRIGGER IF EXISTS audit_reservation_taxes_trigger_ ON reservation_taxes;
+DROP TRIGGER IF EXISTS audit_reservation_add_ons_trigger_ ON reservation_add_ons;
+DROP TRIGGER IF EXISTS audit_reservation_tags_trigger_ ON reservation_tags;
+DROP TRIGGER IF EXISTS audit_reservation_notes_trigger_ ON reservation_notes;
+DROP TRIGGER IF EXISTS audit_reservation_groups_trigger_ ON reservation_groups;
+DROP TRIGGER IF EXISTS audit_reservations_trigger_ ON reservations;
+
+DROP FUNCTION IF EXISTS audit_row_change_();
+
+DROP TRIGGER IF EXISTS audit_entries_no_truncate_trigger_ ON audit_entries;
+DROP TRIGGER IF EXISTS audit_entries_no_update_trigger_ ON audit_entries;
+
+DROP FUNCTION IF EXISTS audit_entries_append_only_();
+
+DROP TABLE IF EXISTS audit_entries;
@@ -0,0 +1,158 @@
+-- append-only log of every change to the data staff and guests work with. The changes are recorded by triggers,
+-- the application tells who makes them and from where with transaction settings audit.user_id, audit.ip,
+-- audit.user_agent, audit.request and audit.request_id
+CREATE TABLE IF NOT EXISTS audit_entries (
+    id          BIGSERIAL NOT NULL PRIMARY KEY,
+    occurred_at TIMESTAMP NOT NULL DEFAULT now(),
+    user_id     INTEGER,
+    action      VARCHAR(16) NOT NULL,
+    entity      VARCHAR(64) NOT NULL,
+    entity_id   VARCHAR(64) NOT NULL DEFAULT '',
+    before      JSONB,
+    after       JSONB,
+    changes     JSONB,
+    ip          VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT '',
+    request     TEXT NOT NULL DEFAULT '',
+    request_id  VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX audit_entries_entity_idx ON audit_entries (entity, entity_id);
+
+CREATE INDEX audit_entries_occurred_at_idx ON audit_entries (occurred_at);
+
+-- history of reservations, rooms and guests includes rows which belong to them
+CREATE INDEX audit_entries_reservation_id_idx ON audit_entries ((COALESCE(after, before) ->> 'reservation_id'));
+
+CREATE INDEX audit_entries_room_id_idx ON audit_entries ((COALESCE(after, before) ->> 'room_id'));
+
+CREATE INDEX audit_entries_guest_id_idx ON audit_entries ((COALESCE(after, before) ->> 'guest_id'));
+
+CREATE OR REPLACE FUNCTION audit_entries_append_only_()
+    RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit log is append-only';
+END;
+$$
+    language PLPGSQL;
+
+CREATE TRIGGER audit_entries_no_update_trigger_ BEFORE UPDATE OR DELETE ON audit_entries
+    FOR EACH ROW EXECUTE PROCEDURE audit_entries_append_only_();
+
+CREATE TRIGGER audit_entries_no_truncate_trigger_ BEFORE TRUNCATE ON audit_entries
+    FOR EACH STATEMENT EXECUTE PROCEDURE audit_entries_append_only_();
+
+-- records the row before and after the change and, for updates, the columns which changed.
+-- Passwords are never recorded and updates which only touch updated_at are skipped
+CREATE OR REPLACE FUNCTION audit_row_change_()
+    RETURNS TRIGGER AS $$
+DECLARE
+    old_row JSONB;
+    new_row JSONB;
+    changed JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_row := to_jsonb(OLD) - 'password';
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_row := to_jsonb(NEW) - 'password';
+    END IF;
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(n.key, jsonb_build_array(o.value, n.value))
+        INTO changed
+        FROM jsonb_each(new_row) AS n
+            JOIN jsonb_each(old_row) AS o ON o.key = n.key
+        WHERE n.value IS DISTINCT FROM o.value
+          AND n.key <> 'updated_at';
+        IF changed IS NULL THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO audit_entries (user_id, action, entity, entity_id, before, after, changes,
+                           ip, user_agent, request, request_id)
+    VALUES (NULLIF(current_setting('audit.user_id', true), '')::INTEGER,
+            lower(TG_OP),
+            TG_TABLE_NAME,
+            COALESCE(new_row ->> 'id', old_row ->> 'id', new_row ->> 'reservation_id', old_row ->> 'reservation_id', ''),
+            old_row,
+            new_row,
+            changed,
+            COALESCE(current_setting('audit.ip', true), ''),
+            COALESCE(current_setting('audit.user_agent', true), ''),
+            COALESCE(current_setting('audit.request', true), ''),
+            COALESCE(current_setting('audit.request_id', true), ''));
+    RETURN NULL;
+END;
+$$
+    language PLPGSQL;
+
+CREATE TRIGGER audit_reservations_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservations
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_reservation_groups_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservation_groups
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_reservation_notes_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservation_notes
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_reservation_tags_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservation_tags
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_reservation_add_ons_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservation_add_ons
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_reservation_taxes_trigger_ AFTER INSERT OR UPDATE OR DELETE ON reservation_taxes
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_folio_entries_trigger_ AFTER INSERT OR UPDATE OR DELETE ON folio_entries
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_payments_trigger_ AFTER INSERT OR UPDATE OR DELETE ON payments
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_invoices_trigger_ AFTER INSERT OR UPDATE OR DELETE ON invoices
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_guests_trigger_ AFTER INSERT OR UPDATE OR DELETE ON guests
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_rooms_trigger_ AFTER INSERT OR UPDATE OR DELETE ON rooms
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_rate_plans_trigger_ AFTER INSERT OR UPDATE OR DELETE ON rate_plans
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_out_of_order_periods_trigger_ AFTER INSERT OR UPDATE OR DELETE ON out_of_order_periods
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_housekeeping_tasks_trigger_ AFTER INSERT OR UPDATE OR DELETE ON housekeeping_tasks
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_cancellation_policies_trigger_ AFTER INSERT OR UPDATE OR DELETE ON cancellation_policies
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_taxes_trigger_ AFTER INSERT OR UPDATE OR DELETE ON taxes
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_add_ons_trigger_ AFTER INSERT OR UPDATE OR DELETE ON add_ons
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_currencies_trigger_ AFTER INSERT OR UPDATE OR DELETE ON currencies
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_waitlist_entries_trigger_ AFTER INSERT OR UPDATE OR DELETE ON waitlist_entries
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_users_trigger_ AFTER INSERT OR UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE PROCEDURE audit_row_change_();
+
+-- blocks are audited, holds come and go with every visitor browsing rooms and are left out
+CREATE TRIGGER audit_room_restrictions_insert_trigger_ AFTER INSERT ON room_restrictions
+    FOR EACH ROW WHEN (NEW.restriction_id <> 3) EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_room_restrictions_update_trigger_ AFTER UPDATE ON room_restrictions
+    FOR EACH ROW WHEN (NEW.restriction_id <> 3) EXECUTE PROCEDURE audit_row_change_();
+
+CREATE TRIGGER audit_room_restrictions_delete_trigger_ AFTER DELETE ON room_restrictions
+    FOR EACH ROW WHEN (OLD.restriction_id <> 3) EXECUTE PROCEDURE audit_row_change_();
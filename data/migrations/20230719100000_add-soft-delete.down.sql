@@ -0,0 +1,16 @@
+DROP INDEX IF EXISTS room_restrictions_deleted_at_idx;
+DROP INDEX IF EXISTS reservation_groups_deleted_at_idx;
+DROP INDEX IF EXISTS reservations_deleted_at_idx;
+
+DELETE FROM room_restrictions WHERE deleted_at IS NOT NULL;
+DELETE FROM reservations WHERE deleted_at IS NOT NULL;
+DELETE FROM reservation_groups WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE IF EXISTS room_restrictions
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE IF EXISTS reservation_groups
+    DROP COLUMN IF EXISTS deleted_at;
+
+ALTER TABLE IF EXISTS reservations
+    DROP COLUMN IF EXISTS deleted_at;
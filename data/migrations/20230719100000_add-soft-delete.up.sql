@@ -0,0 +1,16 @@
+-- deleted reservations, groups and blocks go to the trash first. They can be restored from there
+-- until they are purged, by staff or automatically after the retention period
+ALTER TABLE IF EXISTS reservations
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE IF EXISTS reservation_groups
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+ALTER TABLE IF EXISTS room_restrictions
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX reservations_deleted_at_idx ON reservations (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX reservation_groups_deleted_at_idx ON reservation_groups (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE INDEX room_restrictions_deleted_at_idx ON room_restrictions (deleted_at) WHERE deleted_at IS NOT NULL;
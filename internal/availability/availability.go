@@ -0,0 +1,127 @@
+package availability
+
+import (
+	"github.com/porky256/course-project/internal/models"
+	"time"
+)
+
+// MaxShift is how many days before and after the requested stay are searched for free windows
+const MaxShift = 14
+
+const dayLayout = "2006-01-02"
+
+// Window is a stay of the requested length on other dates
+type Window struct {
+	StartDate time.Time     `json:"start_date"`
+	EndDate   time.Time     `json:"end_date"`
+	Rooms     []models.Room `json:"rooms"`
+}
+
+// Segment is a part of a split stay spent in one room
+type Segment struct {
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date"`
+	Room      models.Room `json:"room"`
+}
+
+// Suggestions are alternatives offered when no room is free for the whole requested stay
+type Suggestions struct {
+	Before    *Window   `json:"before,omitempty"`
+	After     *Window   `json:"after,omitempty"`
+	SplitStay []Segment `json:"split_stay,omitempty"`
+}
+
+// Empty reports if nothing could be suggested
+func (s Suggestions) Empty() bool {
+	return s.Before == nil && s.After == nil && len(s.SplitStay) == 0
+}
+
+// occupancy holds busy nights of every room
+type occupancy map[int]map[string]bool
+
+func newOccupancy(restrictions []models.RoomRestriction) occupancy {
+	busy := make(occupancy)
+	for _, rr := range restrictions {
+		if busy[rr.RoomID] == nil {
+			busy[rr.RoomID] = make(map[string]bool)
+		}
+		for night := rr.StartDate; night.Before(rr.EndDate); night = night.AddDate(0, 0, 1) {
+			busy[rr.RoomID][night.Format(dayLayout)] = true
+		}
+	}
+	return busy
+}
+
+// freeUntil returns the first night after start the room is busy, but not later than end
+func (o occupancy) freeUntil(roomID int, start, end time.Time) time.Time {
+	night := start
+	for night.Before(end) && !o[roomID][night.Format(dayLayout)] {
+		night = night.AddDate(0, 0, 1)
+	}
+	return night
+}
+
+// freeRooms returns rooms free for all nights from start to end
+func (o occupancy) freeRooms(rooms []models.Room, start, end time.Time) []models.Room {
+	var free []models.Room
+	for _, room := range rooms {
+		if !o.freeUntil(room.ID, start, end).Before(end) {
+			free = append(free, room)
+		}
+	}
+	return free
+}
+
+// Suggest looks for the nearest free windows of the same length before and after the requested stay
+// and for a way to split the stay between rooms. Restrictions must cover MaxShift days around the stay.
+// Windows starting before today are never suggested
+func Suggest(rooms []models.Room, restrictions []models.RoomRestriction, start, end, today time.Time) Suggestions {
+	busy := newOccupancy(restrictions)
+	var suggestions Suggestions
+
+	for shift := 1; shift <= MaxShift; shift++ {
+		s, e := start.AddDate(0, 0, -shift), end.AddDate(0, 0, -shift)
+		if s.Before(today) {
+			break
+		}
+		if free := busy.freeRooms(rooms, s, e); len(free) > 0 {
+			suggestions.Before = &Window{StartDate: s, EndDate: e, Rooms: free}
+			break
+		}
+	}
+
+	for shift := 1; shift <= MaxShift; shift++ {
+		s, e := start.AddDate(0, 0, shift), end.AddDate(0, 0, shift)
+		if free := busy.freeRooms(rooms, s, e); len(free) > 0 {
+			suggestions.After = &Window{StartDate: s, EndDate: e, Rooms: free}
+			break
+		}
+	}
+
+	suggestions.SplitStay = splitStay(busy, rooms, start, end)
+	return suggestions
+}
+
+// splitStay covers the stay with as few rooms as possible by always taking the room free for the longest time.
+// Returns nil if some night has no free room at all or the stay doesn't need splitting
+func splitStay(busy occupancy, rooms []models.Room, start, end time.Time) []Segment {
+	var segments []Segment
+	for current := start; current.Before(end); {
+		var best *models.Room
+		bestEnd := current
+		for i := range rooms {
+			if until := busy.freeUntil(rooms[i].ID, current, end); until.After(bestEnd) {
+				best, bestEnd = &rooms[i], until
+			}
+		}
+		if best == nil {
+			return nil
+		}
+		segments = append(segments, Segment{StartDate: current, EndDate: bestEnd, Room: *best})
+		current = bestEnd
+	}
+	if len(segments) < 2 {
+		return nil
+	}
+	return segments
+}
@@ -0,0 +1,13 @@
+package availability_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestAvailability(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Availability Suite")
+}
@@ -0,0 +1,90 @@
+package availability_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/availability"
+	"github.com/porky256/course-project/internal/models"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2050, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Availability", func() {
+	var rooms []models.Room
+	var today time.Time
+
+	BeforeEach(func() {
+		rooms = []models.Room{
+			{ID: 1, Name: "first"},
+			{ID: 2, Name: "second"},
+		}
+		today = day(1)
+	})
+
+	Context("Suggest", func() {
+		It("suggests nearest windows before and after", func() {
+			restrictions := []models.RoomRestriction{
+				{RoomID: 1, StartDate: day(8), EndDate: day(12)},
+				{RoomID: 2, StartDate: day(9), EndDate: day(13)},
+			}
+			suggestions := availability.Suggest(rooms, restrictions, day(10), day(12), today)
+
+			Expect(suggestions.Before).ToNot(BeNil())
+			Expect(suggestions.Before.StartDate).To(Equal(day(7)))
+			Expect(suggestions.Before.EndDate).To(Equal(day(9)))
+			Expect(suggestions.Before.Rooms).To(Equal([]models.Room{{ID: 2, Name: "second"}}))
+
+			Expect(suggestions.After).ToNot(BeNil())
+			Expect(suggestions.After.StartDate).To(Equal(day(12)))
+			Expect(suggestions.After.EndDate).To(Equal(day(14)))
+			Expect(suggestions.After.Rooms).To(Equal([]models.Room{{ID: 1, Name: "first"}}))
+		})
+
+		It("doesn't suggest windows in the past", func() {
+			restrictions := []models.RoomRestriction{
+				{RoomID: 1, StartDate: day(1), EndDate: day(5)},
+				{RoomID: 2, StartDate: day(1), EndDate: day(5)},
+			}
+			suggestions := availability.Suggest(rooms, restrictions, day(2), day(4), day(2))
+
+			Expect(suggestions.Before).To(BeNil())
+			Expect(suggestions.After.StartDate).To(Equal(day(5)))
+		})
+
+		It("splits stay between rooms", func() {
+			restrictions := []models.RoomRestriction{
+				{RoomID: 1, StartDate: day(12), EndDate: day(14)},
+				{RoomID: 2, StartDate: day(10), EndDate: day(11)},
+			}
+			suggestions := availability.Suggest(rooms, restrictions, day(10), day(14), today)
+
+			Expect(suggestions.SplitStay).To(Equal([]availability.Segment{
+				{StartDate: day(10), EndDate: day(12), Room: rooms[0]},
+				{StartDate: day(12), EndDate: day(14), Room: rooms[1]},
+			}))
+		})
+
+		It("doesn't split stay when some night is fully booked", func() {
+			restrictions := []models.RoomRestriction{
+				{RoomID: 1, StartDate: day(11), EndDate: day(12)},
+				{RoomID: 2, StartDate: day(11), EndDate: day(12)},
+			}
+			suggestions := availability.Suggest(rooms, restrictions, day(10), day(13), today)
+
+			Expect(suggestions.SplitStay).To(BeNil())
+		})
+
+		It("returns empty suggestions when everything is booked", func() {
+			restrictions := []models.RoomRestriction{
+				{RoomID: 1, StartDate: day(1), EndDate: day(31)},
+				{RoomID: 2, StartDate: day(1), EndDate: day(31)},
+			}
+			suggestions := availability.Suggest(rooms, restrictions, day(10), day(12), today)
+
+			Expect(suggestions.Empty()).To(Equal(true))
+		})
+	})
+})
@@ -2,9 +2,12 @@ package config
 
 import (
 	"github.com/alexedwards/scs/v2"
+	"github.com/porky256/course-project/internal/currency"
+	"github.com/porky256/course-project/internal/frontdesk"
 	"github.com/porky256/course-project/internal/models"
 	"html/template"
 	"log"
+	"time"
 )
 
 type AppConfig struct {
@@ -17,4 +20,10 @@ type AppConfig struct {
 	ErrorLog      *log.Logger
 	DateLayout    string
 	MailChan      chan models.MailData
+	HoldDuration  time.Duration
+	Property      models.Property
+	Currencies    *currency.Table
+	FrontDesk     frontdesk.Hours
+	// TrashRetention is how long deleted reservations and blocks can be restored before they are purged
+	TrashRetention time.Duration
 }
@@ -0,0 +1,108 @@
+package currency
+
+import (
+	"errors"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrBadRate is returned when an exchange rate can't be parsed
+var ErrBadRate = errors.New("exchange rate must be a positive number")
+
+var codePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// Table is the exchange rate table prices are shown with. Charges are always made in the base currency,
+// other currencies are for display only. It is safe for concurrent use
+type Table struct {
+	mu         sync.RWMutex
+	base       models.Currency
+	currencies []models.Currency
+}
+
+// NewTable creates a table holding only the base currency
+func NewTable(base models.Currency) *Table {
+	base.Rate = 1
+	return &Table{base: base}
+}
+
+// Base returns the base currency
+func (t *Table) Base() models.Currency {
+	return t.base
+}
+
+// Set replaces the exchange rates, a rate of the base currency is ignored
+func (t *Table) Set(currencies []models.Currency) {
+	kept := make([]models.Currency, 0, len(currencies))
+	for _, c := range currencies {
+		if c.Code != t.base.Code {
+			kept = append(kept, c)
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.currencies = kept
+}
+
+// All returns the base currency followed by the other currencies
+func (t *Table) All() []models.Currency {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return append([]models.Currency{t.base}, t.currencies...)
+}
+
+// Get returns the currency with the code, the base currency is returned for unknown codes
+func (t *Table) Get(code string) models.Currency {
+	c, _ := t.Lookup(code)
+	return c
+}
+
+// Lookup returns the currency with the code and whether it is in the table
+func (t *Table) Lookup(code string) (models.Currency, bool) {
+	if code == t.base.Code {
+		return t.base, true
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, c := range t.currencies {
+		if c.Code == code {
+			return c, true
+		}
+	}
+	return t.base, false
+}
+
+// ValidCode reports whether code looks like an ISO 4217 code
+func ValidCode(code string) bool {
+	return codePattern.MatchString(code)
+}
+
+// ParseRate parses an exchange rate like 0.92
+func ParseRate(s string) (float64, error) {
+	rate, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || !(rate > 0) || math.IsInf(rate, 0) {
+		return 0, ErrBadRate
+	}
+	return rate, nil
+}
+
+// Convert converts base currency cents into cents of the currency
+func Convert(cents int, c models.Currency) int {
+	if c.Rate <= 0 {
+		return cents
+	}
+	return int(math.Round(float64(cents) * c.Rate))
+}
+
+// Format converts base currency cents into the currency and formats them with its symbol
+func Format(cents int, c models.Currency) string {
+	converted := Convert(cents, c)
+	if converted < 0 {
+		return "-" + c.Symbol + pricing.FormatCents(-converted)
+	}
+	return c.Symbol + pricing.FormatCents(converted)
+}
@@ -0,0 +1,52 @@
+package currency_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/currency"
+	"github.com/porky256/course-project/internal/models"
+)
+
+var _ = Describe("Currency", func() {
+	var table *currency.Table
+	euro := models.Currency{ID: 1, Code: "EUR", Symbol: "€", Rate: 0.68}
+	pound := models.Currency{ID: 2, Code: "GBP", Symbol: "£", Rate: 0.585}
+
+	BeforeEach(func() {
+		table = currency.NewTable(models.Currency{Code: "CAD", Symbol: "$"})
+	})
+
+	It("keeps the base currency first and ignores its rate", func() {
+		table.Set([]models.Currency{euro, {Code: "CAD", Symbol: "C$", Rate: 2}, pound})
+		Expect(table.All()).To(Equal([]models.Currency{
+			{Code: "CAD", Symbol: "$", Rate: 1}, euro, pound,
+		}))
+	})
+
+	It("falls back to the base currency for unknown codes", func() {
+		table.Set([]models.Currency{euro})
+		Expect(table.Get("EUR")).To(Equal(euro))
+		_, ok := table.Lookup("GBP")
+		Expect(ok).To(BeFalse())
+		Expect(table.Get("GBP")).To(Equal(table.Base()))
+		Expect(table.Get("")).To(Equal(table.Base()))
+	})
+
+	It("converts and formats base cents", func() {
+		Expect(currency.Convert(12345, euro)).To(Equal(8395))
+		Expect(currency.Format(12345, euro)).To(Equal("€83.95"))
+		Expect(currency.Format(-20000, pound)).To(Equal("-£117.00"))
+		Expect(currency.Format(12345, table.Base())).To(Equal("$123.45"))
+	})
+
+	It("parses rates and codes", func() {
+		Expect(currency.ParseRate(" 0.92 ")).To(Equal(0.92))
+		for _, bad := range []string{"", "abc", "0", "-1", "Inf", "NaN"} {
+			_, err := currency.ParseRate(bad)
+			Expect(err).To(MatchError(currency.ErrBadRate))
+		}
+		Expect(currency.ValidCode("EUR")).To(BeTrue())
+		Expect(currency.ValidCode("eur")).To(BeFalse())
+		Expect(currency.ValidCode("EURO")).To(BeFalse())
+	})
+})
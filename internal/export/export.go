@@ -0,0 +1,193 @@
+package export
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"io"
+	"strings"
+	"time"
+)
+
+// Formats of the export files
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+)
+
+// ErrUnknownFormat is returned for an export format which doesn't exist
+var ErrUnknownFormat = errors.New("unknown export format")
+
+// Money is an amount in cents, files show it in currency units
+type Money int
+
+// Column is a column of the export file
+type Column struct {
+	Title string
+	// Value is a string, an int, Money or a date
+	Value func(res models.Reservation) interface{}
+}
+
+// Group is a set of columns which are picked together
+type Group struct {
+	Key     string
+	Title   string
+	Columns []Column
+}
+
+// keyColumns start every export, so rows can be traced back to their reservations
+var keyColumns = []Column{
+	{"ID", func(res models.Reservation) interface{} { return res.ID }},
+	{"Confirmation code", func(res models.Reservation) interface{} { return res.ConfirmationCode }},
+}
+
+// Groups are the columns which can be picked for the export, in the order they are written
+var Groups = []Group{
+	{Key: "guest", Title: "Guest", Columns: []Column{
+		{"First name", func(res models.Reservation) interface{} { return res.FirstName }},
+		{"Last name", func(res models.Reservation) interface{} { return res.LastName }},
+		{"Email", func(res models.Reservation) interface{} { return res.Email }},
+		{"Phone", func(res models.Reservation) interface{} { return res.Phone }},
+	}},
+	{Key: "room", Title: "Room", Columns: []Column{
+		{"Room", func(res models.Reservation) interface{} {
+			if res.Room == nil {
+				return ""
+			}
+			return res.Room.Name
+		}},
+	}},
+	{Key: "dates", Title: "Dates", Columns: []Column{
+		{"Arrival", func(res models.Reservation) interface{} { return res.StartDate }},
+		{"Departure", func(res models.Reservation) interface{} { return res.EndDate }},
+	}},
+	{Key: "nights", Title: "Nights", Columns: []Column{
+		{"Nights", func(res models.Reservation) interface{} { return pricing.Nights(res.StartDate, res.EndDate) }},
+	}},
+	{Key: "status", Title: "Status", Columns: []Column{
+		{"Status", func(res models.Reservation) interface{} {
+			if res.Status == "" {
+				return models.ReservationConfirmed
+			}
+			return res.Status
+		}},
+		{"Payment", func(res models.Reservation) interface{} { return res.PaymentStatus }},
+		{"Source", func(res models.Reservation) interface{} {
+			if res.Source == "" {
+				return models.SourceWebsite
+			}
+			return res.Source
+		}},
+	}},
+	{Key: "totals", Title: "Totals", Columns: []Column{
+		{"Charged", func(res models.Reservation) interface{} { return Money(res.Charged) }},
+		{"Paid", func(res models.Reservation) interface{} { return Money(res.Charged - res.Balance) }},
+		{"Balance", func(res models.Reservation) interface{} { return Money(res.Balance) }},
+	}},
+}
+
+// Select returns the columns of the picked groups, unknown keys are skipped and picking nothing picks all groups
+func Select(keys []string) []Column {
+	picked := make(map[string]bool)
+	for _, key := range keys {
+		picked[key] = true
+	}
+	columns := append([]Column{}, keyColumns...)
+	for _, group := range Groups {
+		if len(keys) == 0 || picked[group.Key] {
+			columns = append(columns, group.Columns...)
+		}
+	}
+	return columns
+}
+
+// Titles returns the header of the columns
+func Titles(columns []Column) []string {
+	titles := make([]string, len(columns))
+	for i, column := range columns {
+		titles[i] = column.Title
+	}
+	return titles
+}
+
+// Row returns the values of the columns for the reservation
+func Row(columns []Column, res models.Reservation) []interface{} {
+	row := make([]interface{}, len(columns))
+	for i, column := range columns {
+		row[i] = column.Value(res)
+	}
+	return row
+}
+
+// Writer writes an export file row by row, so nothing but the current row is kept in memory
+type Writer interface {
+	Header(titles []string) error
+	Write(row []interface{}) error
+	// Close finishes the file, it is broken without it
+	Close() error
+}
+
+// ContentType returns the MIME type of the format, nothing for an unknown format
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return ""
+	}
+}
+
+// NewWriter returns a writer of the format writing to w
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case XLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+// csvWriter writes comma separated values
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) Header(titles []string) error {
+	return c.w.Write(titles)
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	record := make([]string, len(row))
+	for i, value := range row {
+		switch v := value.(type) {
+		case string:
+			record[i] = safeText(v)
+		case Money:
+			record[i] = pricing.FormatCents(int(v))
+		case time.Time:
+			record[i] = v.Format("2006-01-02")
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// safeText keeps spreadsheets from running text typed by guests as a formula
+func safeText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
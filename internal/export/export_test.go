@@ -0,0 +1,97 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/export"
+	"github.com/porky256/course-project/internal/models"
+	"io"
+	"time"
+)
+
+var _ = Describe("Export", func() {
+	res := models.Reservation{
+		ID:               7,
+		ConfirmationCode: "ABC123",
+		FirstName:        "=HYPERLINK(\"x\")",
+		LastName:         "Smith & Sons",
+		StartDate:        time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:          time.Date(2023, 7, 4, 0, 0, 0, 0, time.UTC),
+		Room:             &models.Room{Name: "General's Quarters"},
+		Charged:          30000,
+		Balance:          5050,
+	}
+
+	Context("Select", func() {
+		It("picks the groups in their order after the key columns", func() {
+			Expect(export.Titles(export.Select([]string{"totals", "nights", "unknown"}))).
+				To(Equal([]string{"ID", "Confirmation code", "Nights", "Charged", "Paid", "Balance"}))
+		})
+		It("picks every group when nothing is picked", func() {
+			Expect(export.Select(nil)).To(HaveLen(2 + 4 + 1 + 2 + 1 + 3 + 3))
+		})
+		It("reads the values", func() {
+			row := export.Row(export.Select([]string{"room", "nights", "status", "totals"}), res)
+			Expect(row).To(Equal([]interface{}{7, "ABC123", "General's Quarters", 3,
+				"confirmed", "", "website", export.Money(30000), export.Money(24950), export.Money(5050)}))
+		})
+	})
+
+	Context("CSV", func() {
+		It("writes rows and keeps formulas as text", func() {
+			var buf bytes.Buffer
+			w, err := export.NewWriter(export.CSV, &buf)
+			Expect(err).ToNot(HaveOccurred())
+			columns := export.Select([]string{"guest", "dates", "totals"})
+			Expect(w.Header(export.Titles(columns))).To(Succeed())
+			Expect(w.Write(export.Row(columns, res))).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+			Expect(buf.String()).To(Equal("ID,Confirmation code,First name,Last name,Email,Phone,Arrival,Departure,Charged,Paid,Balance\n" +
+				"7,ABC123,\"'=HYPERLINK(\"\"x\"\")\",Smith & Sons,,,2023-07-01,2023-07-04,300.00,249.50,50.50\n"))
+		})
+	})
+
+	Context("XLSX", func() {
+		It("writes a workbook", func() {
+			var buf bytes.Buffer
+			w, err := export.NewWriter(export.XLSX, &buf)
+			Expect(err).ToNot(HaveOccurred())
+			columns := export.Select([]string{"guest", "dates", "totals"})
+			Expect(w.Header(export.Titles(columns))).To(Succeed())
+			Expect(w.Write(export.Row(columns, res))).To(Succeed())
+			Expect(w.Close()).To(Succeed())
+
+			archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+			Expect(err).ToNot(HaveOccurred())
+			names := make([]string, 0)
+			var sheet string
+			for _, f := range archive.File {
+				names = append(names, f.Name)
+				if f.Name == "xl/worksheets/sheet1.xml" {
+					r, err := f.Open()
+					Expect(err).ToNot(HaveOccurred())
+					content, err := io.ReadAll(r)
+					Expect(err).ToNot(HaveOccurred())
+					sheet = string(content)
+				}
+			}
+			Expect(names).To(ConsistOf("[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+				"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"))
+			Expect(sheet).To(ContainSubstring(`<c t="inlineStr" s="3"><is><t xml:space="preserve">ID</t></is></c>`))
+			Expect(sheet).To(ContainSubstring(`<c><v>7</v></c>`))
+			Expect(sheet).To(ContainSubstring(`<t xml:space="preserve">=HYPERLINK(&#34;x&#34;)</t>`))
+			Expect(sheet).To(ContainSubstring(`<t xml:space="preserve">Smith &amp; Sons</t>`))
+			Expect(sheet).To(ContainSubstring(`<c s="1"><v>45108</v></c>`))
+			Expect(sheet).To(ContainSubstring(`<c s="2"><v>249.50</v></c>`))
+			Expect(sheet).To(HaveSuffix("</sheetData></worksheet>"))
+		})
+	})
+
+	It("fails on an unknown format", func() {
+		_, err := export.NewWriter("pdf", io.Discard)
+		Expect(err).To(Equal(export.ErrUnknownFormat))
+		Expect(export.ContentType("pdf")).To(Equal(""))
+	})
+})
@@ -0,0 +1,83 @@
+package frontdesk
+
+import (
+	"github.com/porky256/course-project/internal/models"
+	"time"
+)
+
+// Hours are the front desk times, each one is how long after midnight of the day it comes
+type Hours struct {
+	// CheckIn is the earliest check-in on the arrival day
+	CheckIn time.Duration
+	// CheckOut is the latest check-out on the departure day
+	CheckOut time.Duration
+	// NoShow is when a guest who hasn't checked in counts as a no-show, usually past midnight of the next day
+	NoShow time.Duration
+}
+
+// Boards are reservations the front desk deals with on a day
+type Boards struct {
+	// Arrivals are guests due on the day or earlier who haven't checked in yet
+	Arrivals []models.Reservation
+	// InHouse are checked in guests staying on after the day
+	InHouse []models.Reservation
+	// Departures are checked in guests due to leave on the day or earlier
+	Departures []models.Reservation
+}
+
+// DateOf returns the day of t the way stay dates are stored
+func DateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// on returns the moment offset after midnight of day in the location
+func on(day time.Time, offset time.Duration, loc *time.Location) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc).Add(offset)
+}
+
+// Sort puts confirmed reservations which aren't checked out on the boards of the day
+func Sort(reservations []models.Reservation, day time.Time) Boards {
+	var boards Boards
+	for _, res := range reservations {
+		switch {
+		case !res.CheckedOutAt.IsZero() || res.Status == models.ReservationCancelled ||
+			res.Status == models.ReservationNoShow:
+		case res.CheckedInAt.IsZero():
+			if !res.StartDate.After(day) && res.EndDate.After(day) {
+				boards.Arrivals = append(boards.Arrivals, res)
+			}
+		case res.EndDate.After(day):
+			boards.InHouse = append(boards.InHouse, res)
+		default:
+			boards.Departures = append(boards.Departures, res)
+		}
+	}
+	return boards
+}
+
+// EarlyCheckIn tells if the guest checking in at the time comes before check-in hours of the arrival day
+func EarlyCheckIn(res models.Reservation, at time.Time, hours Hours) bool {
+	return at.Before(on(res.StartDate, hours.CheckIn, at.Location()))
+}
+
+// LateCheckOut tells if the guest checking out at the time leaves after check-out hours of the departure day
+func LateCheckOut(res models.Reservation, at time.Time, hours Hours) bool {
+	return at.After(on(res.EndDate, hours.CheckOut, at.Location()))
+}
+
+// EarlyDeparture tells if the guest checking out at the time leaves before the departure day
+func EarlyDeparture(res models.Reservation, at time.Time) bool {
+	return DateOf(at).Before(res.EndDate)
+}
+
+// NoShowDay returns the last arrival day whose guests are no-shows at the time if they haven't checked in
+func NoShowDay(now time.Time, hours Hours) time.Time {
+	return DateOf(now.Add(-hours.NoShow))
+}
+
+// NoShow tells if the guest of the reservation is a no-show once the arrival day passes the no-show cutoff.
+// Only arrivals of the day count, guests of earlier stays came already or were swept on their own day
+func NoShow(res models.Reservation, day time.Time) bool {
+	return res.Status != models.ReservationCancelled && res.Status != models.ReservationNoShow &&
+		res.CheckedInAt.IsZero() && res.CheckedOutAt.IsZero() && res.StartDate.Equal(day)
+}
@@ -0,0 +1,13 @@
+package frontdesk_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFrontDesk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Front Desk Suite")
+}
@@ -0,0 +1,95 @@
+package frontdesk_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/frontdesk"
+	"github.com/porky256/course-project/internal/models"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2050, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+func at(d, hour, minute int) time.Time {
+	return time.Date(2050, 1, d, hour, minute, 0, 0, time.UTC)
+}
+
+var _ = Describe("Front desk", func() {
+	hours := frontdesk.Hours{CheckIn: 15 * time.Hour, CheckOut: 11 * time.Hour, NoShow: 26 * time.Hour}
+
+	Context("Sort", func() {
+		It("puts reservations on boards of the day", func() {
+			reservations := []models.Reservation{
+				{ID: 1, StartDate: day(10), EndDate: day(12)},
+				{ID: 2, StartDate: day(9), EndDate: day(12)},
+				{ID: 3, StartDate: day(11), EndDate: day(12)},
+				{ID: 4, StartDate: day(8), EndDate: day(12), CheckedInAt: at(8, 16, 0)},
+				{ID: 5, StartDate: day(8), EndDate: day(10), CheckedInAt: at(8, 16, 0)},
+				{ID: 6, StartDate: day(7), EndDate: day(9), CheckedInAt: at(7, 16, 0)},
+				{ID: 7, StartDate: day(8), EndDate: day(10), CheckedInAt: at(8, 16, 0), CheckedOutAt: at(10, 9, 0)},
+				{ID: 8, StartDate: day(10), EndDate: day(12), Status: models.ReservationNoShow},
+				{ID: 9, StartDate: day(9), EndDate: day(10)},
+			}
+			boards := frontdesk.Sort(reservations, day(10))
+
+			ids := func(list []models.Reservation) []int {
+				var result []int
+				for _, res := range list {
+					result = append(result, res.ID)
+				}
+				return result
+			}
+			Expect(ids(boards.Arrivals)).To(Equal([]int{1, 2}))
+			Expect(ids(boards.InHouse)).To(Equal([]int{4}))
+			Expect(ids(boards.Departures)).To(Equal([]int{5, 6}))
+		})
+	})
+
+	Context("early and late", func() {
+		res := models.Reservation{StartDate: day(10), EndDate: day(12)}
+
+		It("tells early check-in", func() {
+			Expect(frontdesk.EarlyCheckIn(res, at(10, 14, 59), hours)).To(BeTrue())
+			Expect(frontdesk.EarlyCheckIn(res, at(10, 15, 0), hours)).To(BeFalse())
+			Expect(frontdesk.EarlyCheckIn(res, at(11, 9, 0), hours)).To(BeFalse())
+		})
+
+		It("tells late check-out", func() {
+			Expect(frontdesk.LateCheckOut(res, at(12, 11, 0), hours)).To(BeFalse())
+			Expect(frontdesk.LateCheckOut(res, at(12, 11, 1), hours)).To(BeTrue())
+			Expect(frontdesk.LateCheckOut(res, at(11, 20, 0), hours)).To(BeFalse())
+		})
+
+		It("tells early departure", func() {
+			Expect(frontdesk.EarlyDeparture(res, at(11, 10, 0))).To(BeTrue())
+			Expect(frontdesk.EarlyDeparture(res, at(12, 10, 0))).To(BeFalse())
+		})
+	})
+
+	Context("NoShow", func() {
+		It("counts arrivals of the day who haven't checked in", func() {
+			Expect(frontdesk.NoShow(models.Reservation{StartDate: day(10), EndDate: day(12),
+				Status: models.ReservationConfirmed}, day(10))).To(BeTrue())
+			Expect(frontdesk.NoShow(models.Reservation{StartDate: day(10), EndDate: day(12),
+				Status: models.ReservationCancelled}, day(10))).To(BeFalse())
+			Expect(frontdesk.NoShow(models.Reservation{StartDate: day(10), EndDate: day(12),
+				CheckedInAt: at(10, 16, 0)}, day(10))).To(BeFalse())
+		})
+
+		It("leaves past and in-house stays alone", func() {
+			Expect(frontdesk.NoShow(models.Reservation{StartDate: day(5), EndDate: day(7),
+				Status: models.ReservationConfirmed}, day(10))).To(BeFalse())
+			Expect(frontdesk.NoShow(models.Reservation{StartDate: day(8), EndDate: day(12),
+				Status: models.ReservationConfirmed}, day(10))).To(BeFalse())
+		})
+	})
+
+	Context("NoShowDay", func() {
+		It("returns last arrival day past the cutoff", func() {
+			Expect(frontdesk.NoShowDay(at(11, 1, 59), hours)).To(Equal(day(9)))
+			Expect(frontdesk.NoShowDay(at(11, 2, 1), hours)).To(Equal(day(10)))
+		})
+	})
+})
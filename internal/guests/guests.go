@@ -0,0 +1,163 @@
+package guests
+
+import (
+	"github.com/porky256/course-project/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// similarNames is how close two names must be to count as the same person spelled differently
+const similarNames = 0.85
+
+// similarEmails is how close two normalized emails must be to count as a typo of each other
+const similarEmails = 0.9
+
+// Duplicate is a pair of guest profiles which may be the same person
+type Duplicate struct {
+	A, B models.Guest
+	// Reasons tell the admin why the pair was picked
+	Reasons []string
+}
+
+// NormalizeEmail returns the mailbox the email delivers to. Case, spaces and +tags are dropped,
+// and so are dots for Gmail which ignores them
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return email
+	}
+	local, domain := email[:at], email[at+1:]
+	if plus := strings.Index(local, "+"); plus > 0 {
+		local = local[:plus]
+	}
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		local = strings.ReplaceAll(local, ".", "")
+		domain = "gmail.com"
+	}
+	return local + "@" + domain
+}
+
+// NormalizePhone keeps the digits of the phone. Numbers longer than ten digits lose the country code,
+// so the same number typed with and without it matches
+func NormalizePhone(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		}
+	}
+	result := digits.String()
+	if len(result) > 10 {
+		result = result[len(result)-10:]
+	}
+	return result
+}
+
+// normalizeName lowercases the name and keeps letters only
+func normalizeName(name string) string {
+	var letters strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) {
+			letters.WriteRune(r)
+		}
+	}
+	return letters.String()
+}
+
+// distance is the Levenshtein distance between a and b
+func distance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minOf(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// minOf returns the smallest of the values
+func minOf(values ...int) int {
+	result := values[0]
+	for _, v := range values[1:] {
+		if v < result {
+			result = v
+		}
+	}
+	return result
+}
+
+// similarity is 1 for equal strings and goes down to 0 as more edits are needed to turn one into the other
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+	return 1 - float64(distance(ra, rb))/float64(longest)
+}
+
+// NameSimilarity tells how alike the full names of two guests are, from 0 to 1.
+// First and last name swapped around count as the same name
+func NameSimilarity(a, b models.Guest) float64 {
+	first, last := normalizeName(a.FirstName), normalizeName(a.LastName)
+	other := normalizeName(b.FirstName) + normalizeName(b.LastName)
+	straight := similarity(first+last, other)
+	swapped := similarity(last+first, other)
+	if swapped > straight {
+		return swapped
+	}
+	return straight
+}
+
+// reasons returns why a and b may be the same guest, nothing if they don't look alike
+func reasons(a, b models.Guest) []string {
+	var result []string
+	emailA, emailB := NormalizeEmail(a.Email), NormalizeEmail(b.Email)
+	if emailA != "" && emailB != "" {
+		if emailA == emailB {
+			result = append(result, "same email")
+		} else if similarity(emailA, emailB) >= similarEmails {
+			result = append(result, "similar email")
+		}
+	}
+	phoneA, phoneB := NormalizePhone(a.Phone), NormalizePhone(b.Phone)
+	if len(phoneA) >= 7 && phoneA == phoneB {
+		result = append(result, "same phone")
+	}
+	if NameSimilarity(a, b) >= similarNames {
+		result = append(result, "similar name")
+	}
+	return result
+}
+
+// FindDuplicates pairs up guests which may be the same person. Pairs with more reasons come first
+func FindDuplicates(list []models.Guest) []Duplicate {
+	duplicates := make([]Duplicate, 0)
+	for i := range list {
+		for j := i + 1; j < len(list); j++ {
+			why := reasons(list[i], list[j])
+			if len(why) > 0 {
+				duplicates = append(duplicates, Duplicate{A: list[i], B: list[j], Reasons: why})
+			}
+		}
+	}
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		return len(duplicates[i].Reasons) > len(duplicates[j].Reasons)
+	})
+	return duplicates
+}
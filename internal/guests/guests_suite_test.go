@@ -0,0 +1,13 @@
+package guests_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGuests(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Guests Suite")
+}
@@ -0,0 +1,69 @@
+package guests_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/guests"
+	"github.com/porky256/course-project/internal/models"
+)
+
+var _ = Describe("Guests", func() {
+	Context("NormalizeEmail", func() {
+		It("drops case, spaces and tags", func() {
+			Expect(guests.NormalizeEmail(" John.Smith+Hotel@Example.com ")).To(Equal("john.smith@example.com"))
+		})
+		It("drops dots for gmail", func() {
+			Expect(guests.NormalizeEmail("john.smith@googlemail.com")).To(Equal("johnsmith@gmail.com"))
+		})
+		It("keeps what isn't an email", func() {
+			Expect(guests.NormalizeEmail("")).To(Equal(""))
+			Expect(guests.NormalizeEmail("@example.com")).To(Equal("@example.com"))
+		})
+	})
+
+	Context("NormalizePhone", func() {
+		It("keeps digits without the country code", func() {
+			Expect(guests.NormalizePhone("+1 (555) 123-4567")).To(Equal("5551234567"))
+			Expect(guests.NormalizePhone("555.123.4567")).To(Equal("5551234567"))
+		})
+	})
+
+	Context("NameSimilarity", func() {
+		It("scores names", func() {
+			john := models.Guest{FirstName: "John", LastName: "Smith"}
+			Expect(guests.NameSimilarity(john, models.Guest{FirstName: "john", LastName: "smith"})).To(Equal(1.0))
+			Expect(guests.NameSimilarity(john, models.Guest{FirstName: "Smith", LastName: "John"})).To(Equal(1.0))
+			Expect(guests.NameSimilarity(john, models.Guest{FirstName: "Jon", LastName: "Smith"})).
+				To(BeNumerically(">=", 0.85))
+			Expect(guests.NameSimilarity(john, models.Guest{FirstName: "Mary", LastName: "Jones"})).
+				To(BeNumerically("<", 0.5))
+		})
+	})
+
+	Context("FindDuplicates", func() {
+		It("pairs up guests which look alike", func() {
+			list := []models.Guest{
+				{ID: 1, FirstName: "John", LastName: "Smith", Email: "john.smith@gmail.com", Phone: "555-123-4567"},
+				{ID: 2, FirstName: "Jon", LastName: "Smith", Email: "johnsmith+trip@gmail.com", Phone: "+1 555 123 4567"},
+				{ID: 3, FirstName: "Mary", LastName: "Jones", Email: "mary@example.com"},
+				{ID: 4, FirstName: "Maria", LastName: "Lopez", Email: "mary@examle.com"},
+				{ID: 5, FirstName: "Peter", LastName: "Brown", Phone: "123"},
+				{ID: 6, FirstName: "Anna", LastName: "White", Phone: "123"},
+			}
+			duplicates := guests.FindDuplicates(list)
+			Expect(duplicates).To(HaveLen(2))
+			Expect(duplicates[0].A.ID).To(Equal(1))
+			Expect(duplicates[0].B.ID).To(Equal(2))
+			Expect(duplicates[0].Reasons).To(Equal([]string{"same email", "same phone", "similar name"}))
+			Expect(duplicates[1].A.ID).To(Equal(3))
+			Expect(duplicates[1].B.ID).To(Equal(4))
+			Expect(duplicates[1].Reasons).To(Equal([]string{"similar email"}))
+		})
+		It("finds nothing in different guests", func() {
+			Expect(guests.FindDuplicates([]models.Guest{
+				{ID: 1, FirstName: "John", LastName: "Smith"},
+				{ID: 2, FirstName: "Mary", LastName: "Jones"},
+			})).To(BeEmpty())
+		})
+	})
+})
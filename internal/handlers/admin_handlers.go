@@ -1,58 +1,583 @@
 package handlers
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/porky256/course-project/internal/currency"
+	"github.com/porky256/course-project/internal/export"
 	"github.com/porky256/course-project/internal/forms"
+	"github.com/porky256/course-project/internal/frontdesk"
+	"github.com/porky256/course-project/internal/guests"
+	"github.com/porky256/course-project/internal/helpers"
+	"github.com/porky256/course-project/internal/importer"
+	"github.com/porky256/course-project/internal/listing"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/payments"
+	"github.com/porky256/course-project/internal/pricing"
+	"github.com/porky256/course-project/internal/reports"
+	"github.com/porky256/course-project/internal/repository"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
 func (h *Handlers) AdminDashboard(w http.ResponseWriter, r *http.Request) {
-	err := h.render.Template(w, r, "admin.dashboard.page.tmpl", &models.TemplateData{})
+	day := today()
+	stringMap := make(map[string]string)
+	stringMap["today"] = day.Format(h.app.DateLayout)
+	stringMap["check_in"] = time.Time{}.Add(h.app.FrontDesk.CheckIn).Format("15:04")
+	stringMap["check_out"] = time.Time{}.Add(h.app.FrontDesk.CheckOut).Format("15:04")
+
+	data := make(map[string]interface{})
+	reservations, err := h.DB.GetFrontDeskReservations(day)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get today's reservations")
+	} else {
+		data["boards"] = frontdesk.Sort(reservations, day)
+	}
+
+	err = h.render.Template(w, r, "admin.dashboard.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 	}
 }
 
 func (h *Handlers) AdminAllReservations(w http.ResponseWriter, r *http.Request) {
-	data := make(map[string]interface{})
-	reservations, err := h.DB.GetAllReservations()
+	list, err := h.reservationList(r.URL.Query(), "/admin/all-reservations")
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad filters")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+	reservations, total, err := h.DB.GetAllReservations(list.Query)
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 		h.app.Session.Put(r.Context(), "error", "can't get all reservations")
 		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 		return
 	}
-	data["reservations"] = reservations
-	err = h.render.Template(w, r, "admin.all-reservations.page.tmpl", &models.TemplateData{
-		Data: data,
+	list.Total = total
+	h.renderReservationList(w, r, "admin.all-reservations.page.tmpl", list, reservations)
+}
+
+func (h *Handlers) AdminNewReservations(w http.ResponseWriter, r *http.Request) {
+	list, err := h.reservationList(r.URL.Query(), "/admin/new-reservations")
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad filters")
+		http.Redirect(w, r, "/admin/new-reservations", http.StatusSeeOther)
+		return
+	}
+	reservations, total, err := h.DB.GetNewReservations(list.Query)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get new reservations")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	list.Total = total
+	h.renderReservationList(w, r, "admin.new-reservations.page.tmpl", list, reservations)
+}
+
+// reservationLists tells for every reservation list whether it only has new reservations
+var reservationLists = map[string]bool{
+	"all-reservations": false,
+	"new-reservations": true,
+}
+
+func (h *Handlers) AdminExportReservations(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.URL.Path, "/")
+	if len(exploded) != 4 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	onlyNew, ok := reservationLists[exploded[2]]
+	if !ok {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	path := "/admin/" + exploded[2]
+
+	list, err := h.reservationList(r.URL.Query(), path)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad filters")
+		http.Redirect(w, r, path, http.StatusSeeOther)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	contentType := export.ContentType(format)
+	if contentType == "" {
+		h.app.ErrorLog.Printf("unknown export format: %s", format)
+		h.app.Session.Put(r.Context(), "error", "unknown export format")
+		http.Redirect(w, r, list.Self(), http.StatusSeeOther)
+		return
+	}
+	columns := export.Select(r.URL.Query()["columns"])
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="reservations-%s.%s"`, today().Format(h.app.DateLayout), format))
+	writer, err := export.NewWriter(format, w)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		return
+	}
+	err = writer.Header(export.Titles(columns))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		return
+	}
+	err = h.DB.ExportReservations(list.Query, onlyNew, func(res models.Reservation) error {
+		return writer.Write(export.Row(columns, res))
 	})
+	if err != nil {
+		// the file is cut short, headers are already sent so there is no way to tell the admin
+		h.app.ErrorLog.Println(err)
+		return
+	}
+	err = writer.Close()
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 	}
 }
 
-func (h *Handlers) AdminNewReservations(w http.ResponseWriter, r *http.Request) {
+// reservationList reads the filters, sort and page of the reservation list at path from the URL query or a form
+func (h *Handlers) reservationList(values url.Values, path string) (listing.List, error) {
+	query, err := listing.Parse(values, h.app.DateLayout)
+	query.Tag = normalizeTag(query.Tag)
+	return listing.List{Path: path, Layout: h.app.DateLayout, Query: query}, err
+}
+
+// renderReservationList shows a page of a reservation list with the choices of its filters
+func (h *Handlers) renderReservationList(w http.ResponseWriter, r *http.Request, tmpl string,
+	list listing.List, reservations []models.Reservation) {
+	var err error
 	data := make(map[string]interface{})
-	reservations, err := h.DB.GetNewReservations()
+	data["reservations"] = reservations
+	data["list"] = list
+	data["statuses"] = listing.Statuses
+	data["sources"] = listing.Sources
+	data["per_page"] = listing.PerPageOptions
+	data["export_groups"] = export.Groups
+	data["tags"], err = h.DB.GetAllTags()
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "can't get new reservations")
+	}
+	data["rooms"], err = h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	stringMap := make(map[string]string)
+	stringMap["tag"] = list.Query.Tag
+	if !list.Query.From.IsZero() {
+		stringMap["from"] = list.Query.From.Format(h.app.DateLayout)
+	}
+	if !list.Query.To.IsZero() {
+		stringMap["to"] = list.Query.To.Format(h.app.DateLayout)
+	}
+	err = h.render.Template(w, r, tmpl, &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// errBulkCancelled is returned for a cancelled reservation a bulk action can't be made to
+var errBulkCancelled = errors.New("reservation is cancelled")
+
+// errBulkNoEmail is returned for a reservation whose guest left no email to send the confirmation to
+var errBulkNoEmail = errors.New("guest has no email")
+
+// bulkActions are the actions which can be made to the picked reservations, with what the summary says they did
+var bulkActions = map[string]string{
+	models.BulkProcess: "are marked as processed",
+	models.BulkConfirm: "are confirmed",
+	models.BulkCancel:  "are cancelled",
+	models.BulkTag:     "are tagged",
+	"resend":           "got their confirmation again",
+	"export":           "",
+}
+
+func (h *Handlers) AdminPostBulkReservations(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.URL.Path, "/")
+	if len(exploded) != 4 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
 		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 		return
 	}
-	data["reservations"] = reservations
-	err = h.render.Template(w, r, "admin.new-reservations.page.tmpl", &models.TemplateData{
-		Data: data,
+	if _, ok := reservationLists[exploded[2]]; !ok {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	path := "/admin/" + exploded[2]
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, path, http.StatusSeeOther)
+		return
+	}
+	list, err := h.reservationList(r.PostForm, path)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad filters")
+		http.Redirect(w, r, path, http.StatusSeeOther)
+		return
+	}
+	ids := list.Query.IDs
+	list.Query.IDs = nil
+	redirectString := list.Self()
+
+	action := r.PostForm.Get("action")
+	done, ok := bulkActions[action]
+	if !ok {
+		h.app.ErrorLog.Printf("unknown bulk action: %s", action)
+		h.app.Session.Put(r.Context(), "error", "unknown bulk action")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if len(ids) == 0 {
+		h.app.Session.Put(r.Context(), "error", "pick reservations first")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if largest := listing.PerPageOptions[len(listing.PerPageOptions)-1]; len(ids) > largest {
+		h.app.Session.Put(r.Context(), "error", fmt.Sprintf("pick at most %d reservations", largest))
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	if action == "export" {
+		query := list.Query
+		query.IDs = ids
+		values := listing.Values(query, h.app.DateLayout)
+		values.Del("page")
+		values.Del("per_page")
+		values.Set("format", r.PostForm.Get("format"))
+		http.Redirect(w, r, path+"/export?"+values.Encode(), http.StatusSeeOther)
+		return
+	}
+
+	change := models.BulkChange{Action: action}
+	var failed map[int]error
+	switch action {
+	case models.BulkTag:
+		change.Tag = normalizeTag(r.PostForm.Get("new_tag"))
+		if change.Tag == "" || len(change.Tag) > maxTagLength {
+			h.app.Session.Put(r.Context(), "error", fmt.Sprintf("tag must be 1 to %d characters long", maxTagLength))
+			http.Redirect(w, r, redirectString, http.StatusSeeOther)
+			return
+		}
+		failed, err = h.repo(r).BulkUpdateReservations(ids, change)
+	case models.BulkProcess, models.BulkConfirm:
+		failed, err = h.repo(r).BulkUpdateReservations(ids, change)
+	case models.BulkCancel:
+		var reservations []models.Reservation
+		reservations, err = h.DB.GetReservationsByIDs(ids)
+		if err != nil {
+			break
+		}
+		now := time.Now()
+		change.Fees = make(map[int]int, len(reservations))
+		for _, res := range reservations {
+			change.Fees[res.ID] = pricing.CancellationFee(res, res.CancellationPolicy, now)
+		}
+		failed, err = h.repo(r).BulkUpdateReservations(ids, change)
+		if err != nil {
+			break
+		}
+		fees := 0
+		for _, res := range reservations {
+			if failed[res.ID] == nil {
+				fees += change.Fees[res.ID]
+				h.notifyWaitlist(r, res.RoomID, res.StartDate, res.EndDate)
+			}
+		}
+		done += ", cancellation fees come to " + currency.Format(fees, h.app.Currencies.Base())
+	case "resend":
+		failed, err = h.resendConfirmations(ids)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update reservations, nothing is changed")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.bulkSummary(r, len(ids), failed, done)
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+// resendConfirmations emails the guests of the reservations their confirmation again,
+// returning the reservations no email was sent for with the reason
+func (h *Handlers) resendConfirmations(ids []int) (map[int]error, error) {
+	reservations, err := h.DB.GetReservationsByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	failed := make(map[int]error)
+	for _, id := range ids {
+		failed[id] = repository.ErrReservationNotFound
+	}
+	for _, res := range reservations {
+		switch {
+		case res.Status == models.ReservationCancelled:
+			failed[res.ID] = errBulkCancelled
+		case res.Email == "":
+			failed[res.ID] = errBulkNoEmail
+		default:
+			delete(failed, res.ID)
+			h.sendConfirmation(res)
+		}
+	}
+	return failed, nil
+}
+
+// bulkSummary tells the admin how many of the picked reservations the bulk action changed,
+// and why it failed for the others
+func (h *Handlers) bulkSummary(r *http.Request, picked int, failed map[int]error, done string) {
+	ids := make([]int, 0, len(failed))
+	for id := range failed {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	failures := make([]string, len(ids))
+	for i, id := range ids {
+		failures[i] = fmt.Sprintf("#%d %v", id, failed[id])
+	}
+	if len(failed) == picked {
+		h.app.Session.Put(r.Context(), "error", "nothing is changed: "+strings.Join(failures, "; "))
+		return
+	}
+	h.app.Session.Put(r.Context(), "flash", fmt.Sprintf("%d of %d reservations %s", picked-len(failed), picked, done))
+	if len(failures) > 0 {
+		h.app.Session.Put(r.Context(), "warning",
+			fmt.Sprintf("%d failed: %s", len(failures), strings.Join(failures, "; ")))
+	}
+}
+
+func (h *Handlers) AdminCreateReservation(w http.ResponseWriter, r *http.Request) {
+	res := models.Reservation{Guests: 1, Source: models.SourcePhone}
+
+	query := r.URL.Query()
+	if query.Get("start") != "" || query.Get("end") != "" {
+		var err error
+		res.StartDate, err = time.Parse(h.app.DateLayout, query.Get("start"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad start time")
+			http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+			return
+		}
+		res.EndDate, err = time.Parse(h.app.DateLayout, query.Get("end"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad end time")
+			http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+			return
+		}
+		if !res.EndDate.After(res.StartDate) {
+			h.app.Session.Put(r.Context(), "error", "departure must be after arrival")
+			http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+			return
+		}
+	}
+
+	h.renderCreateReservation(w, r, forms.New(nil), res)
+}
+
+// renderCreateReservation renders the staff booking form with rooms free on the chosen dates, if there are any
+func (h *Handlers) renderCreateReservation(w http.ResponseWriter, r *http.Request, form *forms.Form,
+	res models.Reservation) {
+	data := make(map[string]interface{})
+	stringMap := make(map[string]string)
+	if !res.StartDate.IsZero() && res.EndDate.After(res.StartDate) {
+		rooms, err := h.DB.AvailabilityOfAllRooms(res.StartDate, res.EndDate)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't get rooms")
+			http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+			return
+		}
+		data["rooms"] = rooms
+		stringMap["start"] = res.StartDate.Format(h.app.DateLayout)
+		stringMap["end"] = res.EndDate.Format(h.app.DateLayout)
+	}
+	data["reservation"] = res
+
+	err := h.render.Template(w, r, "admin.create-reservation.page.tmpl", &models.TemplateData{
+		Form:      form,
+		Data:      data,
+		StringMap: stringMap,
 	})
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 	}
 }
 
+func (h *Handlers) AdminPostCreateReservation(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+		return
+	}
+
+	form := forms.New(r.PostForm)
+	res := models.Reservation{
+		FirstName:      form.Get("first_name"),
+		LastName:       form.Get("last_name"),
+		Email:          form.Get("email"),
+		Phone:          form.Get("phone"),
+		IsProcessed:    1,
+		Source:         form.Get("source"),
+		OverrideReason: strings.TrimSpace(form.Get("override_reason")),
+		CreatedBy:      h.app.Session.GetInt(r.Context(), "user_id"),
+	}
+
+	form.Required("first_name", "last_name", "start", "end", "room_id")
+	if form.Has("send_confirmation") {
+		form.Required("email")
+	}
+	if form.Has("email") {
+		form.IsEmail("email")
+	}
+	switch res.Source {
+	case models.SourcePhone, models.SourceWalkIn, models.SourceEmail, models.SourceOTA:
+	default:
+		form.Errors.Add("source", "Unknown booking source")
+	}
+
+	res.Guests = 1
+	if form.Has("guests") {
+		res.Guests, err = strconv.Atoi(form.Get("guests"))
+		if err != nil || res.Guests < 1 {
+			form.Errors.Add("guests", "At least one guest is required")
+		}
+	}
+
+	res.StartDate, err = time.Parse(h.app.DateLayout, form.Get("start"))
+	if err != nil {
+		form.Errors.Add("start", "Wrong arrival date")
+	}
+	res.EndDate, err = time.Parse(h.app.DateLayout, form.Get("end"))
+	if err != nil {
+		form.Errors.Add("end", "Wrong departure date")
+	} else if !res.EndDate.After(res.StartDate) {
+		form.Errors.Add("end", "Departure must be after arrival")
+	}
+
+	if form.Has("room_id") {
+		res.RoomID, err = strconv.Atoi(form.Get("room_id"))
+		if err != nil {
+			form.Errors.Add("room_id", "Choose a room")
+		}
+	}
+	if res.RoomID != 0 {
+		res.Room, err = h.DB.GetRoomByID(res.RoomID)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't find room")
+			http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+			return
+		}
+		applyRatePlan(&res, 0)
+	}
+
+	overridden := false
+	if form.Has("nightly_rate") && res.Room != nil {
+		rate, err := pricing.ParseCents(form.Get("nightly_rate"))
+		if err != nil {
+			form.Errors.Add("nightly_rate", "Price must be an amount with at most two decimals")
+		} else if rate != res.NightlyRate {
+			res.NightlyRate = rate
+			overridden = true
+		}
+	}
+	if broken := stayRuleBreak(res, today()); broken != "" {
+		if form.Has("override_rules") {
+			overridden = true
+		} else {
+			form.Errors.Add("start", broken)
+		}
+	}
+	if !overridden {
+		res.OverrideReason = ""
+	} else if res.OverrideReason == "" {
+		form.Errors.Add("override_reason", "A reason is required to override the price or stay rules")
+	}
+
+	if !form.Valid() {
+		h.renderCreateReservation(w, r, form, res)
+		return
+	}
+
+	res.ConfirmationCode = helpers.NewConfirmationCode()
+	h.app.InfoLog.Printf("saving to db reservation: %+v\n", res)
+	newID, err := h.repo(r).InsertReservationWithHold(&res)
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "room is not available on these dates")
+		http.Redirect(w, r, fmt.Sprintf("/admin/create-reservation?start=%s&end=%s",
+			form.Get("start"), form.Get("end")), http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't insert reservation")
+		http.Redirect(w, r, "/admin/create-reservation", http.StatusSeeOther)
+		return
+	}
+	res.ID = newID
+
+	flash := "reservation is created"
+	if form.Has("send_confirmation") {
+		h.sendConfirmation(res)
+		flash = "reservation is created, confirmation is sent to the guest"
+	}
+	h.app.Session.Put(r.Context(), "flash", flash)
+	http.Redirect(w, r, fmt.Sprintf("/admin/reservations/all/%d/show", newID), http.StatusSeeOther)
+}
+
+// calendarTooltip describes the reservation on the calendar: the guest, the tags and the notes, latest first
+func calendarTooltip(res models.Reservation) string {
+	lines := []string{res.FirstName + " " + res.LastName}
+	if len(res.Tags) > 0 {
+		tags := make([]string, 0, len(res.Tags))
+		for _, tag := range res.Tags {
+			tags = append(tags, tag.Tag)
+		}
+		lines = append(lines, "Tags: "+strings.Join(tags, ", "))
+	}
+	for _, note := range res.Notes {
+		lines = append(lines, note.CreatedAt.Format("2006-01-02")+": "+note.Body)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (h *Handlers) AdminReservationCalendar(w http.ResponseWriter, r *http.Request) {
 	now := time.Now()
 	if r.URL.Query().Get("y") != "" {
@@ -103,14 +628,18 @@ func (h *Handlers) AdminReservationCalendar(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	data["rooms"] = rooms
+	tooltips := make(map[int]string)
+	data["tooltips"] = tooltips
 
 	for _, room := range rooms {
 		reservationMap := make(map[string]int)
 		blockMap := make(map[string]int)
+		outOfOrderMap := make(map[string]int)
 
 		for current := firstDayOfMonth; !current.After(lastDayOfMonth); current = current.AddDate(0, 0, 1) {
 			reservationMap[current.Format("2006-01-2")] = 0
 			blockMap[current.Format("2006-01-2")] = 0
+			outOfOrderMap[current.Format("2006-01-2")] = 0
 		}
 		roomRestrictions, err := h.DB.GetRoomRestrictionsByRoomIdWithinDates(room.ID, firstDayOfMonth, lastDayOfMonth.AddDate(0, 0, 1))
 		if err != nil {
@@ -121,9 +650,14 @@ func (h *Handlers) AdminReservationCalendar(w http.ResponseWriter, r *http.Reque
 		}
 		for _, rr := range roomRestrictions {
 			if rr.Reservation != nil {
+				tooltips[rr.ReservationID] = calendarTooltip(*rr.Reservation)
 				for current := rr.Reservation.StartDate; !current.Equal(rr.Reservation.EndDate); current = current.AddDate(0, 0, 1) {
 					reservationMap[current.Format("2006-01-2")] = rr.ReservationID
 				}
+			} else if rr.RestrictionID == models.RestrictionOutOfOrder {
+				for current := rr.StartDate; !current.Equal(rr.EndDate); current = current.AddDate(0, 0, 1) {
+					outOfOrderMap[current.Format("2006-01-2")] = rr.OutOfOrderID
+				}
 			} else {
 				for current := rr.StartDate; !current.Equal(rr.EndDate); current = current.AddDate(0, 0, 1) {
 					blockMap[current.Format("2006-01-2")] = rr.ID
@@ -133,6 +667,7 @@ func (h *Handlers) AdminReservationCalendar(w http.ResponseWriter, r *http.Reque
 
 		data[fmt.Sprintf("reservation_map_%d", room.ID)] = reservationMap
 		data[fmt.Sprintf("block_map_%d", room.ID)] = blockMap
+		data[fmt.Sprintf("out_of_order_map_%d", room.ID)] = outOfOrderMap
 
 		h.app.Session.Put(r.Context(), fmt.Sprintf("block_map_%d", room.ID), blockMap)
 	}
@@ -182,10 +717,25 @@ func (h *Handlers) AdminSingleReservation(w http.ResponseWriter, r *http.Request
 		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
+	intMap := make(map[string]int)
+	intMap["cancellation_fee"] = pricing.CancellationFee(*reservation, reservation.CancellationPolicy, time.Now())
+	intMap["balance"] = pricing.RunningBalance(reservation.FolioEntries)
+	addOns, err := h.DB.GetAllAddOns()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+
 	data := make(map[string]interface{})
 	data["reservation"] = reservation
+	data["add_ons"] = addOns
+	data["rooms"] = rooms
 	err = h.render.Template(w, r, "admin.single-reservation.page.tmpl", &models.TemplateData{
 		StringMap: stringMap,
+		IntMap:    intMap,
 		Data:      data,
 		Form:      forms.New(nil),
 	})
@@ -234,7 +784,7 @@ func (h *Handlers) AdminPostSingleReservation(w http.ResponseWriter, r *http.Req
 	reservation.Phone = r.Form.Get("phone")
 	reservation.ID = id
 
-	err = h.DB.UpdateReservation(reservation)
+	err = h.repo(r).UpdateReservation(reservation)
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 		h.app.Session.Put(r.Context(), "error", "can't update reservation")
@@ -246,7 +796,7 @@ func (h *Handlers) AdminPostSingleReservation(w http.ResponseWriter, r *http.Req
 	http.Redirect(w, r, redirectString, http.StatusSeeOther)
 }
 
-func (h *Handlers) AdminProcessReservation(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) AdminPostMoveReservation(w http.ResponseWriter, r *http.Request) {
 	exploded := strings.Split(r.RequestURI, "/")
 	if len(exploded) != 6 {
 		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
@@ -255,36 +805,123 @@ func (h *Handlers) AdminProcessReservation(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	redirectString := fmt.Sprintf("/admin/%s-reservations", exploded[3])
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
-	if month != "" && year != "" {
-		redirectString = fmt.Sprintf("/admin/reservation-calendar?y=%s&m=%s", year, month)
-	}
-
 	id, err := strconv.Atoi(exploded[4])
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id)
+	month := r.Form.Get("month")
+	year := r.Form.Get("year")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	startDate, err := time.Parse(h.app.DateLayout, r.Form.Get("start"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad start time")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	endDate, err := time.Parse(h.app.DateLayout, r.Form.Get("end"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad end time")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if !endDate.After(startDate) {
+		h.app.Session.Put(r.Context(), "error", "departure must be after arrival")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	roomID, err := strconv.Atoi(r.Form.Get("room_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong room")
 		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	err = h.DB.UpdateReservationProcessed(id, 1)
+	old, err := h.DB.GetReservationByID(id)
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "can't update reservation")
+		h.app.Session.Put(r.Context(), "error", "can't find reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if old.StartDate.Equal(startDate) && old.EndDate.Equal(endDate) && old.RoomID == roomID {
+		h.app.Session.Put(r.Context(), "flash", "nothing to change")
 		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	h.app.Session.Put(r.Context(), "flash", "reservation is marked as processed")
+	res := *old
+	res.StartDate = startDate
+	res.EndDate = endDate
+	if roomID != old.RoomID {
+		res.RoomID = roomID
+		res.Room, err = h.DB.GetRoomByID(roomID)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't find room")
+			http.Redirect(w, r, redirectString, http.StatusSeeOther)
+			return
+		}
+		applyRatePlan(&res, 0)
+	}
+
+	err = h.repo(r).MoveReservation(res, h.app.Session.GetInt(r.Context(), "user_id"))
+	if errors.Is(err, repository.ErrReservationCancelled) {
+		h.app.Session.Put(r.Context(), "error", "reservation is cancelled")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrReservationNoShow) {
+		h.app.Session.Put(r.Context(), "error", "reservation is marked as a no-show")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAlreadyCheckedOut) {
+		h.app.Session.Put(r.Context(), "error", "guest is already checked out")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.Session.Put(r.Context(), "error", "room is not available on these dates")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't change reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	h.notifyWaitlist(r, old.RoomID, old.StartDate, old.EndDate)
+
+	flash := "reservation is changed"
+	if r.Form.Get("notify") != "" {
+		h.sendStayChange(*old, res)
+		flash = "reservation is changed, the guest is notified"
+	}
+	h.app.Session.Put(r.Context(), "flash", flash)
 	http.Redirect(w, r, redirectString, http.StatusSeeOther)
 }
 
-func (h *Handlers) AdminDeleteReservation(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) AdminPostFolioEntry(w http.ResponseWriter, r *http.Request) {
 	exploded := strings.Split(r.RequestURI, "/")
-
 	if len(exploded) != 6 {
 		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
 		h.app.Session.Put(r.Context(), "error", "incorrect request url")
@@ -292,123 +929,3045 @@ func (h *Handlers) AdminDeleteReservation(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	redirectString := fmt.Sprintf("/admin/%s-reservations", exploded[3])
-	year := r.URL.Query().Get("y")
-	month := r.URL.Query().Get("m")
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id)
+	month := r.Form.Get("month")
+	year := r.Form.Get("year")
 	if month != "" && year != "" {
-		redirectString = fmt.Sprintf("/admin/reservation-calendar?y=%s&m=%s", year, month)
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	amount, err := pricing.ParseCents(r.Form.Get("amount"))
+	if err == nil {
+		amount, err = pricing.FolioAmount(r.Form.Get("entry_type"), amount)
+	}
+	if errors.Is(err, pricing.ErrUnknownEntryType) {
+		h.app.Session.Put(r.Context(), "error", "unknown entry type")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.Session.Put(r.Context(), "error", "amount must be a positive amount with at most two decimals")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	entry := models.FolioEntry{
+		ReservationID: id,
+		EntryType:     r.Form.Get("entry_type"),
+		Description:   strings.TrimSpace(r.Form.Get("description")),
+		Amount:        amount,
+		UserID:        h.app.Session.GetInt(r.Context(), "user_id"),
+	}
+	_, err = h.repo(r).InsertFolioEntry(&entry)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't add folio entry")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "folio entry is added")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostReservationAddOn(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
 	}
 
 	id, err := strconv.Atoi(exploded[4])
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id)
+	month := r.Form.Get("month")
+	year := r.Form.Get("year")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	addOnID, err := strconv.Atoi(r.Form.Get("add_on_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong add-on")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	quantity, err := strconv.Atoi(r.Form.Get("quantity"))
+	if err != nil || quantity < 1 {
+		h.app.Session.Put(r.Context(), "error", "quantity must be a positive whole number")
 		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	err = h.DB.DeleteReservationByID(id)
+	reservation, err := h.DB.GetReservationByID(id)
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "can't delete reservation")
+		h.app.Session.Put(r.Context(), "error", "can't find reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	addOn, err := h.DB.GetAddOnByID(addOnID)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find add-on")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	item := pricing.NewReservationAddOn(*reservation, *addOn, quantity)
+	_, err = h.repo(r).AddReservationAddOn(&item, h.app.Session.GetInt(r.Context(), "user_id"))
+	if errors.Is(err, repository.ErrReservationCancelled) {
+		h.app.Session.Put(r.Context(), "error", "reservation is cancelled")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAddOnNotAvailable) {
+		h.app.Session.Put(r.Context(), "error", addOn.Name+" is sold out on these dates")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't add add-on")
 		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	h.app.Session.Put(r.Context(), "flash", "reservation is deleted")
+	h.app.Session.Put(r.Context(), "flash", "add-on is added")
 	http.Redirect(w, r, redirectString, http.StatusSeeOther)
 }
 
-func (h *Handlers) AdminPostReservationCalendar(w http.ResponseWriter, r *http.Request) {
+func (h *Handlers) AdminPostRemoveReservationAddOn(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 7 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
 	err := r.ParseForm()
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 		h.app.Session.Put(r.Context(), "error", "bad form")
-		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
 		return
 	}
-
-	year, err := strconv.Atoi(r.Form.Get("y"))
-	fmt.Println(year, r.Form.Get("y"))
+	src := exploded[3]
+	id, err := strconv.Atoi(exploded[4])
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "wrong year")
-		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
 		return
 	}
-	month, err := strconv.Atoi(r.Form.Get("m"))
-	fmt.Println(month, r.Form.Get("m"))
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", src, id)
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	itemID, err := strconv.Atoi(exploded[5])
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "wrong month")
-		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	rooms, err := h.DB.GetAllRooms()
+	err = h.repo(r).RemoveReservationAddOn(id, itemID, h.app.Session.GetInt(r.Context(), "user_id"))
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "can't get rooms")
-		http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+		h.app.Session.Put(r.Context(), "error", "can't remove add-on")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
 		return
 	}
 
-	form := forms.New(r.Form)
+	h.app.Session.Put(r.Context(), "flash", "add-on is removed")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
 
-	for _, room := range rooms {
-		curMap, ok := h.app.Session.Get(r.Context(), fmt.Sprintf("block_map_%d", room.ID)).(map[string]int)
-		if !ok {
-			h.app.ErrorLog.Println("can't get block map for room: ", room.Name)
-			h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't get block map for room: %s", room.Name))
-			http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
-			return
-		}
-		for name, value := range curMap {
-			fmt.Println("both", name, value)
-			fmt.Println(form.Has(fmt.Sprintf("remove_block_%d_%s", room.ID, name)))
-			if value > 0 {
-				if !form.Has(fmt.Sprintf("remove_block_%d_%s", room.ID, name)) {
-					fmt.Println("pizdit")
-					err = h.DB.DeleteRoomRestrictionByID(value)
-					if err != nil {
-						h.app.ErrorLog.Println(err)
-						h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't delete restriction %d", value))
-						http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
-						return
-					}
-				}
-			}
-		}
+func (h *Handlers) AdminPostReservationNote(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
 	}
 
-	for name, _ := range r.Form {
-		if strings.HasPrefix(name, "add_block_") {
-			exploded := strings.Split(name, "_")
-			roomId, err := strconv.Atoi(exploded[2])
-			if err != nil {
-				h.app.ErrorLog.Println(err)
-				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't parse room id %s", name))
-				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
-				return
-			}
-			date, err := time.Parse("2006-01-02", exploded[3])
-			if err != nil {
-				h.app.ErrorLog.Println(err)
-				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't parse date %s", name))
-				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
-				return
-			}
-
-			_, err = h.DB.AddSingleDayRoomRestriction(roomId, 2, date)
-			if err != nil {
-				h.app.ErrorLog.Println(err)
-				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't save this restriction %s", name))
-				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
-				return
-			}
-		}
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
 	}
 
-	h.app.Session.Put(r.Context(), "flash", "changes saved!")
-	http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id)
+	month := r.Form.Get("month")
+	year := r.Form.Get("year")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	body := strings.TrimSpace(r.Form.Get("body"))
+	if body == "" {
+		h.app.Session.Put(r.Context(), "error", "note can't be empty")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	note := models.ReservationNote{
+		ReservationID: id,
+		Body:          body,
+		CreatedBy:     h.app.Session.GetInt(r.Context(), "user_id"),
+	}
+	_, err = h.repo(r).InsertReservationNote(&note)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't add note")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "note is added")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+// maxTagLength is the longest tag the database stores
+const maxTagLength = 64
+
+// normalizeTag lowercases the tag and collapses the spaces in it, so the same tag typed differently is one tag
+func normalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), " ")
+}
+
+func (h *Handlers) AdminPostReservationTag(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id)
+	month := r.Form.Get("month")
+	year := r.Form.Get("year")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	tag := normalizeTag(r.Form.Get("tag"))
+	if tag == "" || len(tag) > maxTagLength {
+		h.app.Session.Put(r.Context(), "error", fmt.Sprintf("tag must be 1 to %d characters long", maxTagLength))
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).AddReservationTag(id, tag)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't add tag")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "tag is added")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostRemoveReservationTag(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 7 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	src := exploded[3]
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", src, id)
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	tag, err := url.PathUnescape(exploded[5])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong tag")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).RemoveReservationTag(id, tag)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't remove tag")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "tag is removed")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminProcessReservation(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	redirectString := fmt.Sprintf("/admin/%s-reservations", exploded[3])
+	year := r.URL.Query().Get("y")
+	month := r.URL.Query().Get("m")
+	if month != "" && year != "" {
+		redirectString = fmt.Sprintf("/admin/reservation-calendar?y=%s&m=%s", year, month)
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).UpdateReservationProcessed(id, 1)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "reservation is marked as processed")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostDeleteReservation(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/%s-reservations", exploded[3])
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		redirectString = fmt.Sprintf("/admin/reservation-calendar?y=%s&m=%s", year, month)
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	h.notifyWaitlist(r, res.RoomID, res.StartDate, res.EndDate)
+
+	h.app.Session.Put(r.Context(), "flash", "reservation is moved to trash")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostCancelReservation(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/%s-reservations", exploded[3])
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		redirectString = fmt.Sprintf("/admin/reservation-calendar?y=%s&m=%s", year, month)
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	fee := pricing.CancellationFee(*res, res.CancellationPolicy, time.Now())
+	err = h.repo(r).CancelReservation(id, fee)
+	if errors.Is(err, repository.ErrReservationCancelled) {
+		h.app.Session.Put(r.Context(), "error", "reservation is already cancelled")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't cancel reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	h.notifyWaitlist(r, res.RoomID, res.StartDate, res.EndDate)
+
+	h.app.Session.Put(r.Context(), "flash",
+		"reservation is cancelled, cancellation fee is "+currency.Format(fee, h.app.Currencies.Base()))
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostCheckIn(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := frontDeskRedirect(exploded[3], id, r.Form.Get("year"), r.Form.Get("month"))
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	day := today()
+	if res.StartDate.After(day) {
+		h.app.Session.Put(r.Context(), "error", fmt.Sprintf("guest arrives on %s, change the stay to check in earlier",
+			res.StartDate.Format(h.app.DateLayout)))
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if !res.EndDate.After(day) {
+		h.app.Session.Put(r.Context(), "error", "the stay is over")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	now := time.Now()
+	err = h.repo(r).CheckInReservation(id, h.app.Session.GetInt(r.Context(), "user_id"), now)
+	if errors.Is(err, repository.ErrReservationCancelled) {
+		h.app.Session.Put(r.Context(), "error", "reservation is cancelled")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrReservationNoShow) {
+		h.app.Session.Put(r.Context(), "error", "reservation is marked as a no-show, book the guest again")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAlreadyCheckedIn) {
+		h.app.Session.Put(r.Context(), "error", "guest is already checked in")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't check in guest")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	flash := "guest is checked in"
+	if frontdesk.EarlyCheckIn(*res, now, h.app.FrontDesk) {
+		flash = "guest is checked in early"
+	}
+	h.app.Session.Put(r.Context(), "flash", flash)
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostCheckOut(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	redirectString := frontDeskRedirect(exploded[3], id, r.Form.Get("year"), r.Form.Get("month"))
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	now := time.Now()
+	err = h.repo(r).CheckOutReservation(id, h.app.Session.GetInt(r.Context(), "user_id"), now)
+	if errors.Is(err, repository.ErrReservationCancelled) {
+		h.app.Session.Put(r.Context(), "error", "reservation is cancelled")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrReservationNoShow) {
+		h.app.Session.Put(r.Context(), "error", "reservation is marked as a no-show")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrNotCheckedIn) {
+		h.app.Session.Put(r.Context(), "error", "guest is not checked in")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAlreadyCheckedOut) {
+		h.app.Session.Put(r.Context(), "error", "guest is already checked out")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't check out guest")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	flash := "guest is checked out"
+	switch {
+	case frontdesk.EarlyDeparture(*res, now):
+		freeFrom := frontdesk.DateOf(now)
+		if !freeFrom.After(res.StartDate) {
+			freeFrom = res.StartDate.AddDate(0, 0, 1)
+		}
+		if freeFrom.Before(res.EndDate) {
+			h.notifyWaitlist(r, res.RoomID, freeFrom, res.EndDate)
+		}
+		flash = fmt.Sprintf("guest is checked out before departure, the room is free from %s",
+			freeFrom.Format(h.app.DateLayout))
+	case frontdesk.LateCheckOut(*res, now, h.app.FrontDesk):
+		flash = "guest is checked out late"
+	}
+	h.app.Session.Put(r.Context(), "flash", flash)
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+// frontDeskRedirect returns where a check-in or check-out goes back to: the dashboard boards for "desk",
+// the reservation page otherwise
+func frontDeskRedirect(src string, id int, year, month string) string {
+	if src == "desk" {
+		return "/admin/dashboard"
+	}
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", src, id)
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+	return redirectString
+}
+
+func (h *Handlers) AdminPostReservationCalendar(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		return
+	}
+
+	year, err := strconv.Atoi(r.Form.Get("y"))
+	fmt.Println(year, r.Form.Get("y"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong year")
+		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		return
+	}
+	month, err := strconv.Atoi(r.Form.Get("m"))
+	fmt.Println(month, r.Form.Get("m"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong month")
+		http.Redirect(w, r, "/admin/reservation-calendar", http.StatusSeeOther)
+		return
+	}
+
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get rooms")
+		http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+		return
+	}
+
+	form := forms.New(r.Form)
+
+	for _, room := range rooms {
+		curMap, ok := h.app.Session.Get(r.Context(), fmt.Sprintf("block_map_%d", room.ID)).(map[string]int)
+		if !ok {
+			h.app.ErrorLog.Println("can't get block map for room: ", room.Name)
+			h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't get block map for room: %s", room.Name))
+			http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+			return
+		}
+		for name, value := range curMap {
+			fmt.Println("both", name, value)
+			fmt.Println(form.Has(fmt.Sprintf("remove_block_%d_%s", room.ID, name)))
+			if value > 0 {
+				if !form.Has(fmt.Sprintf("remove_block_%d_%s", room.ID, name)) {
+					fmt.Println("pizdit")
+					err = h.repo(r).DeleteRoomRestrictionByID(value)
+					if err != nil {
+						h.app.ErrorLog.Println(err)
+						h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't delete restriction %d", value))
+						http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+						return
+					}
+					date, err := time.Parse("2006-01-2", name)
+					if err != nil {
+						h.app.ErrorLog.Println(err)
+						continue
+					}
+					h.notifyWaitlist(r, room.ID, date, date.AddDate(0, 0, 1))
+				}
+			}
+		}
+	}
+
+	for name, _ := range r.Form {
+		if strings.HasPrefix(name, "add_block_") {
+			exploded := strings.Split(name, "_")
+			roomId, err := strconv.Atoi(exploded[2])
+			if err != nil {
+				h.app.ErrorLog.Println(err)
+				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't parse room id %s", name))
+				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+				return
+			}
+			date, err := time.Parse("2006-01-02", exploded[3])
+			if err != nil {
+				h.app.ErrorLog.Println(err)
+				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't parse date %s", name))
+				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+				return
+			}
+
+			_, err = h.repo(r).AddSingleDayRoomRestriction(roomId, models.RestrictionOwnerBlock, date)
+			if err != nil {
+				h.app.ErrorLog.Println(err)
+				h.app.Session.Put(r.Context(), "error", fmt.Sprintf("can't save this restriction %s", name))
+				http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+				return
+			}
+		}
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "changes saved!")
+	http.Redirect(w, r, fmt.Sprintf("/admin/reservation-calendar?y=%d&m=%d", year, month), http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminReservationGroup(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+	group, err := h.DB.GetReservationGroupByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find reservation group")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+	data := make(map[string]interface{})
+	data["group"] = group
+	err = h.render.Template(w, r, "admin.reservation-group.page.tmpl", &models.TemplateData{
+		Data: data,
+		Form: forms.New(nil),
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostReservationGroup(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservation-groups/%d/show", id)
+
+	group := models.ReservationGroup{
+		ID:        id,
+		FirstName: r.Form.Get("first_name"),
+		LastName:  r.Form.Get("last_name"),
+		Email:     r.Form.Get("email"),
+		Phone:     r.Form.Get("phone"),
+	}
+
+	err = h.repo(r).UpdateReservationGroup(group)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update reservation group")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "reservation group updated")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminProcessReservationGroup(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/new-reservations", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/reservation-groups/%d/show", id)
+
+	err = h.repo(r).UpdateReservationGroupProcessed(id, 1)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update reservation group")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "reservation group is marked as processed")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostDeleteReservationGroup(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+
+	group, err := h.DB.GetReservationGroupByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation group")
+		http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteReservationGroupByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete reservation group")
+		http.Redirect(w, r, fmt.Sprintf("/admin/reservation-groups/%d/show", id), http.StatusSeeOther)
+		return
+	}
+	for _, res := range group.Reservations {
+		h.notifyWaitlist(r, res.RoomID, res.StartDate, res.EndDate)
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "reservation group is moved to trash")
+	http.Redirect(w, r, "/admin/all-reservations", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminGuests(w http.ResponseWriter, r *http.Request) {
+	guests, err := h.DB.GetAllGuests()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get guests")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["guests"] = guests
+	err = h.render.Template(w, r, "admin.guests.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminGuest(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/guests", http.StatusSeeOther)
+		return
+	}
+	guest, err := h.DB.GetGuestByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find guest")
+		http.Redirect(w, r, "/admin/guests", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["guest"] = guest
+	err = h.render.Template(w, r, "admin.guest.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostGuest(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/guests", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/guests", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/guests/%d/show", id)
+
+	form := forms.New(r.PostForm)
+	form.Required("first_name", "last_name")
+	if form.Has("email") {
+		form.IsEmail("email")
+	}
+	if !form.Valid() {
+		h.app.Session.Put(r.Context(), "error", "guest needs a first and last name and a valid email")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	guest := models.Guest{
+		ID:          id,
+		FirstName:   strings.TrimSpace(form.Get("first_name")),
+		LastName:    strings.TrimSpace(form.Get("last_name")),
+		Email:       strings.TrimSpace(form.Get("email")),
+		Phone:       strings.TrimSpace(form.Get("phone")),
+		Preferences: strings.TrimSpace(form.Get("preferences")),
+		Notes:       strings.TrimSpace(form.Get("notes")),
+		VIP:         form.Has("vip"),
+	}
+
+	err = h.repo(r).UpdateGuest(guest)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update guest")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "guest updated")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminGuestDuplicates(w http.ResponseWriter, r *http.Request) {
+	list, err := h.DB.GetAllGuests()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get guests")
+		http.Redirect(w, r, "/admin/guests", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["duplicates"] = guests.FindDuplicates(list)
+	err = h.render.Template(w, r, "admin.guest-duplicates.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// mergeGuestIDs reads the ids of the guest to keep and the guest to merge into it from the request url
+func (h *Handlers) mergeGuestIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return 0, 0, false
+	}
+
+	keepID, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return 0, 0, false
+	}
+	mergeID, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return 0, 0, false
+	}
+	if keepID == mergeID {
+		h.app.Session.Put(r.Context(), "error", "can't merge a guest into itself")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return 0, 0, false
+	}
+	return keepID, mergeID, true
+}
+
+// joinText puts two texts together, once if they are the same
+func joinText(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case a == "" || a == b:
+		return b
+	case b == "":
+		return a
+	}
+	return a + "\n" + b
+}
+
+func (h *Handlers) AdminMergeGuests(w http.ResponseWriter, r *http.Request) {
+	keepID, mergeID, ok := h.mergeGuestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	keep, err := h.DB.GetGuestByID(keepID)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find guest")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return
+	}
+	merge, err := h.DB.GetGuestByID(mergeID)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find guest")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return
+	}
+
+	stringMap := make(map[string]string)
+	stringMap["preferences"] = joinText(keep.Preferences, merge.Preferences)
+	stringMap["notes"] = joinText(keep.Notes, merge.Notes)
+
+	data := make(map[string]interface{})
+	data["keep"] = keep
+	data["merge"] = merge
+	err = h.render.Template(w, r, "admin.merge-guests.page.tmpl", &models.TemplateData{
+		StringMap: stringMap,
+		Data:      data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostMergeGuests(w http.ResponseWriter, r *http.Request) {
+	keepID, mergeID, ok := h.mergeGuestIDs(w, r)
+	if !ok {
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/guest-duplicates", http.StatusSeeOther)
+		return
+	}
+	redirectString := fmt.Sprintf("/admin/merge-guests/%d/%d", keepID, mergeID)
+
+	form := forms.New(r.PostForm)
+	form.Required("first_name", "last_name")
+	if form.Has("email") {
+		form.IsEmail("email")
+	}
+	if !form.Valid() {
+		h.app.Session.Put(r.Context(), "error", "guest needs a first and last name and a valid email")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	survivor := models.Guest{
+		ID:          keepID,
+		FirstName:   strings.TrimSpace(form.Get("first_name")),
+		LastName:    strings.TrimSpace(form.Get("last_name")),
+		Email:       strings.TrimSpace(form.Get("email")),
+		Phone:       strings.TrimSpace(form.Get("phone")),
+		Preferences: strings.TrimSpace(form.Get("preferences")),
+		Notes:       strings.TrimSpace(form.Get("notes")),
+		VIP:         form.Has("vip"),
+	}
+
+	err = h.repo(r).MergeGuests(survivor, mergeID, h.app.Session.GetInt(r.Context(), "user_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't merge guests")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "guests merged")
+	http.Redirect(w, r, fmt.Sprintf("/admin/guests/%d/show", keepID), http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminWaitlist(w http.ResponseWriter, r *http.Request) {
+	data := make(map[string]interface{})
+	entries, err := h.DB.GetAllWaitlistEntries()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get waitlist")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	data["entries"] = entries
+	err = h.render.Template(w, r, "admin.waitlist.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostNotifyWaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+		return
+	}
+
+	entry, err := h.DB.GetWaitlistEntryByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get waitlist entry")
+		http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+		return
+	}
+
+	err = h.sendWaitlistEmail(r, *entry)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update waitlist entry")
+		http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "guest is notified")
+	http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostDeleteWaitlistEntry(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteWaitlistEntryByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete waitlist entry")
+		http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "waitlist entry is deleted")
+	http.Redirect(w, r, "/admin/waitlist", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminCancellationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := h.DB.GetAllCancellationPolicies()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get cancellation policies")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["policies"] = policies
+	err = h.render.Template(w, r, "admin.cancellation-policies.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostCancellationPolicy(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+		return
+	}
+
+	policy, err := parseCancellationPolicy(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+		return
+	}
+
+	if policy.ID == 0 {
+		_, err = h.repo(r).InsertCancellationPolicy(&policy)
+	} else {
+		err = h.repo(r).UpdateCancellationPolicy(policy)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save cancellation policy")
+		http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "cancellation policy is saved")
+	http.Redirect(w, r, "/admin/cancellation-policies", http.StatusSeeOther)
+}
+
+// parseCancellationPolicy reads a policy from the admin form, id is 0 for a new policy
+func parseCancellationPolicy(form *forms.Form) (models.CancellationPolicy, error) {
+	var policy models.CancellationPolicy
+	var err error
+
+	if form.Has("id") {
+		policy.ID, err = strconv.Atoi(form.Get("id"))
+		if err != nil {
+			return policy, errors.New("wrong id")
+		}
+	}
+	policy.Name = strings.TrimSpace(form.Get("name"))
+	if policy.Name == "" {
+		return policy, errors.New("policy name is required")
+	}
+	policy.FreeDays, err = strconv.Atoi(form.Get("free_days"))
+	if err != nil || policy.FreeDays < 0 {
+		return policy, errors.New("free days must be a non-negative number")
+	}
+	policy.FeeType = form.Get("fee_type")
+	switch policy.FeeType {
+	case models.FeeFirstNight:
+	case models.FeePercent:
+		policy.FeePercent, err = strconv.Atoi(form.Get("fee_percent"))
+		if err != nil || policy.FeePercent < 0 || policy.FeePercent > 100 {
+			return policy, errors.New("fee percent must be between 0 and 100")
+		}
+	default:
+		return policy, errors.New("unknown fee type")
+	}
+	return policy, nil
+}
+
+func (h *Handlers) AdminTaxes(w http.ResponseWriter, r *http.Request) {
+	taxes, err := h.DB.GetAllTaxes()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get taxes")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["taxes"] = taxes
+	err = h.render.Template(w, r, "admin.taxes.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostTax(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+		return
+	}
+
+	tax, err := h.parseTax(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+		return
+	}
+
+	if tax.ID == 0 {
+		_, err = h.repo(r).InsertTax(&tax)
+	} else {
+		err = h.repo(r).UpdateTax(tax)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save tax")
+		http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "tax is saved")
+	http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+}
+
+// parseTax reads a tax from the admin form, id is 0 for a new tax. The value is a percent for
+// percentages and an amount for flat taxes, both with at most two decimals
+func (h *Handlers) parseTax(form *forms.Form) (models.Tax, error) {
+	var tax models.Tax
+	var err error
+
+	if form.Has("id") {
+		tax.ID, err = strconv.Atoi(form.Get("id"))
+		if err != nil {
+			return tax, errors.New("wrong id")
+		}
+	}
+	tax.Name = strings.TrimSpace(form.Get("name"))
+	if tax.Name == "" {
+		return tax, errors.New("tax name is required")
+	}
+
+	tax.TaxType = form.Get("tax_type")
+	tax.Basis = form.Get("basis")
+	if tax.Basis == "" {
+		tax.Basis = models.TaxPerStay
+	}
+	switch tax.Basis {
+	case models.TaxPerStay, models.TaxPerNight, models.TaxPerGuest, models.TaxPerGuestNight:
+	default:
+		return tax, errors.New("unknown tax basis")
+	}
+	value, err := pricing.ParseCents(form.Get("value"))
+	switch tax.TaxType {
+	case models.TaxPercent:
+		if err != nil || value == 0 || value > 10000 {
+			return tax, errors.New("tax percent must be between 0.01 and 100")
+		}
+		tax.Rate = value
+	case models.TaxFlat:
+		if err != nil || value == 0 {
+			return tax, errors.New("tax amount must be a positive amount with at most two decimals")
+		}
+		tax.Amount = value
+	default:
+		return tax, errors.New("unknown tax type")
+	}
+	tax.Inclusive = form.Has("inclusive")
+
+	if form.Has("valid_from") {
+		tax.ValidFrom, err = time.Parse(h.app.DateLayout, form.Get("valid_from"))
+		if err != nil {
+			return tax, errors.New("wrong start date")
+		}
+	}
+	if form.Has("valid_to") {
+		tax.ValidTo, err = time.Parse(h.app.DateLayout, form.Get("valid_to"))
+		if err != nil {
+			return tax, errors.New("wrong end date")
+		}
+		if tax.ValidTo.Before(tax.ValidFrom) {
+			return tax, errors.New("tax ends before it starts")
+		}
+	}
+	return tax, nil
+}
+
+func (h *Handlers) AdminPostDeleteTax(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteTaxByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete tax")
+		http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "tax is deleted")
+	http.Redirect(w, r, "/admin/taxes", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminAddOns(w http.ResponseWriter, r *http.Request) {
+	addOns, err := h.DB.GetAllAddOns()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get add-ons")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["add_ons"] = addOns
+	err = h.render.Template(w, r, "admin.add-ons.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostAddOn(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+		return
+	}
+
+	addOn, err := parseAddOn(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+		return
+	}
+
+	if addOn.ID == 0 {
+		_, err = h.repo(r).InsertAddOn(&addOn)
+	} else {
+		err = h.repo(r).UpdateAddOn(addOn)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save add-on")
+		http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "add-on is saved")
+	http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+}
+
+// parseAddOn reads an add-on from the admin form, id is 0 for a new add-on and an empty limit means no limit
+func parseAddOn(form *forms.Form) (models.AddOn, error) {
+	var addOn models.AddOn
+	var err error
+
+	if form.Has("id") {
+		addOn.ID, err = strconv.Atoi(form.Get("id"))
+		if err != nil {
+			return addOn, errors.New("wrong id")
+		}
+	}
+	addOn.Name = strings.TrimSpace(form.Get("name"))
+	if addOn.Name == "" {
+		return addOn, errors.New("add-on name is required")
+	}
+	addOn.Description = strings.TrimSpace(form.Get("description"))
+
+	addOn.Price, err = pricing.ParseCents(form.Get("price"))
+	if err != nil || addOn.Price == 0 {
+		return addOn, errors.New("add-on price must be a positive amount with at most two decimals")
+	}
+	addOn.Basis = form.Get("basis")
+	switch addOn.Basis {
+	case models.AddOnPerStay, models.AddOnPerNight, models.AddOnPerPerson, models.AddOnPerPersonNight:
+	default:
+		return addOn, errors.New("unknown add-on basis")
+	}
+
+	if form.Has("daily_limit") {
+		addOn.DailyLimit, err = strconv.Atoi(strings.TrimSpace(form.Get("daily_limit")))
+		if err != nil || addOn.DailyLimit < 0 {
+			return addOn, errors.New("daily limit must be a whole number")
+		}
+	}
+	return addOn, nil
+}
+
+func (h *Handlers) AdminPostDeleteAddOn(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteAddOnByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete add-on")
+		http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "add-on is deleted")
+	http.Redirect(w, r, "/admin/add-ons", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := h.DB.GetAllCurrencies()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get currencies")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["currencies"] = currencies
+	data["base"] = h.app.Currencies.Base()
+	err = h.render.Template(w, r, "admin.currencies.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostCurrency(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+		return
+	}
+
+	cur, err := h.parseCurrency(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+		return
+	}
+
+	if cur.ID == 0 {
+		_, err = h.repo(r).InsertCurrency(&cur)
+	} else {
+		err = h.repo(r).UpdateCurrency(cur)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save currency")
+		http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+		return
+	}
+
+	err = h.LoadCurrencies()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	h.app.Session.Put(r.Context(), "flash", "currency is saved")
+	http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+}
+
+// parseCurrency reads an exchange rate from the admin form, id is 0 for a new currency
+func (h *Handlers) parseCurrency(form *forms.Form) (models.Currency, error) {
+	var cur models.Currency
+	var err error
+
+	if form.Has("id") {
+		cur.ID, err = strconv.Atoi(form.Get("id"))
+		if err != nil {
+			return cur, errors.New("wrong id")
+		}
+	}
+	cur.Code = strings.ToUpper(strings.TrimSpace(form.Get("code")))
+	if !currency.ValidCode(cur.Code) {
+		return cur, errors.New("currency code must be three letters")
+	}
+	if cur.Code == h.app.Currencies.Base().Code {
+		return cur, errors.New("base currency has no exchange rate")
+	}
+	cur.Symbol = strings.TrimSpace(form.Get("symbol"))
+	if cur.Symbol == "" {
+		return cur, errors.New("currency symbol is required")
+	}
+	cur.Rate, err = currency.ParseRate(form.Get("rate"))
+	if err != nil {
+		return cur, err
+	}
+	return cur, nil
+}
+
+func (h *Handlers) AdminPostDeleteCurrency(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteCurrencyByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete currency")
+		http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+		return
+	}
+
+	err = h.LoadCurrencies()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	h.app.Session.Put(r.Context(), "flash", "currency is deleted")
+	http.Redirect(w, r, "/admin/currencies", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.DB.GetRoomsWithRates()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get rooms")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	policies, err := h.DB.GetAllCancellationPolicies()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get cancellation policies")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["rooms"] = rooms
+	data["policies"] = policies
+	err = h.render.Template(w, r, "admin.rooms.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostRoom(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 4 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	err = r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	room := models.Room{ID: id}
+	room.Price, room.CancellationPolicyID, err = parseRate(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).UpdateRoomRates(room)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update room")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "room is updated")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostRatePlan(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	form := forms.New(r.PostForm)
+	var plan models.RatePlan
+	if form.Has("id") {
+		plan.ID, err = strconv.Atoi(form.Get("id"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "wrong id")
+			http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+			return
+		}
+	}
+	plan.RoomID, err = strconv.Atoi(form.Get("room_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong room id")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+	plan.Name = strings.TrimSpace(form.Get("name"))
+	if plan.Name == "" {
+		h.app.Session.Put(r.Context(), "error", "rate plan name is required")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+	plan.Price, plan.CancellationPolicyID, err = parseRate(form)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+	plan.DepositType, plan.DepositPercent, err = parseDeposit(form)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	if plan.ID == 0 {
+		_, err = h.repo(r).InsertRatePlan(&plan)
+	} else {
+		err = h.repo(r).UpdateRatePlan(plan)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save rate plan")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "rate plan is saved")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+// parseRate reads nightly price and optional cancellation policy of a room or a rate plan
+func parseRate(form *forms.Form) (int, int, error) {
+	price, err := pricing.ParseCents(form.Get("price"))
+	if err != nil {
+		return 0, 0, err
+	}
+	policyID := 0
+	if form.Has("cancellation_policy_id") {
+		policyID, err = strconv.Atoi(form.Get("cancellation_policy_id"))
+		if err != nil {
+			return 0, 0, errors.New("wrong cancellation policy")
+		}
+	}
+	return price, policyID, nil
+}
+
+// parseDeposit reads deposit type and percent of a rate plan, no deposit is taken when type is not set
+func parseDeposit(form *forms.Form) (string, int, error) {
+	switch form.Get("deposit_type") {
+	case "", models.DepositNone:
+		return models.DepositNone, 0, nil
+	case models.DepositFirstNight:
+		return models.DepositFirstNight, 0, nil
+	case models.DepositPercent:
+		percent, err := strconv.Atoi(form.Get("deposit_percent"))
+		if err != nil || percent < 1 || percent > 100 {
+			return "", 0, errors.New("deposit percent must be between 1 and 100")
+		}
+		return models.DepositPercent, percent, nil
+	}
+	return "", 0, errors.New("unknown deposit type")
+}
+
+func (h *Handlers) AdminPostDeleteRatePlan(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteRatePlanByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete rate plan")
+		http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "rate plan is deleted")
+	http.Redirect(w, r, "/admin/rooms", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminHousekeeping(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get rooms")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	tasks := make(map[int]models.HousekeepingTask)
+	dayTasks, err := h.DB.GetHousekeepingTasks(today())
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get housekeeping tasks")
+	}
+	for _, task := range dayTasks {
+		tasks[task.RoomID] = task
+	}
+
+	data := make(map[string]interface{})
+	data["rooms"] = rooms
+	data["tasks"] = tasks
+	err = h.render.Template(w, r, "admin.housekeeping.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostHousekeeping(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/housekeeping", http.StatusSeeOther)
+		return
+	}
+
+	roomID, err := strconv.Atoi(r.Form.Get("room_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong room")
+		http.Redirect(w, r, "/admin/housekeeping", http.StatusSeeOther)
+		return
+	}
+	status := r.Form.Get("status")
+	switch status {
+	case models.HousekeepingClean, models.HousekeepingDirty, models.HousekeepingInspected,
+		models.HousekeepingOutOfOrder:
+	default:
+		h.app.Session.Put(r.Context(), "error", "unknown housekeeping status")
+		http.Redirect(w, r, "/admin/housekeeping", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).UpdateHousekeepingStatus(roomID, status, h.app.Session.GetInt(r.Context(), "user_id"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't update room status")
+		http.Redirect(w, r, "/admin/housekeeping", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "room status is updated")
+	http.Redirect(w, r, fmt.Sprintf("/admin/housekeeping#room-%d", roomID), http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminOutOfOrder(w http.ResponseWriter, r *http.Request) {
+	periods, err := h.DB.GetOutOfOrderPeriods(today())
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get out of order periods")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get rooms")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["periods"] = periods
+	data["rooms"] = rooms
+	err = h.render.Template(w, r, "admin.out-of-order.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostOutOfOrder(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+
+	period, err := h.parseOutOfOrderPeriod(forms.New(r.PostForm))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+	userID := h.app.Session.GetInt(r.Context(), "user_id")
+	period.CreatedBy = userID
+
+	_, err = h.repo(r).InsertOutOfOrderPeriod(&period)
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.Session.Put(r.Context(), "error", "room is booked on these dates, move the guests first")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't save out of order period")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+	if !period.StartDate.After(today()) {
+		err = h.repo(r).UpdateHousekeepingStatus(period.RoomID, models.HousekeepingOutOfOrder, userID)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		}
+	}
+
+	h.app.Session.Put(r.Context(), "flash", fmt.Sprintf("room is out of order from %s to %s",
+		period.StartDate.Format(h.app.DateLayout), period.EndDate.Format(h.app.DateLayout)))
+	http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+}
+
+// parseOutOfOrderPeriod reads an out of order period from the admin form, the room is back in service on the end date
+func (h *Handlers) parseOutOfOrderPeriod(form *forms.Form) (models.OutOfOrderPeriod, error) {
+	var period models.OutOfOrderPeriod
+	var err error
+
+	period.RoomID, err = strconv.Atoi(form.Get("room_id"))
+	if err != nil {
+		return period, errors.New("wrong room")
+	}
+	period.StartDate, err = time.Parse(h.app.DateLayout, form.Get("start"))
+	if err != nil {
+		return period, errors.New("bad start time")
+	}
+	period.EndDate, err = time.Parse(h.app.DateLayout, form.Get("end"))
+	if err != nil {
+		return period, errors.New("bad end time")
+	}
+	if !period.EndDate.After(period.StartDate) {
+		return period, errors.New("room must be back in service after the start date")
+	}
+	if period.StartDate.Before(today()) {
+		return period, errors.New("out of order period can't start in the past")
+	}
+	period.Reason = strings.TrimSpace(form.Get("reason"))
+	if period.Reason == "" {
+		return period, errors.New("a reason is required")
+	}
+	return period, nil
+}
+
+func (h *Handlers) AdminPostDeleteOutOfOrder(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+
+	period, err := h.DB.GetOutOfOrderPeriodByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get out of order period")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+
+	err = h.repo(r).DeleteOutOfOrderPeriodByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete out of order period")
+		http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+		return
+	}
+	h.notifyWaitlist(r, period.RoomID, period.StartDate, period.EndDate)
+
+	h.app.Session.Put(r.Context(), "flash", "out of order period is deleted")
+	http.Redirect(w, r, "/admin/out-of-order", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostCapturePayment(w http.ResponseWriter, r *http.Request) {
+	h.adminPaymentAction(w, r, "captured", func(payment *models.Payment) error {
+		if payment.Status != models.PaymentAuthorized {
+			return payments.ErrWrongState
+		}
+		err := h.gateway.Capture(payment.Reference)
+		if err != nil {
+			return err
+		}
+		return h.repo(r).UpdatePaymentStatusByReference(payment.Reference, models.PaymentCaptured, 0)
+	})
+}
+
+func (h *Handlers) AdminPostVoidPayment(w http.ResponseWriter, r *http.Request) {
+	h.adminPaymentAction(w, r, "voided", func(payment *models.Payment) error {
+		if payment.Status != models.PaymentAuthorized {
+			return payments.ErrWrongState
+		}
+		err := h.gateway.Void(payment.Reference)
+		if err != nil {
+			return err
+		}
+		return h.repo(r).UpdatePaymentStatusByReference(payment.Reference, models.PaymentVoided, 0)
+	})
+}
+
+func (h *Handlers) AdminPostRefundPayment(w http.ResponseWriter, r *http.Request) {
+	h.adminPaymentAction(w, r, "refunded", func(payment *models.Payment) error {
+		if payment.Status != models.PaymentCaptured {
+			return payments.ErrWrongState
+		}
+		err := h.gateway.Refund(payment.Reference, payment.Amount-payment.Refunded)
+		if err != nil {
+			return err
+		}
+		payment.Refunded = payment.Amount
+		payment.Status = models.PaymentRefunded
+		return h.repo(r).UpdatePayment(*payment)
+	})
+}
+
+// adminPaymentAction runs action on the payment from /admin/{action}-payment/{src}/{id}/do
+// and goes back to its reservation. Payments of a group share the gateway reference,
+// so capturing or voiding one of them changes all of them
+func (h *Handlers) adminPaymentAction(w http.ResponseWriter, r *http.Request, done string,
+	action func(payment *models.Payment) error) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	src := exploded[3]
+	query := ""
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		query = fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
+		return
+	}
+
+	payment, err := h.DB.GetPaymentByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get payment")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
+		return
+	}
+
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show%s", src, payment.ReservationID, query)
+	err = action(payment)
+	if errors.Is(err, payments.ErrWrongState) {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", fmt.Sprintf("payment is %s and can't be %s", payment.Status, done))
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "payment can't be "+done)
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "payment is "+done)
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminInvoice(w http.ResponseWriter, r *http.Request) {
+	h.adminDocument(w, r, models.DocumentInvoice)
+}
+
+func (h *Handlers) AdminReceipt(w http.ResponseWriter, r *http.Request) {
+	h.adminDocument(w, r, models.DocumentReceipt)
+}
+
+// adminDocument downloads the document of the reservation from /admin/reservations/{src}/{id}/{kind}
+func (h *Handlers) adminDocument(w http.ResponseWriter, r *http.Request, kind string) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", exploded[3]), http.StatusSeeOther)
+		return
+	}
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", exploded[3]), http.StatusSeeOther)
+		return
+	}
+
+	inv, pdf, err := h.issueDocument(r, kind, *res)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't issue "+kind)
+		http.Redirect(w, r, fmt.Sprintf("/admin/reservations/%s/%d/show", exploded[3], id), http.StatusSeeOther)
+		return
+	}
+	h.writeDocument(w, inv, pdf)
+}
+
+func (h *Handlers) AdminPostEmailInvoice(w http.ResponseWriter, r *http.Request) {
+	h.adminEmailDocument(w, r, models.DocumentInvoice)
+}
+
+func (h *Handlers) AdminPostEmailReceipt(w http.ResponseWriter, r *http.Request) {
+	h.adminEmailDocument(w, r, models.DocumentReceipt)
+}
+
+// adminEmailDocument emails the document of the reservation from /admin/email-{kind}/{src}/{id}/do
+// to the guest and goes back to the reservation
+func (h *Handlers) adminEmailDocument(w http.ResponseWriter, r *http.Request, kind string) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	src := exploded[3]
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, fmt.Sprintf("/admin/%s-reservations", src), http.StatusSeeOther)
+		return
+	}
+
+	redirectString := fmt.Sprintf("/admin/reservations/%s/%d/show", src, id)
+	year := r.Form.Get("year")
+	month := r.Form.Get("month")
+	if month != "" && year != "" {
+		redirectString += fmt.Sprintf("?y=%s&m=%s", year, month)
+	}
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get reservation")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	err = h.sendDocument(r, kind, *res)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't issue "+kind)
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", fmt.Sprintf("%s is sent to %s", kind, res.Email))
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+// trashKinds name what can be in the trash
+var trashKinds = map[string]string{
+	"reservations": "reservation",
+	"blocks":       "block",
+}
+
+func (h *Handlers) AdminTrash(w http.ResponseWriter, r *http.Request) {
+	reservations, err := h.DB.GetDeletedReservations()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get trash")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+	blocks, err := h.DB.GetDeletedRoomRestrictions()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get trash")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["reservations"] = reservations
+	data["blocks"] = blocks
+	intMap := make(map[string]int)
+	intMap["retention_days"] = int(h.app.TrashRetention.Hours() / 24)
+	err = h.render.Template(w, r, "admin.trash.page.tmpl", &models.TemplateData{
+		Data:   data,
+		IntMap: intMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// trashItem reads what to restore or purge from the url, ok is false when the url is wrong
+// and the user is redirected already
+func (h *Handlers) trashItem(w http.ResponseWriter, r *http.Request) (kind string, id int, ok bool) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 6 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return "", 0, false
+	}
+	kind, ok = trashKinds[exploded[3]]
+	if !ok {
+		h.app.Session.Put(r.Context(), "error", "wrong kind of item")
+		http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+		return "", 0, false
+	}
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+		return "", 0, false
+	}
+	return kind, id, true
+}
+
+func (h *Handlers) AdminPostRestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	kind, id, ok := h.trashItem(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	if kind == "reservation" {
+		err = h.repo(r).RestoreReservation(id)
+	} else {
+		err = h.repo(r).RestoreRoomRestriction(id)
+	}
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.Session.Put(r.Context(), "error", "room is not available on these dates anymore")
+		http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't restore "+kind)
+		http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", kind+" is restored")
+	http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+}
+
+func (h *Handlers) AdminPostPurgeFromTrash(w http.ResponseWriter, r *http.Request) {
+	kind, id, ok := h.trashItem(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	if kind == "reservation" {
+		err = h.repo(r).PurgeReservation(id)
+	} else {
+		err = h.repo(r).PurgeRoomRestriction(id)
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't delete "+kind)
+		http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", kind+" is deleted permanently")
+	http.Redirect(w, r, "/admin/trash", http.StatusSeeOther)
+}
+
+// auditedEntities are the tables the audit log records changes of
+var auditedEntities = []string{
+	"reservations", "reservation_groups", "reservation_notes", "reservation_tags", "reservation_add_ons",
+	"reservation_taxes", "room_restrictions", "folio_entries", "payments", "invoices", "guests", "rooms",
+	"rate_plans", "out_of_order_periods", "housekeeping_tasks", "cancellation_policies", "taxes", "add_ons",
+	"currencies", "waitlist_entries", "users",
+}
+
+// historyPages are the pages which have a history, the history links back to them
+var historyPages = map[string]string{
+	"reservations": "/admin/reservations/all/%d/show",
+	"guests":       "/admin/guests/%d/show",
+	"rooms":        "/admin/rooms#room-%d",
+}
+
+func (h *Handlers) AdminAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	search := models.AuditSearch{
+		Query:  strings.TrimSpace(query.Get("q")),
+		Entity: query.Get("entity"),
+	}
+	var err error
+	if query.Get("from") != "" {
+		search.From, err = time.Parse(h.app.DateLayout, query.Get("from"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad start time")
+			http.Redirect(w, r, "/admin/audit-log", http.StatusSeeOther)
+			return
+		}
+	}
+	if query.Get("to") != "" {
+		search.To, err = time.Parse(h.app.DateLayout, query.Get("to"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad end time")
+			http.Redirect(w, r, "/admin/audit-log", http.StatusSeeOther)
+			return
+		}
+	}
+
+	entries, err := h.DB.SearchAuditLog(search)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get audit log")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["entries"] = entries
+	data["entities"] = auditedEntities
+	stringMap := make(map[string]string)
+	stringMap["q"] = search.Query
+	stringMap["entity"] = search.Entity
+	stringMap["from"] = query.Get("from")
+	stringMap["to"] = query.Get("to")
+	err = h.render.Template(w, r, "admin.audit-log.page.tmpl", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminHistory(w http.ResponseWriter, r *http.Request) {
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 5 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return
+	}
+
+	entity := exploded[3]
+	page, ok := historyPages[entity]
+	if !ok {
+		h.app.Session.Put(r.Context(), "error", "no history for "+entity)
+		http.Redirect(w, r, "/admin/audit-log", http.StatusSeeOther)
+		return
+	}
+	id, err := strconv.Atoi(exploded[4])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/admin/audit-log", http.StatusSeeOther)
+		return
+	}
+
+	entries, err := h.DB.GetEntityHistory(entity, id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get history")
+		http.Redirect(w, r, "/admin/audit-log", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["entries"] = entries
+	stringMap := make(map[string]string)
+	stringMap["entity"] = entity
+	stringMap["back"] = fmt.Sprintf(page, id)
+	intMap := make(map[string]int)
+	intMap["id"] = id
+	err = h.render.Template(w, r, "admin.history.page.tmpl", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+		IntMap:    intMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// maxImportSize is the biggest file the import takes, in bytes
+const maxImportSize = 2 << 20
+
+// errNothingToImport is returned when no file was uploaded for the import
+var errNothingToImport = errors.New("nothing to import")
+
+func (h *Handlers) AdminImport(w http.ResponseWriter, r *http.Request) {
+	data := make(map[string]interface{})
+	data["date_formats"] = importer.DateFormats
+	stringMap := make(map[string]string)
+	stringMap["date_layout"] = h.app.DateLayout
+
+	content := h.app.Session.GetString(r.Context(), "import_csv")
+	if content != "" {
+		kind := h.app.Session.GetString(r.Context(), "import_kind")
+		header, records, err := importer.Read(strings.NewReader(content))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't read file")
+			h.app.Session.Remove(r.Context(), "import_csv")
+			http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+			return
+		}
+		guessed := importer.Guess(kind, header)
+		mapping := make(map[string]int)
+		for _, field := range importer.Fields[kind] {
+			mapping[field.Key] = -1
+			if column, ok := guessed[field.Key]; ok {
+				mapping[field.Key] = column
+			}
+		}
+		if len(records) > 3 {
+			records = records[:3]
+		}
+		data["header"] = header
+		data["sample"] = records
+		data["fields"] = importer.Fields[kind]
+		data["mapping"] = mapping
+		stringMap["kind"] = kind
+		stringMap["file_name"] = h.app.Session.GetString(r.Context(), "import_file")
+	}
+
+	err := h.render.Template(w, r, "admin.import.page.tmpl", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostImport(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseMultipartForm(maxImportSize)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	kind := r.Form.Get("kind")
+	if _, ok := importer.Fields[kind]; !ok {
+		h.app.Session.Put(r.Context(), "error", "unknown kind of rows")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	file, fileHeader, err := r.FormFile("file")
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "choose a file")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	defer file.Close()
+	content, err := io.ReadAll(io.LimitReader(file, maxImportSize+1))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't read file")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	if len(content) > maxImportSize {
+		h.app.Session.Put(r.Context(), "error", "file is too big")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	_, _, err = importer.Read(bytes.NewReader(content))
+	if errors.Is(err, importer.ErrEmptyFile) || errors.Is(err, importer.ErrTooManyRows) {
+		h.app.Session.Put(r.Context(), "error", err.Error())
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "file is not a valid CSV")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "import_csv", string(content))
+	h.app.Session.Put(r.Context(), "import_kind", kind)
+	h.app.Session.Put(r.Context(), "import_file", fileHeader.Filename)
+	http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+}
+
+// importRows reads the uploaded file with the column mapping and the date format of the form and checks every row,
+// rows whose rooms are taken on their dates are errors
+func (h *Handlers) importRows(r *http.Request) (string, []importer.Row, error) {
+	content := h.app.Session.GetString(r.Context(), "import_csv")
+	if content == "" {
+		return "", nil, errNothingToImport
+	}
+	kind := h.app.Session.GetString(r.Context(), "import_kind")
+	header, records, err := importer.Read(strings.NewReader(content))
+	if err != nil {
+		return kind, nil, err
+	}
+
+	mapping := make(importer.Mapping)
+	for _, field := range importer.Fields[kind] {
+		column, err := strconv.Atoi(r.Form.Get("map_" + field.Key))
+		if err == nil && column >= 0 && column < len(header) {
+			mapping[field.Key] = column
+		}
+	}
+	layout := h.app.DateLayout
+	for _, format := range importer.DateFormats {
+		if format.Layout == r.Form.Get("date_format") {
+			layout = format.Layout
+		}
+	}
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		return kind, nil, err
+	}
+
+	rows := importer.Parse(kind, records, mapping, rooms, layout)
+	for i := range rows {
+		if !rows[i].Valid() {
+			continue
+		}
+		roomID, start, end := rows[i].Span()
+		available, err := h.DB.LookForAvailabilityOfRoom(start, end, roomID)
+		if err != nil {
+			return kind, nil, err
+		}
+		if !available {
+			rows[i].Errors = append(rows[i].Errors, "Room is taken on these dates")
+		}
+	}
+	return kind, rows, nil
+}
+
+func (h *Handlers) AdminPostImportPreview(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	kind, rows, err := h.importRows(r)
+	if errors.Is(err, errNothingToImport) {
+		h.app.Session.Put(r.Context(), "error", "nothing to import")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't check rows")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	valid := 0
+	for _, row := range rows {
+		if row.Valid() {
+			valid++
+		}
+	}
+	settings := url.Values{}
+	for key, values := range r.PostForm {
+		if strings.HasPrefix(key, "map_") || key == "date_format" {
+			settings[key] = values
+		}
+	}
+
+	data := make(map[string]interface{})
+	data["rows"] = rows
+	data["settings"] = settings
+	stringMap := make(map[string]string)
+	stringMap["kind"] = kind
+	intMap := make(map[string]int)
+	intMap["valid"] = valid
+	intMap["invalid"] = len(rows) - valid
+	err = h.render.Template(w, r, "admin.import-report.page.tmpl", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+		IntMap:    intMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminPostImportCommit(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	kind, rows, err := h.importRows(r)
+	if errors.Is(err, errNothingToImport) {
+		h.app.Session.Put(r.Context(), "error", "nothing to import")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't check rows")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	userID := h.app.Session.GetInt(r.Context(), "user_id")
+	reservations := make([]models.Reservation, 0)
+	blocks := make([]models.RoomRestriction, 0)
+	for _, row := range rows {
+		if !row.Valid() {
+			continue
+		}
+		if kind == importer.Blocks {
+			blocks = append(blocks, row.Block)
+			continue
+		}
+		res := row.Reservation
+		if res.ConfirmationCode == "" {
+			res.ConfirmationCode = helpers.NewConfirmationCode()
+		}
+		res.CreatedBy = userID
+		reservations = append(reservations, res)
+	}
+	imported := len(reservations) + len(blocks)
+	if imported == 0 {
+		h.app.Session.Put(r.Context(), "error", "no valid rows to import")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	back := "/admin/all-reservations"
+	if kind == importer.Blocks {
+		err = h.repo(r).ImportBlocks(blocks)
+		back = "/admin/reservation-calendar"
+	} else {
+		err = h.repo(r).ImportReservations(reservations)
+	}
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "a room was taken while importing, check the rows again")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't import rows")
+		http.Redirect(w, r, "/admin/import", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Remove(r.Context(), "import_csv")
+	h.app.Session.Remove(r.Context(), "import_kind")
+	h.app.Session.Remove(r.Context(), "import_file")
+	h.app.Session.Put(r.Context(), "flash", fmt.Sprintf("%d %s imported, %d rows skipped", imported, kind, len(rows)-imported))
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// report reads the period of the report from the URL and builds it, the current month by default.
+// It tells the admin what went wrong when it fails
+func (h *Handlers) report(w http.ResponseWriter, r *http.Request) (reports.Report, bool) {
+	day := today()
+	from := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+	var err error
+	query := r.URL.Query()
+	if query.Get("from") != "" {
+		from, err = time.Parse(h.app.DateLayout, query.Get("from"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad start date")
+			http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+			return reports.Report{}, false
+		}
+	}
+	if query.Get("to") != "" {
+		to, err = time.Parse(h.app.DateLayout, query.Get("to"))
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "bad end date")
+			http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+			return reports.Report{}, false
+		}
+	}
+	if to.Before(from) {
+		h.app.Session.Put(r.Context(), "error", "period ends before it starts")
+		http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+		return reports.Report{}, false
+	}
+	if pricing.Nights(from, to) >= reports.MaxDays {
+		h.app.Session.Put(r.Context(), "error", fmt.Sprintf("period can't be longer than %d days", reports.MaxDays))
+		http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+		return reports.Report{}, false
+	}
+
+	report, err := h.buildReport(from, to)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get report")
+		http.Redirect(w, r, "/admin/dashboard", http.StatusSeeOther)
+		return reports.Report{}, false
+	}
+	return report, true
+}
+
+// buildReport loads the rooms and what took them over the period and builds the report
+func (h *Handlers) buildReport(from, to time.Time) (reports.Report, error) {
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		return reports.Report{}, err
+	}
+	reservations, err := h.DB.GetReportReservations(from, to)
+	if err != nil {
+		return reports.Report{}, err
+	}
+	closures, err := h.DB.GetRoomClosures(from, to)
+	if err != nil {
+		return reports.Report{}, err
+	}
+	return reports.Build(from, to, rooms, reservations, closures), nil
+}
+
+// reportPeriod is the URL query of the report period
+func (h *Handlers) reportPeriod(report reports.Report) url.Values {
+	return url.Values{
+		"from": {report.From.Format(h.app.DateLayout)},
+		"to":   {report.To.Format(h.app.DateLayout)},
+	}
+}
+
+func (h *Handlers) AdminReports(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["report"] = report
+	stringMap := make(map[string]string)
+	stringMap["from"] = report.From.Format(h.app.DateLayout)
+	stringMap["to"] = report.To.Format(h.app.DateLayout)
+	err := h.render.Template(w, r, "admin.reports.page.tmpl", &models.TemplateData{
+		Data:      data,
+		StringMap: stringMap,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminReportsData(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	out, err := json.Marshal(report)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "json marshalling error")
+		http.Redirect(w, r, "/admin/reports", http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(out)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+func (h *Handlers) AdminExportReport(w http.ResponseWriter, r *http.Request) {
+	report, ok := h.report(w, r)
+	if !ok {
+		return
+	}
+
+	table := r.URL.Query().Get("table")
+	out := new(bytes.Buffer)
+	err := report.WriteCSV(out, table)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "unknown report table")
+		http.Redirect(w, r, "/admin/reports?"+h.reportPeriod(report).Encode(), http.StatusSeeOther)
+		return
+	}
+	w.Header().Set("Content-Type", export.ContentType(export.CSV))
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="report-%s-%s-%s.csv"`,
+		table, report.From.Format(h.app.DateLayout), report.To.Format(h.app.DateLayout)))
+	_, err = w.Write(out.Bytes())
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
 }
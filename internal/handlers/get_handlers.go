@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/porky256/course-project/internal/forms"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/repository"
 	"net/http"
 	"strconv"
 	"strings"
@@ -66,14 +68,38 @@ func (h *Handlers) MakeReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	res.Room = room
+	if !applyRatePlan(&res, res.RatePlanID) {
+		applyRatePlan(&res, 0)
+	}
 
 	h.app.Session.Put(r.Context(), "reservation", res)
+	h.extendHolds(r)
+
+	h.renderMakeReservation(w, r, forms.New(nil), res, h.getCart(r))
+}
+
+// renderMakeReservation renders the reservation form with a quote of the stay and the add-ons on offer
+func (h *Handlers) renderMakeReservation(w http.ResponseWriter, r *http.Request, form *forms.Form,
+	res models.Reservation, cart []models.Reservation) {
+	h.quoteTaxes(&res)
+	addOns, err := h.DB.GetAllAddOns()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	quantities := make(map[int]int)
+	for _, item := range res.AddOns {
+		quantities[item.AddOnID] = item.Quantity
+	}
 
 	data := make(map[string]interface{})
 	data["reservation"] = res
+	data["cart"] = cart
+	data["card_required"] = cardRequired(res, cart)
+	data["add_ons"] = addOns
+	data["add_on_quantities"] = quantities
 
 	err = h.render.Template(w, r, "make-reservation.page.tmpl", &models.TemplateData{
-		Form: forms.New(nil),
+		Form: form,
 		Data: data,
 	})
 	if err != nil {
@@ -83,7 +109,14 @@ func (h *Handlers) MakeReservation(w http.ResponseWriter, r *http.Request) {
 
 // SearchAvailability renders search availability page
 func (h *Handlers) SearchAvailability(w http.ResponseWriter, r *http.Request) {
-	err := h.render.Template(w, r, "search-availability.page.tmpl", &models.TemplateData{})
+	h.extendHolds(r)
+
+	data := make(map[string]interface{})
+	data["cart"] = h.getCart(r)
+
+	err := h.render.Template(w, r, "search-availability.page.tmpl", &models.TemplateData{
+		Data: data,
+	})
 	if err != nil {
 		h.app.ErrorLog.Println(err)
 	}
@@ -91,6 +124,20 @@ func (h *Handlers) SearchAvailability(w http.ResponseWriter, r *http.Request) {
 
 // ReservationSummary handles request for reservation summary
 func (h *Handlers) ReservationSummary(w http.ResponseWriter, r *http.Request) {
+	if group, ok := h.app.Session.Get(r.Context(), "reservation_group").(models.ReservationGroup); ok {
+		h.app.Session.Remove(r.Context(), "reservation_group")
+		data := make(map[string]interface{})
+		data["group"] = group
+
+		err := h.render.Template(w, r, "reservation-summary.page.tmpl", &models.TemplateData{
+			Data: data,
+		})
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		}
+		return
+	}
+
 	res, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation)
 	if !ok {
 		h.app.ErrorLog.Println("Can't find reservation >:(")
@@ -136,7 +183,26 @@ func (h *Handlers) ChooseRoom(w http.ResponseWriter, r *http.Request) {
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
+	h.releaseHold(res.HoldID)
 	res.RoomID = roomID
+	res.HoldID, err = h.DB.HoldRoom(&models.RoomRestriction{
+		StartDate: res.StartDate,
+		EndDate:   res.EndDate,
+		RoomID:    roomID,
+		ExpiresAt: h.holdExpiration(),
+	})
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "sorry, this room has just been taken")
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't hold the room")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
 	h.app.Session.Put(r.Context(), "reservation", res)
 	http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
 }
@@ -181,6 +247,147 @@ func (h *Handlers) BookRoom(w http.ResponseWriter, r *http.Request) {
 		Room:      room,
 	}
 
+	if prev, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation); ok {
+		h.releaseHold(prev.HoldID)
+	}
+	res.HoldID, err = h.DB.HoldRoom(&models.RoomRestriction{
+		StartDate: startDate,
+		EndDate:   endDate,
+		RoomID:    roomID,
+		ExpiresAt: h.holdExpiration(),
+	})
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "sorry, this room has just been taken")
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't hold the room")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
 	h.app.Session.Put(r.Context(), "reservation", res)
 	http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
 }
+
+// Waitlist renders the waitlist form, dates and room are prefilled from the url
+func (h *Handlers) Waitlist(w http.ResponseWriter, r *http.Request) {
+	h.renderWaitlist(w, r, forms.New(r.URL.Query()))
+}
+
+// renderWaitlist renders the waitlist form with the list of rooms to choose from
+func (h *Handlers) renderWaitlist(w http.ResponseWriter, r *http.Request, form *forms.Form) {
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get rooms")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	data := make(map[string]interface{})
+	data["rooms"] = rooms
+
+	err = h.render.Template(w, r, "waitlist.page.tmpl", &models.TemplateData{
+		Form: form,
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// MyBooking renders the booking the guest has looked up, or the form to look it up
+func (h *Handlers) MyBooking(w http.ResponseWriter, r *http.Request) {
+	code := h.app.Session.GetString(r.Context(), "booking_code")
+	if code == "" {
+		h.renderMyBooking(w, r, forms.New(nil), nil)
+		return
+	}
+
+	reservations, err := h.DB.GetReservationsByConfirmationCode(code)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get your booking")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	h.renderMyBooking(w, r, forms.New(nil), reservations)
+}
+
+// renderMyBooking renders the guest's reservations, the lookup form is shown when there are none
+func (h *Handlers) renderMyBooking(w http.ResponseWriter, r *http.Request, form *forms.Form,
+	reservations []models.Reservation) {
+	data := make(map[string]interface{})
+	data["reservations"] = reservations
+	err := h.render.Template(w, r, "my-booking.page.tmpl", &models.TemplateData{
+		Form: form,
+		Data: data,
+	})
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// MyBookingInvoice handles request for the invoice of a reservation of the looked up booking
+func (h *Handlers) MyBookingInvoice(w http.ResponseWriter, r *http.Request) {
+	h.guestDocument(w, r, models.DocumentInvoice)
+}
+
+// MyBookingReceipt handles request for the receipt of a reservation of the looked up booking
+func (h *Handlers) MyBookingReceipt(w http.ResponseWriter, r *http.Request) {
+	h.guestDocument(w, r, models.DocumentReceipt)
+}
+
+// guestDocument downloads the document from /my-booking/{id}/{kind}, guests only get documents
+// of reservations booked under the code they have looked up
+func (h *Handlers) guestDocument(w http.ResponseWriter, r *http.Request, kind string) {
+	code := h.app.Session.GetString(r.Context(), "booking_code")
+	if code == "" {
+		h.app.Session.Put(r.Context(), "error", "please look up your booking first")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 4 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+
+	id, err := strconv.Atoi(exploded[2])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "wrong id")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+
+	res, err := h.DB.GetReservationByID(id)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find reservation")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+	if res.ConfirmationCode != code {
+		h.app.ErrorLog.Printf("reservation %d is not booked under %s", id, code)
+		h.app.Session.Put(r.Context(), "error", "can't find reservation")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+
+	inv, pdf, err := h.issueDocument(r, kind, *res)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't issue "+kind)
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+	h.writeDocument(w, inv, pdf)
+}
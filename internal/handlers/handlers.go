@@ -1,32 +1,461 @@
 package handlers
 
 import (
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/porky256/course-project/internal/availability"
 	"github.com/porky256/course-project/internal/config"
+	"github.com/porky256/course-project/internal/currency"
 	"github.com/porky256/course-project/internal/driver"
+	"github.com/porky256/course-project/internal/forms"
+	"github.com/porky256/course-project/internal/invoice"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/payments"
+	"github.com/porky256/course-project/internal/pricing"
 	"github.com/porky256/course-project/internal/render"
 	"github.com/porky256/course-project/internal/repository"
 	"github.com/porky256/course-project/internal/repository/dbrepo"
 	mock_dbrepo "github.com/porky256/course-project/internal/repository/mock"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// mailFrom is the address guests get emails from
+const mailFrom = "info@fsbb.ca"
+
 type Handlers struct {
-	app    *config.AppConfig
-	render *render.Render
-	DB     repository.DatabaseRepo
+	app     *config.AppConfig
+	render  *render.Render
+	DB      repository.DatabaseRepo
+	gateway payments.PaymentGateway
 }
 
-func NewHandlers(app *config.AppConfig, render *render.Render, db *driver.DB) *Handlers {
+func NewHandlers(app *config.AppConfig, render *render.Render, db *driver.DB, gateway payments.PaymentGateway) *Handlers {
 	return &Handlers{
-		app:    app,
-		render: render,
-		DB:     dbrepo.NewPostgresDB(db.DB, app),
+		app:     app,
+		render:  render,
+		DB:      dbrepo.NewPostgresDB(db.DB, app),
+		gateway: gateway,
 	}
 }
 
-func NewTestHandlers(app *config.AppConfig, render *render.Render, db *mock_dbrepo.MockDatabaseRepo) *Handlers {
+func NewTestHandlers(app *config.AppConfig, render *render.Render, db *mock_dbrepo.MockDatabaseRepo,
+	gateway payments.PaymentGateway) *Handlers {
 	return &Handlers{
-		app:    app,
-		render: render,
-		DB:     db,
+		app:     app,
+		render:  render,
+		DB:      db,
+		gateway: gateway,
+	}
+}
+
+// LoadCurrencies refreshes the exchange rate table prices are shown with
+func (h *Handlers) LoadCurrencies() error {
+	currencies, err := h.DB.GetAllCurrencies()
+	if err != nil {
+		return err
+	}
+	h.app.Currencies.Set(currencies)
+	return nil
+}
+
+// getCart returns rooms the guest has already added to the booking
+func (h *Handlers) getCart(r *http.Request) []models.Reservation {
+	cart, _ := h.app.Session.Get(r.Context(), "cart").([]models.Reservation)
+	return cart
+}
+
+// cartConflict checks if reservation overlaps any reservation of the same room in the cart
+func cartConflict(cart []models.Reservation, reservation models.Reservation) bool {
+	for _, item := range cart {
+		if item.RoomID == reservation.RoomID &&
+			item.StartDate.Before(reservation.EndDate) &&
+			reservation.StartDate.Before(item.EndDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdExpiration returns time until which a room chosen right now stays held for the guest
+func (h *Handlers) holdExpiration() time.Time {
+	return time.Now().Add(h.app.HoldDuration)
+}
+
+// extendHolds keeps holds of the guest's rooms alive while the guest is still booking
+func (h *Handlers) extendHolds(r *http.Request) {
+	var ids []int
+	if res, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation); ok && res.HoldID != 0 {
+		ids = append(ids, res.HoldID)
+	}
+	for _, item := range h.getCart(r) {
+		if item.HoldID != 0 {
+			ids = append(ids, item.HoldID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	err := h.DB.ExtendRoomHolds(ids, h.holdExpiration())
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// applyRatePlan prices the reservation by the chosen rate plan of its room, or by the room itself when
+// ratePlanID is 0. A rate plan without own cancellation policy uses the room's one
+func applyRatePlan(res *models.Reservation, ratePlanID int) bool {
+	res.RatePlanID = 0
+	res.RatePlan = nil
+	res.NightlyRate = 0
+	res.CancellationPolicyID = 0
+	res.CancellationPolicy = nil
+	if res.Room == nil {
+		return ratePlanID == 0
+	}
+
+	res.NightlyRate = res.Room.Price
+	res.CancellationPolicyID = res.Room.CancellationPolicyID
+	res.CancellationPolicy = res.Room.CancellationPolicy
+	if ratePlanID == 0 {
+		return true
+	}
+
+	for _, plan := range res.Room.RatePlans {
+		if plan.ID != ratePlanID {
+			continue
+		}
+		plan := plan
+		res.RatePlanID = plan.ID
+		res.RatePlan = &plan
+		res.NightlyRate = plan.Price
+		if plan.CancellationPolicyID != 0 {
+			res.CancellationPolicyID = plan.CancellationPolicyID
+			res.CancellationPolicy = plan.CancellationPolicy
+		}
+		return true
+	}
+	return false
+}
+
+// quoteTaxes works out taxes of the reservation to show the guest what the stay costs. The saved booking
+// is taxed by the repository, so a quote without taxes is better than no quote when they can't be loaded
+func (h *Handlers) quoteTaxes(reservations ...*models.Reservation) {
+	taxes, err := h.DB.GetAllTaxes()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		return
+	}
+	for _, res := range reservations {
+		res.Taxes = pricing.ApplyTaxes(*res, taxes)
+	}
+}
+
+// chosenAddOns reads quantities of add-ons picked on the reservation form, keyed by add-on id.
+// Fields are named add_on_<id>, add-ons left at 0 are not picked
+func chosenAddOns(form *forms.Form) map[int]int {
+	chosen := make(map[int]int)
+	for field := range form.Values {
+		if !strings.HasPrefix(field, "add_on_") || strings.TrimSpace(form.Get(field)) == "" {
+			continue
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(field, "add_on_"))
+		if err != nil {
+			form.Errors.Add("add_ons", "Unknown add-on")
+			continue
+		}
+		quantity, err := strconv.Atoi(strings.TrimSpace(form.Get(field)))
+		if err != nil || quantity < 0 {
+			form.Errors.Add("add_ons", "Quantity must be a whole number")
+			continue
+		}
+		if quantity > 0 {
+			chosen[id] = quantity
+		}
+	}
+	return chosen
+}
+
+// pickAddOns turns the chosen quantities into line items of the reservation, in catalog order
+func pickAddOns(form *forms.Form, res models.Reservation, catalog []models.AddOn,
+	chosen map[int]int) []models.ReservationAddOn {
+	items := make([]models.ReservationAddOn, 0, len(chosen))
+	for _, addOn := range catalog {
+		quantity, ok := chosen[addOn.ID]
+		if !ok {
+			continue
+		}
+		delete(chosen, addOn.ID)
+		if addOn.DailyLimit > 0 && quantity > addOn.DailyLimit {
+			form.Errors.Add("add_ons", fmt.Sprintf("Only %d of %s can be booked", addOn.DailyLimit, addOn.Name))
+			continue
+		}
+		items = append(items, pricing.NewReservationAddOn(res, addOn, quantity))
+	}
+	if len(chosen) > 0 {
+		form.Errors.Add("add_ons", "Unknown add-on")
+	}
+	return items
+}
+
+// depositOf returns the deposit taken at booking for all the reservations
+func depositOf(reservations ...models.Reservation) int {
+	total := 0
+	for _, res := range reservations {
+		total += pricing.Deposit(res)
+	}
+	return total
+}
+
+// cardRequired tells if a deposit can be taken for the booking, either for the cart or for one of the room's rate plans
+func cardRequired(res models.Reservation, cart []models.Reservation) bool {
+	if depositOf(cart...)+depositOf(res) > 0 {
+		return true
+	}
+	if res.Room == nil {
+		return false
+	}
+	for i := range res.Room.RatePlans {
+		option := res
+		option.RatePlan = &res.Room.RatePlans[i]
+		option.NightlyRate = option.RatePlan.Price
+		if pricing.Deposit(option) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// captureDeposit takes the authorized deposit and records each reservation's share of it.
+// Nothing is done when reference is empty, i.e. no deposit was authorized
+func (h *Handlers) captureDeposit(r *http.Request, reference string, reservations []models.Reservation) {
+	if reference == "" {
+		return
+	}
+	status := models.PaymentCaptured
+	err := h.gateway.Capture(reference)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		status = models.PaymentAuthorized
+	}
+
+	for i := range reservations {
+		amount := pricing.Deposit(reservations[i])
+		if amount == 0 {
+			continue
+		}
+		payment := models.Payment{
+			ReservationID: reservations[i].ID,
+			Amount:        amount,
+			Status:        status,
+			Reference:     reference,
+		}
+		_, err = h.repo(r).InsertPayment(&payment)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			continue
+		}
+		reservations[i].PaymentStatus = status
+	}
+}
+
+// voidDeposit releases the authorized deposit when the booking could not be saved
+func (h *Handlers) voidDeposit(reference string) {
+	if reference == "" {
+		return
+	}
+	err := h.gateway.Void(reference)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// releaseHold frees the room held by the guest, if any
+func (h *Handlers) releaseHold(holdID int) {
+	if holdID == 0 {
+		return
+	}
+	err := h.DB.ReleaseRoomHold(holdID)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// suggestAlternatives looks for other dates and split stays when no room is free for the whole stay
+func (h *Handlers) suggestAlternatives(start, end time.Time) (availability.Suggestions, error) {
+	rooms, err := h.DB.GetAllRooms()
+	if err != nil {
+		return availability.Suggestions{}, err
+	}
+	restrictions, err := h.DB.GetActiveRoomRestrictionsWithinDates(
+		start.AddDate(0, 0, -availability.MaxShift),
+		end.AddDate(0, 0, availability.MaxShift),
+	)
+	if err != nil {
+		return availability.Suggestions{}, err
+	}
+	return availability.Suggest(rooms, restrictions, start, end, today()), nil
+}
+
+// today returns the current date the way stay dates are stored
+func today() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// stayRuleBreak tells which stay rule the reservation breaks, empty if none. Guests can't pick an arrival
+// in the past on the site, staff may still book one, e.g. a walk-in entered late, by overriding the rule
+func stayRuleBreak(res models.Reservation, today time.Time) string {
+	if res.StartDate.Before(today) {
+		return "Arrival is in the past"
+	}
+	return ""
+}
+
+// notifyWaitlist emails the earliest waiting guest whose stay now fits into the room freed on dates
+func (h *Handlers) notifyWaitlist(r *http.Request, roomID int, start, end time.Time) {
+	entries, err := h.DB.GetWaitingEntriesForRoom(roomID, start, end)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		return
+	}
+	for _, entry := range entries {
+		ok, err := h.DB.LookForAvailabilityOfRoom(entry.StartDate, entry.EndDate, roomID)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			return
+		}
+		if !ok {
+			continue
+		}
+		err = h.sendWaitlistEmail(r, entry)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		}
+		return
+	}
+}
+
+// sendWaitlistEmail tells the guest a room is free on their dates and marks the entry as notified
+func (h *Handlers) sendWaitlistEmail(r *http.Request, entry models.WaitlistEntry) error {
+	content := fmt.Sprintf(`
+		<strong>A room is available</strong><br>
+		Dear %s,<br>
+		good news: a room has become available for your stay from %s to %s.<br>
+		Rooms are not held for the waitlist, so please book it as soon as you can.
+	`, html.EscapeString(entry.FirstName), entry.StartDate.Format(h.app.DateLayout),
+		entry.EndDate.Format(h.app.DateLayout))
+	h.app.MailChan <- models.MailData{
+		To:      entry.Email,
+		From:    mailFrom,
+		Subject: "A room is available on your dates",
+		Content: content,
+	}
+	return h.repo(r).MarkWaitlistEntryNotified(entry.ID)
+}
+
+// sendConfirmation emails the guest their booking with the code they can look it up by
+func (h *Handlers) sendConfirmation(res models.Reservation) {
+	room := ""
+	if res.Room != nil {
+		room = res.Room.Name
+	}
+	content := fmt.Sprintf(`
+		<strong>Reservation confirmation</strong><br>
+		Dear %s,<br>
+		your stay in %s from %s to %s is booked, your confirmation code is %s.<br>
+		The stay costs %s in total. You can see your booking on our site with this code and your email.
+	`, res.FirstName, room, res.StartDate.Format(h.app.DateLayout), res.EndDate.Format(h.app.DateLayout),
+		res.ConfirmationCode, currency.Format(pricing.Total(res), h.app.Currencies.Base()))
+	h.app.MailChan <- models.MailData{
+		To:      res.Email,
+		From:    mailFrom,
+		Subject: fmt.Sprintf("Your reservation %s", res.ConfirmationCode),
+		Content: content,
+	}
+}
+
+// sendStayChange tells the guest their booking now has other dates or another room
+func (h *Handlers) sendStayChange(old, res models.Reservation) {
+	room := ""
+	if res.Room != nil {
+		room = res.Room.Name
+	}
+	content := fmt.Sprintf(`
+		<strong>Your reservation has changed</strong><br>
+		Dear %s,<br>
+		your stay booked from %s to %s under confirmation code %s is now in %s from %s to %s.<br>
+		Please contact us if anything doesn't look right.
+	`, res.FirstName, old.StartDate.Format(h.app.DateLayout), old.EndDate.Format(h.app.DateLayout),
+		res.ConfirmationCode, room, res.StartDate.Format(h.app.DateLayout), res.EndDate.Format(h.app.DateLayout))
+	h.app.MailChan <- models.MailData{
+		To:      res.Email,
+		From:    mailFrom,
+		Subject: fmt.Sprintf("Your reservation %s has changed", res.ConfirmationCode),
+		Content: content,
+	}
+}
+
+// issueDocument numbers the invoice or receipt of the reservation and renders it as PDF
+func (h *Handlers) issueDocument(r *http.Request, kind string, res models.Reservation) (models.Invoice, []byte, error) {
+	inv, err := h.repo(r).IssueInvoice(res.ID, kind)
+	if err != nil {
+		return models.Invoice{}, nil, err
 	}
+	return *inv, invoice.Build(h.app.Property, *inv, res), nil
+}
+
+// writeDocument sends the PDF of the document as a download
+func (h *Handlers) writeDocument(w http.ResponseWriter, inv models.Invoice, pdf []byte) {
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, invoice.Filename(inv)))
+	_, err := w.Write(pdf)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+}
+
+// sendDocument emails the invoice or receipt of the reservation to the guest as an attachment
+func (h *Handlers) sendDocument(r *http.Request, kind string, res models.Reservation) error {
+	inv, pdf, err := h.issueDocument(r, kind, res)
+	if err != nil {
+		return err
+	}
+	content := fmt.Sprintf(`
+		<strong>Your %s</strong><br>
+		Dear %s,<br>
+		please find attached %s %s for your stay from %s to %s.<br>
+		Thank you for staying with us.
+	`, kind, res.FirstName, kind, inv.Number,
+		res.StartDate.Format(h.app.DateLayout), res.EndDate.Format(h.app.DateLayout))
+	h.app.MailChan <- models.MailData{
+		To:      res.Email,
+		From:    mailFrom,
+		Subject: fmt.Sprintf("Your %s %s", kind, inv.Number),
+		Content: content,
+		Attachments: []models.Attachment{
+			{Name: invoice.Filename(inv), MimeType: "application/pdf", Data: pdf},
+		},
+	}
+	return nil
+}
+
+// requestActor tells where the request comes from, the user is left for the caller
+func requestActor(r *http.Request) models.Actor {
+	return models.Actor{
+		IP:        r.RemoteAddr,
+		UserAgent: r.UserAgent(),
+		Request:   r.Method + " " + r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+// repo returns the repository which records the user making the request in the audit log
+func (h *Handlers) repo(r *http.Request) repository.DatabaseRepo {
+	actor := requestActor(r)
+	actor.UserID = h.app.Session.GetInt(r.Context(), "user_id")
+	return h.DB.WithActor(actor)
 }
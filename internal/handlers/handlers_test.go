@@ -1,9 +1,12 @@
 package handlers_test
 
 import (
+	"bytes"
 	"context"
 	"encoding/gob"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -11,12 +14,17 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"github.com/porky256/course-project/internal/config"
+	"github.com/porky256/course-project/internal/currency"
+	"github.com/porky256/course-project/internal/frontdesk"
 	"github.com/porky256/course-project/internal/handlers"
 	"github.com/porky256/course-project/internal/helpers"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/payments"
 	"github.com/porky256/course-project/internal/render"
+	"github.com/porky256/course-project/internal/repository"
 	mock_dbrepo "github.com/porky256/course-project/internal/repository/mock"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -38,6 +46,7 @@ var _ = Describe("Handlers", Ordered, func() {
 	var ctrl *gomock.Controller
 	var h *handlers.Handlers
 	var mockDB *mock_dbrepo.MockDatabaseRepo
+	var gateway *payments.Fake
 	BeforeAll(func() {
 		ctrl = gomock.NewController(GinkgoT())
 		gob.Register(models.Reservation{})
@@ -46,10 +55,14 @@ var _ = Describe("Handlers", Ordered, func() {
 		app.InfoLog = log.New(os.Stdout, "INFO\t", log.Ldate|log.Ltime)
 		app.ErrorLog = log.New(os.Stdout, "ERROR\t", log.Ldate|log.Ltime|log.Lshortfile)
 		app.MailChan = make(chan models.MailData, 100)
+		app.Currencies = currency.NewTable(models.Currency{Code: "CAD", Symbol: "$"})
+		app.FrontDesk = frontdesk.Hours{CheckIn: 15 * time.Hour, CheckOut: 11 * time.Hour, NoShow: 26 * time.Hour}
 		helpers.NewHelpers(&app)
 		r := render.NewRender(&app)
 		mockDB = mock_dbrepo.NewMockDatabaseRepo(ctrl)
-		h = handlers.NewTestHandlers(&app, r, mockDB)
+		mockDB.EXPECT().WithActor(gomock.Any()).Return(mockDB).AnyTimes()
+		gateway = payments.NewFake("secret")
+		h = handlers.NewTestHandlers(&app, r, mockDB, gateway)
 		server = httptest.NewTLSServer(routes(h))
 	})
 
@@ -73,9 +86,11 @@ var _ = Describe("Handlers", Ordered, func() {
 			{"sa", "/search-availability", "GET", http.StatusOK},
 			{"contact", "/contact", "GET", http.StatusOK},
 			{"adminDashboard", "/admin/dashboard", "GET", http.StatusOK},
+			{"deleteReservationByLink", "/admin/delete-reservation/all/1/do", "GET", http.StatusMethodNotAllowed},
 		}
 
 		It("iterate through all basic handlers", func() {
+			mockDB.EXPECT().GetFrontDeskReservations(gomock.Any()).Return(nil, nil).Times(1)
 			for _, e := range theTests {
 				By(e.name)
 				resp, err := server.Client().Get(server.URL + e.url)
@@ -152,6 +167,8 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("test with right data", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
 			mockDB.EXPECT().GetRoomByID(gomock.Eq(1)).Return(&models.Room{
 				ID:   1,
 				Name: "room name",
@@ -166,6 +183,23 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("test with held room", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			basicRes.HoldID = 7
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(1)).Return(&models.Room{
+				ID:   1,
+				Name: "room name",
+			}, nil).Times(1)
+			mockDB.EXPECT().ExtendRoomHolds(gomock.Eq([]int{7}), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/some-url",
+			}
+			doall(data)
+		})
+
 		It("test with incorrect room", func() {
 			mockDB.EXPECT().GetRoomByID(gomock.Eq(100)).Return(nil, errors.New("no such room"))
 			basicRes.RoomID = 100
@@ -211,8 +245,7 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("normal", func() {
-			mockDB.EXPECT().InsertReservation(gomock.Any()).Return(1, nil)
-			mockDB.EXPECT().InsertRoomRestriction(gomock.Any()).Return(1, nil)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(1, nil)
 			mockDB.EXPECT().GetRoomByID(gomock.Any()).Return(&models.Room{
 				ID:   1,
 				Name: "room name",
@@ -250,6 +283,8 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("form is invalid", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
 			basicVal.Set("first_name", "")
 			data := testData{
 				val:         &basicVal,
@@ -262,7 +297,7 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("can't insert reservation", func() {
-			mockDB.EXPECT().InsertReservation(gomock.Any()).Return(0, errors.New("can't insert reservation"))
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, errors.New("can't insert reservation"))
 			data := testData{
 				val:         &basicVal,
 				reservation: &basicRes,
@@ -274,14 +309,59 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
-		It("can't insert room restriction", func() {
-			mockDB.EXPECT().InsertReservation(gomock.Any()).Return(1, nil)
-			mockDB.EXPECT().InsertRoomRestriction(gomock.Any()).Return(0, errors.New("can't insert room restriction"))
+		It("room is taken in the meantime", func() {
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, repository.ErrRoomNotAvailable)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, the room is no longer available for your dates",
+				url:         "/some-url",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("normal with hold", func() {
+			basicRes.HoldID = 3
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				DoAndReturn(func(res *models.Reservation) (int, error) {
+					Expect(res.HoldID).To(Equal(3))
+					Expect(res.ConfirmationCode).ToNot(BeEmpty())
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/some-url",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("hold expired and room is taken", func() {
+			basicRes.HoldID = 3
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, repository.ErrRoomNotAvailable).Times(1)
 			data := testData{
 				val:         &basicVal,
 				reservation: &basicRes,
 				statusCode:  http.StatusSeeOther,
-				errorString: "can't insert room restriction",
+				errorString: "sorry, your hold has expired and the room is no longer available",
+				url:         "/some-url",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("can't insert reservation with hold", func() {
+			basicRes.HoldID = 3
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't insert reservation",
 				url:         "/some-url",
 				redirectURL: "/",
 			}
@@ -332,7 +412,7 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("bad start", func() {
-			basicVal.Set("start", "bad")
+			basicVal["start"][0] = "bad"
 			data := testData{
 				val:         &basicVal,
 				statusCode:  http.StatusSeeOther,
@@ -370,12 +450,47 @@ var _ = Describe("Handlers", Ordered, func() {
 
 		It("no available rooms", func() {
 			mockDB.EXPECT().AvailabilityOfAllRooms(gomock.Any(), gomock.Any()).Return([]models.Room{}, nil)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
+			mockDB.EXPECT().GetActiveRoomRestrictionsWithinDates(gomock.Any(), gomock.Any()).
+				Return([]models.RoomRestriction{}, nil).Times(1)
 			data := testData{
 				val:         &basicVal,
 				statusCode:  http.StatusSeeOther,
 				errorString: "sorry, no available rooms on this dates >:(",
 				url:         "/some-url",
-				redirectURL: "/search-availability",
+				redirectURL: "/waitlist?start=2050-01-01&end=2050-01-02",
+			}
+			doall(data)
+		})
+
+		It("no available rooms with suggestions", func() {
+			mockDB.EXPECT().AvailabilityOfAllRooms(gomock.Any(), gomock.Any()).Return([]models.Room{}, nil)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "name 1"}}, nil).Times(1)
+			mockDB.EXPECT().GetActiveRoomRestrictionsWithinDates(gomock.Any(), gomock.Any()).
+				Return([]models.RoomRestriction{
+					{
+						RoomID:    1,
+						StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/some-url",
+			}
+			doall(data)
+		})
+
+		It("no available rooms and error in suggestions", func() {
+			mockDB.EXPECT().AvailabilityOfAllRooms(gomock.Any(), gomock.Any()).Return([]models.Room{}, nil)
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, no available rooms on this dates >:(",
+				url:         "/some-url",
+				redirectURL: "/waitlist?start=2050-01-01&end=2050-01-02",
 			}
 			doall(data)
 		})
@@ -404,6 +519,27 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("room is taken", func() {
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "name 1"}}, nil).Times(1)
+			mockDB.EXPECT().GetActiveRoomRestrictionsWithinDates(gomock.Any(), gomock.Any()).
+				Return([]models.RoomRestriction{
+					{
+						RoomID:    1,
+						StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/some-url",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`"suggestions"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`"after"`))
+		})
+
 		It("bad form", func() {
 			data := testData{
 				val:         nil,
@@ -507,11 +643,13 @@ var _ = Describe("Handlers", Ordered, func() {
 		var basicRes models.Reservation
 
 		BeforeEach(func() {
+			basicRes = models.Reservation{}
 			handler = h.ChooseRoom
 			method = "GET"
 		})
 
 		It("test with right data", func() {
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
 			data := testData{
 				val:         nil,
 				reservation: &basicRes,
@@ -523,6 +661,43 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("test with previous hold", func() {
+			basicRes.HoldID = 5
+			mockDB.EXPECT().ReleaseRoomHold(gomock.Eq(5)).Return(nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(6, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/choose-room/1",
+				redirectURL: "/make-reservation",
+			}
+			doall(data)
+		})
+
+		It("test with room taken by other guest", func() {
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(0, repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, this room has just been taken",
+				url:         "/choose-room/1",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("test with error in HoldRoom", func() {
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't hold the room",
+				url:         "/choose-room/1",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
 		It("test with insufficient room id", func() {
 			data := testData{
 				val:         nil,
@@ -558,6 +733,7 @@ var _ = Describe("Handlers", Ordered, func() {
 				ID:   1,
 				Name: "room name",
 			}, nil).AnyTimes()
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
 			data := testData{
 				statusCode:  http.StatusSeeOther,
 				url:         "/book-room?s=2050-01-01&e=2050-01-02&id=1",
@@ -566,6 +742,21 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("test with room taken by other guest", func() {
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(3)).Return(&models.Room{
+				ID:   3,
+				Name: "room name",
+			}, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(0, repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, this room has just been taken",
+				url:         "/book-room?s=2050-01-01&e=2050-01-02&id=3",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
 		It("bad start", func() {
 			data := testData{
 				statusCode:  http.StatusSeeOther,
@@ -609,6 +800,7 @@ var _ = Describe("Handlers", Ordered, func() {
 	})
 
 	Context("AdminAllReservations", func() {
+		defaultQuery := models.ReservationQuery{Sort: "arrival", Page: 1, PerPage: 25}
 
 		BeforeEach(func() {
 			handler = h.AdminAllReservations
@@ -616,7 +808,100 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("test with right data", func() {
-			mockDB.EXPECT().GetAllReservations().Return([]models.Reservation{}, nil).Times(1)
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{}, 0, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return([]string{}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/some-url",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("No reservations match the filters"))
+		})
+
+		It("shows balance due", func() {
+			mockDB.EXPECT().GetAllTags().Return([]string{}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{
+				{ID: 1, Room: &models.Room{Name: "room name"}, Balance: 20000},
+				{ID: 2, Room: &models.Room{Name: "room name"}, Balance: -500},
+			}, 2, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/some-url",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Due $200.00"))
+			Expect(rr.Body.String()).To(ContainSubstring(`title="-$5.00">Credit`))
+		})
+
+		It("filters by tag", func() {
+			query := defaultQuery
+			query.Tag = "late arrival"
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(query)).Return([]models.Reservation{
+				{ID: 1, Room: &models.Room{Name: "room name"}, Tags: []models.ReservationTag{
+					{ReservationID: 1, Tag: "late arrival"},
+				}},
+			}, 1, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return([]string{"anniversary", "late arrival"}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/all-reservations?tag=Late++Arrival",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`<option value="late arrival" selected>`))
+			Expect(rr.Body.String()).To(ContainSubstring(`<span class="badge bg-secondary">late arrival</span>`))
+		})
+
+		It("filters, sorts and pages", func() {
+			query := models.ReservationQuery{
+				Search:  "smith",
+				From:    time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2023, 7, 31, 0, 0, 0, 0, time.UTC),
+				RoomID:  2,
+				Status:  models.ReservationConfirmed,
+				Source:  models.SourcePhone,
+				Sort:    "name",
+				Desc:    true,
+				Page:    2,
+				PerPage: 50,
+			}
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(query)).Return([]models.Reservation{
+				{ID: 1, Room: &models.Room{Name: "room name"}},
+			}, 120, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return([]string{}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "first"}, {ID: 2, Name: "second"}}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url: "/admin/all-reservations?q=smith&from=2023-07-01&to=2023-07-31&room=2&status=confirmed" +
+					"&source=phone&sort=name&order=desc&page=2&per_page=50",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`<option value="2" selected>second</option>`))
+			Expect(body).To(ContainSubstring(`value="2023-07-01"`))
+			Expect(body).To(ContainSubstring("51–100 of 120"))
+			Expect(body).To(ContainSubstring(`href="/admin/all-reservations?from=2023-07-01&amp;per_page=50&amp;q=smith&amp;` +
+				`room=2&amp;sort=name&amp;source=phone&amp;status=confirmed&amp;to=2023-07-31"`))
+			Expect(body).To(ContainSubstring(`href="/admin/all-reservations?from=2023-07-01&amp;order=desc&amp;page=3&amp;` +
+				`per_page=50&amp;q=smith&amp;room=2&amp;sort=name&amp;source=phone&amp;status=confirmed&amp;to=2023-07-31"`))
+		})
+
+		It("bad filters", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad filters",
+				url:         "/admin/all-reservations?from=yesterday",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllTags and GetAllRooms", func() {
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{}, 0, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return(nil, errors.New("error text")).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
 			data := testData{
 				statusCode: http.StatusOK,
 				url:        "/some-url",
@@ -625,7 +910,7 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("bad db call", func() {
-			mockDB.EXPECT().GetAllReservations().Return([]models.Reservation{}, errors.New("error text")).Times(1)
+			mockDB.EXPECT().GetAllReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{}, 0, errors.New("error text")).Times(1)
 			data := testData{
 				statusCode:  http.StatusSeeOther,
 				errorString: "can't get all reservations",
@@ -637,6 +922,7 @@ var _ = Describe("Handlers", Ordered, func() {
 	})
 
 	Context("AdminNewReservations", func() {
+		defaultQuery := models.ReservationQuery{Sort: "arrival", Page: 1, PerPage: 25}
 
 		BeforeEach(func() {
 			handler = h.AdminNewReservations
@@ -644,7 +930,9 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("test with right data", func() {
-			mockDB.EXPECT().GetNewReservations().Return([]models.Reservation{}, nil).Times(1)
+			mockDB.EXPECT().GetNewReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{}, 0, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return([]string{}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
 			data := testData{
 				statusCode: http.StatusOK,
 				url:        "/some-url",
@@ -652,8 +940,32 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("filters by tag", func() {
+			query := defaultQuery
+			query.Tag = "anniversary"
+			mockDB.EXPECT().GetNewReservations(gomock.Eq(query)).Return([]models.Reservation{}, 0, nil).Times(1)
+			mockDB.EXPECT().GetAllTags().Return([]string{"anniversary"}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/new-reservations?tag=anniversary",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`<option value="anniversary" selected>`))
+		})
+
+		It("bad filters", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad filters",
+				url:         "/admin/new-reservations?to=2023-02-30",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
 		It("bad db call", func() {
-			mockDB.EXPECT().GetNewReservations().Return([]models.Reservation{}, errors.New("error text")).Times(1)
+			mockDB.EXPECT().GetNewReservations(gomock.Eq(defaultQuery)).Return([]models.Reservation{}, 0, errors.New("error text")).Times(1)
 			data := testData{
 				statusCode:  http.StatusSeeOther,
 				errorString: "can't get new reservations",
@@ -696,6 +1008,30 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
+		It("shows notes and tags on the tooltip", func() {
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "Room #1"}}, nil).Times(1)
+			mockDB.EXPECT().GetRoomRestrictionsByRoomIdWithinDates(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.RoomRestriction{
+					{ID: 3, RoomID: 1, ReservationID: 7, RestrictionID: 1,
+						StartDate: time.Date(2050, 1, 10, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 1, 12, 0, 0, 0, 0, time.UTC),
+						Reservation: &models.Reservation{ID: 7, FirstName: "John", LastName: "Smith",
+							StartDate: time.Date(2050, 1, 10, 0, 0, 0, 0, time.UTC),
+							EndDate:   time.Date(2050, 1, 12, 0, 0, 0, 0, time.UTC),
+							Tags:      []models.ReservationTag{{ReservationID: 7, Tag: "anniversary"}},
+							Notes: []models.ReservationNote{{ID: 1, ReservationID: 7, Body: "flowers in the room",
+								CreatedAt: time.Date(2050, 1, 2, 10, 0, 0, 0, time.UTC)}},
+						}},
+				}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/reservation-calendar?y=2050&m=1",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(MatchRegexp(
+				`title="John Smith(\n|&#10;)Tags: anniversary(\n|&#10;)2050-01-02: flowers in the room"`))
+		})
+
 		It("test with insufficient y", func() {
 			data := testData{
 				statusCode:  http.StatusSeeOther,
@@ -764,11 +1100,33 @@ var _ = Describe("Handlers", Ordered, func() {
 				RoomID:      1,
 				IsProcessed: 0,
 			}, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/new/1/show",
+			}
+			doall(data)
+		})
+
+		It("shows notes and tags", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(&models.Reservation{
+				ID: 1, FirstName: "First", LastName: "Last", Room: &models.Room{ID: 1, Name: "room name"},
+				Tags: []models.ReservationTag{{ReservationID: 1, Tag: "late arrival"}},
+				Notes: []models.ReservationNote{{ID: 2, ReservationID: 1, Body: "arrives after midnight",
+					User: &models.User{FirstName: "Jane", LastName: "Doe"}}},
+			}, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
 			data := testData{
 				statusCode: http.StatusOK,
 				url:        "/admin/reservations/new/1/show",
 			}
 			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("late arrival"))
+			Expect(body).To(ContainSubstring("arrives after midnight"))
+			Expect(body).To(ContainSubstring("by Jane Doe"))
 		})
 
 		It("test with wrong url", func() {
@@ -970,15 +1328,29 @@ var _ = Describe("Handlers", Ordered, func() {
 
 	})
 
-	Context("AdminDeleteReservation", func() {
+	Context("AdminPostDeleteReservation", func() {
+		var calendarVal url.Values
+
 		BeforeEach(func() {
-			handler = h.AdminDeleteReservation
-			method = "GET"
+			calendarVal = url.Values{}
+			calendarVal.Add("year", "2023")
+			calendarVal.Add("month", "11")
+			handler = h.AdminPostDeleteReservation
+			method = "POST"
 		})
 
 		It("test with right data to new", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(&models.Reservation{
+				ID:        1,
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+			}, nil).Times(1)
 			mockDB.EXPECT().DeleteReservationByID(gomock.Eq(1)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
 			data := testData{
+				val:         &url.Values{},
 				statusCode:  http.StatusSeeOther,
 				url:         "/admin/delete-reservation/new/1/do",
 				redirectURL: "/admin/new-reservations",
@@ -987,8 +1359,17 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("test with right data to all", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(&models.Reservation{
+				ID:        1,
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+			}, nil).Times(1)
 			mockDB.EXPECT().DeleteReservationByID(gomock.Eq(1)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
 			data := testData{
+				val:         &url.Values{},
 				statusCode:  http.StatusSeeOther,
 				url:         "/admin/delete-reservation/all/1/do",
 				redirectURL: "/admin/all-reservations",
@@ -997,10 +1378,19 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 		It("test with right data to calendar", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(&models.Reservation{
+				ID:        1,
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+			}, nil).Times(1)
 			mockDB.EXPECT().DeleteReservationByID(gomock.Eq(1)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
 			data := testData{
+				val:         &calendarVal,
 				statusCode:  http.StatusSeeOther,
-				url:         "/admin/delete-reservation/cal/1/do?y=2023&m=11",
+				url:         "/admin/delete-reservation/cal/1/do",
 				redirectURL: "/admin/reservation-calendar?y=2023&m=11",
 			}
 			doall(data)
@@ -1008,6 +1398,7 @@ var _ = Describe("Handlers", Ordered, func() {
 
 		It("test with wrong url", func() {
 			data := testData{
+				val:         &url.Values{},
 				statusCode:  http.StatusSeeOther,
 				url:         "/admin/delete-reservation",
 				errorString: "incorrect request url",
@@ -1018,6 +1409,7 @@ var _ = Describe("Handlers", Ordered, func() {
 
 		It("test with wrong id", func() {
 			data := testData{
+				val:         &url.Values{},
 				statusCode:  http.StatusSeeOther,
 				errorString: "wrong id",
 				url:         "/admin/delete-reservation/new/q/do",
@@ -1026,18 +1418,31 @@ var _ = Describe("Handlers", Ordered, func() {
 			doall(data)
 		})
 
-		It("test with error in UpdateReservationProcessed", func() {
+		It("test with error in DeleteReservationByID", func() {
 
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(&models.Reservation{ID: 1}, nil).Times(1)
 			mockDB.EXPECT().DeleteReservationByID(gomock.Eq(1)).Return(errors.New("error text")).Times(1)
 			data := testData{
+				val:         &calendarVal,
 				statusCode:  http.StatusSeeOther,
 				errorString: "can't delete reservation",
-				url:         "/admin/delete-reservation/new/1/do?y=2023&m=11",
+				url:         "/admin/delete-reservation/new/1/do",
 				redirectURL: "/admin/reservation-calendar?y=2023&m=11",
 			}
 			doall(data)
 		})
 
+		It("test with error in GetReservationByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(1)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation",
+				url:         "/admin/delete-reservation/all/1/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
 	})
 
 	Context("AdminPostReservationCalendar", func() {
@@ -1060,6 +1465,10 @@ var _ = Describe("Handlers", Ordered, func() {
 			mockDB.EXPECT().AddSingleDayRoomRestriction(gomock.Eq(1), gomock.Eq(2), gomock.Any()).
 				Return(0, nil).Times(1)
 			mockDB.EXPECT().DeleteRoomRestrictionByID(gomock.Eq(1)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1),
+				gomock.Eq(time.Date(2023, 2, 5, 0, 0, 0, 0, time.UTC)),
+				gomock.Eq(time.Date(2023, 2, 6, 0, 0, 0, 0, time.UTC))).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
 			basicVal.Add("add_block_1_2023-02-02", "1")
 			//basicVal.Add("remove_block_1_2023-02-05", "1")
 			data := testData{
@@ -1229,33 +1638,6033 @@ var _ = Describe("Handlers", Ordered, func() {
 		})
 
 	})
-})
-
-func routes(handler *handlers.Handlers) http.Handler {
-	mux := chi.NewRouter()
-	mux.Use(middleware.Recoverer)
-	mux.Use(SessionLoad)
 
-	fileServer := http.FileServer(http.Dir("./static/"))
-	mux.Handle("/static/*", http.StripPrefix("/static", fileServer))
+	Context("PostMakeReservation with cart", func() {
+		var basicVal url.Values
+		var basicRes models.Reservation
+		var cart []models.Reservation
 
-	mux.Get("/", http.HandlerFunc(handler.Home))
-	mux.Get("/about", http.HandlerFunc(handler.About))
-	mux.Get("/generals-quarters", http.HandlerFunc(handler.GeneralsQuarters))
-	mux.Get("/majors-suite", http.HandlerFunc(handler.MajorsSuite))
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicRes = models.Reservation{
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+			}
+			cart = []models.Reservation{
+				{
+					RoomID:    2,
+					StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+					EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				},
+			}
+			handler = h.PostMakeReservation
+			method = "POST"
+		})
 
-	mux.Get("/make-reservation", http.HandlerFunc(handler.MakeReservation))
-	mux.Post("/make-reservation", http.HandlerFunc(handler.PostMakeReservation))
+		It("normal", func() {
+			mockDB.EXPECT().InsertReservationGroup(gomock.Any(), gomock.Len(2)).
+				DoAndReturn(func(group *models.ReservationGroup, reservations []models.Reservation) (int, error) {
+					Expect(group.ConfirmationCode).ToNot(BeEmpty())
+					Expect(group.Email).To(Equal("john@here.com"))
+					for _, res := range reservations {
+						Expect(res.Email).To(Equal("john@here.com"))
+					}
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:            &basicVal,
+				reservation:    &basicRes,
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				url:            "/some-url",
+				redirectURL:    "/reservation-summary",
+			}
+			doall(data)
+		})
 
-	mux.Get("/search-availability", http.HandlerFunc(handler.SearchAvailability))
-	mux.Post("/search-availability", http.HandlerFunc(handler.PostSearchAvailability))
-	mux.Post("/search-availability-json", http.HandlerFunc(handler.SearchAvailabilityJson))
-	mux.Get("/choose-room/{id}", http.HandlerFunc(handler.ChooseRoom))
-	mux.Get("/book-room", http.HandlerFunc(handler.BookRoom))
+		It("room overlaps the cart", func() {
+			cart[0].RoomID = 1
+			data := testData{
+				val:            &basicVal,
+				reservation:    &basicRes,
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "this room is already in your booking for these dates",
+				url:            "/some-url",
+				redirectURL:    "/make-reservation",
+			}
+			doall(data)
+		})
 
-	mux.Get("/reservation-summary", http.HandlerFunc(handler.ReservationSummary))
+		It("room is taken meanwhile", func() {
+			mockDB.EXPECT().InsertReservationGroup(gomock.Any(), gomock.Any()).
+				Return(0, repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				val:            &basicVal,
+				reservation:    &basicRes,
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "one of the rooms is no longer available",
+				url:            "/some-url",
+				redirectURL:    "/search-availability",
+			}
+			doall(data)
+		})
 
-	mux.Get("/contact", http.HandlerFunc(handler.Contact))
+		It("can't insert reservation group", func() {
+			mockDB.EXPECT().InsertReservationGroup(gomock.Any(), gomock.Any()).
+				Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:            &basicVal,
+				reservation:    &basicRes,
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't insert reservation group",
+				url:            "/some-url",
+				redirectURL:    "/",
+			}
+			doall(data)
+		})
+	})
+
+	Context("PostAddToCart", func() {
+		var basicRes models.Reservation
+
+		BeforeEach(func() {
+			basicRes = models.Reservation{
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+			}
+			handler = h.PostAddToCart
+			method = "POST"
+		})
+
+		It("normal", func() {
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/cart/add",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("no reservation", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "cannot find reservation",
+				url:         "/cart/add",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
+		It("bad dates", func() {
+			basicRes.EndDate = basicRes.StartDate
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "departure must be after arrival",
+				url:         "/cart/add",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("room is already in the cart", func() {
+			data := testData{
+				reservation:    &basicRes,
+				dataForSession: map[string]interface{}{"cart": []models.Reservation{basicRes}},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "this room is already in your booking for these dates",
+				url:            "/cart/add",
+				redirectURL:    "/make-reservation",
+			}
+			doall(data)
+		})
+	})
+
+	Context("PostRemoveFromCart", func() {
+		var cart []models.Reservation
+
+		BeforeEach(func() {
+			cart = []models.Reservation{{RoomID: 1}, {RoomID: 2}}
+			handler = h.PostRemoveFromCart
+			method = "POST"
+		})
+
+		It("normal", func() {
+			data := testData{
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				url:            "/cart/remove/1",
+				redirectURL:    "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("normal with hold", func() {
+			cart[1].HoldID = 4
+			mockDB.EXPECT().ReleaseRoomHold(gomock.Eq(4)).Return(nil).Times(1)
+			data := testData{
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				url:            "/cart/remove/1",
+				redirectURL:    "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("normal with current reservation", func() {
+			data := testData{
+				reservation:    &models.Reservation{RoomID: 3},
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				url:            "/cart/remove/0",
+				redirectURL:    "/make-reservation",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/cart/remove",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("index out of cart", func() {
+			data := testData{
+				dataForSession: map[string]interface{}{"cart": cart},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't find room in your booking",
+				url:            "/cart/remove/2",
+				redirectURL:    "/search-availability",
+			}
+			doall(data)
+		})
+	})
+
+	Context("ReservationSummary with group", func() {
+		BeforeEach(func() {
+			handler = h.ReservationSummary
+			method = "GET"
+		})
+
+		It("test with right data", func() {
+			group := models.ReservationGroup{
+				ConfirmationCode: "ABCD2345",
+				Reservations: []models.Reservation{
+					{RoomID: 1, Room: &models.Room{ID: 1, Name: "name"}},
+					{RoomID: 2, Room: &models.Room{ID: 2, Name: "other"}},
+				},
+			}
+			data := testData{
+				dataForSession: map[string]interface{}{"reservation_group": group},
+				statusCode:     http.StatusOK,
+				url:            "/some-url",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminReservationGroup", func() {
+		BeforeEach(func() {
+			handler = h.AdminReservationGroup
+			method = "GET"
+		})
+
+		It("test with right data", func() {
+			mockDB.EXPECT().GetReservationGroupByID(gomock.Eq(1)).Return(&models.ReservationGroup{
+				ID:               1,
+				ConfirmationCode: "ABCD2345",
+				Reservations: []models.Reservation{
+					{ID: 1, RoomID: 1, Room: &models.Room{ID: 1, Name: "name"}},
+				},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+
+		It("test with wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/reservation-groups",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("test with wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/reservation-groups/q/show",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("test with insufficient group", func() {
+			mockDB.EXPECT().GetReservationGroupByID(gomock.Eq(1)).
+				Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find reservation group",
+				url:         "/admin/reservation-groups/1/show",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostReservationGroup", func() {
+		var basicVal url.Values
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "First")
+			basicVal.Add("last_name", "Last")
+			basicVal.Add("email", "e@e.com")
+			basicVal.Add("phone", "12345")
+			handler = h.AdminPostReservationGroup
+			method = "POST"
+		})
+
+		It("test with right data", func() {
+			mockDB.EXPECT().UpdateReservationGroup(gomock.Eq(models.ReservationGroup{
+				ID:        1,
+				FirstName: "First",
+				LastName:  "Last",
+				Email:     "e@e.com",
+				Phone:     "12345",
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservation-groups/1/show",
+				redirectURL: "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+
+		It("test with error in UpdateReservationGroup", func() {
+			mockDB.EXPECT().UpdateReservationGroup(gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update reservation group",
+				url:         "/admin/reservation-groups/1/show",
+				redirectURL: "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminProcessReservationGroup", func() {
+		BeforeEach(func() {
+			handler = h.AdminProcessReservationGroup
+			method = "GET"
+		})
+
+		It("test with right data", func() {
+			mockDB.EXPECT().UpdateReservationGroupProcessed(gomock.Eq(1), gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/process-reservation-group/1/do",
+				redirectURL: "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+
+		It("test with error in UpdateReservationGroupProcessed", func() {
+			mockDB.EXPECT().UpdateReservationGroupProcessed(gomock.Eq(1), gomock.Eq(1)).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update reservation group",
+				url:         "/admin/process-reservation-group/1/do",
+				redirectURL: "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostDeleteReservationGroup", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteReservationGroup
+			method = "POST"
+		})
+
+		It("test with right data", func() {
+			mockDB.EXPECT().GetReservationGroupByID(gomock.Eq(1)).Return(&models.ReservationGroup{
+				ID: 1,
+				Reservations: []models.Reservation{
+					{ID: 1, RoomID: 1},
+					{ID: 2, RoomID: 2},
+				},
+			}, nil).Times(1)
+			mockDB.EXPECT().DeleteReservationGroupByID(gomock.Eq(1)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(2), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-reservation-group/1/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("test with wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/delete-reservation-group/q/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("test with error in DeleteReservationGroupByID", func() {
+			mockDB.EXPECT().GetReservationGroupByID(gomock.Eq(1)).Return(&models.ReservationGroup{ID: 1}, nil).Times(1)
+			mockDB.EXPECT().DeleteReservationGroupByID(gomock.Eq(1)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete reservation group",
+				url:         "/admin/delete-reservation-group/1/do",
+				redirectURL: "/admin/reservation-groups/1/show",
+			}
+			doall(data)
+		})
+
+		It("test with error in GetReservationGroupByID", func() {
+			mockDB.EXPECT().GetReservationGroupByID(gomock.Eq(1)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation group",
+				url:         "/admin/delete-reservation-group/1/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+	})
+
+	Context("PostBookSplitStay", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("room_id", "5")
+			basicVal.Add("start", "2050-01-01")
+			basicVal.Add("end", "2050-01-03")
+			basicVal.Add("room_id", "6")
+			basicVal.Add("start", "2050-01-03")
+			basicVal.Add("end", "2050-01-05")
+			handler = h.PostBookSplitStay
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(5)).Return(&models.Room{ID: 5, Name: "first"}, nil).Times(1)
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(6)).Return(&models.Room{ID: 6, Name: "second"}, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(2, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/book-split-stay",
+				redirectURL: "/make-reservation",
+			}
+			doall(data)
+		})
+
+		It("bad form", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad form",
+				url:         "/book-split-stay",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
+		It("mismatched segments", func() {
+			basicVal.Del("end")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad split stay",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("second part is taken", func() {
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(5)).Return(&models.Room{ID: 5, Name: "first"}, nil).Times(1)
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(6)).Return(&models.Room{ID: 6, Name: "second"}, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(0, repository.ErrRoomNotAvailable).Times(1)
+			mockDB.EXPECT().ReleaseRoomHold(gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, this split stay is no longer available",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("part ends before it starts", func() {
+			basicVal["end"][1] = "2050-01-02"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(5)).Return(&models.Room{ID: 5, Name: "first"}, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
+			mockDB.EXPECT().ReleaseRoomHold(gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "departure must be after arrival",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("part starts in the past", func() {
+			basicVal["start"][0] = "2000-01-01"
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "arrival is in the past",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("parts don't follow each other", func() {
+			basicVal["start"][1] = "2050-01-04"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(5)).Return(&models.Room{ID: 5, Name: "first"}, nil).Times(1)
+			mockDB.EXPECT().HoldRoom(gomock.Any()).Return(1, nil).Times(1)
+			mockDB.EXPECT().ReleaseRoomHold(gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "parts of the split stay must follow each other",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+
+		It("bad start", func() {
+			basicVal["start"][0] = "bad"
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad start time",
+				url:         "/book-split-stay",
+				redirectURL: "/search-availability",
+			}
+			doall(data)
+		})
+	})
+
+	Context("Waitlist", func() {
+		BeforeEach(func() {
+			handler = h.Waitlist
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "name 1"}}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/waitlist?start=2050-01-01&end=2050-01-02&room_id=1",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`value="2050-01-01"`))
+			Expect(rr.Body.String()).To(ContainSubstring(`<option value="1" selected>`))
+		})
+
+		It("error in GetAllRooms", func() {
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get rooms",
+				url:         "/waitlist",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+	})
+
+	Context("PostWaitlist", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Smith")
+			basicVal.Add("email", "john@smith.com")
+			basicVal.Add("phone", "123")
+			basicVal.Add("start", "2050-01-01")
+			basicVal.Add("end", "2050-01-03")
+			basicVal.Add("room_id", "1")
+			handler = h.PostWaitlist
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().InsertWaitlistEntry(gomock.Eq(&models.WaitlistEntry{
+				FirstName: "John",
+				LastName:  "Smith",
+				Email:     "john@smith.com",
+				Phone:     "123",
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				RoomID:    1,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/waitlist",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
+		It("any room", func() {
+			basicVal.Set("room_id", "")
+			mockDB.EXPECT().InsertWaitlistEntry(gomock.Any()).
+				DoAndReturn(func(entry *models.WaitlistEntry) (int, error) {
+					Expect(entry.RoomID).To(Equal(0))
+					return 2, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/waitlist",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
+		It("bad form", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad form",
+				url:         "/waitlist",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+
+		It("invalid form", func() {
+			basicVal.Set("email", "not an email")
+			basicVal.Set("end", "2049-12-31")
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "name 1"}}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/waitlist",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("This field is not a valid email"))
+			Expect(rr.Body.String()).To(ContainSubstring("Departure must be after arrival"))
+		})
+
+		It("error in InsertWaitlistEntry", func() {
+			mockDB.EXPECT().InsertWaitlistEntry(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't add you to the waitlist",
+				url:         "/waitlist",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminWaitlist", func() {
+		BeforeEach(func() {
+			handler = h.AdminWaitlist
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllWaitlistEntries().Return([]models.WaitlistEntry{
+				{ID: 1, FirstName: "John", Room: &models.Room{ID: 1, Name: "name 1"}},
+				{ID: 2, FirstName: "Jane", NotifiedAt: time.Now()},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/waitlist",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllWaitlistEntries", func() {
+			mockDB.EXPECT().GetAllWaitlistEntries().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get waitlist",
+				url:         "/admin/waitlist",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostNotifyWaitlistEntry", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostNotifyWaitlistEntry
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetWaitlistEntryByID(gomock.Eq(1)).
+				Return(&models.WaitlistEntry{ID: 1, FirstName: "<b>John</b>", Email: "john@smith.com"}, nil).Times(1)
+			mockDB.EXPECT().MarkWaitlistEntryNotified(gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/notify-waitlist-entry/1/do",
+				redirectURL: "/admin/waitlist",
+			}
+			doall(data)
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("john@smith.com"))
+			Expect(msg.Content).To(ContainSubstring("Dear &lt;b&gt;John&lt;/b&gt;,"))
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/notify-waitlist-entry",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/notify-waitlist-entry/q/do",
+				redirectURL: "/admin/waitlist",
+			}
+			doall(data)
+		})
+
+		It("error in GetWaitlistEntryByID", func() {
+			mockDB.EXPECT().GetWaitlistEntryByID(gomock.Eq(1)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get waitlist entry",
+				url:         "/admin/notify-waitlist-entry/1/do",
+				redirectURL: "/admin/waitlist",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostDeleteWaitlistEntry", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteWaitlistEntry
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().DeleteWaitlistEntryByID(gomock.Eq(1)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-waitlist-entry/1/do",
+				redirectURL: "/admin/waitlist",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteWaitlistEntryByID", func() {
+			mockDB.EXPECT().DeleteWaitlistEntryByID(gomock.Eq(1)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete waitlist entry",
+				url:         "/admin/delete-waitlist-entry/1/do",
+				redirectURL: "/admin/waitlist",
+			}
+			doall(data)
+		})
+	})
+
+	Context("waitlist notification on freed room", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteReservation
+			method = "POST"
+		})
+
+		It("emails the first guest whose stay fits", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(7)).Return(&models.Reservation{
+				ID:        7,
+				RoomID:    2,
+				StartDate: time.Date(2050, 2, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 2, 5, 0, 0, 0, 0, time.UTC),
+			}, nil).Times(1)
+			mockDB.EXPECT().DeleteReservationByID(gomock.Eq(7)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(2), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{
+					{
+						ID:        10,
+						Email:     "first@guest.com",
+						StartDate: time.Date(2050, 1, 30, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 2, 2, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						ID:        11,
+						Email:     "second@guest.com",
+						StartDate: time.Date(2050, 2, 2, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 2, 4, 0, 0, 0, 0, time.UTC),
+					},
+					{
+						ID:        12,
+						Email:     "third@guest.com",
+						StartDate: time.Date(2050, 2, 1, 0, 0, 0, 0, time.UTC),
+						EndDate:   time.Date(2050, 2, 3, 0, 0, 0, 0, time.UTC),
+					},
+				}, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(
+				gomock.Eq(time.Date(2050, 1, 30, 0, 0, 0, 0, time.UTC)), gomock.Any(), gomock.Eq(2)).
+				Return(false, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(
+				gomock.Eq(time.Date(2050, 2, 2, 0, 0, 0, 0, time.UTC)), gomock.Any(), gomock.Eq(2)).
+				Return(true, nil).Times(1)
+			mockDB.EXPECT().MarkWaitlistEntryNotified(gomock.Eq(11)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-reservation/all/7/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("second@guest.com"))
+			Expect(app.MailChan).To(BeEmpty())
+		})
+	})
+
+	Context("rate plans at booking", func() {
+		var basicVal url.Values
+		var basicRes models.Reservation
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicRes = models.Reservation{
+				RoomID:    9,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				Room: &models.Room{
+					ID:                   9,
+					Name:                 "room name",
+					Price:                10000,
+					CancellationPolicyID: 1,
+					CancellationPolicy: &models.CancellationPolicy{
+						ID: 1, FreeDays: 2, FeeType: models.FeeFirstNight,
+					},
+					RatePlans: []models.RatePlan{
+						{
+							ID:                   4,
+							RoomID:               9,
+							Name:                 "Non-refundable",
+							Price:                8000,
+							CancellationPolicyID: 3,
+							CancellationPolicy: &models.CancellationPolicy{
+								ID: 3, FreeDays: 30, FeeType: models.FeePercent, FeePercent: 100,
+							},
+						},
+						{ID: 5, RoomID: 9, Name: "Breakfast", Price: 12000},
+					},
+				},
+			}
+			handler = h.PostMakeReservation
+			method = "POST"
+		})
+
+		It("shows rates and policies", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			handler = h.MakeReservation
+			method = "GET"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(9)).Return(basicRes.Room, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("$200.00 for 2 night(s)"))
+			Expect(body).To(ContainSubstring("Free cancellation until 2 days before arrival, after that the first night is charged."))
+			Expect(body).To(ContainSubstring("Non-refundable &mdash; $80.00 per night"))
+			Expect(body).To(ContainSubstring("after that 100% of the stay is charged."))
+		})
+
+		It("books on chosen rate plan", func() {
+			basicVal.Add("rate_plan_id", "4")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				DoAndReturn(func(res *models.Reservation) (int, error) {
+					Expect(res.RatePlanID).To(Equal(4))
+					Expect(res.NightlyRate).To(Equal(8000))
+					Expect(res.CancellationPolicyID).To(Equal(3))
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("rate plan without policy uses the room's one", func() {
+			basicVal.Add("rate_plan_id", "5")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				DoAndReturn(func(res *models.Reservation) (int, error) {
+					Expect(res.RatePlanID).To(Equal(5))
+					Expect(res.NightlyRate).To(Equal(12000))
+					Expect(res.CancellationPolicyID).To(Equal(1))
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("books on standard rate", func() {
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				DoAndReturn(func(res *models.Reservation) (int, error) {
+					Expect(res.RatePlanID).To(Equal(0))
+					Expect(res.NightlyRate).To(Equal(10000))
+					Expect(res.CancellationPolicyID).To(Equal(1))
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("unknown rate plan", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			basicVal.Add("rate_plan_id", "99")
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Unknown rate plan"))
+		})
+	})
+
+	Context("AdminPostCancelReservation", func() {
+		var res models.Reservation
+
+		BeforeEach(func() {
+			now := time.Now()
+			res = models.Reservation{
+				ID:          11,
+				RoomID:      1,
+				StartDate:   time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1),
+				EndDate:     time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 4),
+				NightlyRate: 10000,
+				CancellationPolicy: &models.CancellationPolicy{
+					ID: 2, FreeDays: 7, FeeType: models.FeePercent, FeePercent: 50,
+				},
+			}
+			handler = h.AdminPostCancelReservation
+			method = "POST"
+		})
+
+		It("charges the fee after the free period", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CancelReservation(gomock.Eq(11), gomock.Eq(15000)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/cancel-reservation/all/11/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("is free before the deadline", func() {
+			res.StartDate = res.StartDate.AddDate(0, 1, 0)
+			res.EndDate = res.EndDate.AddDate(0, 1, 0)
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CancelReservation(gomock.Eq(11), gomock.Eq(0)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"1"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/cancel-reservation/cal/11/do",
+				redirectURL: "/admin/reservation-calendar?y=2050&m=1",
+			}
+			doall(data)
+		})
+
+		It("already cancelled", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CancelReservation(gomock.Eq(11), gomock.Any()).
+				Return(repository.ErrReservationCancelled).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "reservation is already cancelled",
+				url:         "/admin/cancel-reservation/all/11/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("error in CancelReservation", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CancelReservation(gomock.Eq(11), gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't cancel reservation",
+				url:         "/admin/cancel-reservation/new/11/do",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation",
+				url:         "/admin/cancel-reservation/all/11/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/cancel-reservation/all/q/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/cancel-reservation",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("shows cancelled reservation", func() {
+			handler = h.AdminSingleReservation
+			method = "GET"
+			res.Status = models.ReservationCancelled
+			res.CancelledAt = time.Now()
+			res.CancellationFee = 15000
+			res.Room = &models.Room{ID: 1, Name: "room name"}
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(11)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/all/11/show",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("fee charged: $150.00"))
+			Expect(rr.Body.String()).ToNot(ContainSubstring("Cancel Reservation"))
+		})
+	})
+
+	Context("AdminCancellationPolicies", func() {
+		BeforeEach(func() {
+			handler = h.AdminCancellationPolicies
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllCancellationPolicies().Return([]models.CancellationPolicy{
+				{ID: 1, Name: "Flexible", FreeDays: 2, FeeType: models.FeeFirstNight},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllCancellationPolicies", func() {
+			mockDB.EXPECT().GetAllCancellationPolicies().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get cancellation policies",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostCancellationPolicy", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("name", "Moderate")
+			basicVal.Add("free_days", "7")
+			basicVal.Add("fee_type", models.FeePercent)
+			basicVal.Add("fee_percent", "50")
+			handler = h.AdminPostCancellationPolicy
+			method = "POST"
+		})
+
+		It("new policy", func() {
+			mockDB.EXPECT().InsertCancellationPolicy(gomock.Eq(&models.CancellationPolicy{
+				Name: "Moderate", FreeDays: 7, FeeType: models.FeePercent, FeePercent: 50,
+			})).Return(4, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("update policy", func() {
+			basicVal.Add("id", "2")
+			basicVal.Set("fee_type", models.FeeFirstNight)
+			mockDB.EXPECT().UpdateCancellationPolicy(gomock.Eq(models.CancellationPolicy{
+				ID: 2, Name: "Moderate", FreeDays: 7, FeeType: models.FeeFirstNight,
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("without name", func() {
+			basicVal.Set("name", "")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "policy name is required",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("negative free days", func() {
+			basicVal.Set("free_days", "-1")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "free days must be a non-negative number",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("unknown fee type", func() {
+			basicVal.Set("fee_type", "everything")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown fee type",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("fee percent over 100", func() {
+			basicVal.Set("fee_percent", "101")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "fee percent must be between 0 and 100",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+
+		It("error in InsertCancellationPolicy", func() {
+			mockDB.EXPECT().InsertCancellationPolicy(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save cancellation policy",
+				url:         "/admin/cancellation-policies",
+				redirectURL: "/admin/cancellation-policies",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminRooms", func() {
+		BeforeEach(func() {
+			handler = h.AdminRooms
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetRoomsWithRates().Return([]models.Room{
+				{
+					ID: 1, Name: "room name", Price: 12000, CancellationPolicyID: 1,
+					RatePlans: []models.RatePlan{{ID: 1, RoomID: 1, Name: "Breakfast", Price: 13500}},
+				},
+			}, nil).Times(1)
+			mockDB.EXPECT().GetAllCancellationPolicies().Return([]models.CancellationPolicy{
+				{ID: 1, Name: "Flexible"},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/rooms",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`value="135.00"`))
+		})
+
+		It("error in GetRoomsWithRates", func() {
+			mockDB.EXPECT().GetRoomsWithRates().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get rooms",
+				url:         "/admin/rooms",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllCancellationPolicies", func() {
+			mockDB.EXPECT().GetRoomsWithRates().Return([]models.Room{}, nil).Times(1)
+			mockDB.EXPECT().GetAllCancellationPolicies().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get cancellation policies",
+				url:         "/admin/rooms",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostRoom", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("price", "125.50")
+			basicVal.Add("cancellation_policy_id", "2")
+			handler = h.AdminPostRoom
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().UpdateRoomRates(gomock.Eq(models.Room{ID: 1, Price: 12550, CancellationPolicyID: 2})).
+				Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/rooms/1",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("without policy", func() {
+			basicVal.Set("cancellation_policy_id", "")
+			mockDB.EXPECT().UpdateRoomRates(gomock.Eq(models.Room{ID: 1, Price: 12550})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/rooms/1",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("bad price", func() {
+			basicVal.Set("price", "cheap")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "price must be a positive amount with at most two decimals",
+				url:         "/admin/rooms/1",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/rooms/q",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("error in UpdateRoomRates", func() {
+			mockDB.EXPECT().UpdateRoomRates(gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update room",
+				url:         "/admin/rooms/1",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostRatePlan", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("room_id", "1")
+			basicVal.Add("name", "Breakfast")
+			basicVal.Add("price", "135")
+			handler = h.AdminPostRatePlan
+			method = "POST"
+		})
+
+		It("new plan", func() {
+			mockDB.EXPECT().InsertRatePlan(gomock.Eq(&models.RatePlan{
+				RoomID: 1, Name: "Breakfast", Price: 13500, DepositType: models.DepositNone,
+			})).
+				Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("update plan", func() {
+			basicVal.Add("id", "3")
+			basicVal.Add("cancellation_policy_id", "1")
+			basicVal.Add("deposit_type", models.DepositPercent)
+			basicVal.Add("deposit_percent", "30")
+			mockDB.EXPECT().UpdateRatePlan(gomock.Eq(models.RatePlan{
+				ID: 3, RoomID: 1, Name: "Breakfast", Price: 13500, CancellationPolicyID: 1,
+				DepositType: models.DepositPercent, DepositPercent: 30,
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("without name", func() {
+			basicVal.Set("name", " ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "rate plan name is required",
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("wrong room id", func() {
+			basicVal.Set("room_id", "q")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong room id",
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("bad deposit percent", func() {
+			basicVal.Add("deposit_type", models.DepositPercent)
+			basicVal.Add("deposit_percent", "0")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "deposit percent must be between 1 and 100",
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("unknown deposit type", func() {
+			basicVal.Add("deposit_type", "everything")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown deposit type",
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("error in InsertRatePlan", func() {
+			mockDB.EXPECT().InsertRatePlan(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save rate plan",
+				url:         "/admin/rate-plans",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostDeleteRatePlan", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteRatePlan
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().DeleteRatePlanByID(gomock.Eq(3)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-rate-plan/3/do",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteRatePlanByID", func() {
+			mockDB.EXPECT().DeleteRatePlanByID(gomock.Eq(3)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete rate plan",
+				url:         "/admin/delete-rate-plan/3/do",
+				redirectURL: "/admin/rooms",
+			}
+			doall(data)
+		})
+	})
+
+	Context("deposits at booking", func() {
+		var basicVal url.Values
+		var basicRes models.Reservation
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicVal.Add("rate_plan_id", "6")
+			basicRes = models.Reservation{
+				RoomID:    8,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				Room: &models.Room{
+					ID:    8,
+					Name:  "room name",
+					Price: 10000,
+					RatePlans: []models.RatePlan{
+						{
+							ID:             6,
+							RoomID:         8,
+							Name:           "Prepaid",
+							Price:          9000,
+							DepositType:    models.DepositPercent,
+							DepositPercent: 50,
+						},
+					},
+				},
+			}
+			handler = h.PostMakeReservation
+			method = "POST"
+		})
+
+		It("asks for a card", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			handler = h.MakeReservation
+			method = "GET"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(8)).Return(basicRes.Room, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("50% of the stay is paid at booking."))
+			Expect(rr.Body.String()).To(ContainSubstring(`name='card_token'`))
+		})
+
+		It("takes the deposit", func() {
+			basicVal.Add("card_token", "4242424242424242")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(21, nil).Times(1)
+			mockDB.EXPECT().InsertPayment(gomock.Any()).
+				DoAndReturn(func(payment *models.Payment) (int, error) {
+					Expect(payment.ReservationID).To(Equal(21))
+					Expect(payment.Amount).To(Equal(9000))
+					Expect(payment.Status).To(Equal(models.PaymentCaptured))
+					Expect(payment.Reference).ToNot(BeEmpty())
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("without a card", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("This field is required"))
+		})
+
+		It("card is declined", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			basicVal.Add("card_token", payments.DeclinedToken)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Card was declined"))
+		})
+
+		It("no card needed on standard rate", func() {
+			basicVal.Set("rate_plan_id", "0")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(22, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("error in InsertReservation", func() {
+			basicVal.Add("card_token", "4242424242424242")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't insert reservation",
+				url:         "/make-reservation",
+				redirectURL: "/",
+			}
+			doall(data)
+		})
+	})
+
+	Context("PaymentWebhook", func() {
+		post := func(payload []byte, signature string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest("POST", "/payments/webhook", bytes.NewReader(payload))
+			req.Header.Set("X-Signature", signature)
+			recorder := httptest.NewRecorder()
+			h.PaymentWebhook(recorder, req)
+			return recorder
+		}
+
+		It("updates payment", func() {
+			payload, signature, err := gateway.NewEvent(payments.Event{
+				Type: payments.EventRefunded, Reference: "fake_77", Amount: 2000,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			mockDB.EXPECT().UpdatePaymentStatusByReference(gomock.Eq("fake_77"), gomock.Eq(models.PaymentRefunded),
+				gomock.Eq(2000)).Return(nil).Times(1)
+			Expect(post(payload, signature).Code).To(Equal(http.StatusOK))
+		})
+
+		It("refund without amount", func() {
+			payload, signature, err := gateway.NewEvent(payments.Event{Type: payments.EventRefunded, Reference: "fake_77"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post(payload, signature).Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("bad signature", func() {
+			payload, _, err := gateway.NewEvent(payments.Event{Type: payments.EventRefunded, Reference: "fake_77"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post(payload, "wrong").Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("unknown payment", func() {
+			payload, signature, err := gateway.NewEvent(payments.Event{Type: payments.EventFailed, Reference: "fake_78"})
+			Expect(err).ToNot(HaveOccurred())
+			mockDB.EXPECT().UpdatePaymentStatusByReference(gomock.Eq("fake_78"), gomock.Eq(models.PaymentFailed), gomock.Eq(0)).
+				Return(repository.ErrPaymentNotFound).Times(1)
+			Expect(post(payload, signature).Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("captured payment can't be voided", func() {
+			payload, signature, err := gateway.NewEvent(payments.Event{Type: payments.EventVoided, Reference: "fake_80"})
+			Expect(err).ToNot(HaveOccurred())
+			mockDB.EXPECT().UpdatePaymentStatusByReference(gomock.Eq("fake_80"), gomock.Eq(models.PaymentVoided), gomock.Eq(0)).
+				Return(repository.ErrPaymentTaken).Times(1)
+			Expect(post(payload, signature).Code).To(Equal(http.StatusConflict))
+		})
+
+		It("ignores other events", func() {
+			payload, signature, err := gateway.NewEvent(payments.Event{Type: "payment.disputed", Reference: "fake_79"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(post(payload, signature).Code).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("admin payment actions", func() {
+		var payment models.Payment
+
+		BeforeEach(func() {
+			ref, err := gateway.Authorize(5000, "4242424242424242")
+			Expect(err).ToNot(HaveOccurred())
+			payment = models.Payment{
+				ID:            31,
+				ReservationID: 21,
+				Amount:        5000,
+				Status:        models.PaymentAuthorized,
+				Reference:     ref,
+			}
+			method = "POST"
+		})
+
+		It("captures", func() {
+			handler = h.AdminPostCapturePayment
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(&payment, nil).Times(1)
+			mockDB.EXPECT().UpdatePaymentStatusByReference(gomock.Eq(payment.Reference), gomock.Eq(models.PaymentCaptured),
+				gomock.Eq(0)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/capture-payment/all/31/do",
+				redirectURL: "/admin/reservations/all/21/show",
+			}
+			doall(data)
+		})
+
+		It("voids", func() {
+			handler = h.AdminPostVoidPayment
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(&payment, nil).Times(1)
+			mockDB.EXPECT().UpdatePaymentStatusByReference(gomock.Eq(payment.Reference), gomock.Eq(models.PaymentVoided),
+				gomock.Eq(0)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"1"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/void-payment/cal/31/do",
+				redirectURL: "/admin/reservations/cal/21/show?y=2050&m=1",
+			}
+			doall(data)
+		})
+
+		It("refunds", func() {
+			handler = h.AdminPostRefundPayment
+			Expect(gateway.Capture(payment.Reference)).To(Succeed())
+			payment.Status = models.PaymentCaptured
+			payment.Refunded = 1000
+			refunded := payment
+			refunded.Refunded = 5000
+			refunded.Status = models.PaymentRefunded
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(&payment, nil).Times(1)
+			mockDB.EXPECT().UpdatePayment(gomock.Eq(refunded)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/refund-payment/all/31/do",
+				redirectURL: "/admin/reservations/all/21/show",
+			}
+			doall(data)
+		})
+
+		It("wrong state", func() {
+			handler = h.AdminPostRefundPayment
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(&payment, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "payment is authorized and can't be refunded",
+				url:         "/admin/refund-payment/all/31/do",
+				redirectURL: "/admin/reservations/all/21/show",
+			}
+			doall(data)
+		})
+
+		It("gateway error", func() {
+			handler = h.AdminPostCapturePayment
+			payment.Reference = "fake_unknown"
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(&payment, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "payment can't be captured",
+				url:         "/admin/capture-payment/all/31/do",
+				redirectURL: "/admin/reservations/all/21/show",
+			}
+			doall(data)
+		})
+
+		It("error in GetPaymentByID", func() {
+			handler = h.AdminPostCapturePayment
+			mockDB.EXPECT().GetPaymentByID(gomock.Eq(31)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get payment",
+				url:         "/admin/capture-payment/new/31/do",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			handler = h.AdminPostVoidPayment
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/void-payment/all/q/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			handler = h.AdminPostVoidPayment
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/void-payment",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("shows payments of reservation", func() {
+			handler = h.AdminSingleReservation
+			method = "GET"
+			res := models.Reservation{
+				ID:            21,
+				RoomID:        1,
+				StartDate:     time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:       time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				PaymentStatus: models.PaymentAuthorized,
+				Room:          &models.Room{ID: 1, Name: "room name"},
+				Payments:      []models.Payment{payment},
+			}
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(21)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/all/21/show",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(payment.Reference))
+			Expect(rr.Body.String()).To(ContainSubstring(">Capture</a>"))
+		})
+	})
+
+	Context("AdminPostFolioEntry", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("entry_type", models.FolioExtra)
+			basicVal.Add("description", " Late checkout ")
+			basicVal.Add("amount", "25")
+			handler = h.AdminPostFolioEntry
+			method = "POST"
+		})
+
+		It("adds a charge", func() {
+			mockDB.EXPECT().InsertFolioEntry(gomock.Eq(&models.FolioEntry{
+				ReservationID: 41,
+				EntryType:     models.FolioExtra,
+				Description:   "Late checkout",
+				Amount:        2500,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/all/41/folio",
+				redirectURL: "/admin/reservations/all/41/show",
+			}
+			doall(data)
+		})
+
+		It("adds a payment as credit", func() {
+			basicVal.Set("entry_type", models.FolioPayment)
+			basicVal.Set("description", "Cash")
+			basicVal.Set("amount", "100.50")
+			basicVal.Add("year", "2050")
+			basicVal.Add("month", "1")
+			mockDB.EXPECT().InsertFolioEntry(gomock.Eq(&models.FolioEntry{
+				ReservationID: 41,
+				EntryType:     models.FolioPayment,
+				Description:   "Cash",
+				Amount:        -10050,
+			})).Return(2, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/cal/41/folio",
+				redirectURL: "/admin/reservations/cal/41/show?y=2050&m=1",
+			}
+			doall(data)
+		})
+
+		It("unknown entry type", func() {
+			basicVal.Set("entry_type", "gift")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown entry type",
+				url:         "/admin/reservations/all/41/folio",
+				redirectURL: "/admin/reservations/all/41/show",
+			}
+			doall(data)
+		})
+
+		It("bad amount", func() {
+			basicVal.Set("amount", "0")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "amount must be a positive amount with at most two decimals",
+				url:         "/admin/reservations/all/41/folio",
+				redirectURL: "/admin/reservations/all/41/show",
+			}
+			doall(data)
+		})
+
+		It("error in InsertFolioEntry", func() {
+			mockDB.EXPECT().InsertFolioEntry(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't add folio entry",
+				url:         "/admin/reservations/all/41/folio",
+				redirectURL: "/admin/reservations/all/41/show",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/reservations/all/q/folio",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/reservations/41/folio",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("shows folio with running balance", func() {
+			handler = h.AdminSingleReservation
+			method = "GET"
+			res := models.Reservation{
+				ID:        41,
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 4, 0, 0, 0, 0, time.UTC),
+				Room:      &models.Room{ID: 1, Name: "room name"},
+				FolioEntries: []models.FolioEntry{
+					{ID: 1, EntryType: models.FolioRoom, Description: "3 night(s) at 100.00", Amount: 30000},
+					{ID: 2, EntryType: models.FolioPayment, Description: "Cash", Amount: -12500,
+						User: &models.User{FirstName: "Ann", LastName: "Admin"}},
+				},
+			}
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(41)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/all/41/show",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("-$125.00"))
+			Expect(body).To(ContainSubstring("Ann Admin"))
+			Expect(body).To(MatchRegexp(`Balance due</th>\s*<th class="text-end text-danger">\s*\$175.00`))
+		})
+	})
+
+	Context("invoices and receipts", func() {
+		var res models.Reservation
+
+		BeforeEach(func() {
+			res = models.Reservation{
+				ID:               61,
+				FirstName:        "John",
+				LastName:         "Smith",
+				Email:            "john@smith.com",
+				ConfirmationCode: "INV61A",
+				StartDate:        time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:          time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				NightlyRate:      10000,
+				Room:             &models.Room{ID: 1, Name: "room name"},
+				FolioEntries: []models.FolioEntry{
+					{ID: 1, EntryType: models.FolioRoom, Description: "2 night(s) at 100.00", Amount: 20000},
+				},
+			}
+			method = "GET"
+		})
+
+		It("downloads invoice", func() {
+			handler = h.AdminInvoice
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().IssueInvoice(gomock.Eq(61), gomock.Eq(models.DocumentInvoice)).
+				Return(&models.Invoice{ID: 1, ReservationID: 61, Kind: models.DocumentInvoice, Number: "INV-000001"}, nil).
+				Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/all/61/invoice",
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Type")).To(Equal("application/pdf"))
+			Expect(rr.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="INV-000001.pdf"`))
+			Expect(rr.Body.String()).To(HavePrefix("%PDF-1.4"))
+			Expect(rr.Body.String()).To(ContainSubstring("(room name, night of Jan 2, 2050)"))
+		})
+
+		It("error in IssueInvoice", func() {
+			handler = h.AdminReceipt
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().IssueInvoice(gomock.Eq(61), gomock.Eq(models.DocumentReceipt)).
+				Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't issue receipt",
+				url:         "/admin/reservations/all/62/receipt",
+				redirectURL: "/admin/reservations/all/62/show",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationByID", func() {
+			handler = h.AdminInvoice
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(63)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation",
+				url:         "/admin/reservations/new/63/invoice",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			handler = h.AdminInvoice
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/reservations/all/q/invoice",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("emails invoice as attachment", func() {
+			handler = h.AdminPostEmailInvoice
+			method = "POST"
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(64)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().IssueInvoice(gomock.Eq(61), gomock.Eq(models.DocumentInvoice)).
+				Return(&models.Invoice{ID: 2, ReservationID: 61, Kind: models.DocumentInvoice, Number: "INV-000002"}, nil).
+				Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"1"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/email-invoice/cal/64/do",
+				redirectURL: "/admin/reservations/cal/64/show?y=2050&m=1",
+			}
+			doall(data)
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("john@smith.com"))
+			Expect(msg.Subject).To(Equal("Your invoice INV-000002"))
+			Expect(msg.Attachments).To(HaveLen(1))
+			Expect(msg.Attachments[0].Name).To(Equal("INV-000002.pdf"))
+			Expect(msg.Attachments[0].MimeType).To(Equal("application/pdf"))
+			Expect(string(msg.Attachments[0].Data)).To(HavePrefix("%PDF-1.4"))
+		})
+
+		It("error emailing receipt", func() {
+			handler = h.AdminPostEmailReceipt
+			method = "POST"
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(65)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().IssueInvoice(gomock.Eq(61), gomock.Eq(models.DocumentReceipt)).
+				Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't issue receipt",
+				url:         "/admin/email-receipt/all/65/do",
+				redirectURL: "/admin/reservations/all/65/show",
+			}
+			doall(data)
+			Expect(app.MailChan).To(BeEmpty())
+		})
+
+		It("shows lookup form", func() {
+			handler = h.MyBooking
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/my-booking",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Find Booking"))
+		})
+
+		It("shows looked up booking", func() {
+			handler = h.MyBooking
+			res.Balance = 5000
+			mockDB.EXPECT().GetReservationsByConfirmationCode(gomock.Eq("INV61A")).
+				Return([]models.Reservation{res}, nil).Times(1)
+			data := testData{
+				statusCode:     http.StatusOK,
+				url:            "/my-booking",
+				dataForSession: map[string]interface{}{"booking_code": "INV61A"},
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("/my-booking/61/invoice"))
+			Expect(rr.Body.String()).To(ContainSubstring("$50.00"))
+		})
+
+		It("looks up booking", func() {
+			handler = h.PostMyBooking
+			method = "POST"
+			val := url.Values{}
+			val.Add("confirmation_code", " inv61a ")
+			val.Add("email", "John@Smith.com")
+			mockDB.EXPECT().GetReservationsByConfirmationCode(gomock.Eq("INV61A")).
+				Return([]models.Reservation{res}, nil).Times(1)
+			data := testData{
+				val:         &val,
+				statusCode:  http.StatusSeeOther,
+				url:         "/my-booking",
+				redirectURL: "/my-booking",
+			}
+			doall(data)
+		})
+
+		It("rejects wrong email", func() {
+			handler = h.PostMyBooking
+			method = "POST"
+			val := url.Values{}
+			val.Add("confirmation_code", "INV61B")
+			val.Add("email", "jane@smith.com")
+			mockDB.EXPECT().GetReservationsByConfirmationCode(gomock.Eq("INV61B")).
+				Return([]models.Reservation{res}, nil).Times(1)
+			data := testData{
+				val:        &val,
+				statusCode: http.StatusOK,
+				url:        "/my-booking",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("No booking found for this code and email"))
+		})
+
+		It("rejects empty form", func() {
+			handler = h.PostMyBooking
+			method = "POST"
+			val := url.Values{}
+			data := testData{
+				val:        &val,
+				statusCode: http.StatusOK,
+				url:        "/my-booking",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("is-invalid"))
+		})
+
+		It("guest downloads receipt", func() {
+			handler = h.MyBookingReceipt
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().IssueInvoice(gomock.Eq(61), gomock.Eq(models.DocumentReceipt)).
+				Return(&models.Invoice{ID: 3, ReservationID: 61, Kind: models.DocumentReceipt, Number: "RCT-000003"}, nil).
+				Times(1)
+			data := testData{
+				statusCode:     http.StatusOK,
+				url:            "/my-booking/61/receipt",
+				dataForSession: map[string]interface{}{"booking_code": "INV61A"},
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Disposition")).To(Equal(`attachment; filename="RCT-000003.pdf"`))
+			Expect(rr.Body.String()).To(ContainSubstring("(RECEIPT)"))
+		})
+
+		It("guest can't download other bookings", func() {
+			handler = h.MyBookingInvoice
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(66)).Return(&res, nil).Times(1)
+			data := testData{
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't find reservation",
+				url:            "/my-booking/66/invoice",
+				redirectURL:    "/my-booking",
+				dataForSession: map[string]interface{}{"booking_code": "OTHER1"},
+			}
+			doall(data)
+		})
+
+		It("guest has to look up booking first", func() {
+			handler = h.MyBookingInvoice
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "please look up your booking first",
+				url:         "/my-booking/61/invoice",
+				redirectURL: "/my-booking",
+			}
+			doall(data)
+		})
+	})
+
+	Context("taxes and fees", func() {
+		var basicVal url.Values
+		var basicRes models.Reservation
+		var taxes []models.Tax
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicRes = models.Reservation{
+				RoomID:    12,
+				StartDate: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 3, 0, 0, 0, 0, time.UTC),
+				Room:      &models.Room{ID: 12, Name: "room name", Price: 10000},
+			}
+			taxes = []models.Tax{
+				{ID: 1, Name: "VAT", TaxType: models.TaxPercent, Rate: 1300, Inclusive: true},
+				{ID: 2, Name: "City tax", TaxType: models.TaxFlat, Amount: 250, Basis: models.TaxPerGuestNight},
+			}
+		})
+
+		It("quotes taxes", func() {
+			handler = h.MakeReservation
+			method = "GET"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(12)).Return(basicRes.Room, nil).Times(1)
+			mockDB.EXPECT().GetAllTaxes().Return(taxes, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("VAT: $23.01 (included in the price)"))
+			Expect(body).To(ContainSubstring("City tax: $5.00"))
+			Expect(body).To(MatchRegexp(`Total: <strong>\$205.00</strong>\s*for 1 guest\(s\)`))
+		})
+
+		It("quotes without taxes when they can't be loaded", func() {
+			handler = h.MakeReservation
+			method = "GET"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(12)).Return(basicRes.Room, nil).Times(1)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, errors.New("error text")).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).ToNot(ContainSubstring("Total:"))
+		})
+
+		It("books for guests", func() {
+			handler = h.PostMakeReservation
+			method = "POST"
+			basicVal.Add("guests", "3")
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				DoAndReturn(func(res *models.Reservation) (int, error) {
+					Expect(res.Guests).To(Equal(3))
+					return 1, nil
+				}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("requotes for guests on invalid form", func() {
+			handler = h.PostMakeReservation
+			method = "POST"
+			basicVal.Add("guests", "0")
+			mockDB.EXPECT().GetAllTaxes().Return(taxes, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("At least one guest is required"))
+		})
+
+		It("shows taxes on summary", func() {
+			handler = h.ReservationSummary
+			method = "GET"
+			basicRes.ConfirmationCode = "TAX123"
+			basicRes.NightlyRate = 10000
+			basicRes.Taxes = []models.ReservationTax{
+				{Name: "VAT", Amount: 2301, Inclusive: true},
+				{Name: "City tax", Amount: 1000},
+			}
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/reservation-summary",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("$23.01 (included in the price)"))
+			Expect(body).To(ContainSubstring("<strong>$210.00</strong>"))
+		})
+	})
+
+	Context("AdminTaxes", func() {
+		BeforeEach(func() {
+			handler = h.AdminTaxes
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllTaxes().Return([]models.Tax{
+				{ID: 1, Name: "VAT", TaxType: models.TaxPercent, Rate: 750, Basis: models.TaxPerStay},
+				{ID: 2, Name: "City tax", TaxType: models.TaxFlat, Amount: 250, Basis: models.TaxPerGuestNight,
+					ValidFrom: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/taxes",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("7.5% of the room rate"))
+			Expect(body).To(ContainSubstring("$2.50 per guest per night, from 2050-01-01"))
+			Expect(body).To(ContainSubstring(`value="2050-01-01"`))
+		})
+
+		It("error in GetAllTaxes", func() {
+			mockDB.EXPECT().GetAllTaxes().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get taxes",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostTax", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("name", " VAT ")
+			basicVal.Add("tax_type", models.TaxPercent)
+			basicVal.Add("value", "13")
+			basicVal.Add("basis", models.TaxPerStay)
+			basicVal.Add("inclusive", "1")
+			handler = h.AdminPostTax
+			method = "POST"
+		})
+
+		It("adds percent tax", func() {
+			mockDB.EXPECT().InsertTax(gomock.Eq(&models.Tax{
+				Name:      "VAT",
+				TaxType:   models.TaxPercent,
+				Rate:      1300,
+				Basis:     models.TaxPerStay,
+				Inclusive: true,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("updates flat tax with dates", func() {
+			basicVal.Add("id", "2")
+			basicVal.Set("name", "City tax")
+			basicVal.Set("tax_type", models.TaxFlat)
+			basicVal.Set("value", "2.5")
+			basicVal.Set("basis", models.TaxPerGuestNight)
+			basicVal.Del("inclusive")
+			basicVal.Add("valid_from", "2050-01-01")
+			basicVal.Add("valid_to", "2050-12-31")
+			mockDB.EXPECT().UpdateTax(gomock.Eq(models.Tax{
+				ID:        2,
+				Name:      "City tax",
+				TaxType:   models.TaxFlat,
+				Amount:    250,
+				Basis:     models.TaxPerGuestNight,
+				ValidFrom: time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+				ValidTo:   time.Date(2050, 12, 31, 0, 0, 0, 0, time.UTC),
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("missing name", func() {
+			basicVal.Set("name", " ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tax name is required",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("bad percent", func() {
+			basicVal.Set("value", "101")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tax percent must be between 0.01 and 100",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("bad amount", func() {
+			basicVal.Set("tax_type", models.TaxFlat)
+			basicVal.Set("value", "2.505")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tax amount must be a positive amount with at most two decimals",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("unknown tax type", func() {
+			basicVal.Set("tax_type", "gift")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown tax type",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("unknown basis", func() {
+			basicVal.Set("basis", "room")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown tax basis",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("ends before it starts", func() {
+			basicVal.Add("valid_from", "2050-02-01")
+			basicVal.Add("valid_to", "2050-01-01")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tax ends before it starts",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("wrong date", func() {
+			basicVal.Add("valid_from", "first of may")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong start date",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("error in InsertTax", func() {
+			mockDB.EXPECT().InsertTax(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save tax",
+				url:         "/admin/taxes",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostDeleteTax", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteTax
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().DeleteTaxByID(gomock.Eq(3)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-tax/3/do",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteTaxByID", func() {
+			mockDB.EXPECT().DeleteTaxByID(gomock.Eq(4)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete tax",
+				url:         "/admin/delete-tax/4/do",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/delete-tax/q/do",
+				redirectURL: "/admin/taxes",
+			}
+			doall(data)
+		})
+	})
+
+	Context("currencies", func() {
+		euro := models.Currency{ID: 1, Code: "EUR", Symbol: "€", Rate: 0.68}
+
+		BeforeEach(func() {
+			app.Currencies.Set([]models.Currency{euro})
+		})
+
+		AfterEach(func() {
+			app.Currencies.Set(nil)
+		})
+
+		post := func(code, referer string) (*httptest.ResponseRecorder, context.Context) {
+			val := url.Values{}
+			val.Add("currency", code)
+			req := httptest.NewRequest("POST", "/currency", strings.NewReader(val.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Referer", referer)
+			ctx, err := getCtx(req, &app)
+			Expect(err).ToNot(HaveOccurred())
+			recorder := httptest.NewRecorder()
+			h.PostCurrency(recorder, req.WithContext(ctx))
+			return recorder, ctx
+		}
+
+		It("keeps chosen currency and goes back to the same page only", func() {
+			recorder, ctx := post("EUR", "https://example.com/make-reservation?room=1")
+			Expect(recorder.Code).To(Equal(http.StatusSeeOther))
+			Expect(recorder.Header().Get("Location")).To(Equal("/make-reservation?room=1"))
+			Expect(app.Session.GetString(ctx, "currency")).To(Equal("EUR"))
+		})
+
+		It("base currency", func() {
+			recorder, ctx := post("CAD", "")
+			Expect(recorder.Header().Get("Location")).To(Equal("/"))
+			Expect(app.Session.GetString(ctx, "currency")).To(Equal("CAD"))
+		})
+
+		It("unknown currency", func() {
+			recorder, ctx := post("GBP", "/about")
+			Expect(recorder.Header().Get("Location")).To(Equal("/about"))
+			Expect(app.Session.GetString(ctx, "currency")).To(Equal(""))
+			Expect(app.Session.GetString(ctx, "error")).To(Equal("unknown currency"))
+		})
+
+		It("shows prices in chosen currency", func() {
+			handler = h.ReservationSummary
+			method = "GET"
+			data := testData{
+				reservation: &models.Reservation{
+					RoomID:      1,
+					StartDate:   time.Date(2050, 1, 2, 0, 0, 0, 0, time.UTC),
+					EndDate:     time.Date(2050, 1, 4, 0, 0, 0, 0, time.UTC),
+					NightlyRate: 10000,
+					Room:        &models.Room{ID: 1, Name: "name"},
+				},
+				statusCode:     http.StatusOK,
+				url:            "/reservation-summary",
+				dataForSession: map[string]interface{}{"currency": "EUR"},
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("€68.00 per night"))
+			Expect(body).To(ContainSubstring("€136.00 for the room"))
+			Expect(body).To(ContainSubstring("Prices are shown in EUR at €0.68 for $1.00"))
+			Expect(body).To(ContainSubstring(`<option value="EUR" selected>`))
+		})
+
+		It("shows prices in base currency by default", func() {
+			handler = h.About
+			method = "GET"
+			doall(testData{statusCode: http.StatusOK, url: "/about"})
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`<option value="CAD" selected>`))
+			Expect(body).ToNot(ContainSubstring("Prices are shown in"))
+		})
+	})
+
+	Context("AdminCurrencies", func() {
+		BeforeEach(func() {
+			handler = h.AdminCurrencies
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllCurrencies().Return([]models.Currency{
+				{ID: 1, Code: "EUR", Symbol: "€", Rate: 0.68},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/currencies",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`value="0.68"`))
+			Expect(body).To(ContainSubstring("€68.00"))
+		})
+
+		It("error in GetAllCurrencies", func() {
+			mockDB.EXPECT().GetAllCurrencies().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get currencies",
+				url:         "/admin/currencies",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostCurrency", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("code", " gbp ")
+			basicVal.Add("symbol", "£")
+			basicVal.Add("rate", "0.585")
+			handler = h.AdminPostCurrency
+			method = "POST"
+		})
+
+		AfterEach(func() {
+			app.Currencies.Set(nil)
+		})
+
+		It("adds currency and reloads rates", func() {
+			mockDB.EXPECT().InsertCurrency(gomock.Eq(&models.Currency{Code: "GBP", Symbol: "£", Rate: 0.585})).
+				Return(2, nil).Times(1)
+			gbp := models.Currency{ID: 2, Code: "GBP", Symbol: "£", Rate: 0.585}
+			mockDB.EXPECT().GetAllCurrencies().Return([]models.Currency{gbp}, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/currencies",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+			Expect(app.Currencies.Get("GBP")).To(Equal(gbp))
+		})
+
+		It("updates currency", func() {
+			basicVal.Add("id", "3")
+			basicVal.Set("code", "USD")
+			basicVal.Set("symbol", "US$")
+			basicVal.Set("rate", "0.74")
+			mockDB.EXPECT().UpdateCurrency(gomock.Eq(models.Currency{ID: 3, Code: "USD", Symbol: "US$", Rate: 0.74})).
+				Return(nil).Times(1)
+			mockDB.EXPECT().GetAllCurrencies().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/currencies",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+		})
+
+		It("error in InsertCurrency", func() {
+			basicVal.Set("code", "JPY")
+			mockDB.EXPECT().InsertCurrency(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save currency",
+				url:         "/admin/currencies",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+		})
+
+		for _, tc := range []struct{ field, value, errorString string }{
+			{"code", "EURO", "currency code must be three letters"},
+			{"code", "cad", "base currency has no exchange rate"},
+			{"symbol", " ", "currency symbol is required"},
+			{"rate", "0", "exchange rate must be a positive number"},
+			{"id", "q", "wrong id"},
+		} {
+			tc := tc
+			It("wrong "+tc.field+" "+tc.value, func() {
+				basicVal.Set(tc.field, tc.value)
+				data := testData{
+					val:         &basicVal,
+					statusCode:  http.StatusSeeOther,
+					errorString: tc.errorString,
+					url:         "/admin/currencies",
+					redirectURL: "/admin/currencies",
+				}
+				doall(data)
+			})
+		}
+	})
+
+	Context("AdminPostDeleteCurrency", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteCurrency
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().DeleteCurrencyByID(gomock.Eq(5)).Return(nil).Times(1)
+			mockDB.EXPECT().GetAllCurrencies().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-currency/5/do",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteCurrencyByID", func() {
+			mockDB.EXPECT().DeleteCurrencyByID(gomock.Eq(6)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete currency",
+				url:         "/admin/delete-currency/6/do",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/delete-currency/q/do",
+				redirectURL: "/admin/currencies",
+			}
+			doall(data)
+		})
+	})
+
+	Context("add-ons", func() {
+		var basicVal url.Values
+		var basicRes models.Reservation
+		catalog := []models.AddOn{
+			{ID: 1, Name: "Breakfast", Description: "Full breakfast", Price: 1500, Basis: models.AddOnPerPersonNight},
+			{ID: 2, Name: "Parking", Price: 1000, Basis: models.AddOnPerNight, DailyLimit: 2},
+		}
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicVal.Add("guests", "2")
+			basicVal.Add("add_on_1", "1")
+			basicVal.Add("add_on_2", "0")
+			basicRes = models.Reservation{
+				RoomID:      13,
+				StartDate:   time.Date(2050, 3, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:     time.Date(2050, 3, 4, 0, 0, 0, 0, time.UTC),
+				NightlyRate: 10000,
+				Room:        &models.Room{ID: 13, Name: "room name", Price: 10000},
+			}
+			handler = h.PostMakeReservation
+			method = "POST"
+		})
+
+		It("offers add-ons on reservation form", func() {
+			handler = h.MakeReservation
+			method = "GET"
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(13)).Return(&models.Room{ID: 13, Name: "room name"}, nil).Times(1)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(1)
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`name="add_on_1"`))
+			Expect(body).To(ContainSubstring("Breakfast &mdash; $15.00 per person per night"))
+			Expect(body).To(ContainSubstring(`max="2"`))
+		})
+
+		It("saves picked add-ons with the reservation", func() {
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).DoAndReturn(func(res *models.Reservation) (int, error) {
+				Expect(res.AddOns).To(Equal([]models.ReservationAddOn{
+					{AddOnID: 1, Name: "Breakfast", Quantity: 1, Amount: 9000},
+				}))
+				return 71, nil
+			}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				url:         "/make-reservation",
+				redirectURL: "/reservation-summary",
+			}
+			doall(data)
+		})
+
+		It("shows add-ons in the quote when form is invalid", func() {
+			basicVal.Set("first_name", "")
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(2)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Breakfast: $90.00"))
+			Expect(body).To(ContainSubstring("Total: <strong>$390.00</strong>"))
+			Expect(body).To(ContainSubstring(`name="add_on_1" value="1"`))
+		})
+
+		It("more than daily limit", func() {
+			basicVal.Set("add_on_2", "3")
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(2)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Only 2 of Parking can be booked"))
+		})
+
+		It("unknown add-on", func() {
+			basicVal.Add("add_on_9", "1")
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(2)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Unknown add-on"))
+		})
+
+		It("wrong quantity", func() {
+			basicVal.Set("add_on_1", "-1")
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(1)
+			mockDB.EXPECT().GetAllTaxes().Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/make-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Quantity must be a whole number"))
+		})
+
+		It("error in GetAllAddOns", func() {
+			mockDB.EXPECT().GetAllAddOns().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get add-ons",
+				url:         "/make-reservation",
+				redirectURL: "/make-reservation",
+			}
+			doall(data)
+		})
+
+		It("sold out add-on", func() {
+			mockDB.EXPECT().GetAllAddOns().Return(catalog, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).Return(0, repository.ErrAddOnNotAvailable).Times(1)
+			data := testData{
+				val:         &basicVal,
+				reservation: &basicRes,
+				statusCode:  http.StatusSeeOther,
+				errorString: "sorry, some of the extras you picked are sold out for your dates",
+				url:         "/make-reservation",
+				redirectURL: "/make-reservation",
+			}
+			doall(data)
+		})
+
+		It("shows add-ons in summary", func() {
+			handler = h.ReservationSummary
+			method = "GET"
+			basicRes.Guests = 2
+			basicRes.AddOns = []models.ReservationAddOn{{AddOnID: 2, Name: "Parking", Quantity: 2, Amount: 6000}}
+			data := testData{
+				reservation: &basicRes,
+				statusCode:  http.StatusOK,
+				url:         "/reservation-summary",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Parking x 2:"))
+			Expect(body).To(ContainSubstring("<strong>$360.00</strong>"))
+		})
+	})
+
+	Context("AdminPostReservationAddOn", func() {
+		var basicVal url.Values
+		var res models.Reservation
+		parking := models.AddOn{ID: 2, Name: "Parking", Price: 1000, Basis: models.AddOnPerNight, DailyLimit: 2}
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("add_on_id", "2")
+			basicVal.Add("quantity", "2")
+			res = models.Reservation{
+				ID:        72,
+				StartDate: time.Date(2050, 3, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 3, 4, 0, 0, 0, 0, time.UTC),
+			}
+			handler = h.AdminPostReservationAddOn
+			method = "POST"
+		})
+
+		It("adds add-on", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(72)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAddOnByID(gomock.Eq(2)).Return(&parking, nil).Times(1)
+			mockDB.EXPECT().AddReservationAddOn(gomock.Eq(&models.ReservationAddOn{
+				ReservationID: 72, AddOnID: 2, Name: "Parking", Quantity: 2, Amount: 6000,
+			}), gomock.Eq(0)).Return(1, nil).Times(1)
+			basicVal.Add("year", "2050")
+			basicVal.Add("month", "3")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/cal/72/add-ons",
+				redirectURL: "/admin/reservations/cal/72/show?y=2050&m=3",
+			}
+			doall(data)
+		})
+
+		It("sold out", func() {
+			res.ID = 73
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(73)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAddOnByID(gomock.Eq(2)).Return(&parking, nil).Times(1)
+			mockDB.EXPECT().AddReservationAddOn(gomock.Any(), gomock.Any()).
+				Return(0, repository.ErrAddOnNotAvailable).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "Parking is sold out on these dates",
+				url:         "/admin/reservations/all/73/add-ons",
+				redirectURL: "/admin/reservations/all/73/show",
+			}
+			doall(data)
+		})
+
+		It("cancelled reservation", func() {
+			res.ID = 74
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(74)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAddOnByID(gomock.Eq(2)).Return(&parking, nil).Times(1)
+			mockDB.EXPECT().AddReservationAddOn(gomock.Any(), gomock.Any()).
+				Return(0, repository.ErrReservationCancelled).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "reservation is cancelled",
+				url:         "/admin/reservations/all/74/add-ons",
+				redirectURL: "/admin/reservations/all/74/show",
+			}
+			doall(data)
+		})
+
+		It("error in GetAddOnByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(75)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAddOnByID(gomock.Eq(2)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find add-on",
+				url:         "/admin/reservations/all/75/add-ons",
+				redirectURL: "/admin/reservations/all/75/show",
+			}
+			doall(data)
+		})
+
+		It("wrong quantity", func() {
+			basicVal.Set("quantity", "0")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "quantity must be a positive whole number",
+				url:         "/admin/reservations/all/72/add-ons",
+				redirectURL: "/admin/reservations/all/72/show",
+			}
+			doall(data)
+		})
+
+		It("wrong add-on", func() {
+			basicVal.Set("add_on_id", "q")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong add-on",
+				url:         "/admin/reservations/all/72/add-ons",
+				redirectURL: "/admin/reservations/all/72/show",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostRemoveReservationAddOn", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostRemoveReservationAddOn
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().RemoveReservationAddOn(gomock.Eq(72), gomock.Eq(5), gomock.Eq(0)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"3"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/remove-add-on/cal/72/5/do",
+				redirectURL: "/admin/reservations/cal/72/show?y=2050&m=3",
+			}
+			doall(data)
+		})
+
+		It("error in RemoveReservationAddOn", func() {
+			mockDB.EXPECT().RemoveReservationAddOn(gomock.Eq(72), gomock.Eq(6), gomock.Eq(0)).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't remove add-on",
+				url:         "/admin/remove-add-on/all/72/6/do",
+				redirectURL: "/admin/reservations/all/72/show",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/remove-add-on/all/72/q/do",
+				redirectURL: "/admin/reservations/all/72/show",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminAddOns", func() {
+		BeforeEach(func() {
+			handler = h.AdminAddOns
+			method = "GET"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().GetAllAddOns().Return([]models.AddOn{
+				{ID: 2, Name: "Parking", Price: 1000, Basis: models.AddOnPerNight, DailyLimit: 4},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/add-ons",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("$10.00 per night, up to 4 a night"))
+		})
+
+		It("error in GetAllAddOns", func() {
+			mockDB.EXPECT().GetAllAddOns().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get add-ons",
+				url:         "/admin/add-ons",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostAddOn", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("name", " Breakfast ")
+			basicVal.Add("description", "Full breakfast")
+			basicVal.Add("price", "15")
+			basicVal.Add("basis", models.AddOnPerPersonNight)
+			handler = h.AdminPostAddOn
+			method = "POST"
+		})
+
+		It("adds add-on", func() {
+			mockDB.EXPECT().InsertAddOn(gomock.Eq(&models.AddOn{
+				Name:        "Breakfast",
+				Description: "Full breakfast",
+				Price:       1500,
+				Basis:       models.AddOnPerPersonNight,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/add-ons",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+
+		It("updates add-on with limit", func() {
+			basicVal.Add("id", "2")
+			basicVal.Set("name", "Parking")
+			basicVal.Set("description", "")
+			basicVal.Set("price", "10")
+			basicVal.Set("basis", models.AddOnPerNight)
+			basicVal.Add("daily_limit", "4")
+			mockDB.EXPECT().UpdateAddOn(gomock.Eq(models.AddOn{
+				ID: 2, Name: "Parking", Price: 1000, Basis: models.AddOnPerNight, DailyLimit: 4,
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/add-ons",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+
+		It("error in InsertAddOn", func() {
+			mockDB.EXPECT().InsertAddOn(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save add-on",
+				url:         "/admin/add-ons",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+
+		for _, tc := range []struct{ field, value, errorString string }{
+			{"name", " ", "add-on name is required"},
+			{"price", "0", "add-on price must be a positive amount with at most two decimals"},
+			{"basis", "week", "unknown add-on basis"},
+			{"daily_limit", "-1", "daily limit must be a whole number"},
+			{"id", "q", "wrong id"},
+		} {
+			tc := tc
+			It("wrong "+tc.field, func() {
+				basicVal.Set(tc.field, tc.value)
+				data := testData{
+					val:         &basicVal,
+					statusCode:  http.StatusSeeOther,
+					errorString: tc.errorString,
+					url:         "/admin/add-ons",
+					redirectURL: "/admin/add-ons",
+				}
+				doall(data)
+			})
+		}
+	})
+
+	Context("AdminPostDeleteAddOn", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostDeleteAddOn
+			method = "POST"
+		})
+
+		It("normal", func() {
+			mockDB.EXPECT().DeleteAddOnByID(gomock.Eq(3)).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-add-on/3/do",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteAddOnByID", func() {
+			mockDB.EXPECT().DeleteAddOnByID(gomock.Eq(4)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete add-on",
+				url:         "/admin/delete-add-on/4/do",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/delete-add-on/q/do",
+				redirectURL: "/admin/add-ons",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminCreateReservation", func() {
+		BeforeEach(func() {
+			handler = h.AdminCreateReservation
+			method = "GET"
+		})
+
+		It("shows search form", func() {
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/create-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Search Availability"))
+			Expect(rr.Body.String()).ToNot(ContainSubstring("Make Reservation\""))
+		})
+
+		It("shows free rooms", func() {
+			mockDB.EXPECT().AvailabilityOfAllRooms(
+				gomock.Eq(time.Date(2050, 4, 1, 0, 0, 0, 0, time.UTC)),
+				gomock.Eq(time.Date(2050, 4, 3, 0, 0, 0, 0, time.UTC)),
+			).Return([]models.Room{{ID: 31, Name: "Blue room", Price: 10000}}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/create-reservation?start=2050-04-01&end=2050-04-03",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`id="room_31"`))
+			Expect(body).To(ContainSubstring("Blue room &mdash; $100.00 per night"))
+		})
+
+		It("error in AvailabilityOfAllRooms", func() {
+			mockDB.EXPECT().AvailabilityOfAllRooms(
+				gomock.Eq(time.Date(2050, 4, 5, 0, 0, 0, 0, time.UTC)), gomock.Any(),
+			).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get rooms",
+				url:         "/admin/create-reservation?start=2050-04-05&end=2050-04-06",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("bad start time", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad start time",
+				url:         "/admin/create-reservation?start=q&end=2050-04-06",
+				redirectURL: "/admin/create-reservation",
+			}
+			doall(data)
+		})
+
+		It("departure before arrival", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "departure must be after arrival",
+				url:         "/admin/create-reservation?start=2050-04-06&end=2050-04-06",
+				redirectURL: "/admin/create-reservation",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostCreateReservation", func() {
+		var basicVal url.Values
+		room := models.Room{ID: 31, Name: "Blue room", Price: 10000}
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("start", "2050-04-01")
+			basicVal.Add("end", "2050-04-03")
+			basicVal.Add("room_id", "31")
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Black")
+			basicVal.Add("email", "john@here.com")
+			basicVal.Add("phone", "123456789")
+			basicVal.Add("guests", "2")
+			basicVal.Add("source", models.SourcePhone)
+			handler = h.AdminPostCreateReservation
+			method = "POST"
+		})
+
+		It("books at room rate without confirmation", func() {
+			basicVal.Add("override_reason", "not needed")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).DoAndReturn(func(res *models.Reservation) (int, error) {
+				Expect(res.NightlyRate).To(Equal(10000))
+				Expect(res.Source).To(Equal(models.SourcePhone))
+				Expect(res.OverrideReason).To(Equal(""))
+				Expect(res.IsProcessed).To(Equal(1))
+				Expect(res.Guests).To(Equal(2))
+				Expect(res.ConfirmationCode).ToNot(BeEmpty())
+				return 81, nil
+			}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/reservations/all/81/show",
+			}
+			doall(data)
+			Expect(app.MailChan).To(BeEmpty())
+		})
+
+		It("overrides price and sends confirmation", func() {
+			basicVal.Add("nightly_rate", "80")
+			basicVal.Add("override_reason", "returning guest")
+			basicVal.Add("send_confirmation", "1")
+			basicVal.Set("source", models.SourceWalkIn)
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).DoAndReturn(func(res *models.Reservation) (int, error) {
+				Expect(res.NightlyRate).To(Equal(8000))
+				Expect(res.Source).To(Equal(models.SourceWalkIn))
+				Expect(res.OverrideReason).To(Equal("returning guest"))
+				return 82, nil
+			}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/reservations/all/82/show",
+			}
+			doall(data)
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("john@here.com"))
+			Expect(msg.Content).To(ContainSubstring("Blue room"))
+			Expect(msg.Content).To(ContainSubstring("$160.00"))
+		})
+
+		It("past arrival with override", func() {
+			basicVal.Set("start", "2020-04-01")
+			basicVal.Set("end", "2020-04-03")
+			basicVal.Add("override_rules", "1")
+			basicVal.Add("override_reason", "walk-in entered late")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).DoAndReturn(func(res *models.Reservation) (int, error) {
+				Expect(res.StartDate).To(Equal(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)))
+				Expect(res.OverrideReason).To(Equal("walk-in entered late"))
+				return 83, nil
+			}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/reservations/all/83/show",
+			}
+			doall(data)
+		})
+
+		It("past arrival without override", func() {
+			basicVal.Set("start", "2020-04-01")
+			basicVal.Set("end", "2020-04-03")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().AvailabilityOfAllRooms(
+				gomock.Eq(time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)), gomock.Any(),
+			).Return([]models.Room{room}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/admin/create-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Arrival is in the past"))
+		})
+
+		It("override without reason", func() {
+			basicVal.Add("nightly_rate", "80")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().AvailabilityOfAllRooms(
+				gomock.Eq(time.Date(2050, 4, 1, 0, 0, 0, 0, time.UTC)), gomock.Any(),
+			).Return([]models.Room{room}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/admin/create-reservation",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("A reason is required to override the price or stay rules"))
+		})
+
+		It("unknown source and confirmation without email", func() {
+			basicVal.Set("source", "fax")
+			basicVal.Set("email", "")
+			basicVal.Add("send_confirmation", "1")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().AvailabilityOfAllRooms(
+				gomock.Eq(time.Date(2050, 4, 1, 0, 0, 0, 0, time.UTC)), gomock.Any(),
+			).Return([]models.Room{room}, nil).Times(1)
+			data := testData{
+				val:        &basicVal,
+				statusCode: http.StatusOK,
+				url:        "/admin/create-reservation",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Unknown booking source"))
+			Expect(body).To(ContainSubstring("This field is required"))
+		})
+
+		It("room is taken", func() {
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				Return(0, repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "room is not available on these dates",
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/create-reservation?start=2050-04-01&end=2050-04-03",
+			}
+			doall(data)
+		})
+
+		It("error in InsertReservationWithHold", func() {
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(31)).Return(&room, nil).Times(1)
+			mockDB.EXPECT().InsertReservationWithHold(gomock.Any()).
+				Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't insert reservation",
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/create-reservation",
+			}
+			doall(data)
+		})
+
+		It("error in GetRoomByID", func() {
+			basicVal.Set("room_id", "32")
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(32)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find room",
+				url:         "/admin/create-reservation",
+				redirectURL: "/admin/create-reservation",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostMoveReservation", func() {
+		var basicVal url.Values
+		var res models.Reservation
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("start", "2050-05-02")
+			basicVal.Add("end", "2050-05-05")
+			basicVal.Add("room_id", "1")
+			res = models.Reservation{
+				ID:               91,
+				FirstName:        "John",
+				Email:            "john@here.com",
+				ConfirmationCode: "ABC123",
+				StartDate:        time.Date(2050, 5, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:          time.Date(2050, 5, 3, 0, 0, 0, 0, time.UTC),
+				RoomID:           1,
+				RatePlanID:       4,
+				NightlyRate:      9000,
+				Room:             &models.Room{ID: 1, Name: "Blue room", Price: 10000},
+			}
+			handler = h.AdminPostMoveReservation
+			method = "POST"
+		})
+
+		It("changes dates keeping the rate", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(91)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Eq(0)).DoAndReturn(func(moved models.Reservation, _ int) error {
+				Expect(moved.StartDate).To(Equal(time.Date(2050, 5, 2, 0, 0, 0, 0, time.UTC)))
+				Expect(moved.EndDate).To(Equal(time.Date(2050, 5, 5, 0, 0, 0, 0, time.UTC)))
+				Expect(moved.RoomID).To(Equal(1))
+				Expect(moved.RatePlanID).To(Equal(4))
+				Expect(moved.NightlyRate).To(Equal(9000))
+				return nil
+			}).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			basicVal.Add("year", "2050")
+			basicVal.Add("month", "5")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/cal/91/stay",
+				redirectURL: "/admin/reservations/cal/91/show?y=2050&m=5",
+			}
+			doall(data)
+			Expect(app.MailChan).To(BeEmpty())
+		})
+
+		It("moves to another room at its rate and notifies guest", func() {
+			res.ID = 92
+			basicVal.Set("room_id", "2")
+			basicVal.Add("notify", "1")
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(92)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(2)).Return(&models.Room{ID: 2, Name: "Red room", Price: 12000}, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Eq(0)).DoAndReturn(func(moved models.Reservation, _ int) error {
+				Expect(moved.RoomID).To(Equal(2))
+				Expect(moved.RatePlanID).To(Equal(0))
+				Expect(moved.NightlyRate).To(Equal(12000))
+				return nil
+			}).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/all/92/stay",
+				redirectURL: "/admin/reservations/all/92/show",
+			}
+			doall(data)
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("john@here.com"))
+			Expect(msg.Content).To(ContainSubstring("is now in Red room from 2050-05-02 to 2050-05-05"))
+		})
+
+		It("nothing to change", func() {
+			res.ID = 93
+			basicVal.Set("start", "2050-05-01")
+			basicVal.Set("end", "2050-05-03")
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(93)).Return(&res, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/all/93/stay",
+				redirectURL: "/admin/reservations/all/93/show",
+			}
+			doall(data)
+		})
+
+		It("room is taken", func() {
+			res.ID = 94
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(94)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Any()).Return(repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "room is not available on these dates",
+				url:         "/admin/reservations/all/94/stay",
+				redirectURL: "/admin/reservations/all/94/show",
+			}
+			doall(data)
+		})
+
+		It("cancelled reservation", func() {
+			res.ID = 95
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(95)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Any()).Return(repository.ErrReservationCancelled).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "reservation is cancelled",
+				url:         "/admin/reservations/all/95/stay",
+				redirectURL: "/admin/reservations/all/95/show",
+			}
+			doall(data)
+		})
+
+		It("no-show reservation", func() {
+			res.ID = 95
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(95)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Any()).Return(repository.ErrReservationNoShow).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "reservation is marked as a no-show",
+				url:         "/admin/reservations/all/95/stay",
+				redirectURL: "/admin/reservations/all/95/show",
+			}
+			doall(data)
+		})
+
+		It("error in MoveReservation", func() {
+			res.ID = 96
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(96)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().MoveReservation(gomock.Any(), gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't change reservation",
+				url:         "/admin/reservations/all/96/stay",
+				redirectURL: "/admin/reservations/all/96/show",
+			}
+			doall(data)
+		})
+
+		It("error in GetRoomByID", func() {
+			res.ID = 97
+			basicVal.Set("room_id", "3")
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(97)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetRoomByID(gomock.Eq(3)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find room",
+				url:         "/admin/reservations/all/97/stay",
+				redirectURL: "/admin/reservations/all/97/show",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(98)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find reservation",
+				url:         "/admin/reservations/all/98/stay",
+				redirectURL: "/admin/reservations/all/98/show",
+			}
+			doall(data)
+		})
+
+		for _, tc := range []struct{ field, value, errorString string }{
+			{"start", "q", "bad start time"},
+			{"end", "q", "bad end time"},
+			{"end", "2050-05-02", "departure must be after arrival"},
+			{"room_id", "q", "wrong room"},
+		} {
+			tc := tc
+			It("wrong "+tc.field+" "+tc.value, func() {
+				basicVal.Set(tc.field, tc.value)
+				data := testData{
+					val:         &basicVal,
+					statusCode:  http.StatusSeeOther,
+					errorString: tc.errorString,
+					url:         "/admin/reservations/all/91/stay",
+					redirectURL: "/admin/reservations/all/91/show",
+				}
+				doall(data)
+			})
+		}
+	})
+
+	Context("AdminDashboard", func() {
+		BeforeEach(func() {
+			handler = h.AdminDashboard
+			method = "GET"
+		})
+
+		It("puts reservations on the boards", func() {
+			now := time.Now()
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			room := &models.Room{ID: 1, Name: "room name"}
+			reservations := []models.Reservation{
+				{ID: 51, FirstName: "Arriving", LastName: "Guest", Room: room,
+					StartDate: day, EndDate: day.AddDate(0, 0, 2), Status: models.ReservationConfirmed},
+				{ID: 52, FirstName: "Staying", LastName: "Guest", Room: room,
+					StartDate: day.AddDate(0, 0, -1), EndDate: day.AddDate(0, 0, 1),
+					Status: models.ReservationConfirmed, CheckedInAt: now.AddDate(0, 0, -1)},
+				{ID: 53, FirstName: "Leaving", LastName: "Guest", Room: room,
+					StartDate: day.AddDate(0, 0, -2), EndDate: day,
+					Status: models.ReservationConfirmed, CheckedInAt: now.AddDate(0, 0, -2)},
+			}
+			mockDB.EXPECT().GetFrontDeskReservations(gomock.Eq(day)).Return(reservations, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/dashboard",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Arriving Guest"))
+			Expect(body).To(ContainSubstring("Staying Guest"))
+			Expect(body).To(ContainSubstring("Leaving Guest"))
+			Expect(strings.Index(body, "Arriving Guest")).To(BeNumerically("<", strings.Index(body, "Staying Guest")))
+			Expect(strings.Index(body, "Staying Guest")).To(BeNumerically("<", strings.Index(body, "Leaving Guest")))
+		})
+
+		It("error in GetFrontDeskReservations", func() {
+			mockDB.EXPECT().GetFrontDeskReservations(gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/dashboard",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("get today"))
+		})
+	})
+
+	Context("AdminPostCheckIn", func() {
+		var res models.Reservation
+
+		BeforeEach(func() {
+			now := time.Now()
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			res = models.Reservation{
+				ID:        61,
+				RoomID:    1,
+				StartDate: day,
+				EndDate:   day.AddDate(0, 0, 2),
+				Status:    models.ReservationConfirmed,
+			}
+			handler = h.AdminPostCheckIn
+			method = "POST"
+		})
+
+		It("checks in from the boards", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Eq(7), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				val:            &url.Values{},
+				statusCode:     http.StatusSeeOther,
+				url:            "/admin/check-in/desk/61/do",
+				redirectURL:    "/admin/dashboard",
+				dataForSession: map[string]interface{}{"user_id": 7},
+			}
+			doall(data)
+		})
+
+		It("checks in from the reservation page", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"1"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/check-in/cal/61/do",
+				redirectURL: "/admin/reservations/cal/61/show?y=2050&m=1",
+			}
+			doall(data)
+		})
+
+		It("guest is late for a past arrival", func() {
+			res.StartDate = res.StartDate.AddDate(0, 0, -1)
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("arrival is in the future", func() {
+			res.StartDate = res.StartDate.AddDate(0, 0, 1)
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			data := testData{
+				val:        &url.Values{},
+				statusCode: http.StatusSeeOther,
+				errorString: fmt.Sprintf("guest arrives on %s, change the stay to check in earlier",
+					res.StartDate.Format("2006-01-02")),
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("stay is over", func() {
+			res.StartDate = res.StartDate.AddDate(0, 0, -3)
+			res.EndDate = res.EndDate.AddDate(0, 0, -2)
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "the stay is over",
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("no-show", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Any(), gomock.Any()).
+				Return(repository.ErrReservationNoShow).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "reservation is marked as a no-show, book the guest again",
+				url:         "/admin/check-in/all/61/do",
+				redirectURL: "/admin/reservations/all/61/show",
+			}
+			doall(data)
+		})
+
+		It("already checked in", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Any(), gomock.Any()).
+				Return(repository.ErrAlreadyCheckedIn).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest is already checked in",
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in CheckInReservation", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckInReservation(gomock.Eq(61), gomock.Any(), gomock.Any()).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't check in guest",
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(61)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation",
+				url:         "/admin/check-in/desk/61/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/check-in/desk/q/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/check-in",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostCheckOut", func() {
+		var res models.Reservation
+
+		BeforeEach(func() {
+			now := time.Now()
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			res = models.Reservation{
+				ID:          62,
+				RoomID:      1,
+				StartDate:   day.AddDate(0, 0, -2),
+				EndDate:     day,
+				Status:      models.ReservationConfirmed,
+				CheckedInAt: now.AddDate(0, 0, -2),
+			}
+			handler = h.AdminPostCheckOut
+			method = "POST"
+		})
+
+		It("checks out on the departure day", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckOutReservation(gomock.Eq(62), gomock.Eq(7), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				val:            &url.Values{},
+				statusCode:     http.StatusSeeOther,
+				url:            "/admin/check-out/desk/62/do",
+				redirectURL:    "/admin/dashboard",
+				dataForSession: map[string]interface{}{"user_id": 7},
+			}
+			doall(data)
+		})
+
+		It("frees the rest of the stay on an early departure", func() {
+			res.EndDate = res.EndDate.AddDate(0, 0, 2)
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckOutReservation(gomock.Eq(62), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Eq(res.EndDate.AddDate(0, 0, -2)),
+				gomock.Eq(res.EndDate)).Return([]models.WaitlistEntry{}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/check-out/all/62/do",
+				redirectURL: "/admin/reservations/all/62/show",
+			}
+			doall(data)
+		})
+
+		It("not checked in", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckOutReservation(gomock.Eq(62), gomock.Any(), gomock.Any()).
+				Return(repository.ErrNotCheckedIn).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest is not checked in",
+				url:         "/admin/check-out/desk/62/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("already checked out", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckOutReservation(gomock.Eq(62), gomock.Any(), gomock.Any()).
+				Return(repository.ErrAlreadyCheckedOut).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest is already checked out",
+				url:         "/admin/check-out/desk/62/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in CheckOutReservation", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().CheckOutReservation(gomock.Eq(62), gomock.Any(), gomock.Any()).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't check out guest",
+				url:         "/admin/check-out/desk/62/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationByID", func() {
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get reservation",
+				url:         "/admin/check-out/desk/62/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("shows check-in and check-out on the reservation page", func() {
+			handler = h.AdminSingleReservation
+			method = "GET"
+			res.CheckedInUser = &models.User{ID: 7, FirstName: "Desk", LastName: "Clerk"}
+			res.Room = &models.Room{ID: 1, Name: "room name"}
+			mockDB.EXPECT().GetReservationByID(gomock.Eq(62)).Return(&res, nil).Times(1)
+			mockDB.EXPECT().GetAllAddOns().Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reservations/all/62/show",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("by Desk Clerk"))
+			Expect(rr.Body.String()).To(ContainSubstring("Check Out"))
+		})
+	})
+
+	Context("AdminHousekeeping", func() {
+		var rooms []models.Room
+
+		BeforeEach(func() {
+			rooms = []models.Room{
+				{ID: 1, Name: "Room #1", HousekeepingStatus: models.HousekeepingDirty},
+				{ID: 2, Name: "Room #2", HousekeepingStatus: models.HousekeepingInspected},
+			}
+			handler = h.AdminHousekeeping
+			method = "GET"
+		})
+
+		It("shows rooms with today's tasks", func() {
+			now := time.Now()
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetHousekeepingTasks(gomock.Eq(day)).Return([]models.HousekeepingTask{
+				{ID: 1, RoomID: 1, TaskDate: day, TaskType: models.TaskDeparture,
+					Reservation: &models.Reservation{FirstName: "Leaving", LastName: "Guest"}},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/housekeeping",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Departure clean"))
+			Expect(body).To(ContainSubstring("Leaving Guest"))
+			Expect(body).To(ContainSubstring("Nothing to do today"))
+			Expect(body).To(ContainSubstring("Inspected"))
+		})
+
+		It("error in GetHousekeepingTasks", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetHousekeepingTasks(gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/housekeeping",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Room #2"))
+		})
+
+		It("error in GetAllRooms", func() {
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get rooms",
+				url:         "/admin/housekeeping",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostHousekeeping", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("room_id", "1")
+			basicVal.Add("status", models.HousekeepingClean)
+			handler = h.AdminPostHousekeeping
+			method = "POST"
+		})
+
+		It("updates room status", func() {
+			mockDB.EXPECT().UpdateHousekeepingStatus(gomock.Eq(1), gomock.Eq(models.HousekeepingClean), gomock.Eq(7)).
+				Return(nil).Times(1)
+			data := testData{
+				val:            &basicVal,
+				statusCode:     http.StatusSeeOther,
+				url:            "/admin/housekeeping",
+				redirectURL:    "/admin/housekeeping#room-1",
+				dataForSession: map[string]interface{}{"user_id": 7},
+			}
+			doall(data)
+		})
+
+		It("unknown status", func() {
+			basicVal.Set("status", "sparkling")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown housekeeping status",
+				url:         "/admin/housekeeping",
+				redirectURL: "/admin/housekeeping",
+			}
+			doall(data)
+		})
+
+		It("wrong room", func() {
+			basicVal.Set("room_id", "q")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong room",
+				url:         "/admin/housekeeping",
+				redirectURL: "/admin/housekeeping",
+			}
+			doall(data)
+		})
+
+		It("error in UpdateHousekeepingStatus", func() {
+			mockDB.EXPECT().UpdateHousekeepingStatus(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update room status",
+				url:         "/admin/housekeeping",
+				redirectURL: "/admin/housekeeping",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminOutOfOrder", func() {
+		BeforeEach(func() {
+			handler = h.AdminOutOfOrder
+			method = "GET"
+		})
+
+		It("shows out of order periods", func() {
+			now := time.Now()
+			day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			mockDB.EXPECT().GetOutOfOrderPeriods(gomock.Eq(day)).Return([]models.OutOfOrderPeriod{
+				{ID: 1, RoomID: 1, StartDate: day, EndDate: day.AddDate(0, 0, 3), Reason: "Leaking pipe",
+					Room: &models.Room{ID: 1, Name: "Room #1"}},
+			}, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "Room #1"}}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/out-of-order",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Leaking pipe"))
+		})
+
+		It("error in GetOutOfOrderPeriods", func() {
+			mockDB.EXPECT().GetOutOfOrderPeriods(gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get out of order periods",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllRooms", func() {
+			mockDB.EXPECT().GetOutOfOrderPeriods(gomock.Any()).Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get rooms",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostOutOfOrder", func() {
+		var basicVal url.Values
+		var day time.Time
+
+		BeforeEach(func() {
+			now := time.Now()
+			day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+			basicVal = url.Values{}
+			basicVal.Add("room_id", "1")
+			basicVal.Add("start", day.AddDate(0, 0, 5).Format("2006-01-02"))
+			basicVal.Add("end", day.AddDate(0, 0, 8).Format("2006-01-02"))
+			basicVal.Add("reason", " Leaking pipe ")
+			handler = h.AdminPostOutOfOrder
+			method = "POST"
+		})
+
+		It("blocks the room in the future", func() {
+			mockDB.EXPECT().InsertOutOfOrderPeriod(gomock.Eq(&models.OutOfOrderPeriod{
+				RoomID:    1,
+				StartDate: day.AddDate(0, 0, 5),
+				EndDate:   day.AddDate(0, 0, 8),
+				Reason:    "Leaking pipe",
+				CreatedBy: 7,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:            &basicVal,
+				statusCode:     http.StatusSeeOther,
+				url:            "/admin/out-of-order",
+				redirectURL:    "/admin/out-of-order",
+				dataForSession: map[string]interface{}{"user_id": 7},
+			}
+			doall(data)
+		})
+
+		It("takes the room out of order from today", func() {
+			basicVal.Set("start", day.Format("2006-01-02"))
+			mockDB.EXPECT().InsertOutOfOrderPeriod(gomock.Any()).Return(1, nil).Times(1)
+			mockDB.EXPECT().UpdateHousekeepingStatus(gomock.Eq(1), gomock.Eq(models.HousekeepingOutOfOrder), gomock.Any()).
+				Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("room is booked", func() {
+			mockDB.EXPECT().InsertOutOfOrderPeriod(gomock.Any()).Return(0, repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "room is booked on these dates, move the guests first",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("error in InsertOutOfOrderPeriod", func() {
+			mockDB.EXPECT().InsertOutOfOrderPeriod(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't save out of order period",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("starts in the past", func() {
+			basicVal.Set("start", day.AddDate(0, 0, -1).Format("2006-01-02"))
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "out of order period can't start in the past",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("ends before it starts", func() {
+			basicVal.Set("end", basicVal.Get("start"))
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "room must be back in service after the start date",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("no reason", func() {
+			basicVal.Set("reason", " ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "a reason is required",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("bad start time", func() {
+			basicVal.Set("start", "soon")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad start time",
+				url:         "/admin/out-of-order",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostDeleteOutOfOrder", func() {
+		var period models.OutOfOrderPeriod
+
+		BeforeEach(func() {
+			period = models.OutOfOrderPeriod{
+				ID:        3,
+				RoomID:    1,
+				StartDate: time.Date(2050, 1, 10, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2050, 1, 12, 0, 0, 0, 0, time.UTC),
+			}
+			handler = h.AdminPostDeleteOutOfOrder
+			method = "POST"
+		})
+
+		It("puts the room back in service", func() {
+			mockDB.EXPECT().GetOutOfOrderPeriodByID(gomock.Eq(3)).Return(&period, nil).Times(1)
+			mockDB.EXPECT().DeleteOutOfOrderPeriodByID(gomock.Eq(3)).Return(nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(1), gomock.Eq(period.StartDate), gomock.Eq(period.EndDate)).
+				Return([]models.WaitlistEntry{}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/delete-out-of-order/3/do",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("error in DeleteOutOfOrderPeriodByID", func() {
+			mockDB.EXPECT().GetOutOfOrderPeriodByID(gomock.Eq(3)).Return(&period, nil).Times(1)
+			mockDB.EXPECT().DeleteOutOfOrderPeriodByID(gomock.Eq(3)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete out of order period",
+				url:         "/admin/delete-out-of-order/3/do",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("error in GetOutOfOrderPeriodByID", func() {
+			mockDB.EXPECT().GetOutOfOrderPeriodByID(gomock.Eq(3)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get out of order period",
+				url:         "/admin/delete-out-of-order/3/do",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/delete-out-of-order/q/do",
+				redirectURL: "/admin/out-of-order",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/delete-out-of-order",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("shows out of order nights on the calendar", func() {
+			handler = h.AdminReservationCalendar
+			method = "GET"
+			mockDB.EXPECT().GetAllRooms().Return([]models.Room{{ID: 1, Name: "Room #1"}}, nil).Times(1)
+			mockDB.EXPECT().GetRoomRestrictionsByRoomIdWithinDates(gomock.Eq(1), gomock.Any(), gomock.Any()).
+				Return([]models.RoomRestriction{{
+					ID: 9, RoomID: 1, StartDate: period.StartDate, EndDate: period.EndDate,
+					RestrictionID: models.RestrictionOutOfOrder, OutOfOrderID: 3,
+				}}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/reservation-calendar?y=2050&m=1",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring(`title="Out of order"`))
+			Expect(rr.Body.String()).ToNot(ContainSubstring("remove_block_1_2050-01-10"))
+		})
+	})
+
+	Context("AdminGuests", func() {
+		BeforeEach(func() {
+			handler = h.AdminGuests
+			method = "GET"
+		})
+
+		It("lists guests", func() {
+			mockDB.EXPECT().GetAllGuests().Return([]models.Guest{
+				{ID: 1, FirstName: "John", LastName: "Smith", VIP: true, Stays: 3, LifetimeValue: 123450},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/guests",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("VIP"))
+			Expect(rr.Body.String()).To(ContainSubstring("$1234.50"))
+		})
+
+		It("error in GetAllGuests", func() {
+			mockDB.EXPECT().GetAllGuests().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get guests",
+				url:         "/admin/guests",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminGuest", func() {
+		BeforeEach(func() {
+			handler = h.AdminGuest
+			method = "GET"
+		})
+
+		It("shows stay history", func() {
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(4)).Return(&models.Guest{
+				ID: 4, FirstName: "John", LastName: "Smith", Preferences: "high floor",
+				Reservations: []models.Reservation{
+					{ID: 21, Status: models.ReservationConfirmed, CheckedOutAt: time.Now(),
+						Room: &models.Room{ID: 1, Name: "room name"}},
+					{ID: 22, Status: models.ReservationCancelled},
+				},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/guests/4/show",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("/admin/reservations/all/21/show"))
+			Expect(body).To(ContainSubstring("Stayed"))
+			Expect(body).To(ContainSubstring("Cancelled"))
+			Expect(body).To(ContainSubstring("high floor"))
+		})
+
+		It("error in GetGuestByID", func() {
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(4)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find guest",
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/guests/q/show",
+				redirectURL: "/admin/guests",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/guests/4",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostGuest", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Smith ")
+			basicVal.Add("email", "john@smith.com")
+			basicVal.Add("phone", "555-0100")
+			basicVal.Add("preferences", "high floor")
+			basicVal.Add("notes", "")
+			basicVal.Add("vip", "1")
+			handler = h.AdminPostGuest
+			method = "POST"
+		})
+
+		It("updates guest", func() {
+			mockDB.EXPECT().UpdateGuest(gomock.Eq(models.Guest{
+				ID: 4, FirstName: "John", LastName: "Smith", Email: "john@smith.com", Phone: "555-0100",
+				Preferences: "high floor", VIP: true,
+			})).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests/4/show",
+			}
+			doall(data)
+		})
+
+		It("clears VIP", func() {
+			basicVal.Del("vip")
+			mockDB.EXPECT().UpdateGuest(gomock.Any()).DoAndReturn(func(guest models.Guest) error {
+				Expect(guest.VIP).To(BeFalse())
+				return nil
+			}).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests/4/show",
+			}
+			doall(data)
+		})
+
+		It("bad email", func() {
+			basicVal.Set("email", "john")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest needs a first and last name and a valid email",
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests/4/show",
+			}
+			doall(data)
+		})
+
+		It("no last name", func() {
+			basicVal.Set("last_name", " ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest needs a first and last name and a valid email",
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests/4/show",
+			}
+			doall(data)
+		})
+
+		It("error in UpdateGuest", func() {
+			mockDB.EXPECT().UpdateGuest(gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update guest",
+				url:         "/admin/guests/4/show",
+				redirectURL: "/admin/guests/4/show",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/guests/q/show",
+				redirectURL: "/admin/guests",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminGuestDuplicates", func() {
+		BeforeEach(func() {
+			handler = h.AdminGuestDuplicates
+			method = "GET"
+		})
+
+		It("lists possible duplicates", func() {
+			mockDB.EXPECT().GetAllGuests().Return([]models.Guest{
+				{ID: 1, FirstName: "John", LastName: "Smith", Email: "john.smith@gmail.com"},
+				{ID: 2, FirstName: "Jon", LastName: "Smith", Email: "JohnSmith@gmail.com"},
+				{ID: 3, FirstName: "Mary", LastName: "Jones"},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/guest-duplicates",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("/admin/merge-guests/1/2"))
+			Expect(body).To(ContainSubstring("same email, similar name"))
+			Expect(body).ToNot(ContainSubstring("Mary"))
+		})
+
+		It("error in GetAllGuests", func() {
+			mockDB.EXPECT().GetAllGuests().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get guests",
+				url:         "/admin/guest-duplicates",
+				redirectURL: "/admin/guests",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminMergeGuests", func() {
+		BeforeEach(func() {
+			handler = h.AdminMergeGuests
+			method = "GET"
+		})
+
+		It("shows both profiles", func() {
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(5)).Return(&models.Guest{
+				ID: 5, FirstName: "John", LastName: "Smith", Email: "john@smith.com", Notes: "late arrival",
+			}, nil).Times(1)
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(6)).Return(&models.Guest{
+				ID: 6, FirstName: "Jon", LastName: "Smith", Phone: "555-0100", Notes: "allergic to nuts", VIP: true,
+				Reservations: []models.Reservation{{ID: 31}},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/merge-guests/5/6",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring(`value="Jon"`))
+			Expect(body).To(MatchRegexp(`value="late arrival(\n|&#10;)allergic to nuts"`))
+			Expect(body).To(ContainSubstring("/admin/merge-guests/6/5"))
+			Expect(body).To(ContainSubstring("The 1 reservations"))
+		})
+
+		It("same guest", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't merge a guest into itself",
+				url:         "/admin/merge-guests/5/5",
+				redirectURL: "/admin/guest-duplicates",
+			}
+			doall(data)
+		})
+
+		It("error in GetGuestByID", func() {
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(5)).Return(&models.Guest{ID: 5}, nil).Times(1)
+			mockDB.EXPECT().GetGuestByID(gomock.Eq(6)).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't find guest",
+				url:         "/admin/merge-guests/5/6",
+				redirectURL: "/admin/guest-duplicates",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/merge-guests/5/q",
+				redirectURL: "/admin/guest-duplicates",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/merge-guests/5",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostMergeGuests", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("first_name", "John")
+			basicVal.Add("last_name", "Smith")
+			basicVal.Add("email", "john@smith.com")
+			basicVal.Add("phone", "555-0100")
+			basicVal.Add("preferences", "")
+			basicVal.Add("notes", "late arrival\nallergic to nuts")
+			basicVal.Add("vip", "1")
+			handler = h.AdminPostMergeGuests
+			method = "POST"
+		})
+
+		It("merges guests", func() {
+			mockDB.EXPECT().MergeGuests(gomock.Eq(models.Guest{
+				ID: 5, FirstName: "John", LastName: "Smith", Email: "john@smith.com", Phone: "555-0100",
+				Notes: "late arrival\nallergic to nuts", VIP: true,
+			}), gomock.Eq(6), gomock.Eq(2)).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/merge-guests/5/6",
+				redirectURL: "/admin/guests/5/show",
+				dataForSession: map[string]interface{}{
+					"user_id": 2,
+				},
+			}
+			doall(data)
+		})
+
+		It("bad email", func() {
+			basicVal.Set("email", "john")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "guest needs a first and last name and a valid email",
+				url:         "/admin/merge-guests/5/6",
+				redirectURL: "/admin/merge-guests/5/6",
+			}
+			doall(data)
+		})
+
+		It("error in MergeGuests", func() {
+			mockDB.EXPECT().MergeGuests(gomock.Any(), gomock.Eq(6), gomock.Any()).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't merge guests",
+				url:         "/admin/merge-guests/5/6",
+				redirectURL: "/admin/merge-guests/5/6",
+			}
+			doall(data)
+		})
+
+		It("same guest", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't merge a guest into itself",
+				url:         "/admin/merge-guests/6/6",
+				redirectURL: "/admin/guest-duplicates",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostReservationNote", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("body", " guest is celebrating an anniversary ")
+			handler = h.AdminPostReservationNote
+			method = "POST"
+		})
+
+		It("adds note", func() {
+			mockDB.EXPECT().InsertReservationNote(gomock.Eq(&models.ReservationNote{
+				ReservationID: 7, Body: "guest is celebrating an anniversary", CreatedBy: 2,
+			})).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/all/7/notes",
+				redirectURL: "/admin/reservations/all/7/show",
+				dataForSession: map[string]interface{}{
+					"user_id": 2,
+				},
+			}
+			doall(data)
+		})
+
+		It("keeps the calendar month", func() {
+			basicVal.Add("year", "2050")
+			basicVal.Add("month", "01")
+			mockDB.EXPECT().InsertReservationNote(gomock.Any()).Return(1, nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/cal/7/notes",
+				redirectURL: "/admin/reservations/cal/7/show?y=2050&m=01",
+			}
+			doall(data)
+		})
+
+		It("empty note", func() {
+			basicVal.Set("body", "  ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "note can't be empty",
+				url:         "/admin/reservations/all/7/notes",
+				redirectURL: "/admin/reservations/all/7/show",
+			}
+			doall(data)
+		})
+
+		It("error in InsertReservationNote", func() {
+			mockDB.EXPECT().InsertReservationNote(gomock.Any()).Return(0, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't add note",
+				url:         "/admin/reservations/all/7/notes",
+				redirectURL: "/admin/reservations/all/7/show",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/reservations/all/q/notes",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostReservationTag", func() {
+		var basicVal url.Values
+
+		BeforeEach(func() {
+			basicVal = url.Values{}
+			basicVal.Add("tag", " Late   Arrival ")
+			handler = h.AdminPostReservationTag
+			method = "POST"
+		})
+
+		It("adds tag", func() {
+			mockDB.EXPECT().AddReservationTag(gomock.Eq(7), gomock.Eq("late arrival")).Return(nil).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/reservations/new/7/tags",
+				redirectURL: "/admin/reservations/new/7/show",
+			}
+			doall(data)
+		})
+
+		It("empty tag", func() {
+			basicVal.Set("tag", " ")
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tag must be 1 to 64 characters long",
+				url:         "/admin/reservations/new/7/tags",
+				redirectURL: "/admin/reservations/new/7/show",
+			}
+			doall(data)
+		})
+
+		It("too long tag", func() {
+			basicVal.Set("tag", strings.Repeat("a", 65))
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "tag must be 1 to 64 characters long",
+				url:         "/admin/reservations/new/7/tags",
+				redirectURL: "/admin/reservations/new/7/show",
+			}
+			doall(data)
+		})
+
+		It("error in AddReservationTag", func() {
+			mockDB.EXPECT().AddReservationTag(gomock.Eq(7), gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &basicVal,
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't add tag",
+				url:         "/admin/reservations/new/7/tags",
+				redirectURL: "/admin/reservations/new/7/show",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostRemoveReservationTag", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostRemoveReservationTag
+			method = "POST"
+		})
+
+		It("removes tag", func() {
+			mockDB.EXPECT().RemoveReservationTag(gomock.Eq(7), gomock.Eq("late arrival")).Return(nil).Times(1)
+			data := testData{
+				val:         &url.Values{"year": {"2050"}, "month": {"01"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/remove-tag/cal/7/late%20arrival/do",
+				redirectURL: "/admin/reservations/cal/7/show?y=2050&m=01",
+			}
+			doall(data)
+		})
+
+		It("error in RemoveReservationTag", func() {
+			mockDB.EXPECT().RemoveReservationTag(gomock.Eq(7), gomock.Eq("anniversary")).
+				Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't remove tag",
+				url:         "/admin/remove-tag/all/7/anniversary/do",
+				redirectURL: "/admin/reservations/all/7/show",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/remove-tag/all/q/anniversary/do",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				val:         &url.Values{},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/remove-tag/all/7/do",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminAuditLog", func() {
+		BeforeEach(func() {
+			handler = h.AdminAuditLog
+			method = "GET"
+		})
+
+		It("shows matching entries", func() {
+			mockDB.EXPECT().SearchAuditLog(gomock.Eq(models.AuditSearch{
+				Query:  "smith",
+				Entity: "reservations",
+				From:   time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC),
+			})).Return([]models.AuditEntry{
+				{
+					ID:       1,
+					UserID:   1,
+					Action:   "update",
+					Entity:   "reservations",
+					EntityID: "7",
+					Changes:  map[string][]interface{}{"last_name": {"Smith", "Smyth"}},
+					IP:       "10.0.0.1:5123",
+					Request:  "POST /admin/reservations/all/7",
+					User:     &models.User{FirstName: "Ann", LastName: "Lee"},
+				},
+				{ID: 2, Action: "delete", Entity: "room_restrictions", EntityID: "4",
+					Before: map[string]interface{}{"room_id": float64(1)}},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/audit-log?q=+smith+&entity=reservations&from=2050-01-01",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("Ann Lee"))
+			Expect(body).To(ContainSubstring("last_name: Smith → Smyth"))
+			Expect(body).To(ContainSubstring("POST /admin/reservations/all/7"))
+			Expect(body).To(ContainSubstring("system"))
+			Expect(body).To(ContainSubstring("room_id: 1"))
+		})
+
+		It("bad date", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad end time",
+				url:         "/admin/audit-log?to=tomorrow",
+				redirectURL: "/admin/audit-log",
+			}
+			doall(data)
+		})
+
+		It("error in SearchAuditLog", func() {
+			mockDB.EXPECT().SearchAuditLog(gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get audit log",
+				url:         "/admin/audit-log",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminHistory", func() {
+		BeforeEach(func() {
+			handler = h.AdminHistory
+			method = "GET"
+		})
+
+		It("shows history of a room", func() {
+			mockDB.EXPECT().GetEntityHistory(gomock.Eq("rooms"), gomock.Eq(3)).Return([]models.AuditEntry{
+				{ID: 5, UserID: 2, Action: "insert", Entity: "room_restrictions", EntityID: "9",
+					After: map[string]interface{}{"room_id": float64(3), "restriction_id": float64(2)}},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/history/rooms/3",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("user #2"))
+			Expect(body).To(ContainSubstring("restriction_id: 2"))
+			Expect(body).To(ContainSubstring(`href="/admin/rooms#room-3"`))
+		})
+
+		It("no history", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "no history for taxes",
+				url:         "/admin/history/taxes/3",
+				redirectURL: "/admin/audit-log",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/history/guests/q",
+				redirectURL: "/admin/audit-log",
+			}
+			doall(data)
+		})
+
+		It("error in GetEntityHistory", func() {
+			mockDB.EXPECT().GetEntityHistory(gomock.Eq("reservations"), gomock.Eq(7)).
+				Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get history",
+				url:         "/admin/history/reservations/7",
+				redirectURL: "/admin/audit-log",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/history/reservations",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminTrash", func() {
+		BeforeEach(func() {
+			handler = h.AdminTrash
+			method = "GET"
+		})
+
+		It("lists deleted reservations and blocks", func() {
+			app.TrashRetention = 30 * 24 * time.Hour
+			mockDB.EXPECT().GetDeletedReservations().Return([]models.Reservation{
+				{ID: 7, FirstName: "John", LastName: "Smith", Room: &models.Room{Name: "Generals Quarters"}},
+			}, nil).Times(1)
+			mockDB.EXPECT().GetDeletedRoomRestrictions().Return([]models.RoomRestriction{
+				{ID: 4, Room: &models.Room{Name: "Majors Suite"}, Restriction: &models.Restriction{RestrictionName: "Owner block"}},
+			}, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/trash",
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("John Smith"))
+			Expect(body).To(ContainSubstring("Owner block"))
+			Expect(body).To(ContainSubstring("30 days after deletion"))
+		})
+
+		It("error in GetDeletedReservations", func() {
+			mockDB.EXPECT().GetDeletedReservations().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get trash",
+				url:         "/admin/trash",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetDeletedRoomRestrictions", func() {
+			mockDB.EXPECT().GetDeletedReservations().Return([]models.Reservation{}, nil).Times(1)
+			mockDB.EXPECT().GetDeletedRoomRestrictions().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get trash",
+				url:         "/admin/trash",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostRestoreFromTrash", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostRestoreFromTrash
+			method = "POST"
+		})
+
+		It("restores reservation", func() {
+			mockDB.EXPECT().RestoreReservation(gomock.Eq(7)).Return(nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/trash/reservations/7/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("restores block", func() {
+			mockDB.EXPECT().RestoreRoomRestriction(gomock.Eq(4)).Return(nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/trash/blocks/4/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("room is taken", func() {
+			mockDB.EXPECT().RestoreReservation(gomock.Eq(7)).Return(repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "room is not available on these dates anymore",
+				url:         "/admin/trash/reservations/7/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("error in RestoreRoomRestriction", func() {
+			mockDB.EXPECT().RestoreRoomRestriction(gomock.Eq(4)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't restore block",
+				url:         "/admin/trash/blocks/4/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("wrong kind", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong kind of item",
+				url:         "/admin/trash/rooms/4/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("wrong id", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "wrong id",
+				url:         "/admin/trash/blocks/q/restore",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/trash/blocks/restore",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostPurgeFromTrash", func() {
+		BeforeEach(func() {
+			handler = h.AdminPostPurgeFromTrash
+			method = "POST"
+		})
+
+		It("purges reservation", func() {
+			mockDB.EXPECT().PurgeReservation(gomock.Eq(7)).Return(nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/trash/reservations/7/purge",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("purges block", func() {
+			mockDB.EXPECT().PurgeRoomRestriction(gomock.Eq(4)).Return(nil).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/trash/blocks/4/purge",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+
+		It("error in PurgeReservation", func() {
+			mockDB.EXPECT().PurgeReservation(gomock.Eq(7)).Return(errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't delete reservation",
+				url:         "/admin/trash/reservations/7/purge",
+				redirectURL: "/admin/trash",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminExportReservations", func() {
+		exportQuery := models.ReservationQuery{Tag: "vip", Sort: "arrival", Page: 1, PerPage: 25}
+		exported := models.Reservation{
+			ID:               1,
+			ConfirmationCode: "ABC123",
+			FirstName:        "John",
+			LastName:         "Smith",
+			StartDate:        time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:          time.Date(2023, 7, 3, 0, 0, 0, 0, time.UTC),
+			Room:             &models.Room{Name: "room name"},
+			Charged:          20000,
+			Balance:          5000,
+		}
+
+		BeforeEach(func() {
+			handler = h.AdminExportReservations
+			method = "GET"
+		})
+
+		It("exports the filtered list as csv", func() {
+			mockDB.EXPECT().ExportReservations(gomock.Eq(exportQuery), gomock.Eq(false), gomock.Any()).
+				DoAndReturn(func(query models.ReservationQuery, onlyNew bool, fn func(res models.Reservation) error) error {
+					return fn(exported)
+				}).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/all-reservations/export?tag=vip&format=csv&columns=guest&columns=nights&columns=totals",
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Type")).To(Equal("text/csv; charset=utf-8"))
+			Expect(rr.Header().Get("Content-Disposition")).To(MatchRegexp(`^attachment; filename="reservations-\d{4}-\d{2}-\d{2}\.csv"$`))
+			Expect(rr.Body.String()).To(Equal(
+				"ID,Confirmation code,First name,Last name,Email,Phone,Nights,Charged,Paid,Balance\n" +
+					"1,ABC123,John,Smith,,,2,200.00,150.00,50.00\n"))
+		})
+
+		It("exports new reservations as xlsx", func() {
+			query := exportQuery
+			query.Tag = ""
+			mockDB.EXPECT().ExportReservations(gomock.Eq(query), gomock.Eq(true), gomock.Any()).Return(nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/new-reservations/export?format=xlsx",
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Type")).To(Equal("application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"))
+			Expect(rr.Body.String()).To(HavePrefix("PK"))
+		})
+
+		It("error in ExportReservations", func() {
+			mockDB.EXPECT().ExportReservations(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/all-reservations/export?format=csv",
+			}
+			doall(data)
+		})
+
+		It("unknown format", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown export format",
+				url:         "/admin/all-reservations/export?tag=vip&format=pdf",
+				redirectURL: "/admin/all-reservations?tag=vip",
+			}
+			doall(data)
+		})
+
+		It("bad filters", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad filters",
+				url:         "/admin/new-reservations/export?format=csv&from=now",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
+		It("wrong list", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/old-reservations/export?format=csv",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("wrong url", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/all-reservations/export/csv",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminImport", func() {
+		BeforeEach(func() {
+			handler = h.AdminImport
+			method = "GET"
+		})
+
+		It("shows the upload form", func() {
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/import",
+			}
+			doall(data)
+			Expect(rr.Body.String()).ToNot(ContainSubstring("Map the columns"))
+		})
+
+		It("maps the columns of the uploaded file", func() {
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/import",
+				dataForSession: map[string]interface{}{
+					"import_csv":  "Room,From,To\nGeneral's Quarters,2023-07-01,2023-07-03\n",
+					"import_kind": "blocks",
+					"import_file": "blocks.csv",
+				},
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("Map the columns of blocks.csv"))
+			Expect(rr.Body.String()).To(ContainSubstring(`<option value="0" selected>Room</option>`))
+			Expect(rr.Body.String()).To(ContainSubstring(`<option value="2" selected>To</option>`))
+		})
+
+		It("broken file in session", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't read file",
+				url:         "/admin/import",
+				redirectURL: "/admin/import",
+				dataForSession: map[string]interface{}{
+					"import_csv":  "Room\n\"General",
+					"import_kind": "blocks",
+				},
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostImport", func() {
+		upload := func(kind, fileName, content string) *http.Request {
+			body := new(bytes.Buffer)
+			writer := multipart.NewWriter(body)
+			Expect(writer.WriteField("kind", kind)).To(Succeed())
+			if fileName != "" {
+				part, err := writer.CreateFormFile("file", fileName)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = part.Write([]byte(content))
+				Expect(err).ToNot(HaveOccurred())
+			}
+			Expect(writer.Close()).To(Succeed())
+			req, err := http.NewRequest("POST", "/admin/import", body)
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			ctx, err := getCtx(req, &app)
+			Expect(err).ToNot(HaveOccurred())
+			return req.WithContext(ctx)
+		}
+		send := func(req *http.Request, errorString string) {
+			rr = httptest.NewRecorder()
+			h.AdminPostImport(rr, req)
+			Expect(rr.Code).To(Equal(http.StatusSeeOther))
+			Expect(rr.Header().Get("Location")).To(Equal("/admin/import"))
+			Expect(app.Session.GetString(req.Context(), "error")).To(Equal(errorString))
+		}
+
+		It("keeps the file for mapping", func() {
+			req := upload("reservations", "old.csv", "first name,last name\nJohn,Smith\n")
+			send(req, "")
+			Expect(app.Session.GetString(req.Context(), "import_csv")).To(Equal("first name,last name\nJohn,Smith\n"))
+			Expect(app.Session.GetString(req.Context(), "import_kind")).To(Equal("reservations"))
+			Expect(app.Session.GetString(req.Context(), "import_file")).To(Equal("old.csv"))
+		})
+
+		It("unknown kind", func() {
+			send(upload("guests", "old.csv", "name\nJohn\n"), "unknown kind of rows")
+		})
+
+		It("no file", func() {
+			send(upload("blocks", "", ""), "choose a file")
+		})
+
+		It("empty file", func() {
+			send(upload("blocks", "old.csv", "room,start,end\n"), "file has no rows")
+		})
+
+		It("not a csv", func() {
+			send(upload("blocks", "old.csv", "room\n\"1\n"), "file is not a valid CSV")
+		})
+
+		It("file too big", func() {
+			send(upload("blocks", "old.csv", "room\n"+strings.Repeat("1\n", 2<<20)), "file is too big")
+		})
+
+		It("bad form", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad form",
+				url:         "/admin/import",
+				redirectURL: "/admin/import",
+			}
+			handler = h.AdminPostImport
+			method = "POST"
+			doall(data)
+		})
+	})
+
+	Context("AdminPostImportPreview", func() {
+		rooms := []models.Room{{ID: 1, Name: "General's Quarters"}, {ID: 2, Name: "Major's Suite"}}
+		session := map[string]interface{}{
+			"import_csv": "Room,From,To\n" +
+				"General's Quarters,01.07.2023,03.07.2023\n" +
+				"Major's Suite,01.07.2023,03.07.2023\n" +
+				"Attic,01.07.2023,03.07.2023\n",
+			"import_kind": "blocks",
+		}
+		mapping := url.Values{"map_room": {"0"}, "map_start": {"1"}, "map_end": {"2"}, "date_format": {"02.01.2006"}}
+
+		BeforeEach(func() {
+			handler = h.AdminPostImportPreview
+			method = "POST"
+		})
+
+		It("reports every row", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Eq(time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)),
+				gomock.Eq(time.Date(2023, 7, 3, 0, 0, 0, 0, time.UTC)), gomock.Eq(1)).Return(true, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Eq(2)).Return(false, nil).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusOK,
+				url:            "/admin/import/preview",
+				dataForSession: session,
+			}
+			doall(data)
+			body := rr.Body.String()
+			Expect(body).To(ContainSubstring("1 rows can be imported, 2 rows have errors"))
+			Expect(body).To(ContainSubstring("Room is taken on these dates"))
+			Expect(body).To(ContainSubstring(`Room &#34;Attic&#34; doesn&#39;t exist`))
+			Expect(body).To(ContainSubstring(`<input type="hidden" name="map_end" value="2">`))
+			Expect(body).To(ContainSubstring(`<input type="hidden" name="date_format" value="02.01.2006">`))
+		})
+
+		It("nothing to import", func() {
+			data := testData{
+				val:         &mapping,
+				statusCode:  http.StatusSeeOther,
+				errorString: "nothing to import",
+				url:         "/admin/import/preview",
+				redirectURL: "/admin/import",
+			}
+			doall(data)
+		})
+
+		It("error in GetAllRooms", func() {
+			mockDB.EXPECT().GetAllRooms().Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't check rows",
+				url:            "/admin/import/preview",
+				redirectURL:    "/admin/import",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("error in LookForAvailabilityOfRoom", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(false, errors.New("error text")).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't check rows",
+				url:            "/admin/import/preview",
+				redirectURL:    "/admin/import",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("bad form", func() {
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad form",
+				url:         "/admin/import/preview",
+				redirectURL: "/admin/import",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostImportCommit", func() {
+		rooms := []models.Room{{ID: 1, Name: "General's Quarters", Price: 10000}}
+		session := map[string]interface{}{
+			"import_csv": "first,last,arrival,departure,room,code\n" +
+				"John,Smith,2023-07-01,2023-07-03,General's Quarters,ABC123\n" +
+				"Mary,Jones,2023-07-05,2023-07-06,General's Quarters,\n" +
+				"Bad,Row,2023-07-05,2023-07-01,General's Quarters,\n",
+			"import_kind": "reservations",
+			"user_id":     3,
+		}
+		mapping := url.Values{"map_first_name": {"0"}, "map_last_name": {"1"}, "map_start": {"2"}, "map_end": {"3"},
+			"map_room": {"4"}, "map_confirmation_code": {"5"}, "date_format": {"2006-01-02"}}
+
+		BeforeEach(func() {
+			handler = h.AdminPostImportCommit
+			method = "POST"
+		})
+
+		It("imports valid rows", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Eq(1)).Return(true, nil).Times(2)
+			mockDB.EXPECT().ImportReservations(gomock.Any()).DoAndReturn(func(reservations []models.Reservation) error {
+				Expect(reservations).To(HaveLen(2))
+				Expect(reservations[0].ConfirmationCode).To(Equal("ABC123"))
+				Expect(reservations[0].NightlyRate).To(Equal(10000))
+				Expect(reservations[0].CreatedBy).To(Equal(3))
+				Expect(reservations[1].FirstName).To(Equal("Mary"))
+				Expect(reservations[1].ConfirmationCode).ToNot(BeEmpty())
+				return nil
+			}).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusSeeOther,
+				url:            "/admin/import/commit",
+				redirectURL:    "/admin/all-reservations",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("imports blocks", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Eq(1)).Return(true, nil).Times(1)
+			mockDB.EXPECT().ImportBlocks(gomock.Any()).DoAndReturn(func(blocks []models.RoomRestriction) error {
+				Expect(blocks).To(HaveLen(1))
+				Expect(blocks[0].RestrictionID).To(Equal(models.RestrictionOwnerBlock))
+				return nil
+			}).Times(1)
+			data := testData{
+				val:         &url.Values{"map_room": {"0"}, "map_start": {"1"}, "map_end": {"2"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/import/commit",
+				redirectURL: "/admin/reservation-calendar",
+				dataForSession: map[string]interface{}{
+					"import_csv":  "room,start,end\n1,2023-07-01,2023-07-03\n",
+					"import_kind": "blocks",
+				},
+			}
+			doall(data)
+		})
+
+		It("no valid rows", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			data := testData{
+				val:            &url.Values{},
+				statusCode:     http.StatusSeeOther,
+				errorString:    "no valid rows to import",
+				url:            "/admin/import/commit",
+				redirectURL:    "/admin/import",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("room taken while importing", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).Times(2)
+			mockDB.EXPECT().ImportReservations(gomock.Any()).Return(repository.ErrRoomNotAvailable).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusSeeOther,
+				errorString:    "a room was taken while importing, check the rows again",
+				url:            "/admin/import/commit",
+				redirectURL:    "/admin/import",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("error in ImportReservations", func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().LookForAvailabilityOfRoom(gomock.Any(), gomock.Any(), gomock.Any()).Return(true, nil).Times(2)
+			mockDB.EXPECT().ImportReservations(gomock.Any()).Return(errors.New("error text")).Times(1)
+			data := testData{
+				val:            &mapping,
+				statusCode:     http.StatusSeeOther,
+				errorString:    "can't import rows",
+				url:            "/admin/import/commit",
+				redirectURL:    "/admin/import",
+				dataForSession: session,
+			}
+			doall(data)
+		})
+
+		It("nothing to import", func() {
+			data := testData{
+				val:         &mapping,
+				statusCode:  http.StatusSeeOther,
+				errorString: "nothing to import",
+				url:         "/admin/import/commit",
+				redirectURL: "/admin/import",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminPostBulkReservations", func() {
+		cancellable := []models.Reservation{
+			{ID: 3, RoomID: 1, StartDate: time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+				EndDate: time.Date(2023, 7, 3, 0, 0, 0, 0, time.UTC)},
+			{ID: 5, RoomID: 2, StartDate: time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+				EndDate: time.Date(2023, 7, 3, 0, 0, 0, 0, time.UTC), NightlyRate: 10000,
+				CancellationPolicy: &models.CancellationPolicy{FreeDays: 1, FeeType: models.FeeFirstNight}},
+		}
+		post := func(values url.Values) *http.Request {
+			req, err := http.NewRequest("POST", "/admin/all-reservations/bulk", strings.NewReader(values.Encode()))
+			Expect(err).ToNot(HaveOccurred())
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			ctx, err := getCtx(req, &app)
+			Expect(err).ToNot(HaveOccurred())
+			req = req.WithContext(ctx)
+			rr = httptest.NewRecorder()
+			h.AdminPostBulkReservations(rr, req)
+			Expect(rr.Code).To(Equal(http.StatusSeeOther))
+			return req
+		}
+
+		BeforeEach(func() {
+			handler = h.AdminPostBulkReservations
+			method = "POST"
+		})
+
+		It("marks reservations as processed and comes back to the list", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Eq([]int{3, 5}),
+				gomock.Eq(models.BulkChange{Action: models.BulkProcess})).Return(map[int]error{}, nil).Times(1)
+			req := post(url.Values{"action": {"process"}, "id": {"3", "5"}, "tag": {"vip"}, "page": {"2"}})
+			Expect(rr.Header().Get("Location")).To(Equal("/admin/all-reservations?page=2&tag=vip"))
+			Expect(app.Session.GetString(req.Context(), "flash")).To(Equal("2 of 2 reservations are marked as processed"))
+			Expect(app.Session.GetString(req.Context(), "warning")).To(Equal(""))
+		})
+
+		It("leaves cancelled reservations unprocessed", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Eq([]int{3, 5}),
+				gomock.Eq(models.BulkChange{Action: models.BulkProcess})).
+				Return(map[int]error{5: repository.ErrReservationCancelled}, nil).Times(1)
+			req := post(url.Values{"action": {"process"}, "id": {"3", "5"}})
+			Expect(app.Session.GetString(req.Context(), "flash")).To(Equal("1 of 2 reservations are marked as processed"))
+			Expect(app.Session.GetString(req.Context(), "warning")).To(Equal(
+				"1 failed: #5 reservation is already cancelled"))
+		})
+
+		It("confirms reservations and reports no-shows whose room is taken", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Eq([]int{3, 5}),
+				gomock.Eq(models.BulkChange{Action: models.BulkConfirm})).
+				Return(map[int]error{5: repository.ErrRoomNotAvailable}, nil).Times(1)
+			req := post(url.Values{"action": {"confirm"}, "id": {"3", "5"}})
+			Expect(app.Session.GetString(req.Context(), "flash")).To(Equal("1 of 2 reservations are confirmed"))
+			Expect(app.Session.GetString(req.Context(), "warning")).To(Equal(
+				"1 failed: #5 " + repository.ErrRoomNotAvailable.Error()))
+		})
+
+		It("cancels reservations with their fees and reports failures", func() {
+			mockDB.EXPECT().GetReservationsByIDs(gomock.Eq([]int{3, 5, 9})).Return(cancellable, nil).Times(1)
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Eq([]int{3, 5, 9}), gomock.Eq(models.BulkChange{
+				Action: models.BulkCancel,
+				Fees:   map[int]int{3: 0, 5: 10000},
+			})).Return(map[int]error{3: repository.ErrReservationCancelled, 9: repository.ErrReservationNotFound}, nil).Times(1)
+			mockDB.EXPECT().GetWaitingEntriesForRoom(gomock.Eq(2), gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			req := post(url.Values{"action": {"cancel"}, "id": {"3", "5", "9"}})
+			Expect(rr.Header().Get("Location")).To(Equal("/admin/all-reservations"))
+			Expect(app.Session.GetString(req.Context(), "flash")).To(Equal(
+				"1 of 3 reservations are cancelled, cancellation fees come to $100.00"))
+			Expect(app.Session.GetString(req.Context(), "warning")).To(Equal(
+				"2 failed: #3 reservation is already cancelled; #9 reservation is not found"))
+		})
+
+		It("tags reservations", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Eq([]int{3}),
+				gomock.Eq(models.BulkChange{Action: models.BulkTag, Tag: "late arrival"})).Return(map[int]error{}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{"action": {"tag"}, "id": {"3"}, "new_tag": {" Late  Arrival "}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/new-reservations/bulk",
+				redirectURL: "/admin/new-reservations",
+			}
+			doall(data)
+		})
+
+		It("resends confirmations", func() {
+			mockDB.EXPECT().GetReservationsByIDs(gomock.Eq([]int{1, 2, 3})).Return([]models.Reservation{
+				{ID: 1, Email: "john@smith.com", ConfirmationCode: "ABC123"},
+				{ID: 2},
+				{ID: 3, Email: "mary@jones.com", Status: models.ReservationCancelled},
+			}, nil).Times(1)
+			req := post(url.Values{"action": {"resend"}, "id": {"1", "2", "3"}})
+			msg := <-app.MailChan
+			Expect(msg.To).To(Equal("john@smith.com"))
+			Expect(msg.Subject).To(Equal("Your reservation ABC123"))
+			Expect(app.MailChan).To(BeEmpty())
+			Expect(app.Session.GetString(req.Context(), "flash")).To(Equal("1 of 3 reservations got their confirmation again"))
+			Expect(app.Session.GetString(req.Context(), "warning")).To(Equal(
+				"2 failed: #2 guest has no email; #3 reservation is cancelled"))
+		})
+
+		It("exports the picked reservations", func() {
+			data := testData{
+				val:         &url.Values{"action": {"export"}, "id": {"3", "5"}, "format": {"xlsx"}, "sort": {"name"}, "page": {"2"}},
+				statusCode:  http.StatusSeeOther,
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations/export?format=xlsx&id=3&id=5&sort=name",
+			}
+			doall(data)
+		})
+
+		It("nothing is changed", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Any(), gomock.Any()).
+				Return(map[int]error{4: repository.ErrReservationNotFound}, nil).Times(1)
+			data := testData{
+				val:         &url.Values{"action": {"process"}, "id": {"4"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "nothing is changed: #4 reservation is not found",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("error in BulkUpdateReservations", func() {
+			mockDB.EXPECT().BulkUpdateReservations(gomock.Any(), gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{"action": {"process"}, "id": {"4"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update reservations, nothing is changed",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("error in GetReservationsByIDs", func() {
+			mockDB.EXPECT().GetReservationsByIDs(gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				val:         &url.Values{"action": {"cancel"}, "id": {"4"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't update reservations, nothing is changed",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("bad tag", func() {
+			data := testData{
+				val:         &url.Values{"action": {"tag"}, "id": {"4"}, "new_tag": {"  "}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "tag must be 1 to 64 characters long",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("nothing picked", func() {
+			data := testData{
+				val:         &url.Values{"action": {"process"}, "status": {"cancelled"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "pick reservations first",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations?status=cancelled",
+			}
+			doall(data)
+		})
+
+		It("too many picked", func() {
+			values := url.Values{"action": {"process"}}
+			for id := 1; id <= 201; id++ {
+				values.Add("id", fmt.Sprint(id))
+			}
+			data := testData{
+				val:         &values,
+				statusCode:  http.StatusSeeOther,
+				errorString: "pick at most 200 reservations",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("unknown action", func() {
+			data := testData{
+				val:         &url.Values{"action": {"delete"}, "id": {"4"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown bulk action",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("bad filters", func() {
+			data := testData{
+				val:         &url.Values{"action": {"process"}, "id": {"4"}, "from": {"yesterday"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad filters",
+				url:         "/admin/all-reservations/bulk",
+				redirectURL: "/admin/all-reservations",
+			}
+			doall(data)
+		})
+
+		It("incorrect request url", func() {
+			data := testData{
+				val:         &url.Values{"action": {"process"}, "id": {"4"}},
+				statusCode:  http.StatusSeeOther,
+				errorString: "incorrect request url",
+				url:         "/admin/guests/bulk",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+	Context("AdminReports", func() {
+		from := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 7, 4, 0, 0, 0, 0, time.UTC)
+		rooms := []models.Room{{ID: 1, Name: "General's Quarters"}}
+		booked := []models.Reservation{{RoomID: 1, StartDate: from, EndDate: to, NightlyRate: 10000, Source: models.SourcePhone}}
+		expectReport := func() {
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetReportReservations(gomock.Eq(from), gomock.Eq(to)).Return(booked, nil).Times(1)
+			mockDB.EXPECT().GetRoomClosures(gomock.Eq(from), gomock.Eq(to)).Return(nil, nil).Times(1)
+		}
+
+		BeforeEach(func() {
+			method = "GET"
+		})
+
+		It("shows the report of the period", func() {
+			handler = h.AdminReports
+			expectReport()
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reports?from=2023-07-01&to=2023-07-04",
+			}
+			doall(data)
+			Expect(rr.Body.String()).To(ContainSubstring("<h3>75%</h3>"))
+			Expect(rr.Body.String()).To(ContainSubstring("3 of 4 room nights sold"))
+			Expect(rr.Body.String()).To(ContainSubstring("<td>Phone</td>"))
+		})
+
+		It("shows the current month by default", func() {
+			handler = h.AdminReports
+			day := time.Now()
+			first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetReportReservations(gomock.Eq(first), gomock.Eq(first.AddDate(0, 1, -1))).Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetRoomClosures(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reports",
+			}
+			doall(data)
+		})
+
+		It("sends the report as json", func() {
+			handler = h.AdminReportsData
+			expectReport()
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reports/data?from=2023-07-01&to=2023-07-04",
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Type")).To(Equal("application/json"))
+			var report map[string]interface{}
+			Expect(json.Unmarshal(rr.Body.Bytes(), &report)).To(Succeed())
+			Expect(report["total"]).To(HaveKeyWithValue("occupancy", BeEquivalentTo(7500)))
+			Expect(report["days"]).To(HaveLen(4))
+		})
+
+		It("downloads a table as csv", func() {
+			handler = h.AdminExportReport
+			expectReport()
+			data := testData{
+				statusCode: http.StatusOK,
+				url:        "/admin/reports/export?table=sources&from=2023-07-01&to=2023-07-04",
+			}
+			doall(data)
+			Expect(rr.Header().Get("Content-Type")).To(Equal("text/csv; charset=utf-8"))
+			Expect(rr.Header().Get("Content-Disposition")).To(Equal(
+				`attachment; filename="report-sources-2023-07-01-2023-07-04.csv"`))
+			Expect(rr.Body.String()).To(ContainSubstring("\nphone,1,3,100,300.00\n"))
+		})
+
+		It("unknown table", func() {
+			handler = h.AdminExportReport
+			expectReport()
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "unknown report table",
+				url:         "/admin/reports/export?table=guests&from=2023-07-01&to=2023-07-04",
+				redirectURL: "/admin/reports?from=2023-07-01&to=2023-07-04",
+			}
+			doall(data)
+		})
+
+		It("bad start date", func() {
+			handler = h.AdminReports
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad start date",
+				url:         "/admin/reports?from=yesterday",
+				redirectURL: "/admin/reports",
+			}
+			doall(data)
+		})
+
+		It("bad end date", func() {
+			handler = h.AdminReportsData
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "bad end date",
+				url:         "/admin/reports/data?to=2023-13-01",
+				redirectURL: "/admin/reports",
+			}
+			doall(data)
+		})
+
+		It("period ends before it starts", func() {
+			handler = h.AdminReports
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "period ends before it starts",
+				url:         "/admin/reports?from=2023-07-04&to=2023-07-01",
+				redirectURL: "/admin/reports",
+			}
+			doall(data)
+		})
+
+		It("period is too long", func() {
+			handler = h.AdminReports
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "period can't be longer than 366 days",
+				url:         "/admin/reports?from=2022-01-01&to=2023-01-02",
+				redirectURL: "/admin/reports",
+			}
+			doall(data)
+		})
+
+		It("error in GetReportReservations", func() {
+			handler = h.AdminReports
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetReportReservations(gomock.Any(), gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get report",
+				url:         "/admin/reports?from=2023-07-01&to=2023-07-04",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+
+		It("error in GetRoomClosures", func() {
+			handler = h.AdminExportReport
+			mockDB.EXPECT().GetAllRooms().Return(rooms, nil).Times(1)
+			mockDB.EXPECT().GetReportReservations(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			mockDB.EXPECT().GetRoomClosures(gomock.Any(), gomock.Any()).Return(nil, errors.New("error text")).Times(1)
+			data := testData{
+				statusCode:  http.StatusSeeOther,
+				errorString: "can't get report",
+				url:         "/admin/reports/export?table=rooms&from=2023-07-01&to=2023-07-04",
+				redirectURL: "/admin/dashboard",
+			}
+			doall(data)
+		})
+	})
+
+})
+
+func routes(handler *handlers.Handlers) http.Handler {
+	mux := chi.NewRouter()
+	mux.Use(middleware.Recoverer)
+	mux.Use(middleware.RequestID)
+	mux.Use(SessionLoad)
+
+	fileServer := http.FileServer(http.Dir("./static/"))
+	mux.Handle("/static/*", http.StripPrefix("/static", fileServer))
+
+	mux.Get("/", http.HandlerFunc(handler.Home))
+	mux.Get("/about", http.HandlerFunc(handler.About))
+	mux.Get("/generals-quarters", http.HandlerFunc(handler.GeneralsQuarters))
+	mux.Get("/majors-suite", http.HandlerFunc(handler.MajorsSuite))
+
+	mux.Get("/make-reservation", http.HandlerFunc(handler.MakeReservation))
+	mux.Post("/make-reservation", http.HandlerFunc(handler.PostMakeReservation))
+
+	mux.Get("/search-availability", http.HandlerFunc(handler.SearchAvailability))
+	mux.Post("/search-availability", http.HandlerFunc(handler.PostSearchAvailability))
+	mux.Post("/search-availability-json", http.HandlerFunc(handler.SearchAvailabilityJson))
+	mux.Get("/choose-room/{id}", http.HandlerFunc(handler.ChooseRoom))
+	mux.Get("/book-room", http.HandlerFunc(handler.BookRoom))
+
+	mux.Post("/cart/add", http.HandlerFunc(handler.PostAddToCart))
+	mux.Post("/cart/remove/{index}", http.HandlerFunc(handler.PostRemoveFromCart))
+	mux.Post("/book-split-stay", http.HandlerFunc(handler.PostBookSplitStay))
+
+	mux.Get("/waitlist", http.HandlerFunc(handler.Waitlist))
+	mux.Post("/waitlist", http.HandlerFunc(handler.PostWaitlist))
+
+	mux.Get("/reservation-summary", http.HandlerFunc(handler.ReservationSummary))
+
+	mux.Get("/my-booking", http.HandlerFunc(handler.MyBooking))
+	mux.Post("/my-booking", http.HandlerFunc(handler.PostMyBooking))
+	mux.Get("/my-booking/{id}/invoice", http.HandlerFunc(handler.MyBookingInvoice))
+	mux.Get("/my-booking/{id}/receipt", http.HandlerFunc(handler.MyBookingReceipt))
+	mux.Post("/currency", http.HandlerFunc(handler.PostCurrency))
+
+	mux.Post("/payments/webhook", http.HandlerFunc(handler.PaymentWebhook))
+
+	mux.Get("/contact", http.HandlerFunc(handler.Contact))
 
 	mux.Route("/user", func(r chi.Router) {
 		r.Get("/login", http.HandlerFunc(handler.Login))
@@ -1268,15 +7677,96 @@ func routes(handler *handlers.Handlers) http.Handler {
 		r.Get("/dashboard", http.HandlerFunc(handler.AdminDashboard))
 		r.Get("/new-reservations", http.HandlerFunc(handler.AdminNewReservations))
 		r.Get("/all-reservations", http.HandlerFunc(handler.AdminAllReservations))
+		r.Get("/new-reservations/export", http.HandlerFunc(handler.AdminExportReservations))
+		r.Get("/all-reservations/export", http.HandlerFunc(handler.AdminExportReservations))
+		r.Post("/new-reservations/bulk", http.HandlerFunc(handler.AdminPostBulkReservations))
+		r.Post("/all-reservations/bulk", http.HandlerFunc(handler.AdminPostBulkReservations))
 
 		r.Get("/reservation-calendar", http.HandlerFunc(handler.AdminReservationCalendar))
 		r.Post("/reservation-calendar", http.HandlerFunc(handler.AdminPostReservationCalendar))
 
+		r.Get("/create-reservation", http.HandlerFunc(handler.AdminCreateReservation))
+		r.Post("/create-reservation", http.HandlerFunc(handler.AdminPostCreateReservation))
+
 		r.Get("/reservations/{src}/{id}/show", http.HandlerFunc(handler.AdminSingleReservation))
 		r.Post("/reservations/{src}/{id}/show", http.HandlerFunc(handler.AdminPostSingleReservation))
+		r.Post("/reservations/{src}/{id}/stay", http.HandlerFunc(handler.AdminPostMoveReservation))
+		r.Post("/reservations/{src}/{id}/folio", http.HandlerFunc(handler.AdminPostFolioEntry))
+		r.Post("/reservations/{src}/{id}/add-ons", http.HandlerFunc(handler.AdminPostReservationAddOn))
+		r.Post("/remove-add-on/{src}/{id}/{item}/do", http.HandlerFunc(handler.AdminPostRemoveReservationAddOn))
+		r.Post("/reservations/{src}/{id}/notes", http.HandlerFunc(handler.AdminPostReservationNote))
+		r.Post("/reservations/{src}/{id}/tags", http.HandlerFunc(handler.AdminPostReservationTag))
+		r.Post("/remove-tag/{src}/{id}/{tag}/do", http.HandlerFunc(handler.AdminPostRemoveReservationTag))
+		r.Get("/reservations/{src}/{id}/invoice", http.HandlerFunc(handler.AdminInvoice))
+		r.Get("/reservations/{src}/{id}/receipt", http.HandlerFunc(handler.AdminReceipt))
+		r.Post("/email-invoice/{src}/{id}/do", http.HandlerFunc(handler.AdminPostEmailInvoice))
+		r.Post("/email-receipt/{src}/{id}/do", http.HandlerFunc(handler.AdminPostEmailReceipt))
 
 		r.Get("/process-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminProcessReservation))
-		r.Get("/delete-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminDeleteReservation))
+		r.Post("/delete-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminPostDeleteReservation))
+		r.Post("/cancel-reservation/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCancelReservation))
+		r.Post("/check-in/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCheckIn))
+		r.Post("/check-out/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCheckOut))
+
+		r.Post("/capture-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostCapturePayment))
+		r.Post("/void-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostVoidPayment))
+		r.Post("/refund-payment/{src}/{id}/do", http.HandlerFunc(handler.AdminPostRefundPayment))
+
+		r.Get("/reservation-groups/{id}/show", http.HandlerFunc(handler.AdminReservationGroup))
+		r.Post("/reservation-groups/{id}/show", http.HandlerFunc(handler.AdminPostReservationGroup))
+		r.Get("/process-reservation-group/{id}/do", http.HandlerFunc(handler.AdminProcessReservationGroup))
+		r.Post("/delete-reservation-group/{id}/do", http.HandlerFunc(handler.AdminPostDeleteReservationGroup))
+
+		r.Get("/guests", http.HandlerFunc(handler.AdminGuests))
+		r.Get("/guests/{id}/show", http.HandlerFunc(handler.AdminGuest))
+		r.Post("/guests/{id}/show", http.HandlerFunc(handler.AdminPostGuest))
+		r.Get("/guest-duplicates", http.HandlerFunc(handler.AdminGuestDuplicates))
+		r.Get("/merge-guests/{keep}/{merge}", http.HandlerFunc(handler.AdminMergeGuests))
+		r.Post("/merge-guests/{keep}/{merge}", http.HandlerFunc(handler.AdminPostMergeGuests))
+
+		r.Get("/waitlist", http.HandlerFunc(handler.AdminWaitlist))
+		r.Post("/notify-waitlist-entry/{id}/do", http.HandlerFunc(handler.AdminPostNotifyWaitlistEntry))
+		r.Post("/delete-waitlist-entry/{id}/do", http.HandlerFunc(handler.AdminPostDeleteWaitlistEntry))
+
+		r.Get("/cancellation-policies", http.HandlerFunc(handler.AdminCancellationPolicies))
+		r.Post("/cancellation-policies", http.HandlerFunc(handler.AdminPostCancellationPolicy))
+
+		r.Get("/taxes", http.HandlerFunc(handler.AdminTaxes))
+		r.Post("/taxes", http.HandlerFunc(handler.AdminPostTax))
+		r.Post("/delete-tax/{id}/do", http.HandlerFunc(handler.AdminPostDeleteTax))
+		r.Get("/add-ons", http.HandlerFunc(handler.AdminAddOns))
+		r.Post("/add-ons", http.HandlerFunc(handler.AdminPostAddOn))
+		r.Post("/delete-add-on/{id}/do", http.HandlerFunc(handler.AdminPostDeleteAddOn))
+		r.Get("/currencies", http.HandlerFunc(handler.AdminCurrencies))
+		r.Post("/currencies", http.HandlerFunc(handler.AdminPostCurrency))
+		r.Post("/delete-currency/{id}/do", http.HandlerFunc(handler.AdminPostDeleteCurrency))
+
+		r.Get("/housekeeping", http.HandlerFunc(handler.AdminHousekeeping))
+		r.Post("/housekeeping", http.HandlerFunc(handler.AdminPostHousekeeping))
+		r.Get("/out-of-order", http.HandlerFunc(handler.AdminOutOfOrder))
+		r.Post("/out-of-order", http.HandlerFunc(handler.AdminPostOutOfOrder))
+		r.Post("/delete-out-of-order/{id}/do", http.HandlerFunc(handler.AdminPostDeleteOutOfOrder))
+
+		r.Get("/rooms", http.HandlerFunc(handler.AdminRooms))
+		r.Post("/rooms/{id}", http.HandlerFunc(handler.AdminPostRoom))
+		r.Post("/rate-plans", http.HandlerFunc(handler.AdminPostRatePlan))
+		r.Post("/delete-rate-plan/{id}/do", http.HandlerFunc(handler.AdminPostDeleteRatePlan))
+
+		r.Get("/trash", http.HandlerFunc(handler.AdminTrash))
+		r.Post("/trash/{kind}/{id}/restore", http.HandlerFunc(handler.AdminPostRestoreFromTrash))
+		r.Post("/trash/{kind}/{id}/purge", http.HandlerFunc(handler.AdminPostPurgeFromTrash))
+
+		r.Get("/import", http.HandlerFunc(handler.AdminImport))
+		r.Post("/import", http.HandlerFunc(handler.AdminPostImport))
+		r.Post("/import/preview", http.HandlerFunc(handler.AdminPostImportPreview))
+		r.Post("/import/commit", http.HandlerFunc(handler.AdminPostImportCommit))
+
+		r.Get("/reports", http.HandlerFunc(handler.AdminReports))
+		r.Get("/reports/data", http.HandlerFunc(handler.AdminReportsData))
+		r.Get("/reports/export", http.HandlerFunc(handler.AdminExportReport))
+
+		r.Get("/audit-log", http.HandlerFunc(handler.AdminAuditLog))
+		r.Get("/history/{entity}/{id}", http.HandlerFunc(handler.AdminHistory))
 	})
 	return mux
 }
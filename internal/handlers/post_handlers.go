@@ -2,10 +2,19 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/availability"
 	"github.com/porky256/course-project/internal/forms"
+	"github.com/porky256/course-project/internal/helpers"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/payments"
+	"github.com/porky256/course-project/internal/repository"
+	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -38,50 +47,366 @@ func (h *Handlers) PostMakeReservation(w http.ResponseWriter, r *http.Request) {
 	form.MinLength("first_name", 3)
 	form.IsEmail("email")
 
-	if !form.Valid() {
-		data := make(map[string]interface{})
-		data["reservation"] = reservation
+	reservation.Guests = 1
+	if form.Has("guests") {
+		reservation.Guests, err = strconv.Atoi(form.Get("guests"))
+		if err != nil || reservation.Guests < 1 {
+			form.Errors.Add("guests", "At least one guest is required")
+		}
+	}
 
-		err := h.render.Template(w, r, "make-reservation.page.tmpl", &models.TemplateData{
-			Form: form,
-			Data: data,
-		})
+	if chosen := chosenAddOns(form); len(chosen) > 0 {
+		addOns, err := h.DB.GetAllAddOns()
 		if err != nil {
 			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't get add-ons")
+			http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
+			return
+		}
+		reservation.AddOns = pickAddOns(form, reservation, addOns, chosen)
+	}
+
+	ratePlanID := 0
+	if form.Has("rate_plan_id") {
+		ratePlanID, err = strconv.Atoi(form.Get("rate_plan_id"))
+	}
+	if err != nil || !applyRatePlan(&reservation, ratePlanID) {
+		form.Errors.Add("rate_plan_id", "Unknown rate plan")
+	}
+
+	cart := h.getCart(r)
+	for i := range cart {
+		if !applyRatePlan(&cart[i], cart[i].RatePlanID) {
+			applyRatePlan(&cart[i], 0)
+		}
+	}
+
+	deposit := depositOf(cart...) + depositOf(reservation)
+	if deposit > 0 {
+		form.Required("card_token")
+	}
+
+	reference := ""
+	if form.Valid() && deposit > 0 {
+		reference, err = h.gateway.Authorize(deposit, form.Get("card_token"))
+		if errors.Is(err, payments.ErrDeclined) {
+			form.Errors.Add("card_token", "Card was declined")
+		} else if err != nil {
+			h.app.ErrorLog.Println(err)
+			h.app.Session.Put(r.Context(), "error", "can't process payment")
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return
 		}
+	}
+
+	if !form.Valid() {
+		h.renderMakeReservation(w, r, form, reservation, cart)
+		return
+	}
+
+	if len(cart) > 0 {
+		h.makeGroupReservation(w, r, reservation, cart, reference)
 		return
 	}
+
+	reservation.ConfirmationCode = helpers.NewConfirmationCode()
+	h.bookReservation(w, r, reservation, reference)
+}
+
+// addOnSoldOut sends the guest back to the reservation form when an add-on they picked is sold out
+func (h *Handlers) addOnSoldOut(w http.ResponseWriter, r *http.Request, err error, reference string) {
+	h.app.ErrorLog.Println(err)
+	h.voidDeposit(reference)
+	h.app.Session.Put(r.Context(), "error", "sorry, some of the extras you picked are sold out for your dates")
+	http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
+}
+
+// bookReservation saves reservation and books its room in one go, turning the guest's hold on the room into
+// the booking if they have one. The deposit authorized under reference is captured once the reservation is saved
+func (h *Handlers) bookReservation(w http.ResponseWriter, r *http.Request, reservation models.Reservation,
+	reference string) {
 	h.app.InfoLog.Printf("saving to db reservation: %+v\n", reservation)
-	newID, err := h.DB.InsertReservation(&reservation)
+	newID, err := h.repo(r).InsertReservationWithHold(&reservation)
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.voidDeposit(reference)
+		msg := "sorry, the room is no longer available for your dates"
+		if reservation.HoldID != 0 {
+			msg = "sorry, your hold has expired and the room is no longer available"
+		}
+		h.app.Session.Put(r.Context(), "error", msg)
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAddOnNotAvailable) {
+		h.addOnSoldOut(w, r, err, reference)
+		return
+	}
 	if err != nil {
 		h.app.ErrorLog.Println(err)
+		h.voidDeposit(reference)
 		h.app.Session.Put(r.Context(), "error", "can't insert reservation")
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
 	h.app.InfoLog.Println("new reservation's id is: ", newID)
 
-	rmrs := models.RoomRestriction{
-		StartDate:     reservation.StartDate,
-		EndDate:       reservation.EndDate,
-		RoomID:        reservation.RoomID,
-		ReservationID: newID,
-		RestrictionID: 1,
+	reservation.ID = newID
+	reservation.HoldID = 0
+	saved := []models.Reservation{reservation}
+	h.captureDeposit(r, reference, saved)
+	h.app.Session.Put(r.Context(), "reservation", saved[0])
+	http.Redirect(w, r, "/reservation-summary", http.StatusSeeOther)
+}
+
+// makeGroupReservation saves rooms from the cart together with the current one as a single reservation group,
+// the deposit authorized under reference is captured once the group is saved
+func (h *Handlers) makeGroupReservation(w http.ResponseWriter, r *http.Request, reservation models.Reservation,
+	cart []models.Reservation, reference string) {
+	if cartConflict(cart, reservation) {
+		h.app.ErrorLog.Println("reservation overlaps the cart")
+		h.voidDeposit(reference)
+		h.app.Session.Put(r.Context(), "error", "this room is already in your booking for these dates")
+		http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
+		return
+	}
+
+	group := models.ReservationGroup{
+		ConfirmationCode: helpers.NewConfirmationCode(),
+		FirstName:        reservation.FirstName,
+		LastName:         reservation.LastName,
+		Email:            reservation.Email,
+		Phone:            reservation.Phone,
+	}
+
+	reservations := make([]models.Reservation, 0, len(cart)+1)
+	for _, item := range append(cart, reservation) {
+		item.FirstName = group.FirstName
+		item.LastName = group.LastName
+		item.Email = group.Email
+		item.Phone = group.Phone
+		reservations = append(reservations, item)
+	}
+
+	h.app.InfoLog.Printf("saving to db reservation group: %+v\n", group)
+	groupID, err := h.repo(r).InsertReservationGroup(&group, reservations)
+	if errors.Is(err, repository.ErrRoomNotAvailable) {
+		h.app.ErrorLog.Println(err)
+		h.voidDeposit(reference)
+		h.app.Session.Put(r.Context(), "error", "one of the rooms is no longer available")
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+	if errors.Is(err, repository.ErrAddOnNotAvailable) {
+		h.addOnSoldOut(w, r, err, reference)
+		return
 	}
-	rmrsID, err := h.DB.InsertRoomRestriction(&rmrs)
 	if err != nil {
 		h.app.ErrorLog.Println(err)
-		h.app.Session.Put(r.Context(), "error", "can't insert room restriction")
+		h.voidDeposit(reference)
+		h.app.Session.Put(r.Context(), "error", "can't insert reservation group")
 		http.Redirect(w, r, "/", http.StatusSeeOther)
 		return
 	}
-	rmrs.ID = rmrsID
-	h.app.InfoLog.Printf("room restriction %+v saved to db\n", rmrs)
+	h.app.InfoLog.Println("new reservation group's id is: ", groupID)
+	h.captureDeposit(r, reference, reservations)
 
-	h.app.Session.Put(r.Context(), "reservation", reservation)
+	group.Reservations = reservations
+	h.app.Session.Remove(r.Context(), "cart")
+	h.app.Session.Remove(r.Context(), "reservation")
+	h.app.Session.Put(r.Context(), "reservation_group", group)
 	http.Redirect(w, r, "/reservation-summary", http.StatusSeeOther)
 }
 
+// PostAddToCart handles request to add the chosen room to the booking and pick another one
+func (h *Handlers) PostAddToCart(w http.ResponseWriter, r *http.Request) {
+	reservation, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation)
+	if !ok || reservation.RoomID == 0 {
+		h.app.ErrorLog.Printf("cannot find reservation")
+		h.app.Session.Put(r.Context(), "error", "cannot find reservation")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	if !reservation.EndDate.After(reservation.StartDate) {
+		h.app.ErrorLog.Printf("bad dates in reservation: %s to %s\n", reservation.StartDate, reservation.EndDate)
+		h.app.Session.Put(r.Context(), "error", "departure must be after arrival")
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+
+	cart := h.getCart(r)
+	if cartConflict(cart, reservation) {
+		h.app.ErrorLog.Println("reservation overlaps the cart")
+		h.app.Session.Put(r.Context(), "error", "this room is already in your booking for these dates")
+		http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
+		return
+	}
+
+	cart = append(cart, reservation)
+	h.app.Session.Put(r.Context(), "cart", cart)
+	h.app.Session.Remove(r.Context(), "reservation")
+	h.app.Session.Put(r.Context(), "flash", "room added to your booking, choose the next one")
+	http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+}
+
+// PostRemoveFromCart handles request to remove a room from the booking
+func (h *Handlers) PostRemoveFromCart(w http.ResponseWriter, r *http.Request) {
+	redirectString := "/search-availability"
+	if h.app.Session.Exists(r.Context(), "reservation") {
+		redirectString = "/make-reservation"
+	}
+
+	exploded := strings.Split(r.RequestURI, "/")
+	if len(exploded) != 4 {
+		h.app.ErrorLog.Printf("incorrect request url: %s", r.RequestURI)
+		h.app.Session.Put(r.Context(), "error", "incorrect request url")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+	index, err := strconv.Atoi(exploded[3])
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't find room in your booking")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	cart := h.getCart(r)
+	if index < 0 || index >= len(cart) {
+		h.app.ErrorLog.Printf("no item %d in cart of %d\n", index, len(cart))
+		h.app.Session.Put(r.Context(), "error", "can't find room in your booking")
+		http.Redirect(w, r, redirectString, http.StatusSeeOther)
+		return
+	}
+
+	h.releaseHold(cart[index].HoldID)
+	cart = append(cart[:index], cart[index+1:]...)
+	h.app.Session.Put(r.Context(), "cart", cart)
+	h.app.Session.Put(r.Context(), "flash", "room removed from your booking")
+	http.Redirect(w, r, redirectString, http.StatusSeeOther)
+}
+
+// PostBookSplitStay handles request to book all parts of a suggested split stay at once. The parts must
+// follow each other without gaps and can't start in the past.
+// Every part is held, the last one becomes current reservation and the others go to the cart
+func (h *Handlers) PostBookSplitStay(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	roomIDs, starts, ends := r.Form["room_id"], r.Form["start"], r.Form["end"]
+	if len(roomIDs) == 0 || len(roomIDs) != len(starts) || len(roomIDs) != len(ends) {
+		h.app.ErrorLog.Printf("bad split stay form: %v\n", r.Form)
+		h.app.Session.Put(r.Context(), "error", "bad split stay")
+		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		return
+	}
+
+	cart := h.getCart(r)
+	var segments []models.Reservation
+	releaseSegments := func() {
+		for _, segment := range segments {
+			h.releaseHold(segment.HoldID)
+		}
+	}
+
+	for i := range roomIDs {
+		roomID, err := strconv.Atoi(roomIDs[i])
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "can't find such room")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		startDate, err := time.Parse(h.app.DateLayout, starts[i])
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "bad start time")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		endDate, err := time.Parse(h.app.DateLayout, ends[i])
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "bad end time")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		if !endDate.After(startDate) {
+			h.app.ErrorLog.Printf("bad dates in split stay: %s to %s\n", startDate, endDate)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "departure must be after arrival")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		if startDate.Before(today()) {
+			h.app.ErrorLog.Printf("split stay starts in the past: %s\n", startDate)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "arrival is in the past")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		if len(segments) > 0 && !startDate.Equal(segments[len(segments)-1].EndDate) {
+			h.app.ErrorLog.Printf("split stay has a gap or an overlap at %s\n", startDate)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "parts of the split stay must follow each other")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		room, err := h.DB.GetRoomByID(roomID)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "no such room")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+
+		segment := models.Reservation{
+			StartDate: startDate,
+			EndDate:   endDate,
+			RoomID:    roomID,
+			Room:      room,
+		}
+		if cartConflict(cart, segment) {
+			h.app.ErrorLog.Println("split stay overlaps the cart")
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "this room is already in your booking for these dates")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		segment.HoldID, err = h.DB.HoldRoom(&models.RoomRestriction{
+			StartDate: startDate,
+			EndDate:   endDate,
+			RoomID:    roomID,
+			ExpiresAt: h.holdExpiration(),
+		})
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+			releaseSegments()
+			h.app.Session.Put(r.Context(), "error", "sorry, this split stay is no longer available")
+			http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+			return
+		}
+		segments = append(segments, segment)
+	}
+
+	if prev, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation); ok {
+		h.releaseHold(prev.HoldID)
+	}
+	last := len(segments) - 1
+	h.app.Session.Put(r.Context(), "cart", append(cart, segments[:last]...))
+	h.app.Session.Put(r.Context(), "reservation", segments[last])
+	http.Redirect(w, r, "/make-reservation", http.StatusSeeOther)
+}
+
 // PostSearchAvailability handles the posting of a search availability form
 func (h *Handlers) PostSearchAvailability(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
@@ -117,8 +442,32 @@ func (h *Handlers) PostSearchAvailability(w http.ResponseWriter, r *http.Request
 	}
 	if len(rooms) == 0 {
 		h.app.InfoLog.Printf("no rooms for dates: %s to %s\n", start, end)
-		h.app.Session.Put(r.Context(), "error", "sorry, no available rooms on this dates >:(")
-		http.Redirect(w, r, "/search-availability", http.StatusSeeOther)
+		suggestions, err := h.suggestAlternatives(startDate, endDate)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		}
+		if err != nil || suggestions.Empty() {
+			h.app.Session.Put(r.Context(), "error", "sorry, no available rooms on this dates >:(")
+			http.Redirect(w, r, fmt.Sprintf("/waitlist?start=%s&end=%s", start, end), http.StatusSeeOther)
+			return
+		}
+
+		h.app.Session.Put(r.Context(), "warning", "sorry, no room is free for the whole stay, but there are other options")
+		data := map[string]interface{}{
+			"suggestions": suggestions,
+			"cart":        h.getCart(r),
+		}
+		stringMap := map[string]string{
+			"start": start,
+			"end":   end,
+		}
+		err = h.render.Template(w, r, "search-availability.page.tmpl", &models.TemplateData{
+			Data:      data,
+			StringMap: stringMap,
+		})
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		}
 		return
 	}
 
@@ -126,6 +475,9 @@ func (h *Handlers) PostSearchAvailability(w http.ResponseWriter, r *http.Request
 		"rooms": rooms,
 	}
 
+	if prev, ok := h.app.Session.Get(r.Context(), "reservation").(models.Reservation); ok {
+		h.releaseHold(prev.HoldID)
+	}
 	res := models.Reservation{
 		StartDate: startDate,
 		EndDate:   endDate,
@@ -142,10 +494,11 @@ func (h *Handlers) PostSearchAvailability(w http.ResponseWriter, r *http.Request
 }
 
 type jsonResponse struct {
-	OK        bool   `json:"ok"`
-	StartDate string `json:"start_date"`
-	EndDate   string `json:"end_date"`
-	RoomID    string `json:"room_id"`
+	OK          bool                      `json:"ok"`
+	StartDate   string                    `json:"start_date"`
+	EndDate     string                    `json:"end_date"`
+	RoomID      string                    `json:"room_id"`
+	Suggestions *availability.Suggestions `json:"suggestions,omitempty"`
 }
 
 // SearchAvailabilityJson handles request for availability and sends JSON response
@@ -198,6 +551,14 @@ func (h *Handlers) SearchAvailabilityJson(w http.ResponseWriter, r *http.Request
 		EndDate:   ed,
 		RoomID:    rid,
 	}
+	if !ok {
+		suggestions, err := h.suggestAlternatives(startDate, endDate)
+		if err != nil {
+			h.app.ErrorLog.Println(err)
+		} else if !suggestions.Empty() {
+			req.Suggestions = &suggestions
+		}
+	}
 
 	out, err := json.MarshalIndent(req, "", "\t")
 	if err != nil {
@@ -212,3 +573,186 @@ func (h *Handlers) SearchAvailabilityJson(w http.ResponseWriter, r *http.Request
 		h.app.ErrorLog.Println(err)
 	}
 }
+
+// PostWaitlist handles request to join the waitlist for sold out dates
+func (h *Handlers) PostWaitlist(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		FirstName: r.Form.Get("first_name"),
+		LastName:  r.Form.Get("last_name"),
+		Email:     r.Form.Get("email"),
+		Phone:     r.Form.Get("phone"),
+	}
+
+	form := forms.New(r.PostForm)
+
+	form.Required("first_name", "last_name", "email", "start", "end")
+	form.MinLength("first_name", 3)
+	form.IsEmail("email")
+
+	if form.Has("start") {
+		entry.StartDate, err = time.Parse(h.app.DateLayout, form.Get("start"))
+		if err != nil {
+			form.Errors.Add("start", "This field is not a valid date")
+		}
+	}
+	if form.Has("end") {
+		entry.EndDate, err = time.Parse(h.app.DateLayout, form.Get("end"))
+		if err != nil {
+			form.Errors.Add("end", "This field is not a valid date")
+		} else if !entry.EndDate.After(entry.StartDate) {
+			form.Errors.Add("end", "Departure must be after arrival")
+		}
+	}
+	if form.Has("room_id") {
+		entry.RoomID, err = strconv.Atoi(form.Get("room_id"))
+		if err != nil {
+			form.Errors.Add("room_id", "Unknown room")
+		}
+	}
+
+	if !form.Valid() {
+		h.renderWaitlist(w, r, form)
+		return
+	}
+
+	_, err = h.repo(r).InsertWaitlistEntry(&entry)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't add you to the waitlist")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "flash", "you are on the waitlist, we will email you if a room frees up")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// PostMyBooking handles the posting of the form guests look up their booking with
+func (h *Handlers) PostMyBooking(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	form := forms.New(r.PostForm)
+	form.Required("confirmation_code", "email")
+	form.IsEmail("email")
+	if !form.Valid() {
+		h.renderMyBooking(w, r, form, nil)
+		return
+	}
+
+	code := strings.ToUpper(strings.TrimSpace(form.Get("confirmation_code")))
+	reservations, err := h.DB.GetReservationsByConfirmationCode(code)
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "can't get your booking")
+		http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+		return
+	}
+	if len(reservations) == 0 || !strings.EqualFold(reservations[0].Email, strings.TrimSpace(form.Get("email"))) {
+		form.Errors.Add("confirmation_code", "No booking found for this code and email")
+		h.renderMyBooking(w, r, form, nil)
+		return
+	}
+
+	err = h.app.Session.RenewToken(r.Context())
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+	}
+	h.app.Session.Put(r.Context(), "booking_code", code)
+	http.Redirect(w, r, "/my-booking", http.StatusSeeOther)
+}
+
+// PostCurrency handles the currency selector, prices are shown in the chosen currency and charged in the base one
+func (h *Handlers) PostCurrency(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		h.app.Session.Put(r.Context(), "error", "bad form")
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+
+	back := "/"
+	if referer, err := url.Parse(r.Referer()); err == nil && referer.Path != "" {
+		back = referer.RequestURI()
+	}
+
+	code := r.PostForm.Get("currency")
+	if _, ok := h.app.Currencies.Lookup(code); !ok {
+		h.app.Session.Put(r.Context(), "error", "unknown currency")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	h.app.Session.Put(r.Context(), "currency", code)
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// paymentEventStatuses maps events of the payment gateway to statuses of our payments
+var paymentEventStatuses = map[string]string{
+	payments.EventCaptured: models.PaymentCaptured,
+	payments.EventRefunded: models.PaymentRefunded,
+	payments.EventVoided:   models.PaymentVoided,
+	payments.EventFailed:   models.PaymentFailed,
+}
+
+// PaymentWebhook handles callbacks the payment gateway posts when a payment changes on its side
+func (h *Handlers) PaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		http.Error(w, "can't read body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := h.gateway.ParseEvent(payload, r.Header.Get("X-Signature"))
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		http.Error(w, "bad event", http.StatusBadRequest)
+		return
+	}
+
+	status, ok := paymentEventStatuses[event.Type]
+	if !ok {
+		h.app.InfoLog.Println("ignoring payment event", event.Type)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if status == models.PaymentRefunded && event.Amount <= 0 {
+		h.app.ErrorLog.Println(payments.ErrBadAmount, event.Reference)
+		http.Error(w, "bad event", http.StatusBadRequest)
+		return
+	}
+
+	// the gateway calls without a session, there is no user to record
+	err = h.DB.WithActor(requestActor(r)).UpdatePaymentStatusByReference(event.Reference, status, event.Amount)
+	if errors.Is(err, repository.ErrPaymentNotFound) {
+		h.app.ErrorLog.Println(err, event.Reference)
+		http.Error(w, "unknown payment", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, repository.ErrPaymentTaken) {
+		h.app.ErrorLog.Println(err, event.Reference)
+		http.Error(w, "payment is captured", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		h.app.ErrorLog.Println(err)
+		http.Error(w, "can't update payment", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -1,14 +1,21 @@
 package helpers
 
 import (
+	"crypto/rand"
 	"fmt"
 	"github.com/porky256/course-project/internal/config"
+	"math/big"
 	"net/http"
 	"runtime/debug"
 )
 
 var app *config.AppConfig
 
+const (
+	confirmationCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	confirmationCodeLength   = 8
+)
+
 func NewHelpers(a *config.AppConfig) {
 	app = a
 }
@@ -23,3 +30,17 @@ func ServerError(w http.ResponseWriter, err error) {
 	app.ErrorLog.Println(trace)
 	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 }
+
+// NewConfirmationCode generates random code guests use to refer to their booking
+func NewConfirmationCode() string {
+	code := make([]byte, confirmationCodeLength)
+	max := big.NewInt(int64(len(confirmationCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		code[i] = confirmationCodeAlphabet[n.Int64()]
+	}
+	return string(code)
+}
@@ -0,0 +1,307 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/asaskevich/govalidator"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kinds of rows which can be imported
+const (
+	Reservations = "reservations"
+	Blocks       = "blocks"
+)
+
+// MaxRows is how many rows a file may have, bigger files have to be split
+const MaxRows = 5000
+
+// ErrEmptyFile is returned for a file without rows under its header
+var ErrEmptyFile = errors.New("file has no rows")
+
+// ErrTooManyRows is returned for a file with more than MaxRows rows
+var ErrTooManyRows = fmt.Errorf("file has more than %d rows", MaxRows)
+
+// DateFormat is a way dates may be written in the file
+type DateFormat struct {
+	Layout string
+	Title  string
+}
+
+// DateFormats are the date formats files may use
+var DateFormats = []DateFormat{
+	{"2006-01-02", "YYYY-MM-DD"},
+	{"01/02/2006", "MM/DD/YYYY"},
+	{"02/01/2006", "DD/MM/YYYY"},
+	{"02.01.2006", "DD.MM.YYYY"},
+}
+
+// Field is a value of the imported rows which is read from a column of the file
+type Field struct {
+	Key      string
+	Title    string
+	Required bool
+	// aliases are other headers old systems use for the column
+	aliases []string
+}
+
+// Fields are the fields of every kind of rows in the order they are shown
+var Fields = map[string][]Field{
+	Reservations: {
+		{"first_name", "First name", true, []string{"firstname", "first", "given name"}},
+		{"last_name", "Last name", true, []string{"lastname", "last", "surname", "family name"}},
+		{"email", "Email", false, []string{"e-mail", "mail"}},
+		{"phone", "Phone", false, []string{"telephone", "tel", "mobile"}},
+		{"start", "Arrival", true, []string{"start", "start date", "check in", "check-in", "checkin", "from"}},
+		{"end", "Departure", true, []string{"end", "end date", "check out", "check-out", "checkout", "to"}},
+		{"room", "Room", true, []string{"room name", "room id"}},
+		{"guests", "Guests", false, []string{"adults", "pax", "people"}},
+		{"nightly_rate", "Nightly rate", false, []string{"rate", "price", "price per night"}},
+		{"confirmation_code", "Confirmation code", false, []string{"code", "confirmation", "booking reference", "reference"}},
+		{"source", "Source", false, []string{"channel", "booking source"}},
+	},
+	Blocks: {
+		{"room", "Room", true, []string{"room name", "room id"}},
+		{"start", "Start", true, []string{"start date", "from"}},
+		{"end", "End", true, []string{"end date", "to"}},
+	},
+}
+
+// sources maps the ways old systems name booking sources to ours
+var sources = map[string]string{
+	"":                     "",
+	"website":              "",
+	"web":                  "",
+	"phone":                models.SourcePhone,
+	"walk_in":              models.SourceWalkIn,
+	"walk-in":              models.SourceWalkIn,
+	"walk in":              models.SourceWalkIn,
+	"email":                models.SourceEmail,
+	"ota":                  models.SourceOTA,
+	"online travel agency": models.SourceOTA,
+}
+
+// Mapping tells for every field key which column of the file holds it, unmapped fields are missing
+type Mapping map[string]int
+
+// Row is a row of the file turned into a reservation or a block, rows with errors are not imported
+type Row struct {
+	// Line is the line of the row in the file, the header is line one
+	Line        int
+	Reservation models.Reservation
+	Block       models.RoomRestriction
+	Errors      []string
+}
+
+// Valid tells if the row can be imported
+func (r Row) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Read reads the header and the rows of a CSV file
+func Read(r io.Reader) (header []string, records [][]string, err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err = reader.Read()
+	if err == io.EOF {
+		return nil, nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(records) == MaxRows {
+			return nil, nil, ErrTooManyRows
+		}
+		records = append(records, record)
+	}
+	if len(records) == 0 {
+		return nil, nil, ErrEmptyFile
+	}
+	return header, records, nil
+}
+
+// normalizeHeader lowercases the header and turns underscores into spaces, so "First_Name" matches "first name"
+func normalizeHeader(header string) string {
+	return strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(header, "_", " "))), " ")
+}
+
+// Guess maps the fields of the kind to the columns whose headers name them
+func Guess(kind string, header []string) Mapping {
+	mapping := make(Mapping)
+	for _, field := range Fields[kind] {
+		names := append([]string{normalizeHeader(field.Key), normalizeHeader(field.Title)}, field.aliases...)
+	columns:
+		for i, column := range header {
+			column = normalizeHeader(column)
+			for _, name := range names {
+				if column == name {
+					mapping[field.Key] = i
+					break columns
+				}
+			}
+		}
+	}
+	return mapping
+}
+
+// Parse turns the rows of the file into reservations or blocks of the kind and checks them,
+// rows overlapping an earlier row of the file in the same room are errors too.
+// Rooms are matched by name or by id, dates are read with layout
+func Parse(kind string, records [][]string, mapping Mapping, rooms []models.Room, layout string) []Row {
+	roomByName := make(map[string]*models.Room, len(rooms))
+	for i := range rooms {
+		roomByName[strings.ToLower(strings.TrimSpace(rooms[i].Name))] = &rooms[i]
+		roomByName[strconv.Itoa(rooms[i].ID)] = &rooms[i]
+	}
+	title := make(map[string]string)
+	for _, field := range Fields[kind] {
+		title[field.Key] = field.Title
+	}
+
+	rows := make([]Row, 0, len(records))
+	for i, record := range records {
+		row := Row{Line: i + 2}
+		get := func(key string) string {
+			column, ok := mapping[key]
+			if !ok || column < 0 || column >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[column])
+		}
+		for _, field := range Fields[kind] {
+			if field.Required && get(field.Key) == "" {
+				row.Errors = append(row.Errors, field.Title+" is required")
+			}
+		}
+
+		var start, end time.Time
+		var err error
+		if get("start") != "" {
+			start, err = time.Parse(layout, get("start"))
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s %q is not a date", title["start"], get("start")))
+			}
+		}
+		if get("end") != "" {
+			end, err = time.Parse(layout, get("end"))
+			if err != nil {
+				row.Errors = append(row.Errors, fmt.Sprintf("%s %q is not a date", title["end"], get("end")))
+			}
+		}
+		if !start.IsZero() && !end.IsZero() && !end.After(start) {
+			row.Errors = append(row.Errors, fmt.Sprintf("%s must be after %s", title["end"], strings.ToLower(title["start"])))
+		}
+		room := roomByName[strings.ToLower(get("room"))]
+		if get("room") != "" && room == nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Room %q doesn't exist", get("room")))
+		}
+		roomID := 0
+		if room != nil {
+			roomID = room.ID
+		}
+
+		switch kind {
+		case Reservations:
+			row.Reservation = reservation(get, &row, room)
+			row.Reservation.StartDate, row.Reservation.EndDate, row.Reservation.RoomID = start, end, roomID
+		case Blocks:
+			row.Block = models.RoomRestriction{
+				StartDate:     start,
+				EndDate:       end,
+				RoomID:        roomID,
+				RestrictionID: models.RestrictionOwnerBlock,
+				Room:          room,
+			}
+		}
+		rows = append(rows, row)
+	}
+	markOverlaps(rows)
+	return rows
+}
+
+// reservation reads the guest and the price of a reservation row, adding its errors to the row
+func reservation(get func(key string) string, row *Row, room *models.Room) models.Reservation {
+	res := models.Reservation{
+		FirstName:        get("first_name"),
+		LastName:         get("last_name"),
+		Email:            get("email"),
+		Phone:            get("phone"),
+		ConfirmationCode: strings.ToUpper(get("confirmation_code")),
+		Guests:           1,
+		IsProcessed:      1,
+		Room:             room,
+	}
+	if res.Email != "" && !govalidator.IsEmail(res.Email) {
+		row.Errors = append(row.Errors, fmt.Sprintf("Email %q is not valid", res.Email))
+	}
+	if get("guests") != "" {
+		guests, err := strconv.Atoi(get("guests"))
+		if err != nil || guests < 1 {
+			row.Errors = append(row.Errors, fmt.Sprintf("Guests %q is not a number of guests", get("guests")))
+		}
+		res.Guests = guests
+	}
+	source, ok := sources[strings.ToLower(get("source"))]
+	if !ok {
+		row.Errors = append(row.Errors, fmt.Sprintf("Source %q is unknown", get("source")))
+	}
+	res.Source = source
+	if room != nil {
+		res.NightlyRate = room.Price
+		res.CancellationPolicyID = room.CancellationPolicyID
+	}
+	if get("nightly_rate") != "" {
+		rate, err := pricing.ParseCents(strings.TrimPrefix(get("nightly_rate"), "$"))
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("Nightly rate %q is not an amount", get("nightly_rate")))
+		}
+		res.NightlyRate = rate
+	}
+	return res
+}
+
+// Span returns the room and the dates the row takes
+func (r Row) Span() (roomID int, start, end time.Time) {
+	if r.Block.RoomID != 0 {
+		return r.Block.RoomID, r.Block.StartDate, r.Block.EndDate
+	}
+	return r.Reservation.RoomID, r.Reservation.StartDate, r.Reservation.EndDate
+}
+
+// markOverlaps adds an error to the valid rows which take a room already taken by an earlier valid row
+func markOverlaps(rows []Row) {
+	for i := range rows {
+		if !rows[i].Valid() {
+			continue
+		}
+		room, start, end := rows[i].Span()
+		for j := 0; j < i; j++ {
+			if !rows[j].Valid() {
+				continue
+			}
+			otherRoom, otherStart, otherEnd := rows[j].Span()
+			if room == otherRoom && start.Before(otherEnd) && otherStart.Before(end) {
+				rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("Overlaps line %d", rows[j].Line))
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package importer_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/importer"
+	"github.com/porky256/course-project/internal/models"
+	"strings"
+	"time"
+)
+
+var _ = Describe("Importer", func() {
+	rooms := []models.Room{
+		{ID: 1, Name: "General's Quarters", Price: 10000, CancellationPolicyID: 2},
+		{ID: 2, Name: "Major's Suite", Price: 15000},
+	}
+	date := func(day int) time.Time {
+		return time.Date(2023, 7, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	Context("Read", func() {
+		It("reads the header and the rows", func() {
+			header, records, err := importer.Read(strings.NewReader("\ufeffName, Room\nJohn,1\nMary\n"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header).To(Equal([]string{"Name", "Room"}))
+			Expect(records).To(Equal([][]string{{"John", "1"}, {"Mary"}}))
+		})
+		It("fails on files without rows", func() {
+			_, _, err := importer.Read(strings.NewReader(""))
+			Expect(err).To(Equal(importer.ErrEmptyFile))
+			_, _, err = importer.Read(strings.NewReader("name,room\n"))
+			Expect(err).To(Equal(importer.ErrEmptyFile))
+		})
+		It("fails on too many rows", func() {
+			_, _, err := importer.Read(strings.NewReader("name\n" + strings.Repeat("x\n", importer.MaxRows+1)))
+			Expect(err).To(Equal(importer.ErrTooManyRows))
+		})
+		It("fails on broken files", func() {
+			_, _, err := importer.Read(strings.NewReader("name\n\"John\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("Guess", func() {
+		It("maps headers by key, title and alias", func() {
+			mapping := importer.Guess(importer.Reservations,
+				[]string{"First_Name", "Surname", "E-mail", "Check-in", "Check-out", "Room", "Notes"})
+			Expect(mapping).To(Equal(importer.Mapping{
+				"first_name": 0, "last_name": 1, "email": 2, "start": 3, "end": 4, "room": 5,
+			}))
+		})
+	})
+
+	Context("Parse", func() {
+		mapping := importer.Mapping{"first_name": 0, "last_name": 1, "email": 2, "start": 3, "end": 4, "room": 5,
+			"guests": 6, "nightly_rate": 7, "confirmation_code": 8, "source": 9}
+
+		It("builds reservations", func() {
+			rows := importer.Parse(importer.Reservations, [][]string{
+				{"John", "Smith", "john@example.com", "07/01/2023", "07/03/2023", "general's quarters", "2", "", "abc123", "Walk-in"},
+				{"Mary", "Jones", "", "07/02/2023", "07/04/2023", "2", "", "$120.50", "", ""},
+			}, mapping, rooms, "01/02/2006")
+			Expect(rows).To(HaveLen(2))
+			Expect(rows[0].Valid()).To(BeTrue())
+			Expect(rows[0].Line).To(Equal(2))
+			res := rows[0].Reservation
+			Expect(res.FirstName).To(Equal("John"))
+			Expect(res.StartDate).To(Equal(date(1)))
+			Expect(res.EndDate).To(Equal(date(3)))
+			Expect(res.RoomID).To(Equal(1))
+			Expect(res.Guests).To(Equal(2))
+			Expect(res.NightlyRate).To(Equal(10000))
+			Expect(res.CancellationPolicyID).To(Equal(2))
+			Expect(res.ConfirmationCode).To(Equal("ABC123"))
+			Expect(res.Source).To(Equal(models.SourceWalkIn))
+			Expect(res.IsProcessed).To(Equal(1))
+			Expect(rows[1].Valid()).To(BeTrue())
+			Expect(rows[1].Reservation.RoomID).To(Equal(2))
+			Expect(rows[1].Reservation.NightlyRate).To(Equal(12050))
+			Expect(rows[1].Reservation.Guests).To(Equal(1))
+		})
+
+		It("reports every error of a row", func() {
+			rows := importer.Parse(importer.Reservations, [][]string{
+				{"", "Smith", "not an email", "2023-07-05", "2023-07-01", "Attic", "none", "cheap", "", "fax"},
+				{"John", "Smith", "", "July 1", "", "1"},
+			}, mapping, rooms, "2006-01-02")
+			Expect(rows[0].Errors).To(Equal([]string{
+				"First name is required",
+				"Departure must be after arrival",
+				`Room "Attic" doesn't exist`,
+				`Email "not an email" is not valid`,
+				`Guests "none" is not a number of guests`,
+				`Source "fax" is unknown`,
+				`Nightly rate "cheap" is not an amount`,
+			}))
+			Expect(rows[1].Errors).To(Equal([]string{
+				"Departure is required",
+				`Arrival "July 1" is not a date`,
+			}))
+		})
+
+		It("builds blocks and finds overlaps in the file", func() {
+			rows := importer.Parse(importer.Blocks, [][]string{
+				{"1", "2023-07-01", "2023-07-05"},
+				{"1", "2023-07-04", "2023-07-06"},
+				{"2", "2023-07-04", "2023-07-06"},
+				{"1", "2023-07-05", "2023-07-07"},
+			}, importer.Mapping{"room": 0, "start": 1, "end": 2}, rooms, "2006-01-02")
+			Expect(rows[0].Valid()).To(BeTrue())
+			Expect(rows[0].Block).To(Equal(models.RoomRestriction{
+				StartDate:     date(1),
+				EndDate:       date(5),
+				RoomID:        1,
+				RestrictionID: models.RestrictionOwnerBlock,
+				Room:          &rooms[0],
+			}))
+			Expect(rows[1].Errors).To(Equal([]string{"Overlaps line 2"}))
+			Expect(rows[2].Valid()).To(BeTrue())
+			Expect(rows[3].Valid()).To(BeTrue())
+			room, start, end := rows[3].Span()
+			Expect([]interface{}{room, start, end}).To(Equal([]interface{}{1, date(5), date(7)}))
+		})
+	})
+})
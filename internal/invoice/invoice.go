@@ -0,0 +1,226 @@
+package invoice
+
+import (
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"strings"
+)
+
+// dateLayout is how dates are printed on documents
+const dateLayout = "Jan 2, 2006"
+
+// Line is a row of a document, amount is in cents
+type Line struct {
+	Description string
+	Amount      int
+}
+
+// Summary is the folio of a reservation grouped the way documents print it, amounts are in cents.
+// Included are taxes which are part of the room rate, they are not added to the total
+type Summary struct {
+	Charges    []Line
+	Taxes      []Line
+	Included   []Line
+	Payments   []Line
+	Subtotal   int
+	Total      int
+	Paid       int
+	BalanceDue int
+}
+
+// Summarize groups folio entries of the reservation into charges, taxes and payments. The room charge
+// made at booking is itemized night by night
+func Summarize(res models.Reservation) Summary {
+	var summary Summary
+	itemized := false
+	for _, entry := range res.FolioEntries {
+		line := Line{Description: entry.Description, Amount: entry.Amount}
+		switch entry.EntryType {
+		case models.FolioPayment, models.FolioRefund:
+			summary.Payments = append(summary.Payments, line)
+			summary.Paid -= entry.Amount
+		case models.FolioTax:
+			summary.Taxes = append(summary.Taxes, line)
+			summary.Total += entry.Amount
+		default:
+			if !itemized && isRoomCharge(res, entry) {
+				summary.Charges = append(summary.Charges, nights(res)...)
+				itemized = true
+			} else {
+				summary.Charges = append(summary.Charges, line)
+			}
+			summary.Subtotal += entry.Amount
+			summary.Total += entry.Amount
+		}
+	}
+	for _, tax := range res.Taxes {
+		if tax.Inclusive {
+			summary.Included = append(summary.Included, Line{Description: tax.Name, Amount: tax.Amount})
+		}
+	}
+	summary.BalanceDue = summary.Total - summary.Paid
+	return summary
+}
+
+// isRoomCharge tells if the entry is the charge of the whole stay posted at booking
+func isRoomCharge(res models.Reservation, entry models.FolioEntry) bool {
+	return entry.EntryType == models.FolioRoom && res.NightlyRate > 0 && entry.Amount == pricing.StayTotal(res)
+}
+
+func nights(res models.Reservation) []Line {
+	room := "Room"
+	if res.Room != nil {
+		room = res.Room.Name
+	}
+	lines := make([]Line, 0, pricing.Nights(res.StartDate, res.EndDate))
+	for night := res.StartDate; night.Before(res.EndDate); night = night.AddDate(0, 0, 1) {
+		lines = append(lines, Line{
+			Description: fmt.Sprintf("%s, night of %s", room, night.Format(dateLayout)),
+			Amount:      res.NightlyRate,
+		})
+	}
+	return lines
+}
+
+// Filename is the name the document is downloaded and attached as
+func Filename(inv models.Invoice) string {
+	return inv.Number + ".pdf"
+}
+
+// Build renders the invoice or the receipt of the reservation as PDF. An invoice lists everything charged,
+// a receipt lists what was paid, both end with the balance due
+func Build(property models.Property, inv models.Invoice, res models.Reservation) []byte {
+	l := &layout{doc: newDocument(), y: marginTop}
+	summary := Summarize(res)
+
+	title := "INVOICE"
+	if inv.Kind == models.DocumentReceipt {
+		title = "RECEIPT"
+	}
+	l.doc.text(marginLeft, l.y-4, 18, bold, property.Name)
+	l.doc.textRight(marginRight, l.y-4, 18, bold, title)
+	l.advance(24)
+	details := append(append([]string{}, property.Address...), property.Phone, property.Email)
+	numbers := []string{"No. " + inv.Number, "Date: " + inv.CreatedAt.Format(dateLayout)}
+	for i := 0; i < len(details) || i < len(numbers); i++ {
+		if i < len(details) {
+			l.doc.text(marginLeft, l.y, 10, regular, details[i])
+		}
+		if i < len(numbers) {
+			l.doc.textRight(marginRight, l.y, 10, regular, numbers[i])
+		}
+		l.advance(14)
+	}
+	l.advance(16)
+
+	l.heading("Guest")
+	l.pair("Name", res.FirstName+" "+res.LastName)
+	l.pair("Email", res.Email)
+	if res.Phone != "" {
+		l.pair("Phone", res.Phone)
+	}
+	l.advance(10)
+
+	l.heading("Stay")
+	l.pair("Confirmation code", res.ConfirmationCode)
+	if res.Room != nil {
+		l.pair("Room", res.Room.Name)
+	}
+	l.pair("Arrival", res.StartDate.Format(dateLayout))
+	l.pair("Departure", res.EndDate.Format(dateLayout))
+	l.pair("Nights", fmt.Sprint(pricing.Nights(res.StartDate, res.EndDate)))
+	if res.Status == models.ReservationCancelled {
+		l.pair("Status", "Cancelled on "+res.CancelledAt.Format(dateLayout))
+	}
+	l.advance(10)
+
+	if inv.Kind == models.DocumentReceipt {
+		l.heading("Payments received")
+		l.lines(summary.Payments)
+		l.rule()
+		l.total("Total charges", summary.Total, regular)
+		l.total("Total paid", summary.Paid, regular)
+	} else {
+		l.heading("Charges")
+		l.lines(summary.Charges)
+		l.rule()
+		l.total("Subtotal", summary.Subtotal, regular)
+		l.lines(summary.Taxes)
+		l.total("Total", summary.Total, bold)
+		for _, line := range summary.Included {
+			l.total("incl. "+line.Description, line.Amount, regular)
+		}
+		if len(summary.Payments) > 0 {
+			l.advance(10)
+			l.heading("Payments")
+			l.lines(summary.Payments)
+			l.rule()
+		}
+	}
+	l.total("Balance due", summary.BalanceDue, bold)
+
+	return l.doc.bytes()
+}
+
+// layout moves down the page as rows are written and starts a new page when the current one is full
+type layout struct {
+	doc *document
+	y   float64
+}
+
+func (l *layout) advance(height float64) {
+	l.y -= height
+	if l.y < marginBottom {
+		l.doc.addPage()
+		l.y = marginTop
+	}
+}
+
+func (l *layout) heading(s string) {
+	l.doc.text(marginLeft, l.y, 12, bold, s)
+	l.advance(16)
+}
+
+func (l *layout) pair(name, value string) {
+	l.doc.text(marginLeft, l.y, 10, regular, name)
+	l.doc.text(marginLeft+120, l.y, 10, regular, value)
+	l.advance(14)
+}
+
+func (l *layout) lines(lines []Line) {
+	if len(lines) == 0 {
+		l.doc.text(marginLeft, l.y, 10, regular, "None")
+		l.advance(14)
+	}
+	for _, line := range lines {
+		l.doc.text(marginLeft, l.y, 10, regular, truncate(line.Description, 80))
+		l.doc.textRight(marginRight, l.y, 10, regular, formatPrice(line.Amount))
+		l.advance(14)
+	}
+}
+
+func (l *layout) total(name string, amount int, font string) {
+	l.doc.textRight(marginRight-100, l.y, 10, font, name)
+	l.doc.textRight(marginRight, l.y, 10, font, formatPrice(amount))
+	l.advance(14)
+}
+
+func (l *layout) rule() {
+	l.doc.line(marginLeft, l.y+10, marginRight, l.y+10)
+	l.advance(4)
+}
+
+func formatPrice(cents int) string {
+	if cents < 0 {
+		return "-$" + pricing.FormatCents(-cents)
+	}
+	return "$" + pricing.FormatCents(cents)
+}
+
+func truncate(s string, length int) string {
+	if len([]rune(s)) <= length {
+		return s
+	}
+	return strings.TrimSpace(string([]rune(s)[:length-3])) + "..."
+}
@@ -0,0 +1,138 @@
+package invoice_test
+
+import (
+	"bytes"
+	"fmt"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/invoice"
+	"github.com/porky256/course-project/internal/models"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var property = models.Property{
+	Name:    "Fort Smythe Bed & Breakfast",
+	Address: []string{"100 Rocky Road", "Northbrook, Ontario"},
+	Phone:   "(555) 555-5555",
+	Email:   "info@fortsmythe.com",
+}
+
+func reservation() models.Reservation {
+	return models.Reservation{
+		ID:               7,
+		FirstName:        "Jane",
+		LastName:         "Doe (Smith)",
+		Email:            "jane@example.com",
+		ConfirmationCode: "ABC123",
+		StartDate:        time.Date(2050, 1, 20, 0, 0, 0, 0, time.UTC),
+		EndDate:          time.Date(2050, 1, 23, 0, 0, 0, 0, time.UTC),
+		NightlyRate:      10000,
+		Room:             &models.Room{Name: "General's Quarters"},
+		FolioEntries: []models.FolioEntry{
+			{EntryType: models.FolioRoom, Description: "3 night(s) at 100.00", Amount: 30000},
+			{EntryType: models.FolioTax, Description: "City tax", Amount: 1500},
+			{EntryType: models.FolioExtra, Description: "Breakfast", Amount: 2500},
+			{EntryType: models.FolioPayment, Description: "Deposit fake_1", Amount: -10000},
+			{EntryType: models.FolioRefund, Description: "Refund fake_1", Amount: 1000},
+		},
+		Taxes: []models.ReservationTax{
+			{Name: "City tax", Amount: 1500},
+			{Name: "VAT", Amount: 3451, Inclusive: true},
+		},
+	}
+}
+
+// checkXref makes sure every offset of the cross-reference table points at the object it lists
+func checkXref(pdf []byte) {
+	match := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	Expect(match).ToNot(BeNil())
+	xref, err := strconv.Atoi(string(match[1]))
+	Expect(err).ToNot(HaveOccurred())
+	Expect(bytes.HasPrefix(pdf[xref:], []byte("xref\n"))).To(BeTrue())
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	Expect(offsets).ToNot(BeEmpty())
+	for i, offset := range offsets {
+		at, err := strconv.Atoi(string(offset[1]))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bytes.HasPrefix(pdf[at:], []byte(fmt.Sprintf("%d 0 obj\n", i+1)))).To(BeTrue())
+	}
+}
+
+var _ = Describe("Invoice", func() {
+	It("itemizes nights and groups the folio", func() {
+		summary := invoice.Summarize(reservation())
+		Expect(summary.Charges).To(Equal([]invoice.Line{
+			{Description: "General's Quarters, night of Jan 20, 2050", Amount: 10000},
+			{Description: "General's Quarters, night of Jan 21, 2050", Amount: 10000},
+			{Description: "General's Quarters, night of Jan 22, 2050", Amount: 10000},
+			{Description: "Breakfast", Amount: 2500},
+		}))
+		Expect(summary.Taxes).To(HaveLen(1))
+		Expect(summary.Included).To(Equal([]invoice.Line{{Description: "VAT", Amount: 3451}}))
+		Expect(summary.Payments).To(HaveLen(2))
+		Expect(summary.Subtotal).To(Equal(32500))
+		Expect(summary.Total).To(Equal(34000))
+		Expect(summary.Paid).To(Equal(9000))
+		Expect(summary.BalanceDue).To(Equal(25000))
+	})
+
+	It("keeps room entries which are not the booked stay", func() {
+		res := reservation()
+		res.FolioEntries = append(res.FolioEntries,
+			models.FolioEntry{EntryType: models.FolioRoom, Description: "Room nights cancelled", Amount: -30000})
+		summary := invoice.Summarize(res)
+		Expect(summary.Charges).To(HaveLen(5))
+		Expect(summary.Charges[4].Description).To(Equal("Room nights cancelled"))
+		Expect(summary.BalanceDue).To(Equal(-5000))
+	})
+
+	It("builds a valid invoice", func() {
+		inv := models.Invoice{Kind: models.DocumentInvoice, Number: "INV-000042",
+			CreatedAt: time.Date(2050, 1, 23, 10, 0, 0, 0, time.UTC)}
+		pdf := invoice.Build(property, inv, reservation())
+		Expect(bytes.HasPrefix(pdf, []byte("%PDF-1.4\n"))).To(BeTrue())
+		checkXref(pdf)
+		Expect(string(pdf)).To(ContainSubstring("(INVOICE)"))
+		Expect(string(pdf)).To(ContainSubstring("(No. INV-000042)"))
+		Expect(string(pdf)).To(ContainSubstring("(Date: Jan 23, 2050)"))
+		Expect(string(pdf)).To(ContainSubstring(`(Jane Doe \(Smith\))`))
+		Expect(string(pdf)).To(ContainSubstring("(General's Quarters, night of Jan 21, 2050)"))
+		Expect(string(pdf)).To(ContainSubstring("(City tax)"))
+		Expect(string(pdf)).To(ContainSubstring("(incl. VAT)"))
+		Expect(string(pdf)).To(ContainSubstring("($34.51)"))
+		Expect(string(pdf)).To(ContainSubstring("($340.00)"))
+		Expect(string(pdf)).To(ContainSubstring("(-$100.00)"))
+		Expect(string(pdf)).To(ContainSubstring("($250.00)"))
+	})
+
+	It("builds a receipt of payments", func() {
+		inv := models.Invoice{Kind: models.DocumentReceipt, Number: "RCT-000043"}
+		pdf := invoice.Build(property, inv, reservation())
+		checkXref(pdf)
+		Expect(string(pdf)).To(ContainSubstring("(RECEIPT)"))
+		Expect(string(pdf)).To(ContainSubstring("(Payments received)"))
+		Expect(string(pdf)).To(ContainSubstring("(Total paid)"))
+		Expect(string(pdf)).To(ContainSubstring("($90.00)"))
+		Expect(string(pdf)).ToNot(ContainSubstring("night of"))
+	})
+
+	It("encodes accents and continues on new pages", func() {
+		res := reservation()
+		res.FirstName = "Zoë"
+		for i := 0; i < 80; i++ {
+			res.FolioEntries = append(res.FolioEntries,
+				models.FolioEntry{EntryType: models.FolioExtra, Description: "Minibar", Amount: 500})
+		}
+		pdf := invoice.Build(property, models.Invoice{Kind: models.DocumentInvoice, Number: "INV-000044"}, res)
+		checkXref(pdf)
+		Expect(string(pdf)).To(ContainSubstring(`(Zo\353 Doe`))
+		Expect(string(pdf)).To(MatchRegexp(`/Kids \[\d+ 0 R \d+ 0 R`))
+	})
+
+	It("names files by number", func() {
+		Expect(invoice.Filename(models.Invoice{Number: "INV-000042"})).To(Equal("INV-000042.pdf"))
+	})
+})
@@ -0,0 +1,242 @@
+package listing
+
+import (
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPerPage is how many reservations a page shows unless the URL asks for another page size
+const DefaultPerPage = 25
+
+// PerPageOptions are the page sizes the lists offer, bigger ones in the URL are capped to the last one
+var PerPageOptions = []int{25, 50, 100, 200}
+
+// DefaultSort is the column lists are sorted by unless the URL asks for another one
+const DefaultSort = "arrival"
+
+// SortKeys are the columns the lists can be sorted by
+var SortKeys = []string{"id", "name", "room", "arrival", "departure", "code", "status", "payment", "source", "balance", "created"}
+
+// Statuses are the reservation statuses the lists can be filtered by
+var Statuses = []string{models.ReservationConfirmed, models.ReservationCancelled, models.ReservationNoShow}
+
+// Sources are the booking sources the lists can be filtered by
+var Sources = []string{models.SourceWebsite, models.SourcePhone, models.SourceWalkIn, models.SourceEmail, models.SourceOTA}
+
+// pagerWindow is how many pages the pager links on each side of the current page
+const pagerWindow = 2
+
+// Parse reads the filters, sort and page of a list from the URL query, dates are in layout.
+// Repeated id parameters pick reservations. Unknown sort columns, statuses and sources and bad numbers fall back to their defaults
+func Parse(values url.Values, layout string) (models.ReservationQuery, error) {
+	query := models.ReservationQuery{
+		Search:  strings.TrimSpace(values.Get("q")),
+		Tag:     strings.TrimSpace(values.Get("tag")),
+		Sort:    DefaultSort,
+		Desc:    values.Get("order") == "desc",
+		Page:    1,
+		PerPage: DefaultPerPage,
+	}
+	var err error
+	if values.Get("from") != "" {
+		query.From, err = time.Parse(layout, values.Get("from"))
+		if err != nil {
+			return query, fmt.Errorf("bad start date: %w", err)
+		}
+	}
+	if values.Get("to") != "" {
+		query.To, err = time.Parse(layout, values.Get("to"))
+		if err != nil {
+			return query, fmt.Errorf("bad end date: %w", err)
+		}
+	}
+	for _, value := range values["id"] {
+		if id, err := strconv.Atoi(value); err == nil && id > 0 {
+			query.IDs = append(query.IDs, id)
+		}
+	}
+	if room, err := strconv.Atoi(values.Get("room")); err == nil && room > 0 {
+		query.RoomID = room
+	}
+	if contains(Statuses, values.Get("status")) {
+		query.Status = values.Get("status")
+	}
+	if contains(Sources, values.Get("source")) {
+		query.Source = values.Get("source")
+	}
+	if contains(SortKeys, values.Get("sort")) {
+		query.Sort = values.Get("sort")
+	}
+	if page, err := strconv.Atoi(values.Get("page")); err == nil && page > 1 {
+		query.Page = page
+	}
+	if perPage, err := strconv.Atoi(values.Get("per_page")); err == nil && perPage > 0 {
+		query.PerPage = perPage
+		if largest := PerPageOptions[len(PerPageOptions)-1]; perPage > largest {
+			query.PerPage = largest
+		}
+	}
+	return query, nil
+}
+
+// contains tells if value is one of list
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Values is the URL query Parse reads query back from. Defaults are left out to keep bookmarks short
+func Values(query models.ReservationQuery, layout string) url.Values {
+	values := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	for _, id := range query.IDs {
+		values.Add("id", strconv.Itoa(id))
+	}
+	set("q", query.Search)
+	if !query.From.IsZero() {
+		set("from", query.From.Format(layout))
+	}
+	if !query.To.IsZero() {
+		set("to", query.To.Format(layout))
+	}
+	if query.RoomID != 0 {
+		set("room", strconv.Itoa(query.RoomID))
+	}
+	set("status", query.Status)
+	set("source", query.Source)
+	set("tag", query.Tag)
+	if query.Sort != DefaultSort {
+		set("sort", query.Sort)
+	}
+	if query.Desc {
+		set("order", "desc")
+	}
+	if query.Page > 1 {
+		set("page", strconv.Itoa(query.Page))
+	}
+	if query.PerPage != DefaultPerPage {
+		set("per_page", strconv.Itoa(query.PerPage))
+	}
+	return values
+}
+
+// Page is a link of the pager, gaps stand for the pages which aren't linked
+type Page struct {
+	Number  int
+	URL     string
+	Current bool
+	Gap     bool
+}
+
+// List is a page of a filtered list. Its links keep the filters, so every view can be bookmarked
+type List struct {
+	Path   string
+	Layout string
+	Query  models.ReservationQuery
+	// Total is how many rows match the filters on all pages
+	Total int
+}
+
+// URL links the list showing query
+func (l List) URL(query models.ReservationQuery) string {
+	encoded := Values(query, l.Layout).Encode()
+	if encoded == "" {
+		return l.Path
+	}
+	return l.Path + "?" + encoded
+}
+
+// Self links the list as it is shown
+func (l List) Self() string {
+	return l.URL(l.Query)
+}
+
+// Params are the URL query of the list as it is shown, for forms coming back to it
+func (l List) Params() url.Values {
+	return Values(l.Query, l.Layout)
+}
+
+// Filters are the URL query of the list without its page, for forms acting on the whole filtered set
+func (l List) Filters() url.Values {
+	values := l.Params()
+	values.Del("page")
+	values.Del("per_page")
+	return values
+}
+
+// SortURL links the list sorted by the column. The sorted column flips its order,
+// and sorting goes back to the first page
+func (l List) SortURL(key string) string {
+	query := l.Query
+	query.Desc = query.Sort == key && !query.Desc
+	query.Sort = key
+	query.Page = 1
+	return l.URL(query)
+}
+
+// SortMark is the arrow showing the order of the sorted column, other columns have none
+func (l List) SortMark(key string) string {
+	if l.Query.Sort != key {
+		return ""
+	}
+	if l.Query.Desc {
+		return "▼"
+	}
+	return "▲"
+}
+
+// PageCount is how many pages the list has, an empty list has one
+func (l List) PageCount() int {
+	if l.Query.PerPage <= 0 || l.Total == 0 {
+		return 1
+	}
+	return (l.Total + l.Query.PerPage - 1) / l.Query.PerPage
+}
+
+// First is the position of the first row shown on the page, counting from one
+func (l List) First() int {
+	if l.Total == 0 {
+		return 0
+	}
+	return (l.Query.Page-1)*l.Query.PerPage + 1
+}
+
+// Last is the position of the last row shown on the page
+func (l List) Last() int {
+	last := l.Query.Page * l.Query.PerPage
+	if last > l.Total {
+		return l.Total
+	}
+	return last
+}
+
+// Pages links the first and the last page and the pages around the current one
+func (l List) Pages() []Page {
+	count := l.PageCount()
+	pages := make([]Page, 0)
+	for number := 1; number <= count; number++ {
+		near := number >= l.Query.Page-pagerWindow && number <= l.Query.Page+pagerWindow
+		if number != 1 && number != count && !near {
+			if !pages[len(pages)-1].Gap {
+				pages = append(pages, Page{Gap: true})
+			}
+			continue
+		}
+		query := l.Query
+		query.Page = number
+		pages = append(pages, Page{Number: number, URL: l.URL(query), Current: number == l.Query.Page})
+	}
+	return pages
+}
@@ -0,0 +1,13 @@
+package listing_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestListing(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Listing Suite")
+}
@@ -0,0 +1,116 @@
+package listing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/listing"
+	"github.com/porky256/course-project/internal/models"
+	"net/url"
+	"time"
+)
+
+const layout = "2006-01-02"
+
+var _ = Describe("Listing", func() {
+	Context("Parse", func() {
+		It("reads defaults from an empty query", func() {
+			query, err := listing.Parse(url.Values{}, layout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal(models.ReservationQuery{
+				Sort:    listing.DefaultSort,
+				Page:    1,
+				PerPage: listing.DefaultPerPage,
+			}))
+		})
+		It("reads every filter", func() {
+			values, _ := url.ParseQuery("q=+john+&from=2023-07-01&to=2023-07-31&room=2&status=cancelled" +
+				"&source=phone&tag=vip&sort=name&order=desc&page=3&per_page=50")
+			query, err := listing.Parse(values, layout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query).To(Equal(models.ReservationQuery{
+				Search:  "john",
+				From:    time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC),
+				To:      time.Date(2023, 7, 31, 0, 0, 0, 0, time.UTC),
+				RoomID:  2,
+				Status:  models.ReservationCancelled,
+				Source:  models.SourcePhone,
+				Tag:     "vip",
+				Sort:    "name",
+				Desc:    true,
+				Page:    3,
+				PerPage: 50,
+			}))
+		})
+		It("drops unknown values", func() {
+			values, _ := url.ParseQuery("room=x&status=lost&source=fax&sort=password&page=-1&per_page=100000")
+			query, err := listing.Parse(values, layout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query.RoomID).To(Equal(0))
+			Expect(query.Status).To(Equal(""))
+			Expect(query.Source).To(Equal(""))
+			Expect(query.Sort).To(Equal(listing.DefaultSort))
+			Expect(query.Page).To(Equal(1))
+			Expect(query.PerPage).To(Equal(200))
+		})
+		It("reads picked reservations", func() {
+			values, _ := url.ParseQuery("id=4&id=x&id=-2&id=9")
+			query, err := listing.Parse(values, layout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(query.IDs).To(Equal([]int{4, 9}))
+		})
+		It("fails on bad dates", func() {
+			_, err := listing.Parse(url.Values{"from": {"yesterday"}}, layout)
+			Expect(err).To(HaveOccurred())
+			_, err = listing.Parse(url.Values{"to": {"2023-13-01"}}, layout)
+			Expect(err).To(HaveOccurred())
+		})
+		It("reads back what Values writes", func() {
+			values, _ := url.ParseQuery("id=3&id=5&q=smith&from=2023-07-01&room=4&source=ota&sort=balance&order=desc&page=2")
+			query, _ := listing.Parse(values, layout)
+			again, err := listing.Parse(listing.Values(query, layout), layout)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(again).To(Equal(query))
+		})
+	})
+
+	Context("List", func() {
+		list := func(page, total int) listing.List {
+			return listing.List{
+				Path:   "/admin/all-reservations",
+				Layout: layout,
+				Query:  models.ReservationQuery{Tag: "vip", Sort: listing.DefaultSort, Page: page, PerPage: 10},
+				Total:  total,
+			}
+		}
+		It("links sorting", func() {
+			Expect(list(3, 100).SortURL("name")).To(Equal("/admin/all-reservations?per_page=10&sort=name&tag=vip"))
+			Expect(list(1, 100).SortURL("arrival")).To(Equal("/admin/all-reservations?order=desc&per_page=10&tag=vip"))
+			Expect(list(1, 100).SortMark("arrival")).To(Equal("▲"))
+			Expect(list(1, 100).SortMark("name")).To(Equal(""))
+		})
+		It("keeps the filters without the page", func() {
+			Expect(list(3, 100).Filters()).To(Equal(url.Values{"tag": {"vip"}}))
+			Expect(list(3, 100).Params()).To(Equal(url.Values{"tag": {"vip"}, "page": {"3"}, "per_page": {"10"}}))
+		})
+		It("counts pages and rows", func() {
+			Expect(list(1, 0).PageCount()).To(Equal(1))
+			Expect(list(1, 0).First()).To(Equal(0))
+			Expect(list(1, 0).Last()).To(Equal(0))
+			Expect(list(3, 25).PageCount()).To(Equal(3))
+			Expect(list(3, 25).First()).To(Equal(21))
+			Expect(list(3, 25).Last()).To(Equal(25))
+		})
+		It("links pages around the current one", func() {
+			pages := list(6, 200).Pages()
+			numbers := make([]int, 0)
+			for _, page := range pages {
+				numbers = append(numbers, page.Number)
+			}
+			Expect(numbers).To(Equal([]int{1, 0, 4, 5, 6, 7, 8, 0, 20}))
+			Expect(pages[4].Current).To(BeTrue())
+			Expect(pages[1].Gap).To(BeTrue())
+			Expect(pages[0].URL).To(Equal("/admin/all-reservations?per_page=10&tag=vip"))
+			Expect(pages[8].URL).To(Equal("/admin/all-reservations?page=20&per_page=10&tag=vip"))
+		})
+	})
+})
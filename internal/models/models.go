@@ -4,6 +4,115 @@ import (
 	"time"
 )
 
+// ids of restrictions seeded by migrations
+const (
+	RestrictionReservation = 1
+	RestrictionOwnerBlock  = 2
+	RestrictionHold        = 3
+	RestrictionOutOfOrder  = 4
+)
+
+// reservation statuses
+const (
+	ReservationConfirmed = "confirmed"
+	ReservationCancelled = "cancelled"
+	ReservationNoShow    = "no_show"
+)
+
+// ways a cancellation fee is charged once the free period is over
+const (
+	FeeFirstNight = "first_night"
+	FeePercent    = "percent"
+)
+
+// ways a deposit is taken at booking
+const (
+	DepositNone       = "none"
+	DepositFirstNight = "first_night"
+	DepositPercent    = "percent"
+)
+
+// changes which can be made to many reservations at once
+const (
+	BulkProcess = "process"
+	BulkConfirm = "confirm"
+	BulkCancel  = "cancel"
+	BulkTag     = "tag"
+)
+
+// payment statuses, reservation without payments is unpaid
+const (
+	PaymentUnpaid     = "unpaid"
+	PaymentAuthorized = "authorized"
+	PaymentCaptured   = "captured"
+	PaymentRefunded   = "refunded"
+	PaymentVoided     = "voided"
+	PaymentFailed     = "failed"
+)
+
+// types of folio entries, payments and discounts lower the balance due, everything else raises it
+const (
+	FolioRoom            = "room"
+	FolioTax             = "tax"
+	FolioExtra           = "extra"
+	FolioAddOn           = "add_on"
+	FolioDiscount        = "discount"
+	FolioCancellationFee = "cancellation_fee"
+	FolioPayment         = "payment"
+	FolioRefund          = "refund"
+)
+
+// ways a tax is worked out, percentages are taken of the room charge
+const (
+	TaxPercent = "percent"
+	TaxFlat    = "flat"
+)
+
+// what a flat tax is charged for
+const (
+	TaxPerStay       = "stay"
+	TaxPerNight      = "night"
+	TaxPerGuest      = "guest"
+	TaxPerGuestNight = "guest_night"
+)
+
+// what the price of an add-on is charged for
+const (
+	AddOnPerStay        = "stay"
+	AddOnPerNight       = "night"
+	AddOnPerPerson      = "person"
+	AddOnPerPersonNight = "person_night"
+)
+
+// how a booking came in, guests booking on the site are the website source and the rest are taken by staff
+const (
+	SourceWebsite = "website"
+	SourcePhone   = "phone"
+	SourceWalkIn  = "walk_in"
+	SourceEmail   = "email"
+	SourceOTA     = "ota"
+)
+
+// housekeeping statuses of rooms, a cleaned room is inspected by a supervisor before it is given to guests
+const (
+	HousekeepingClean      = "clean"
+	HousekeepingDirty      = "dirty"
+	HousekeepingInspected  = "inspected"
+	HousekeepingOutOfOrder = "out_of_order"
+)
+
+// kinds of housekeeping tasks, rooms of departing guests get a full clean and stay-overs a tidy
+const (
+	TaskDeparture = "departure"
+	TaskStayOver  = "stay_over"
+)
+
+// kinds of documents issued for a reservation
+const (
+	DocumentInvoice = "invoice"
+	DocumentReceipt = "receipt"
+)
+
 type User struct {
 	ID          int `bun:",pk,autoincrement"`
 	FirstName   string
@@ -16,10 +125,42 @@ type User struct {
 }
 
 type Room struct {
-	ID        int       `bun:",pk,autoincrement"`
-	Name      string    `bun:"room_name"`
-	CreatedAt time.Time `bun:",nullzero"`
-	UpdatedAt time.Time `bun:",nullzero"`
+	ID                    int    `bun:",pk,autoincrement"`
+	Name                  string `bun:"room_name"`
+	Price                 int
+	CancellationPolicyID  int                 `bun:",nullzero"`
+	HousekeepingStatus    string              `bun:",nullzero"`
+	HousekeepingUpdatedAt time.Time           `bun:",nullzero"`
+	HousekeepingUpdatedBy int                 `bun:",nullzero"`
+	CreatedAt             time.Time           `bun:",nullzero"`
+	UpdatedAt             time.Time           `bun:",nullzero"`
+	CancellationPolicy    *CancellationPolicy `bun:"rel:belongs-to,join:cancellation_policy_id=id"`
+	RatePlans             []RatePlan          `bun:"rel:has-many,join:id=room_id"`
+}
+
+// CancellationPolicy is free until FreeDays before arrival, after that FeeType decides the fee
+type CancellationPolicy struct {
+	ID         int    `bun:",pk,autoincrement"`
+	Name       string `bun:"policy_name"`
+	FreeDays   int
+	FeeType    string
+	FeePercent int
+	CreatedAt  time.Time `bun:",nullzero"`
+	UpdatedAt  time.Time `bun:",nullzero"`
+}
+
+// RatePlan is an alternative price of a room, with its own cancellation policy and deposit
+type RatePlan struct {
+	ID                   int `bun:",pk,autoincrement"`
+	RoomID               int
+	Name                 string `bun:"plan_name"`
+	Price                int
+	CancellationPolicyID int    `bun:",nullzero"`
+	DepositType          string `bun:",nullzero"`
+	DepositPercent       int
+	CreatedAt            time.Time           `bun:",nullzero"`
+	UpdatedAt            time.Time           `bun:",nullzero"`
+	CancellationPolicy   *CancellationPolicy `bun:"rel:belongs-to,join:cancellation_policy_id=id"`
 }
 
 type Restriction struct {
@@ -29,19 +170,222 @@ type Restriction struct {
 	UpdatedAt       time.Time `bun:",nullzero"`
 }
 
+// Guest is the profile of a guest across stays, Stays and LifetimeValue are worked out from the reservations
+// and are not stored. LifetimeValue is in cents
+type Guest struct {
+	ID            int `bun:",pk,autoincrement"`
+	FirstName     string
+	LastName      string
+	Email         string
+	Phone         string
+	Preferences   string
+	Notes         string
+	VIP           bool          `bun:"vip"`
+	Stays         int           `bun:",scanonly"`
+	LifetimeValue int           `bun:",scanonly"`
+	CreatedAt     time.Time     `bun:",nullzero"`
+	UpdatedAt     time.Time     `bun:",nullzero"`
+	Reservations  []Reservation `bun:"rel:has-many,join:id=guest_id"`
+	Merges        []GuestMerge  `bun:"rel:has-many,join:id=guest_id"`
+}
+
+// GuestMerge records a duplicate profile merged into the guest. Reservations is how many were moved over
+type GuestMerge struct {
+	ID            int `bun:",pk,autoincrement"`
+	GuestID       int
+	MergedGuestID int
+	MergedName    string
+	MergedEmail   string
+	MergedPhone   string
+	Reservations  int
+	MergedBy      int       `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	User          *User     `bun:"rel:belongs-to,join:merged_by=id"`
+}
+
 type Reservation struct {
+	ID               int `bun:",pk,autoincrement"`
+	GuestID          int `bun:",nullzero"`
+	FirstName        string
+	LastName         string
+	Email            string
+	Phone            string
+	StartDate        time.Time `bun:"type:Date"`
+	EndDate          time.Time `bun:"type:Date"`
+	RoomID           int
+	IsProcessed      int
+	GroupID          int `bun:",nullzero"`
+	ConfirmationCode string
+	Guests           int `bun:",nullzero"`
+	HoldID           int `bun:"-"`
+	// prices are in cents
+	RatePlanID           int `bun:",nullzero"`
+	NightlyRate          int
+	CancellationPolicyID int       `bun:",nullzero"`
+	Status               string    `bun:",nullzero"`
+	CancelledAt          time.Time `bun:",nullzero"`
+	CancellationFee      int
+	PaymentStatus        string `bun:",nullzero"`
+	Source               string `bun:",nullzero"`
+	OverrideReason       string
+	CreatedBy            int                 `bun:",nullzero"`
+	CheckedInAt          time.Time           `bun:",nullzero"`
+	CheckedInBy          int                 `bun:",nullzero"`
+	CheckedOutAt         time.Time           `bun:",nullzero"`
+	CheckedOutBy         int                 `bun:",nullzero"`
+	Balance              int                 `bun:",scanonly"`
+	Charged              int                 `bun:",scanonly"`
+	CreatedAt            time.Time           `bun:",nullzero"`
+	UpdatedAt            time.Time           `bun:",nullzero"`
+	DeletedAt            time.Time           `bun:",soft_delete,nullzero"`
+	Guest                *Guest              `bun:"rel:belongs-to,join:guest_id=id"`
+	Room                 *Room               `bun:"rel:belongs-to,join:room_id=id"`
+	Group                *ReservationGroup   `bun:"rel:belongs-to,join:group_id=id"`
+	RatePlan             *RatePlan           `bun:"rel:belongs-to,join:rate_plan_id=id"`
+	CancellationPolicy   *CancellationPolicy `bun:"rel:belongs-to,join:cancellation_policy_id=id"`
+	Payments             []Payment           `bun:"rel:has-many,join:id=reservation_id"`
+	FolioEntries         []FolioEntry        `bun:"rel:has-many,join:id=reservation_id"`
+	Taxes                []ReservationTax    `bun:"rel:has-many,join:id=reservation_id"`
+	AddOns               []ReservationAddOn  `bun:"rel:has-many,join:id=reservation_id"`
+	Notes                []ReservationNote   `bun:"rel:has-many,join:id=reservation_id"`
+	Tags                 []ReservationTag    `bun:"rel:has-many,join:id=reservation_id"`
+	CheckedInUser        *User               `bun:"rel:belongs-to,join:checked_in_by=id"`
+	CheckedOutUser       *User               `bun:"rel:belongs-to,join:checked_out_by=id"`
+}
+
+// ReservationNote is an internal note staff leave on a reservation, guests never see it
+type ReservationNote struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	Body          string
+	CreatedBy     int       `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	User          *User     `bun:"rel:belongs-to,join:created_by=id"`
+}
+
+// ReservationTag is a free-form label on a reservation, such as "anniversary"
+type ReservationTag struct {
+	ReservationID int    `bun:",pk"`
+	Tag           string `bun:",pk"`
+}
+
+// Tax is a tax or fee charged on stays. Rate of a percentage is in hundredths of a percent, Amount of a flat
+// tax is in cents and is charged per Basis. Inclusive taxes are part of the room rate and are only itemized.
+// The tax is in effect on nights from ValidFrom to ValidTo, an empty date leaves that side open
+type Tax struct {
+	ID        int `bun:",pk,autoincrement"`
+	Name      string
+	TaxType   string
+	Rate      int
+	Amount    int
+	Basis     string `bun:",nullzero"`
+	Inclusive bool
+	ValidFrom time.Time `bun:"type:Date,nullzero"`
+	ValidTo   time.Time `bun:"type:Date,nullzero"`
+	CreatedAt time.Time `bun:",nullzero"`
+	UpdatedAt time.Time `bun:",nullzero"`
+}
+
+// ReservationTax is a tax of the reservation as it was worked out at booking, amount is in cents
+type ReservationTax struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	TaxID         int `bun:",nullzero"`
+	Name          string
+	Amount        int
+	Inclusive     bool
+	CreatedAt     time.Time `bun:",nullzero"`
+}
+
+// AddOn is an extra service sold with stays, Price is in cents and is charged per Basis. DailyLimit is how
+// many can be booked for any one night, 0 means there is no limit
+type AddOn struct {
 	ID          int `bun:",pk,autoincrement"`
-	FirstName   string
-	LastName    string
-	Email       string
-	Phone       string
-	StartDate   time.Time `bun:"type:Date"`
-	EndDate     time.Time `bun:"type:Date"`
-	RoomID      int
-	IsProcessed int
+	Name        string
+	Description string
+	Price       int
+	Basis       string `bun:",nullzero"`
+	DailyLimit  int
 	CreatedAt   time.Time `bun:",nullzero"`
 	UpdatedAt   time.Time `bun:",nullzero"`
-	Room        *Room     `bun:"rel:belongs-to,join:room_id=id"`
+}
+
+// ReservationAddOn is an add-on as it was sold with the reservation, Amount is in cents for the whole quantity
+type ReservationAddOn struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	AddOnID       int `bun:",nullzero"`
+	Name          string
+	Quantity      int
+	Amount        int
+	CreatedAt     time.Time `bun:",nullzero"`
+}
+
+// FolioEntry is a line of the reservation's append-only ledger. Amount is in cents, charges are positive
+// and credits are negative, Balance is the running balance after the entry and is not stored
+type FolioEntry struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	EntryType     string
+	Description   string
+	Amount        int
+	UserID        int       `bun:",nullzero"`
+	CreatedAt     time.Time `bun:",nullzero"`
+	Balance       int       `bun:"-"`
+	User          *User     `bun:"rel:belongs-to,join:user_id=id"`
+}
+
+// Payment is money taken through the payment gateway for a reservation, amounts are in cents
+type Payment struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	Amount        int
+	Refunded      int
+	Status        string
+	Reference     string
+	CreatedAt     time.Time `bun:",nullzero"`
+	UpdatedAt     time.Time `bun:",nullzero"`
+}
+
+// Currency is a currency prices can be shown in, Rate is how much of it one unit of the base currency buys
+type Currency struct {
+	ID        int `bun:",pk,autoincrement"`
+	Code      string
+	Symbol    string
+	Rate      float64
+	CreatedAt time.Time `bun:",nullzero"`
+	UpdatedAt time.Time `bun:",nullzero"`
+}
+
+// Invoice is a numbered document issued for a reservation, the number is kept when it is issued again
+type Invoice struct {
+	ID            int `bun:",pk,autoincrement"`
+	ReservationID int
+	Kind          string
+	Number        string
+	CreatedAt     time.Time `bun:",nullzero"`
+}
+
+// Property is the bed and breakfast as it is printed on invoices
+type Property struct {
+	Name    string
+	Address []string
+	Phone   string
+	Email   string
+}
+
+type ReservationGroup struct {
+	ID               int `bun:",pk,autoincrement"`
+	ConfirmationCode string
+	FirstName        string
+	LastName         string
+	Email            string
+	Phone            string
+	IsProcessed      int
+	CreatedAt        time.Time     `bun:",nullzero"`
+	UpdatedAt        time.Time     `bun:",nullzero"`
+	DeletedAt        time.Time     `bun:",soft_delete,nullzero"`
+	Reservations     []Reservation `bun:"rel:has-many,join:id=group_id"`
 }
 
 type RoomRestriction struct {
@@ -51,9 +395,124 @@ type RoomRestriction struct {
 	RoomID        int
 	ReservationID int `bun:",nullzero"`
 	RestrictionID int
+	OutOfOrderID  int          `bun:",nullzero"`
+	ExpiresAt     time.Time    `bun:",nullzero"`
 	CreatedAt     time.Time    `bun:",nullzero"`
 	UpdatedAt     time.Time    `bun:",nullzero"`
+	DeletedAt     time.Time    `bun:",soft_delete,nullzero"`
 	Room          *Room        `bun:"rel:belongs-to,join:room_id=id"`
 	Reservation   *Reservation `bun:"rel:has-one,join:reservation_id=id"`
 	Restriction   *Restriction `bun:"rel:belongs-to,join:restriction_id=id"`
 }
+
+// HousekeepingTask is the cleaning of a room on a day, the task is done once the room is clean again
+type HousekeepingTask struct {
+	ID            int `bun:",pk,autoincrement"`
+	RoomID        int
+	TaskDate      time.Time `bun:"type:Date"`
+	TaskType      string
+	ReservationID int          `bun:",nullzero"`
+	CompletedAt   time.Time    `bun:",nullzero"`
+	CompletedBy   int          `bun:",nullzero"`
+	CreatedAt     time.Time    `bun:",nullzero"`
+	Room          *Room        `bun:"rel:belongs-to,join:room_id=id"`
+	Reservation   *Reservation `bun:"rel:belongs-to,join:reservation_id=id"`
+}
+
+// OutOfOrderPeriod is when a room is out of service from StartDate until EndDate, the room is blocked on
+// these nights by a room restriction
+type OutOfOrderPeriod struct {
+	ID        int `bun:",pk,autoincrement"`
+	RoomID    int
+	StartDate time.Time `bun:"type:Date"`
+	EndDate   time.Time `bun:"type:Date"`
+	Reason    string
+	CreatedBy int       `bun:",nullzero"`
+	CreatedAt time.Time `bun:",nullzero"`
+	UpdatedAt time.Time `bun:",nullzero"`
+	Room      *Room     `bun:"rel:belongs-to,join:room_id=id"`
+}
+
+// WaitlistEntry is a guest waiting for a room on sold out dates, RoomID is 0 when any room will do
+type WaitlistEntry struct {
+	ID         int `bun:",pk,autoincrement"`
+	FirstName  string
+	LastName   string
+	Email      string
+	Phone      string
+	StartDate  time.Time `bun:"type:Date"`
+	EndDate    time.Time `bun:"type:Date"`
+	RoomID     int       `bun:",nullzero"`
+	NotifiedAt time.Time `bun:",nullzero"`
+	CreatedAt  time.Time `bun:",nullzero"`
+	UpdatedAt  time.Time `bun:",nullzero"`
+	Room       *Room     `bun:"rel:belongs-to,join:room_id=id"`
+}
+
+// Actor is who makes changes to the data and from where, the audit log records it with every change.
+// The zero Actor is the application itself, such as the no-show sweep
+type Actor struct {
+	UserID    int
+	IP        string
+	UserAgent string
+	// Request is the method and path of the request
+	Request   string
+	RequestID string
+}
+
+// AuditEntry is a change of a row in the audit log. Before is empty for inserts and After for deletes,
+// Changes holds the old and the new value of every column an update changed
+type AuditEntry struct {
+	ID         int64 `bun:",pk,autoincrement"`
+	OccurredAt time.Time
+	UserID     int `bun:",nullzero"`
+	Action     string
+	Entity     string
+	EntityID   string
+	Before     map[string]interface{}   `bun:"type:jsonb"`
+	After      map[string]interface{}   `bun:"type:jsonb"`
+	Changes    map[string][]interface{} `bun:"type:jsonb"`
+	IP         string
+	UserAgent  string
+	Request    string
+	RequestID  string
+	User       *User `bun:"rel:belongs-to,join:user_id=id"`
+}
+
+// AuditSearch narrows down the audit log. Query is matched against the actor, the request and the rows,
+// zero fields don't narrow anything
+type AuditSearch struct {
+	Query  string
+	Entity string
+	From   time.Time
+	To     time.Time
+}
+
+// ReservationQuery filters, sorts and pages a reservation list, zero fields don't narrow anything.
+// Search is matched against the guest name, email, phone and the confirmation code,
+// From and To keep reservations staying at any day of the range
+type ReservationQuery struct {
+	// IDs narrow the list down to the picked reservations
+	IDs    []int
+	Search string
+	From   time.Time
+	To     time.Time
+	RoomID int
+	Status string
+	Source string
+	Tag    string
+	// Sort is the key of the column to sort by
+	Sort    string
+	Desc    bool
+	Page    int
+	PerPage int
+}
+
+// BulkChange is a change made to many reservations at once
+type BulkChange struct {
+	Action string
+	// Tag is the tag BulkTag adds
+	Tag string
+	// Fees are the cancellation fees BulkCancel charges, by reservation id
+	Fees map[int]int
+}
@@ -13,4 +13,6 @@ type TemplateData struct {
 	Error           string
 	Form            *forms.Form
 	IsAuthenticated int
+	Currency        Currency
+	Currencies      []Currency
 }
@@ -0,0 +1,127 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DeclinedToken is a card the fake gateway always declines
+const DeclinedToken = "4000000000000002"
+
+// states of a payment inside the fake gateway
+const (
+	fakeAuthorized = "authorized"
+	fakeCaptured   = "captured"
+	fakeRefunded   = "refunded"
+	fakeVoided     = "voided"
+)
+
+type fakePayment struct {
+	amount   int
+	refunded int
+	state    string
+}
+
+// Fake is a gateway which keeps payments in memory, for development and tests
+type Fake struct {
+	secret   string
+	mu       sync.Mutex
+	lastID   int
+	payments map[string]*fakePayment
+}
+
+// NewFake creates fake gateway which signs callbacks with secret
+func NewFake(secret string) *Fake {
+	return &Fake{
+		secret:   secret,
+		payments: make(map[string]*fakePayment),
+	}
+}
+
+// Authorize reserves the amount unless token is DeclinedToken or empty
+func (f *Fake) Authorize(amount int, token string) (string, error) {
+	if amount <= 0 {
+		return "", ErrBadAmount
+	}
+	if token == "" || token == DeclinedToken {
+		return "", ErrDeclined
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastID++
+	reference := fmt.Sprintf("fake_%d", f.lastID)
+	f.payments[reference] = &fakePayment{amount: amount, state: fakeAuthorized}
+	return reference, nil
+}
+
+// Capture takes the authorized amount
+func (f *Fake) Capture(reference string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payment, ok := f.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.state != fakeAuthorized {
+		return ErrWrongState
+	}
+	payment.state = fakeCaptured
+	return nil
+}
+
+// Refund returns amount of a captured payment, the payment is refunded once nothing is left on it
+func (f *Fake) Refund(reference string, amount int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payment, ok := f.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.state != fakeCaptured {
+		return ErrWrongState
+	}
+	if amount <= 0 || payment.refunded+amount > payment.amount {
+		return ErrBadAmount
+	}
+	payment.refunded += amount
+	if payment.refunded == payment.amount {
+		payment.state = fakeRefunded
+	}
+	return nil
+}
+
+// Void releases an authorization which is not captured yet
+func (f *Fake) Void(reference string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	payment, ok := f.payments[reference]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.state != fakeAuthorized {
+		return ErrWrongState
+	}
+	payment.state = fakeVoided
+	return nil
+}
+
+// ParseEvent checks the signature of a callback and decodes it
+func (f *Fake) ParseEvent(payload []byte, signature string) (Event, error) {
+	if !Verify(f.secret, payload, signature) {
+		return Event{}, ErrBadSignature
+	}
+	var event Event
+	err := json.Unmarshal(payload, &event)
+	return event, err
+}
+
+// NewEvent builds a signed callback the way the fake gateway would post it
+func (f *Fake) NewEvent(event Event) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(f.secret, payload), nil
+}
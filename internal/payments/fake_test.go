@@ -0,0 +1,71 @@
+package payments_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/payments"
+)
+
+var _ = Describe("Fake gateway", func() {
+	var gateway *payments.Fake
+
+	BeforeEach(func() {
+		gateway = payments.NewFake("secret")
+	})
+
+	It("authorizes and captures", func() {
+		ref, err := gateway.Authorize(5000, "4242424242424242")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ref).ToNot(BeEmpty())
+		Expect(gateway.Capture(ref)).To(Succeed())
+		Expect(gateway.Capture(ref)).To(MatchError(payments.ErrWrongState))
+		Expect(gateway.Void(ref)).To(MatchError(payments.ErrWrongState))
+	})
+
+	It("declines", func() {
+		_, err := gateway.Authorize(5000, payments.DeclinedToken)
+		Expect(err).To(MatchError(payments.ErrDeclined))
+		_, err = gateway.Authorize(5000, "")
+		Expect(err).To(MatchError(payments.ErrDeclined))
+		_, err = gateway.Authorize(0, "4242424242424242")
+		Expect(err).To(MatchError(payments.ErrBadAmount))
+	})
+
+	It("refunds captured amount only", func() {
+		ref, err := gateway.Authorize(5000, "4242424242424242")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gateway.Refund(ref, 1000)).To(MatchError(payments.ErrWrongState))
+		Expect(gateway.Capture(ref)).To(Succeed())
+		Expect(gateway.Refund(ref, 3000)).To(Succeed())
+		Expect(gateway.Refund(ref, 3000)).To(MatchError(payments.ErrBadAmount))
+		Expect(gateway.Refund(ref, 2000)).To(Succeed())
+		Expect(gateway.Refund(ref, 1)).To(MatchError(payments.ErrWrongState))
+	})
+
+	It("voids authorization", func() {
+		ref, err := gateway.Authorize(5000, "4242424242424242")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gateway.Void(ref)).To(Succeed())
+		Expect(gateway.Capture(ref)).To(MatchError(payments.ErrWrongState))
+		Expect(gateway.Void("fake_100")).To(MatchError(payments.ErrUnknownPayment))
+	})
+
+	It("signs and parses events", func() {
+		event := payments.Event{Type: payments.EventRefunded, Reference: "fake_1", Amount: 5000}
+		payload, signature, err := gateway.NewEvent(event)
+		Expect(err).ToNot(HaveOccurred())
+
+		parsed, err := gateway.ParseEvent(payload, signature)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(event))
+
+		_, err = gateway.ParseEvent(payload, payments.Sign("other secret", payload))
+		Expect(err).To(MatchError(payments.ErrBadSignature))
+	})
+
+	It("rejects every event without a secret", func() {
+		payload := []byte(`{"type":"refunded","reference":"fake_1"}`)
+		_, err := payments.NewFake("").ParseEvent(payload, payments.Sign("", payload))
+		Expect(err).To(MatchError(payments.ErrBadSignature))
+	})
+})
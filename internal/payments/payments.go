@@ -0,0 +1,67 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	// ErrDeclined is returned when the card can't be charged
+	ErrDeclined = errors.New("card was declined")
+	// ErrUnknownPayment is returned when the gateway has no payment with the reference
+	ErrUnknownPayment = errors.New("unknown payment")
+	// ErrWrongState is returned when the payment can't go through the operation in its current state
+	ErrWrongState = errors.New("operation is not allowed in current payment state")
+	// ErrBadAmount is returned when the amount is not positive or exceeds what is left on the payment
+	ErrBadAmount = errors.New("bad amount")
+	// ErrBadSignature is returned when a callback is not signed by the gateway
+	ErrBadSignature = errors.New("bad signature")
+)
+
+// types of events the gateway posts to the callback endpoint
+const (
+	EventCaptured = "payment.captured"
+	EventRefunded = "payment.refunded"
+	EventVoided   = "payment.voided"
+	EventFailed   = "payment.failed"
+)
+
+// Event is a change of a payment the gateway tells us about
+type Event struct {
+	Type      string `json:"type"`
+	Reference string `json:"reference"`
+	Amount    int    `json:"amount"`
+}
+
+// PaymentGateway takes money from guests. Amounts are in cents, payments are identified by the
+// reference the gateway returns on authorization
+type PaymentGateway interface {
+	// Authorize reserves the amount on the card represented by token
+	Authorize(amount int, token string) (string, error)
+	// Capture takes the authorized amount
+	Capture(reference string) error
+	// Refund returns amount of a captured payment to the guest
+	Refund(reference string, amount int) error
+	// Void releases an authorization which is not captured yet
+	Void(reference string) error
+	// ParseEvent checks the signature of a callback and decodes it
+	ParseEvent(payload []byte, signature string) (Event, error)
+}
+
+// Sign returns hex encoded HMAC-SHA256 of the payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that signature is Sign of the payload. Nothing is verified without a secret,
+// as anyone could sign with an empty one
+func Verify(secret string, payload []byte, signature string) bool {
+	if secret == "" {
+		return false
+	}
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
@@ -0,0 +1,96 @@
+package pricing
+
+import (
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+)
+
+// addOnUnits returns how many times the price of quantity of an add-on charged on the basis is paid for the stay
+func addOnUnits(res models.Reservation, basis string, quantity int) int {
+	guests := res.Guests
+	if guests < 1 {
+		guests = 1
+	}
+	units := quantity
+	switch basis {
+	case models.AddOnPerNight:
+		units *= Nights(res.StartDate, res.EndDate)
+	case models.AddOnPerPerson:
+		units *= guests
+	case models.AddOnPerPersonNight:
+		units *= guests * Nights(res.StartDate, res.EndDate)
+	}
+	return units
+}
+
+// AddOnAmount returns what quantity of the add-on costs for the stay of the reservation in cents
+func AddOnAmount(res models.Reservation, addOn models.AddOn, quantity int) int {
+	return addOn.Price * addOnUnits(res, addOn.Basis, quantity)
+}
+
+// RepriceAddOn returns the line item sold with the stay of old charged for the stay of res instead,
+// at the price it was sold for. Items of add-ons charged per stay keep their amount
+func RepriceAddOn(old, res models.Reservation, addOn models.AddOn, item models.ReservationAddOn) models.ReservationAddOn {
+	units := addOnUnits(old, addOn.Basis, item.Quantity)
+	if units == 0 {
+		return item
+	}
+	item.Amount = item.Amount / units * addOnUnits(res, addOn.Basis, item.Quantity)
+	return item
+}
+
+// NewReservationAddOn returns the line item selling quantity of the add-on with the reservation
+func NewReservationAddOn(res models.Reservation, addOn models.AddOn, quantity int) models.ReservationAddOn {
+	return models.ReservationAddOn{
+		ReservationID: res.ID,
+		AddOnID:       addOn.ID,
+		Name:          addOn.Name,
+		Quantity:      quantity,
+		Amount:        AddOnAmount(res, addOn, quantity),
+	}
+}
+
+// AddOnsTotal returns the sum of add-ons of the reservation in cents
+func AddOnsTotal(res models.Reservation) int {
+	total := 0
+	for _, item := range res.AddOns {
+		total += item.Amount
+	}
+	return total
+}
+
+// AddOnCharge is the folio entry charging the add-on line item
+func AddOnCharge(item models.ReservationAddOn) models.FolioEntry {
+	description := item.Name
+	if item.Quantity > 1 {
+		description = fmt.Sprintf("%s x %d", item.Name, item.Quantity)
+	}
+	return models.FolioEntry{
+		ReservationID: item.ReservationID,
+		EntryType:     models.FolioAddOn,
+		Description:   description,
+		Amount:        item.Amount,
+	}
+}
+
+// addOnBases are how add-on prices are described
+var addOnBases = map[string]string{
+	models.AddOnPerStay:        "per stay",
+	models.AddOnPerNight:       "per night",
+	models.AddOnPerPerson:      "per person",
+	models.AddOnPerPersonNight: "per person per night",
+}
+
+// AddOnBasis tells what the price of the add-on is charged for, like "per person per night"
+func AddOnBasis(addOn models.AddOn) string {
+	return addOnBases[addOn.Basis]
+}
+
+// DescribeAddOn explains how the add-on is priced, like "$15.00 per person per night" or "$10.00 per night, up to 4 a night"
+func DescribeAddOn(addOn models.AddOn) string {
+	description := "$" + FormatCents(addOn.Price) + " " + AddOnBasis(addOn)
+	if addOn.DailyLimit > 0 {
+		description += fmt.Sprintf(", up to %d a night", addOn.DailyLimit)
+	}
+	return description
+}
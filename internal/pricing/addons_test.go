@@ -0,0 +1,68 @@
+package pricing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+)
+
+var _ = Describe("Add-ons", func() {
+	var res models.Reservation
+	breakfast := models.AddOn{ID: 1, Name: "Breakfast", Price: 1500, Basis: models.AddOnPerPersonNight}
+	parking := models.AddOn{ID: 2, Name: "Parking", Price: 1000, Basis: models.AddOnPerNight, DailyLimit: 4}
+	pickup := models.AddOn{ID: 3, Name: "Airport pickup", Price: 4500, Basis: models.AddOnPerStay}
+	towels := models.AddOn{ID: 4, Name: "Beach towels", Price: 300, Basis: models.AddOnPerPerson}
+
+	BeforeEach(func() {
+		res = models.Reservation{
+			ID:          5,
+			StartDate:   day(20),
+			EndDate:     day(23),
+			NightlyRate: 10000,
+			Guests:      2,
+		}
+	})
+
+	It("multiplies price by quantity and basis", func() {
+		Expect(pricing.AddOnAmount(res, breakfast, 1)).To(Equal(9000))
+		Expect(pricing.AddOnAmount(res, parking, 2)).To(Equal(6000))
+		Expect(pricing.AddOnAmount(res, pickup, 1)).To(Equal(4500))
+		Expect(pricing.AddOnAmount(res, towels, 1)).To(Equal(600))
+		res.Guests = 0
+		Expect(pricing.AddOnAmount(res, towels, 1)).To(Equal(300))
+	})
+
+	It("adds line items to the total and charges them", func() {
+		res.AddOns = []models.ReservationAddOn{
+			pricing.NewReservationAddOn(res, parking, 2),
+			pricing.NewReservationAddOn(res, pickup, 1),
+		}
+		Expect(res.AddOns[0]).To(Equal(models.ReservationAddOn{
+			ReservationID: 5, AddOnID: 2, Name: "Parking", Quantity: 2, Amount: 6000,
+		}))
+		Expect(pricing.AddOnsTotal(res)).To(Equal(10500))
+		Expect(pricing.Total(res)).To(Equal(40500))
+		Expect(pricing.AddOnCharge(res.AddOns[0])).To(Equal(models.FolioEntry{
+			ReservationID: 5, EntryType: models.FolioAddOn, Description: "Parking x 2", Amount: 6000,
+		}))
+		Expect(pricing.AddOnCharge(res.AddOns[1]).Description).To(Equal("Airport pickup"))
+	})
+
+	It("reprices line items for another stay", func() {
+		longer := res
+		longer.EndDate = day(25)
+		item := pricing.NewReservationAddOn(res, breakfast, 1)
+		item.Amount = 7500
+		Expect(pricing.RepriceAddOn(res, longer, breakfast, item).Amount).To(Equal(12500))
+		Expect(pricing.RepriceAddOn(res, longer, parking, pricing.NewReservationAddOn(res, parking, 2)).Amount).
+			To(Equal(10000))
+		Expect(pricing.RepriceAddOn(res, longer, pickup, pricing.NewReservationAddOn(res, pickup, 1)).Amount).
+			To(Equal(4500))
+	})
+
+	It("describes prices", func() {
+		Expect(pricing.DescribeAddOn(breakfast)).To(Equal("$15.00 per person per night"))
+		Expect(pricing.DescribeAddOn(parking)).To(Equal("$10.00 per night, up to 4 a night"))
+	})
+})
@@ -0,0 +1,57 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+)
+
+// ErrUnknownEntryType is returned for a folio entry type which doesn't exist
+var ErrUnknownEntryType = errors.New("unknown entry type")
+
+// folioCredits tells for every entry type whether it lowers the balance due
+var folioCredits = map[string]bool{
+	models.FolioRoom:            false,
+	models.FolioTax:             false,
+	models.FolioExtra:           false,
+	models.FolioAddOn:           false,
+	models.FolioDiscount:        true,
+	models.FolioCancellationFee: false,
+	models.FolioPayment:         true,
+	models.FolioRefund:          false,
+}
+
+// FolioAmount turns a positive amount entered for the entry type into the signed amount stored in the ledger
+func FolioAmount(entryType string, amount int) (int, error) {
+	credit, ok := folioCredits[entryType]
+	if !ok {
+		return 0, ErrUnknownEntryType
+	}
+	if amount <= 0 {
+		return 0, ErrBadPrice
+	}
+	if credit {
+		return -amount, nil
+	}
+	return amount, nil
+}
+
+// RunningBalance fills the balance after each entry and returns the balance due
+func RunningBalance(entries []models.FolioEntry) int {
+	balance := 0
+	for i := range entries {
+		balance += entries[i].Amount
+		entries[i].Balance = balance
+	}
+	return balance
+}
+
+// RoomCharge is the folio entry charging the stay of the reservation
+func RoomCharge(res models.Reservation) models.FolioEntry {
+	return models.FolioEntry{
+		ReservationID: res.ID,
+		EntryType:     models.FolioRoom,
+		Description:   fmt.Sprintf("%d night(s) at %s", Nights(res.StartDate, res.EndDate), FormatCents(res.NightlyRate)),
+		Amount:        StayTotal(res),
+	}
+}
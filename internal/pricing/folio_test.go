@@ -0,0 +1,53 @@
+package pricing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+)
+
+var _ = Describe("Folio", func() {
+	It("signs amounts by entry type", func() {
+		amount, err := pricing.FolioAmount(models.FolioExtra, 1500)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amount).To(Equal(1500))
+
+		amount, err = pricing.FolioAmount(models.FolioPayment, 1500)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amount).To(Equal(-1500))
+
+		amount, err = pricing.FolioAmount(models.FolioDiscount, 200)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(amount).To(Equal(-200))
+	})
+
+	It("rejects bad entries", func() {
+		_, err := pricing.FolioAmount("gift", 1500)
+		Expect(err).To(MatchError(pricing.ErrUnknownEntryType))
+		_, err = pricing.FolioAmount(models.FolioTax, 0)
+		Expect(err).To(MatchError(pricing.ErrBadPrice))
+	})
+
+	It("keeps running balance", func() {
+		entries := []models.FolioEntry{
+			{EntryType: models.FolioRoom, Amount: 30000},
+			{EntryType: models.FolioPayment, Amount: -10000},
+			{EntryType: models.FolioExtra, Amount: 2500},
+			{EntryType: models.FolioRefund, Amount: 1000},
+		}
+		Expect(pricing.RunningBalance(entries)).To(Equal(23500))
+		Expect(entries[1].Balance).To(Equal(20000))
+		Expect(entries[3].Balance).To(Equal(23500))
+	})
+
+	It("charges room nights", func() {
+		entry := pricing.RoomCharge(models.Reservation{
+			ID: 3, StartDate: day(20), EndDate: day(23), NightlyRate: 10000,
+		})
+		Expect(entry.ReservationID).To(Equal(3))
+		Expect(entry.EntryType).To(Equal(models.FolioRoom))
+		Expect(entry.Description).To(Equal("3 night(s) at 100.00"))
+		Expect(entry.Amount).To(Equal(30000))
+	})
+})
@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrBadPrice is returned when a price can't be parsed
+var ErrBadPrice = errors.New("price must be a positive amount with at most two decimals")
+
+// Nights returns number of nights between arrival and departure
+func Nights(start, end time.Time) int {
+	return int(dateOf(end).Sub(dateOf(start)).Hours() / 24)
+}
+
+// StayTotal returns the room charge for the whole stay in cents
+func StayTotal(res models.Reservation) int {
+	return res.NightlyRate * Nights(res.StartDate, res.EndDate)
+}
+
+// CancellationFee returns the fee in cents for cancelling the reservation at the given time
+func CancellationFee(res models.Reservation, policy *models.CancellationPolicy, at time.Time) int {
+	if policy == nil {
+		return 0
+	}
+	deadline := dateOf(res.StartDate).AddDate(0, 0, -policy.FreeDays)
+	if !dateOf(at).After(deadline) {
+		return 0
+	}
+
+	total := StayTotal(res)
+	fee := 0
+	switch policy.FeeType {
+	case models.FeeFirstNight:
+		fee = res.NightlyRate
+	case models.FeePercent:
+		fee = total * policy.FeePercent / 100
+	}
+	if fee > total {
+		fee = total
+	}
+	return fee
+}
+
+// Deposit returns the amount in cents taken at booking by the rate plan of the reservation
+func Deposit(res models.Reservation) int {
+	if res.RatePlan == nil {
+		return 0
+	}
+
+	total := StayTotal(res)
+	deposit := 0
+	switch res.RatePlan.DepositType {
+	case models.DepositFirstNight:
+		deposit = res.NightlyRate
+	case models.DepositPercent:
+		deposit = total * res.RatePlan.DepositPercent / 100
+	}
+	if deposit > total {
+		deposit = total
+	}
+	return deposit
+}
+
+// DescribeDeposit explains the deposit of the rate plan to a guest, it's empty when there is no deposit
+func DescribeDeposit(plan models.RatePlan) string {
+	switch plan.DepositType {
+	case models.DepositFirstNight:
+		return "The first night is paid at booking."
+	case models.DepositPercent:
+		if plan.DepositPercent > 0 {
+			return fmt.Sprintf("%d%% of the stay is paid at booking.", plan.DepositPercent)
+		}
+	}
+	return ""
+}
+
+// DescribePolicy explains the policy to a guest
+func DescribePolicy(policy *models.CancellationPolicy) string {
+	if policy == nil {
+		return "Free cancellation."
+	}
+
+	var until string
+	switch policy.FreeDays {
+	case 0:
+		until = "Free cancellation until the day of arrival"
+	case 1:
+		until = "Free cancellation until 1 day before arrival"
+	default:
+		until = fmt.Sprintf("Free cancellation until %d days before arrival", policy.FreeDays)
+	}
+
+	switch policy.FeeType {
+	case models.FeeFirstNight:
+		return until + ", after that the first night is charged."
+	case models.FeePercent:
+		return fmt.Sprintf("%s, after that %d%% of the stay is charged.", until, policy.FeePercent)
+	}
+	return until + "."
+}
+
+// FormatCents formats an amount in cents as units with two decimals
+func FormatCents(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// ParseCents parses an amount like 120 or 120.5 into cents
+func ParseCents(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	units, fraction, found := strings.Cut(s, ".")
+	if units == "" || len(fraction) > 2 || (found && fraction == "") {
+		return 0, ErrBadPrice
+	}
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+	u, err := strconv.Atoi(units)
+	if err != nil || u < 0 {
+		return 0, ErrBadPrice
+	}
+	f, err := strconv.Atoi(fraction)
+	if err != nil || f < 0 {
+		return 0, ErrBadPrice
+	}
+	return u*100 + f, nil
+}
+
+// dateOf drops the time of day so dates from forms and from db compare equally
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
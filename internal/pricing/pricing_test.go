@@ -0,0 +1,122 @@
+package pricing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2050, 1, d, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Pricing", func() {
+	var res models.Reservation
+
+	BeforeEach(func() {
+		res = models.Reservation{
+			StartDate:   day(20),
+			EndDate:     day(23),
+			NightlyRate: 10000,
+		}
+	})
+
+	Context("StayTotal", func() {
+		It("multiplies nightly rate by nights", func() {
+			Expect(pricing.Nights(res.StartDate, res.EndDate)).To(Equal(3))
+			Expect(pricing.StayTotal(res)).To(Equal(30000))
+		})
+	})
+
+	Context("CancellationFee", func() {
+		var policy *models.CancellationPolicy
+
+		BeforeEach(func() {
+			policy = &models.CancellationPolicy{FreeDays: 7, FeeType: models.FeePercent, FeePercent: 50}
+		})
+
+		It("is free without a policy", func() {
+			Expect(pricing.CancellationFee(res, nil, day(19))).To(Equal(0))
+		})
+
+		It("is free until the deadline day inclusive", func() {
+			Expect(pricing.CancellationFee(res, policy, day(1))).To(Equal(0))
+			Expect(pricing.CancellationFee(res, policy, day(13).Add(23*time.Hour))).To(Equal(0))
+		})
+
+		It("charges percent of the stay after the deadline", func() {
+			Expect(pricing.CancellationFee(res, policy, day(14))).To(Equal(15000))
+		})
+
+		It("charges the first night after the deadline", func() {
+			policy.FeeType = models.FeeFirstNight
+			Expect(pricing.CancellationFee(res, policy, day(14))).To(Equal(10000))
+		})
+
+		It("never charges more than the stay", func() {
+			policy.FeePercent = 150
+			Expect(pricing.CancellationFee(res, policy, day(20))).To(Equal(30000))
+		})
+	})
+
+	Context("Deposit", func() {
+		It("is nothing without a rate plan", func() {
+			Expect(pricing.Deposit(res)).To(Equal(0))
+		})
+
+		It("takes the first night", func() {
+			res.RatePlan = &models.RatePlan{DepositType: models.DepositFirstNight}
+			Expect(pricing.Deposit(res)).To(Equal(10000))
+			Expect(pricing.DescribeDeposit(*res.RatePlan)).To(Equal("The first night is paid at booking."))
+		})
+
+		It("takes percent of the stay but never more than the stay", func() {
+			res.RatePlan = &models.RatePlan{DepositType: models.DepositPercent, DepositPercent: 20}
+			Expect(pricing.Deposit(res)).To(Equal(6000))
+			Expect(pricing.DescribeDeposit(*res.RatePlan)).To(Equal("20% of the stay is paid at booking."))
+			res.RatePlan.DepositPercent = 120
+			Expect(pricing.Deposit(res)).To(Equal(30000))
+		})
+
+		It("is nothing for plan without deposit", func() {
+			res.RatePlan = &models.RatePlan{DepositType: models.DepositNone}
+			Expect(pricing.Deposit(res)).To(Equal(0))
+			Expect(pricing.DescribeDeposit(*res.RatePlan)).To(Equal(""))
+		})
+	})
+
+	Context("DescribePolicy", func() {
+		It("describes both fee types", func() {
+			Expect(pricing.DescribePolicy(nil)).To(Equal("Free cancellation."))
+			Expect(pricing.DescribePolicy(&models.CancellationPolicy{FreeDays: 2, FeeType: models.FeeFirstNight})).
+				To(Equal("Free cancellation until 2 days before arrival, after that the first night is charged."))
+			Expect(pricing.DescribePolicy(&models.CancellationPolicy{FreeDays: 0, FeeType: models.FeePercent, FeePercent: 100})).
+				To(Equal("Free cancellation until the day of arrival, after that 100% of the stay is charged."))
+		})
+	})
+
+	Context("cents", func() {
+		It("formats cents", func() {
+			Expect(pricing.FormatCents(12000)).To(Equal("120.00"))
+			Expect(pricing.FormatCents(5)).To(Equal("0.05"))
+			Expect(pricing.FormatCents(-1050)).To(Equal("-10.50"))
+		})
+
+		It("parses prices", func() {
+			for in, out := range map[string]int{"120": 12000, "120.5": 12050, "0.05": 5, " 99.99 ": 9999} {
+				cents, err := pricing.ParseCents(in)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cents).To(Equal(out))
+			}
+		})
+
+		It("rejects bad prices", func() {
+			for _, in := range []string{"", "abc", "1.234", "-5", "1.", ".5", "1.-5"} {
+				_, err := pricing.ParseCents(in)
+				Expect(err).To(MatchError(pricing.ErrBadPrice))
+			}
+		})
+	})
+})
@@ -0,0 +1,153 @@
+package pricing
+
+import (
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"time"
+)
+
+// ApplyTaxes works out taxes of the reservation from the definitions in effect during the stay. Nightly taxes
+// count the nights they are in effect, taxes charged once per stay are in effect on the arrival date.
+// Taxes which come to nothing are left out
+func ApplyTaxes(res models.Reservation, taxes []models.Tax) []models.ReservationTax {
+	guests := res.Guests
+	if guests < 1 {
+		guests = 1
+	}
+
+	applied := make([]models.ReservationTax, 0, len(taxes))
+	for _, tax := range taxes {
+		// room charge and count of units of the nights the tax is in effect
+		base, units := 0, 0
+		for night := dateOf(res.StartDate); night.Before(dateOf(res.EndDate)); night = night.AddDate(0, 0, 1) {
+			if !taxInEffect(tax, night) {
+				continue
+			}
+			base += res.NightlyRate
+			switch tax.Basis {
+			case models.TaxPerNight:
+				units++
+			case models.TaxPerGuestNight:
+				units += guests
+			}
+		}
+		if taxInEffect(tax, res.StartDate) {
+			switch tax.Basis {
+			case models.TaxPerStay:
+				units = 1
+			case models.TaxPerGuest:
+				units = guests
+			}
+		}
+
+		amount := 0
+		switch tax.TaxType {
+		case models.TaxPercent:
+			amount = percentOf(base, tax.Rate, tax.Inclusive)
+		case models.TaxFlat:
+			amount = tax.Amount * units
+		}
+		if amount == 0 {
+			continue
+		}
+		applied = append(applied, models.ReservationTax{
+			ReservationID: res.ID,
+			TaxID:         tax.ID,
+			Name:          tax.Name,
+			Amount:        amount,
+			Inclusive:     tax.Inclusive,
+		})
+	}
+	return applied
+}
+
+// percentOf returns the tax at rate hundredths of a percent of base, rounded to a cent. An inclusive tax
+// is the part of base which is the tax
+func percentOf(base, rate int, inclusive bool) int {
+	if inclusive {
+		return base - (base*10000+(10000+rate)/2)/(10000+rate)
+	}
+	return (base*rate + 5000) / 10000
+}
+
+func taxInEffect(tax models.Tax, day time.Time) bool {
+	day = dateOf(day)
+	if !tax.ValidFrom.IsZero() && day.Before(dateOf(tax.ValidFrom)) {
+		return false
+	}
+	return tax.ValidTo.IsZero() || !day.After(dateOf(tax.ValidTo))
+}
+
+// ExclusiveTaxes returns the sum of taxes of the reservation which are added to the room charge
+func ExclusiveTaxes(res models.Reservation) int {
+	total := 0
+	for _, tax := range res.Taxes {
+		if !tax.Inclusive {
+			total += tax.Amount
+		}
+	}
+	return total
+}
+
+// Total returns what the stay costs with taxes and add-ons in cents
+func Total(res models.Reservation) int {
+	return StayTotal(res) + ExclusiveTaxes(res) + AddOnsTotal(res)
+}
+
+// TaxCharges are the folio entries charging taxes of a new reservation, inclusive taxes are part of
+// the room charge already
+func TaxCharges(res models.Reservation) []models.FolioEntry {
+	entries := make([]models.FolioEntry, 0, len(res.Taxes))
+	for _, tax := range res.Taxes {
+		if tax.Inclusive {
+			continue
+		}
+		entries = append(entries, models.FolioEntry{
+			ReservationID: res.ID,
+			EntryType:     models.FolioTax,
+			Description:   tax.Name,
+			Amount:        tax.Amount,
+		})
+	}
+	return entries
+}
+
+// taxBases are how flat taxes are described to admins
+var taxBases = map[string]string{
+	models.TaxPerStay:       "per stay",
+	models.TaxPerNight:      "per night",
+	models.TaxPerGuest:      "per guest",
+	models.TaxPerGuestNight: "per guest per night",
+}
+
+// DescribeTax explains how the tax is worked out, like "13% of the room rate, included" or "$2.50 per guest per night"
+func DescribeTax(tax models.Tax) string {
+	description := ""
+	switch tax.TaxType {
+	case models.TaxPercent:
+		description = FormatPercent(tax.Rate) + "% of the room rate"
+	case models.TaxFlat:
+		description = "$" + FormatCents(tax.Amount) + " " + taxBases[tax.Basis]
+	}
+	if tax.Inclusive {
+		description += ", included"
+	}
+	if !tax.ValidFrom.IsZero() {
+		description += fmt.Sprintf(", from %s", tax.ValidFrom.Format("2006-01-02"))
+	}
+	if !tax.ValidTo.IsZero() {
+		description += fmt.Sprintf(", until %s", tax.ValidTo.Format("2006-01-02"))
+	}
+	return description
+}
+
+// FormatPercent formats a rate in hundredths of a percent, dropping decimals which are zero
+func FormatPercent(rate int) string {
+	switch {
+	case rate%100 == 0:
+		return fmt.Sprint(rate / 100)
+	case rate%10 == 0:
+		return fmt.Sprintf("%d.%d", rate/100, rate%100/10)
+	}
+	return fmt.Sprintf("%d.%02d", rate/100, rate%100)
+}
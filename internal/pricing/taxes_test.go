@@ -0,0 +1,94 @@
+package pricing_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+)
+
+var _ = Describe("Taxes", func() {
+	var res models.Reservation
+	var vat, cityTax models.Tax
+
+	BeforeEach(func() {
+		res = models.Reservation{
+			ID:          5,
+			StartDate:   day(20),
+			EndDate:     day(23),
+			NightlyRate: 10000,
+			Guests:      2,
+		}
+		vat = models.Tax{ID: 1, Name: "VAT", TaxType: models.TaxPercent, Rate: 1300}
+		cityTax = models.Tax{ID: 2, Name: "City tax", TaxType: models.TaxFlat, Amount: 250,
+			Basis: models.TaxPerGuestNight}
+	})
+
+	It("adds percent of the room charge and flat amount per guest and night", func() {
+		taxes := pricing.ApplyTaxes(res, []models.Tax{vat, cityTax})
+		Expect(taxes).To(Equal([]models.ReservationTax{
+			{ReservationID: 5, TaxID: 1, Name: "VAT", Amount: 3900},
+			{ReservationID: 5, TaxID: 2, Name: "City tax", Amount: 1500},
+		}))
+		res.Taxes = taxes
+		Expect(pricing.ExclusiveTaxes(res)).To(Equal(5400))
+		Expect(pricing.Total(res)).To(Equal(35400))
+	})
+
+	It("itemizes inclusive taxes without adding them", func() {
+		vat.Inclusive = true
+		res.Taxes = pricing.ApplyTaxes(res, []models.Tax{vat})
+		Expect(res.Taxes).To(HaveLen(1))
+		Expect(res.Taxes[0].Amount).To(Equal(3451))
+		Expect(res.Taxes[0].Inclusive).To(BeTrue())
+		Expect(pricing.Total(res)).To(Equal(30000))
+		Expect(pricing.TaxCharges(res)).To(BeEmpty())
+	})
+
+	It("charges by basis", func() {
+		taxes := []models.Tax{
+			{ID: 3, TaxType: models.TaxFlat, Amount: 500, Basis: models.TaxPerStay},
+			{ID: 4, TaxType: models.TaxFlat, Amount: 500, Basis: models.TaxPerNight},
+			{ID: 5, TaxType: models.TaxFlat, Amount: 500, Basis: models.TaxPerGuest},
+		}
+		applied := pricing.ApplyTaxes(res, taxes)
+		Expect(applied).To(HaveLen(3))
+		Expect(applied[0].Amount).To(Equal(500))
+		Expect(applied[1].Amount).To(Equal(1500))
+		Expect(applied[2].Amount).To(Equal(1000))
+	})
+
+	It("counts one guest when guests are unknown", func() {
+		res.Guests = 0
+		taxes := pricing.ApplyTaxes(res, []models.Tax{cityTax})
+		Expect(taxes[0].Amount).To(Equal(750))
+	})
+
+	It("applies taxes on nights they are in effect", func() {
+		vat.ValidFrom = day(22)
+		cityTax.ValidTo = day(20)
+		stayTax := models.Tax{ID: 6, TaxType: models.TaxFlat, Amount: 500, Basis: models.TaxPerStay, ValidFrom: day(21)}
+		taxes := pricing.ApplyTaxes(res, []models.Tax{vat, cityTax, stayTax})
+		Expect(taxes).To(HaveLen(2))
+		Expect(taxes[0].Amount).To(Equal(1300))
+		Expect(taxes[1].Amount).To(Equal(500))
+	})
+
+	It("charges exclusive taxes to the folio", func() {
+		res.Taxes = pricing.ApplyTaxes(res, []models.Tax{vat, cityTax})
+		entries := pricing.TaxCharges(res)
+		Expect(entries).To(HaveLen(2))
+		Expect(entries[0].EntryType).To(Equal(models.FolioTax))
+		Expect(entries[0].Description).To(Equal("VAT"))
+		Expect(entries[0].Amount).To(Equal(3900))
+	})
+
+	It("describes taxes", func() {
+		vat.Inclusive = true
+		Expect(pricing.DescribeTax(vat)).To(Equal("13% of the room rate, included"))
+		cityTax.ValidFrom = day(1)
+		Expect(pricing.DescribeTax(cityTax)).To(Equal("$2.50 per guest per night, from 2050-01-01"))
+		Expect(pricing.FormatPercent(750)).To(Equal("7.5"))
+		Expect(pricing.FormatPercent(1225)).To(Equal("12.25"))
+	})
+})
@@ -1,21 +1,43 @@
 package render
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/justinas/nosurf"
 	"github.com/porky256/course-project/internal/config"
+	"github.com/porky256/course-project/internal/currency"
 	"github.com/porky256/course-project/internal/helpers"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
 	"html/template"
 	"net/http"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 )
 
 var functions = template.FuncMap{
-	"humanDate":  humanDate,
-	"formatTime": formatTime,
-	"makeRange":  makeRange,
+	"humanDate":       humanDate,
+	"formatTime":      formatTime,
+	"makeRange":       makeRange,
+	"formatPrice":     formatPrice,
+	"formatMoney":     formatMoney,
+	"formatCents":     pricing.FormatCents,
+	"stayTotal":       pricing.StayTotal,
+	"total":           pricing.Total,
+	"deposit":         pricing.Deposit,
+	"nights":          pricing.Nights,
+	"describePolicy":  pricing.DescribePolicy,
+	"describeDeposit": pricing.DescribeDeposit,
+	"describeTax":     pricing.DescribeTax,
+	"formatPercent":   pricing.FormatPercent,
+	"describeAddOn":   pricing.DescribeAddOn,
+	"addOnBasis":      pricing.AddOnBasis,
+	"bookingSource":   bookingSource,
+	"roomStatus":      roomStatus,
+	"auditActor":      auditActor,
+	"auditChanges":    auditChanges,
 }
 
 type Render struct {
@@ -32,6 +54,110 @@ func formatTime(t time.Time, layout string) string {
 	return t.Format(layout)
 }
 
+func formatPrice(cents int) string {
+	if cents < 0 {
+		return "-$" + pricing.FormatCents(-cents)
+	}
+	return "$" + pricing.FormatCents(cents)
+}
+
+// formatMoney shows base currency cents in the currency, prices are in the base currency when it is not set
+func formatMoney(cents int, c models.Currency) string {
+	if c.Code == "" {
+		return formatPrice(cents)
+	}
+	return currency.Format(cents, c)
+}
+
+// bookingSource names the way a booking came in, bookings without a source were made on the site
+func bookingSource(source string) string {
+	switch source {
+	case models.SourcePhone:
+		return "Phone"
+	case models.SourceWalkIn:
+		return "Walk-in"
+	case models.SourceEmail:
+		return "Email"
+	case models.SourceOTA:
+		return "Online travel agency"
+	default:
+		return "Website"
+	}
+}
+
+// roomStatus names the housekeeping status of a room, rooms without a status are clean
+func roomStatus(status string) string {
+	switch status {
+	case models.HousekeepingDirty:
+		return "Dirty"
+	case models.HousekeepingInspected:
+		return "Inspected"
+	case models.HousekeepingOutOfOrder:
+		return "Out of order"
+	default:
+		return "Clean"
+	}
+}
+
+// auditActor names who made the change. Changes without a user were made by guests on the site
+// or by the application itself
+func auditActor(entry models.AuditEntry) string {
+	switch {
+	case entry.User != nil:
+		return entry.User.FirstName + " " + entry.User.LastName
+	case entry.UserID != 0:
+		return fmt.Sprintf("user #%d", entry.UserID)
+	case entry.Request != "":
+		return "guest"
+	default:
+		return "system"
+	}
+}
+
+// auditValue shows a column value of the audit log
+func auditValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "empty"
+	case string:
+		return v
+	default:
+		text, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(text)
+	}
+}
+
+// auditChanges lists the changed columns of an update, or the columns of an inserted or deleted row
+func auditChanges(entry models.AuditEntry) []string {
+	lines := make([]string, 0)
+	if len(entry.Changes) > 0 {
+		for column, change := range entry.Changes {
+			values := make([]string, 0, len(change))
+			for _, v := range change {
+				values = append(values, auditValue(v))
+			}
+			lines = append(lines, column+": "+strings.Join(values, " → "))
+		}
+		sort.Strings(lines)
+		return lines
+	}
+	row := entry.After
+	if row == nil {
+		row = entry.Before
+	}
+	for column, v := range row {
+		if v == nil || column == "created_at" || column == "updated_at" {
+			continue
+		}
+		lines = append(lines, column+": "+auditValue(v))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
 func makeRange(start, end, step int) []int {
 	var ans []int
 	for i := start; i <= end; i += step {
@@ -119,6 +245,10 @@ func (r *Render) addDefaultData(td *models.TemplateData, req *http.Request) *mod
 	td.Error = r.app.Session.PopString(req.Context(), "error")
 	td.Warning = r.app.Session.PopString(req.Context(), "warning")
 	td.CSRFToken = nosurf.Token(req)
+	if r.app.Currencies != nil {
+		td.Currency = r.app.Currencies.Get(r.app.Session.GetString(req.Context(), "currency"))
+		td.Currencies = r.app.Currencies.All()
+	}
 	//if r.app.Session.Exists(req.Context(), "user_id") {
 	//	td.IsAuthenticated = 1
 	//}
@@ -69,6 +69,45 @@ var _ = Describe("Render", func() {
 		})
 	})
 
+	Context("audit log", func() {
+		It("lists changed columns of an update", func() {
+			entry := models.AuditEntry{
+				Action: "update",
+				Changes: map[string][]interface{}{
+					"phone":      {"555", "556"},
+					"first_name": {"Jon", "John"},
+					"room_id":    {float64(1), nil},
+				},
+			}
+			Expect(auditChanges(entry)).To(Equal([]string{
+				"first_name: Jon → John",
+				"phone: 555 → 556",
+				"room_id: 1 → empty",
+			}))
+		})
+
+		It("lists columns of a deleted row", func() {
+			entry := models.AuditEntry{
+				Action: "delete",
+				Before: map[string]interface{}{
+					"id":         float64(7),
+					"notes":      nil,
+					"updated_at": "2023-07-17T10:00:00",
+					"start_date": "2023-07-20",
+				},
+			}
+			Expect(auditChanges(entry)).To(Equal([]string{"id: 7", "start_date: 2023-07-20"}))
+		})
+
+		It("names the actor", func() {
+			Expect(auditActor(models.AuditEntry{UserID: 1, User: &models.User{FirstName: "Ann", LastName: "Lee"}})).
+				To(Equal("Ann Lee"))
+			Expect(auditActor(models.AuditEntry{UserID: 2})).To(Equal("user #2"))
+			Expect(auditActor(models.AuditEntry{Request: "POST /make-reservation"})).To(Equal("guest"))
+			Expect(auditActor(models.AuditEntry{})).To(Equal("system"))
+		})
+	})
+
 	Context("CreateTemplateCacheMap", func() {
 		It("Check if function works correctly", func() {
 			cache, err := CreateTemplateCacheMap(render.app)
@@ -0,0 +1,289 @@
+package reports
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MaxDays is the longest period a report covers
+const MaxDays = 366
+
+// Tables of the report which can be downloaded
+const (
+	RoomsTable   = "rooms"
+	DaysTable    = "days"
+	SourcesTable = "sources"
+)
+
+// ErrUnknownTable is returned for a report table which doesn't exist
+var ErrUnknownTable = errors.New("unknown report table")
+
+// Metrics are the figures of a report for the whole hotel or a room. Nights are counted within the period only,
+// so a stay running past its end adds just its nights inside. Rates are in hundredths of a percent and money in cents
+type Metrics struct {
+	// NightsAvailable are the room nights which could be sold, nights blocked or out of order can't be
+	NightsAvailable int `json:"nights_available"`
+	NightsSold      int `json:"nights_sold"`
+	Revenue         int `json:"revenue"`
+	Occupancy       int `json:"occupancy"`
+	// ADR is the average daily rate, the revenue of a sold night
+	ADR int `json:"adr"`
+	// RevPAR is the revenue per available room night
+	RevPAR int `json:"revpar"`
+	// Bookings are the stays which weren't cancelled
+	Bookings int `json:"bookings"`
+	// LeadTime is how many days before arrival stays are booked on average
+	LeadTime float64 `json:"lead_time"`
+	// LengthOfStay is how many nights stays last on average, nights outside the period count too
+	LengthOfStay     float64 `json:"length_of_stay"`
+	Cancellations    int     `json:"cancellations"`
+	CancelledNights  int     `json:"cancelled_nights"`
+	CancellationFees int     `json:"cancellation_fees"`
+	// CancellationRate is the share of stays which were cancelled
+	CancellationRate int `json:"cancellation_rate"`
+
+	leadDays, stayNights int
+}
+
+// RoomMetrics are the figures of a room
+type RoomMetrics struct {
+	RoomID int    `json:"room_id"`
+	Room   string `json:"room"`
+	Metrics
+}
+
+// SourceMetrics are the stays booked through a source
+type SourceMetrics struct {
+	Source     string `json:"source"`
+	Bookings   int    `json:"bookings"`
+	NightsSold int    `json:"nights_sold"`
+	Revenue    int    `json:"revenue"`
+	// Share is the part of the sold nights booked through the source
+	Share int `json:"share"`
+}
+
+// DayMetrics are the figures of a night of the period
+type DayMetrics struct {
+	Date            time.Time `json:"date"`
+	NightsAvailable int       `json:"nights_available"`
+	NightsSold      int       `json:"nights_sold"`
+	Revenue         int       `json:"revenue"`
+	Occupancy       int       `json:"occupancy"`
+}
+
+// Report is what the hotel sold over a period, from the night of From to the night of To
+type Report struct {
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Total   Metrics         `json:"total"`
+	Rooms   []RoomMetrics   `json:"rooms"`
+	Sources []SourceMetrics `json:"sources"`
+	Days    []DayMetrics    `json:"days"`
+}
+
+// sources are the booking sources in the order the report shows them
+var sources = []string{models.SourceWebsite, models.SourcePhone, models.SourceWalkIn, models.SourceEmail, models.SourceOTA}
+
+// dateOf drops the time of day
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// rate is part of whole in hundredths of a percent
+func rate(part, whole int) int {
+	if whole == 0 {
+		return 0
+	}
+	return (part*10000 + whole/2) / whole
+}
+
+// average is total split by count
+func average(total, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return float64(total) / float64(count)
+}
+
+// finish works out the rates and averages from the counts
+func (m *Metrics) finish() {
+	m.Occupancy = rate(m.NightsSold, m.NightsAvailable)
+	if m.NightsSold > 0 {
+		m.ADR = (m.Revenue + m.NightsSold/2) / m.NightsSold
+	}
+	if m.NightsAvailable > 0 {
+		m.RevPAR = (m.Revenue + m.NightsAvailable/2) / m.NightsAvailable
+	}
+	m.LeadTime = average(m.leadDays, m.Bookings)
+	m.LengthOfStay = average(m.stayNights, m.Bookings)
+	m.CancellationRate = rate(m.Cancellations, m.Bookings+m.Cancellations)
+}
+
+// add counts a stay with nights nights inside the period
+func (m *Metrics) add(res models.Reservation, nights int) {
+	if res.Status == models.ReservationCancelled {
+		m.Cancellations++
+		m.CancelledNights += nights
+		m.CancellationFees += res.CancellationFee
+		return
+	}
+	m.Bookings++
+	m.NightsSold += nights
+	m.Revenue += nights * res.NightlyRate
+	m.stayNights += pricing.Nights(res.StartDate, res.EndDate)
+	if !res.CreatedAt.IsZero() {
+		if lead := pricing.Nights(res.CreatedAt, res.StartDate); lead > 0 {
+			m.leadDays += lead
+		}
+	}
+}
+
+// Build works out the report of the period from the stays and the restrictions taking rooms out of sale.
+// Stays and restrictions outside the period or of other rooms are skipped, no-shows count as sold
+func Build(from, to time.Time, rooms []models.Room, reservations []models.Reservation,
+	restrictions []models.RoomRestriction) Report {
+	from, to = dateOf(from), dateOf(to)
+	report := Report{From: from, To: to, Rooms: make([]RoomMetrics, len(rooms))}
+	days := pricing.Nights(from, to) + 1
+	if days < 0 {
+		days = 0
+	}
+	report.Days = make([]DayMetrics, days)
+	for i := range report.Days {
+		report.Days[i] = DayMetrics{Date: from.AddDate(0, 0, i), NightsAvailable: len(rooms)}
+	}
+
+	roomIndex := make(map[int]int, len(rooms))
+	for i, room := range rooms {
+		roomIndex[room.ID] = i
+		report.Rooms[i] = RoomMetrics{RoomID: room.ID, Room: room.Name, Metrics: Metrics{NightsAvailable: days}}
+	}
+	// nights returns the days of the period the span takes
+	nights := func(start, end time.Time) (first, last int) {
+		first = pricing.Nights(from, start)
+		if first < 0 {
+			first = 0
+		}
+		last = pricing.Nights(from, end)
+		if last > days {
+			last = days
+		}
+		return first, last
+	}
+
+	blocked := make(map[[2]int]bool)
+	for _, restriction := range restrictions {
+		i, ok := roomIndex[restriction.RoomID]
+		if !ok {
+			continue
+		}
+		first, last := nights(restriction.StartDate, restriction.EndDate)
+		for day := first; day < last; day++ {
+			if blocked[[2]int{restriction.RoomID, day}] {
+				continue
+			}
+			blocked[[2]int{restriction.RoomID, day}] = true
+			report.Rooms[i].NightsAvailable--
+			report.Days[day].NightsAvailable--
+		}
+	}
+
+	order := append([]string{}, sources...)
+	bySource := make(map[string]*SourceMetrics, len(order))
+	for _, source := range order {
+		bySource[source] = &SourceMetrics{Source: source}
+	}
+	for _, res := range reservations {
+		i, ok := roomIndex[res.RoomID]
+		if !ok {
+			continue
+		}
+		first, last := nights(res.StartDate, res.EndDate)
+		if first >= last {
+			continue
+		}
+		report.Rooms[i].add(res, last-first)
+		report.Total.add(res, last-first)
+		if res.Status == models.ReservationCancelled {
+			continue
+		}
+		for day := first; day < last; day++ {
+			report.Days[day].NightsSold++
+			report.Days[day].Revenue += res.NightlyRate
+		}
+		source := res.Source
+		if source == "" {
+			source = models.SourceWebsite
+		}
+		if bySource[source] == nil {
+			bySource[source] = &SourceMetrics{Source: source}
+			order = append(order, source)
+		}
+		bySource[source].Bookings++
+		bySource[source].NightsSold += last - first
+		bySource[source].Revenue += (last - first) * res.NightlyRate
+	}
+
+	for i := range report.Rooms {
+		report.Total.NightsAvailable += report.Rooms[i].NightsAvailable
+		report.Rooms[i].finish()
+	}
+	report.Total.finish()
+	for i := range report.Days {
+		report.Days[i].Occupancy = rate(report.Days[i].NightsSold, report.Days[i].NightsAvailable)
+	}
+	for _, source := range order {
+		bySource[source].Share = rate(bySource[source].NightsSold, report.Total.NightsSold)
+		report.Sources = append(report.Sources, *bySource[source])
+	}
+	return report
+}
+
+// WriteCSV writes the table of the report as comma separated values, money in currency units
+func (r Report) WriteCSV(w io.Writer, table string) error {
+	var records [][]string
+	money := pricing.FormatCents
+	percent := pricing.FormatPercent
+	switch table {
+	case RoomsTable:
+		records = append(records, []string{"Room", "Nights available", "Nights sold", "Occupancy %", "Revenue",
+			"ADR", "RevPAR", "Bookings", "Average lead time", "Average length of stay", "Cancellations",
+			"Cancelled nights", "Cancellation fees", "Cancellation rate %"})
+		row := func(name string, m Metrics) []string {
+			return []string{name, strconv.Itoa(m.NightsAvailable), strconv.Itoa(m.NightsSold), percent(m.Occupancy),
+				money(m.Revenue), money(m.ADR), money(m.RevPAR), strconv.Itoa(m.Bookings),
+				fmt.Sprintf("%.1f", m.LeadTime), fmt.Sprintf("%.1f", m.LengthOfStay), strconv.Itoa(m.Cancellations),
+				strconv.Itoa(m.CancelledNights), money(m.CancellationFees), percent(m.CancellationRate)}
+		}
+		for _, room := range r.Rooms {
+			records = append(records, row(room.Room, room.Metrics))
+		}
+		records = append(records, row("Total", r.Total))
+	case DaysTable:
+		records = append(records, []string{"Date", "Nights available", "Nights sold", "Occupancy %", "Revenue"})
+		for _, day := range r.Days {
+			records = append(records, []string{day.Date.Format("2006-01-02"), strconv.Itoa(day.NightsAvailable),
+				strconv.Itoa(day.NightsSold), percent(day.Occupancy), money(day.Revenue)})
+		}
+	case SourcesTable:
+		records = append(records, []string{"Source", "Bookings", "Nights sold", "Share %", "Revenue"})
+		for _, source := range r.Sources {
+			records = append(records, []string{source.Source, strconv.Itoa(source.Bookings),
+				strconv.Itoa(source.NightsSold), percent(source.Share), money(source.Revenue)})
+		}
+	default:
+		return ErrUnknownTable
+	}
+	writer := csv.NewWriter(w)
+	err := writer.WriteAll(records)
+	if err != nil {
+		return err
+	}
+	return writer.Error()
+}
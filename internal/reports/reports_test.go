@@ -0,0 +1,107 @@
+package reports_test
+
+import (
+	"bytes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/reports"
+	"time"
+)
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2023, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+var _ = Describe("Reports", func() {
+	rooms := []models.Room{{ID: 1, Name: "General's Quarters"}, {ID: 2, Name: "Major's Suite"}}
+	reservations := []models.Reservation{
+		// two nights inside the period, booked ten days ahead
+		{RoomID: 1, StartDate: date(7, 1), EndDate: date(7, 3), NightlyRate: 10000, CreatedAt: date(6, 21)},
+		// runs past the end of the period, one night inside
+		{RoomID: 2, StartDate: date(7, 4), EndDate: date(7, 7), NightlyRate: 20000, Source: models.SourceOTA,
+			CreatedAt: date(7, 4)},
+		{RoomID: 2, StartDate: date(7, 1), EndDate: date(7, 2), NightlyRate: 20000,
+			Status: models.ReservationCancelled, CancellationFee: 5000},
+		// outside the period and of a room which isn't reported
+		{RoomID: 1, StartDate: date(7, 10), EndDate: date(7, 12), NightlyRate: 10000},
+		{RoomID: 3, StartDate: date(7, 1), EndDate: date(7, 2), NightlyRate: 10000},
+	}
+	restrictions := []models.RoomRestriction{
+		{RoomID: 1, StartDate: date(7, 3), EndDate: date(7, 5), RestrictionID: models.RestrictionOwnerBlock},
+		{RoomID: 1, StartDate: date(7, 4), EndDate: date(7, 6), RestrictionID: models.RestrictionOutOfOrder},
+	}
+	report := reports.Build(date(7, 1), date(7, 4), rooms, reservations, restrictions)
+
+	It("counts the whole hotel", func() {
+		total := report.Total
+		Expect(total.NightsAvailable).To(Equal(6))
+		Expect(total.NightsSold).To(Equal(3))
+		Expect(total.Revenue).To(Equal(40000))
+		Expect(total.Occupancy).To(Equal(5000))
+		Expect(total.ADR).To(Equal(13333))
+		Expect(total.RevPAR).To(Equal(6667))
+		Expect(total.Bookings).To(Equal(2))
+		Expect(total.LeadTime).To(Equal(5.0))
+		Expect(total.LengthOfStay).To(Equal(2.5))
+		Expect(total.Cancellations).To(Equal(1))
+		Expect(total.CancelledNights).To(Equal(1))
+		Expect(total.CancellationFees).To(Equal(5000))
+		Expect(total.CancellationRate).To(Equal(3333))
+	})
+
+	It("counts every room", func() {
+		Expect(report.Rooms).To(HaveLen(2))
+		Expect(report.Rooms[0].Room).To(Equal("General's Quarters"))
+		Expect(report.Rooms[0].NightsAvailable).To(Equal(2))
+		Expect(report.Rooms[0].Occupancy).To(Equal(10000))
+		Expect(report.Rooms[1].NightsAvailable).To(Equal(4))
+		Expect(report.Rooms[1].NightsSold).To(Equal(1))
+		Expect(report.Rooms[1].Occupancy).To(Equal(2500))
+		Expect(report.Rooms[1].Cancellations).To(Equal(1))
+	})
+
+	It("counts every night", func() {
+		Expect(report.Days).To(HaveLen(4))
+		Expect(report.Days[0]).To(Equal(reports.DayMetrics{Date: date(7, 1), NightsAvailable: 2, NightsSold: 1,
+			Revenue: 10000, Occupancy: 5000}))
+		Expect(report.Days[2].NightsAvailable).To(Equal(1))
+		Expect(report.Days[3]).To(Equal(reports.DayMetrics{Date: date(7, 4), NightsAvailable: 1, NightsSold: 1,
+			Revenue: 20000, Occupancy: 10000}))
+	})
+
+	It("splits sold nights by source", func() {
+		Expect(report.Sources).To(HaveLen(5))
+		Expect(report.Sources[0]).To(Equal(reports.SourceMetrics{Source: models.SourceWebsite, Bookings: 1,
+			NightsSold: 2, Revenue: 20000, Share: 6667}))
+		Expect(report.Sources[4]).To(Equal(reports.SourceMetrics{Source: models.SourceOTA, Bookings: 1,
+			NightsSold: 1, Revenue: 20000, Share: 3333}))
+	})
+
+	It("has nothing for a period without rooms", func() {
+		empty := reports.Build(date(7, 4), date(7, 1), nil, reservations, nil)
+		Expect(empty.Days).To(BeEmpty())
+		Expect(empty.Total).To(Equal(reports.Metrics{}))
+	})
+
+	Context("WriteCSV", func() {
+		It("writes the rooms with the total", func() {
+			out := new(bytes.Buffer)
+			Expect(report.WriteCSV(out, reports.RoomsTable)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("Room,Nights available,Nights sold,Occupancy %,Revenue,ADR,RevPAR,"))
+			Expect(out.String()).To(ContainSubstring("\nGeneral's Quarters,2,2,100,200.00,100.00,100.00,1,10.0,2.0,0,0,0.00,0\n"))
+			Expect(out.String()).To(HaveSuffix("\nTotal,6,3,50,400.00,133.33,66.67,2,5.0,2.5,1,1,50.00,33.33\n"))
+		})
+		It("writes the nights and the sources", func() {
+			out := new(bytes.Buffer)
+			Expect(report.WriteCSV(out, reports.DaysTable)).To(Succeed())
+			Expect(out.String()).To(HavePrefix("Date,Nights available,Nights sold,Occupancy %,Revenue\n2023-07-01,2,1,50,100.00\n"))
+			out.Reset()
+			Expect(report.WriteCSV(out, reports.SourcesTable)).To(Succeed())
+			Expect(out.String()).To(ContainSubstring("\nota,1,1,33.33,200.00\n"))
+		})
+		It("fails on an unknown table", func() {
+			Expect(report.WriteCSV(new(bytes.Buffer), "guests")).To(MatchError(reports.ErrUnknownTable))
+		})
+	})
+})
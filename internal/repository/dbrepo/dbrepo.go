@@ -1,14 +1,19 @@
 package dbrepo
 
 import (
+	"context"
 	"github.com/porky256/course-project/internal/config"
+	"github.com/porky256/course-project/internal/models"
 	"github.com/porky256/course-project/internal/repository"
 	"github.com/uptrace/bun"
+	"strconv"
 )
 
 type postgresDB struct {
 	App *config.AppConfig
 	DB  *bun.DB
+	// Actor makes the changes, the audit log records it
+	Actor models.Actor
 }
 
 // NewPostgresDB creates a new postgres DB entity
@@ -18,3 +23,31 @@ func NewPostgresDB(conn *bun.DB, a *config.AppConfig) repository.DatabaseRepo {
 		DB:  conn,
 	}
 }
+
+// WithActor returns the repository which records the actor with every change it makes
+func (pdb *postgresDB) WithActor(actor models.Actor) repository.DatabaseRepo {
+	withActor := *pdb
+	withActor.Actor = actor
+	return &withActor
+}
+
+// audited runs fn in a transaction and tells the audit log triggers who makes the changes. The settings last
+// until the transaction ends, so connections go back to the pool without them
+func (pdb *postgresDB) audited(ctx context.Context, fn func(ctx context.Context, tx bun.Tx) error) error {
+	return pdb.DB.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+		if pdb.Actor != (models.Actor{}) {
+			userID := ""
+			if pdb.Actor.UserID != 0 {
+				userID = strconv.Itoa(pdb.Actor.UserID)
+			}
+			_, err := tx.ExecContext(ctx, "SELECT set_config('audit.user_id', ?, true), "+
+				"set_config('audit.ip', ?, true), set_config('audit.user_agent', ?, true), "+
+				"set_config('audit.request', ?, true), set_config('audit.request_id', ?, true)",
+				userID, pdb.Actor.IP, pdb.Actor.UserAgent, pdb.Actor.Request, pdb.Actor.RequestID)
+			if err != nil {
+				return err
+			}
+		}
+		return fn(ctx, tx)
+	})
+}
@@ -2,9 +2,17 @@ package dbrepo
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/porky256/course-project/internal/frontdesk"
 	"github.com/porky256/course-project/internal/models"
+	"github.com/porky256/course-project/internal/pricing"
+	"github.com/porky256/course-project/internal/repository"
+	"github.com/uptrace/bun"
 	"golang.org/x/crypto/bcrypt"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -12,12 +20,23 @@ const (
 	queryTimeout = 3 * time.Second
 )
 
-// InsertReservation inserts a reservation
+// InsertReservation inserts a reservation and charges its room nights to the folio
 func (pdb *postgresDB) InsertReservation(res *models.Reservation) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	var newID int
-	err := pdb.DB.NewInsert().Model(res).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := guestForReservationInTx(ctx, tx, res)
+		if err != nil {
+			return err
+		}
+		err = tx.NewInsert().Model(res).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		res.ID = newID
+		return chargeStayInTx(ctx, tx, res)
+	})
 	return newID, err
 }
 
@@ -26,7 +45,9 @@ func (pdb *postgresDB) InsertRoom(room *models.Room) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	var newID int
-	err := pdb.DB.NewInsert().Model(room).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(room).Returning("id").Scan(ctx, &newID)
+	})
 	return newID, err
 }
 
@@ -35,7 +56,9 @@ func (pdb *postgresDB) InsertUser(user *models.User) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	var newID int
-	err := pdb.DB.NewInsert().Model(user).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(user).Returning("id").Scan(ctx, &newID)
+	})
 	return newID, err
 }
 
@@ -44,7 +67,9 @@ func (pdb *postgresDB) InsertRestriction(res *models.Restriction) (int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	var newID int
-	err := pdb.DB.NewInsert().Model(res).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(res).Returning("id").Scan(ctx, &newID)
+	})
 	return newID, err
 }
 
@@ -53,7 +78,9 @@ func (pdb *postgresDB) InsertRoomRestriction(rmres *models.RoomRestriction) (int
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	var newID int
-	err := pdb.DB.NewInsert().Model(rmres).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(rmres).Returning("id").Scan(ctx, &newID)
+	})
 	return newID, err
 }
 
@@ -63,9 +90,11 @@ func (pdb *postgresDB) LookForAvailabilityOfRoom(start, end time.Time, roomID in
 	defer cancel()
 	numberRows, err := pdb.DB.NewSelect().
 		Table("room_restrictions").
+		Where("deleted_at IS NULL").
 		Where("room_id = ?", roomID).
 		Where("end_date>?", start).
 		Where("start_date<?", end).
+		Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
 		Count(ctx)
 	return numberRows == 0, err
 }
@@ -78,8 +107,10 @@ func (pdb *postgresDB) AvailabilityOfAllRooms(start, end time.Time) ([]models.Ro
 	subq := pdb.DB.NewSelect().
 		Table("room_restrictions").
 		Column("room_id").
+		Where("deleted_at IS NULL").
 		Where("end_date>?", start).
-		Where("start_date<?", end)
+		Where("start_date<?", end).
+		Where("(expires_at IS NULL OR expires_at>?)", time.Now())
 	err := pdb.DB.NewSelect().
 		Model((*models.Room)(nil)).
 		Where("room.id not in (?)", subq).
@@ -92,7 +123,13 @@ func (pdb *postgresDB) GetRoomByID(id int) (*models.Room, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 	room := new(models.Room)
-	err := pdb.DB.NewSelect().Model(room).Where("id=?", id).Scan(ctx)
+	err := pdb.DB.NewSelect().Model(room).
+		Relation("CancellationPolicy").
+		Relation("RatePlans", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("rate_plan.price")
+		}).
+		Relation("RatePlans.CancellationPolicy").
+		Where("room.id=?", id).Scan(ctx)
 	return room, err
 }
 
@@ -129,26 +166,211 @@ func (pdb *postgresDB) Authenticate(email, passwordSample string) (int, string,
 	return user.ID, user.Password, nil
 }
 
-// GetAllReservations search for all reservations
-func (pdb *postgresDB) GetAllReservations() ([]models.Reservation, error) {
+// GetAllReservations returns a page of the reservations matching the query with their balance due,
+// and how many reservations match it on all pages
+func (pdb *postgresDB) GetAllReservations(query models.ReservationQuery) ([]models.Reservation, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
 	reservations := make([]models.Reservation, 0)
-	err := pdb.DB.NewSelect().Model(&reservations).Relation("Room").Scan(ctx)
+	q := reservationListQuery(pdb.DB, &reservations, query)
+	count, err := q.ScanAndCount(ctx)
 
-	return reservations, err
+	return reservations, count, err
+}
+
+// reservationSorts are the columns the reservation lists can be sorted by
+var reservationSorts = map[string][]string{
+	"id":        {"reservation.id"},
+	"name":      {"reservation.last_name", "reservation.first_name"},
+	"room":      {"room.room_name"},
+	"arrival":   {"reservation.start_date"},
+	"departure": {"reservation.end_date"},
+	"code":      {"reservation.confirmation_code"},
+	"status":    {"COALESCE(reservation.status, 'confirmed')"},
+	"payment":   {"reservation.payment_status"},
+	"source":    {"COALESCE(reservation.source, 'website')"},
+	"balance":   {"balance"},
+	"created":   {"reservation.created_at"},
+}
+
+// reservationListQuery selects the reservations matching the query into reservations
+func reservationListQuery(db bun.IDB, reservations *[]models.Reservation, query models.ReservationQuery) *bun.SelectQuery {
+	q := db.NewSelect().Model(reservations).
+		ColumnExpr("reservation.*").
+		ColumnExpr("(?) AS balance", balanceQuery(db)).
+		Relation("Room").
+		Relation("Tags", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_tag.tag")
+		})
+	return filterReservations(db, q, query)
+}
+
+// filterReservations narrows q down to the reservations matching the query and pages it,
+// sorted by arrival unless the query asks for a known column. q must join the room as room
+func filterReservations(db bun.IDB, q *bun.SelectQuery, query models.ReservationQuery) *bun.SelectQuery {
+	for _, term := range strings.Fields(query.Search) {
+		pattern := "%" + likeEscaper.Replace(term) + "%"
+		digits := onlyDigits(term)
+		q.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			q = q.Where("reservation.first_name ILIKE ?", pattern).
+				WhereOr("reservation.last_name ILIKE ?", pattern).
+				WhereOr("reservation.email ILIKE ?", pattern).
+				WhereOr("reservation.confirmation_code ILIKE ?", pattern)
+			if digits != "" {
+				q = q.WhereOr("regexp_replace(reservation.phone, '[^0-9]', '', 'g') LIKE ?", "%"+digits+"%")
+			}
+			return q
+		})
+	}
+	if !query.From.IsZero() {
+		q.Where("reservation.end_date>=?", query.From)
+	}
+	if !query.To.IsZero() {
+		q.Where("reservation.start_date<=?", query.To)
+	}
+	if len(query.IDs) > 0 {
+		q.Where("reservation.id IN (?)", bun.In(query.IDs))
+	}
+	if query.RoomID != 0 {
+		q.Where("reservation.room_id=?", query.RoomID)
+	}
+	if query.Status != "" {
+		q.Where("COALESCE(reservation.status, ?)=?", models.ReservationConfirmed, query.Status)
+	}
+	if query.Source != "" {
+		q.Where("COALESCE(reservation.source, ?)=?", models.SourceWebsite, query.Source)
+	}
+	if query.Tag != "" {
+		q.Where("EXISTS (?)", taggedQuery(db, query.Tag))
+	}
+
+	columns, ok := reservationSorts[query.Sort]
+	if !ok {
+		columns = reservationSorts["arrival"]
+	}
+	for _, column := range columns {
+		if query.Desc {
+			column += " DESC"
+		}
+		q.OrderExpr(column)
+	}
+	q.OrderExpr("reservation.id")
+
+	if query.PerPage > 0 {
+		q.Limit(query.PerPage)
+		if query.Page > 1 {
+			q.Offset((query.Page - 1) * query.PerPage)
+		}
+	}
+	return q
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns, so searched text matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// onlyDigits keeps the digits of s, so phones match however they were typed
+func onlyDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// taggedQuery selects the tag of the reservation selected by the outer query
+func taggedQuery(db bun.IDB, tag string) *bun.SelectQuery {
+	return db.NewSelect().Model((*models.ReservationTag)(nil)).
+		ColumnExpr("1").
+		Where("reservation_tag.reservation_id = reservation.id").
+		Where("reservation_tag.tag = ?", tag)
+}
+
+// balanceQuery sums the folio of the reservation selected by the outer query
+func balanceQuery(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().Model((*models.FolioEntry)(nil)).
+		ColumnExpr("COALESCE(SUM(folio_entry.amount), 0)").
+		Where("folio_entry.reservation_id = reservation.id")
 }
 
-// GetNewReservations search for new reservations
-func (pdb *postgresDB) GetNewReservations() ([]models.Reservation, error) {
+// GetNewReservations returns a page of the unprocessed reservations which aren't cancelled and match the query,
+// and how many of them match it on all pages
+func (pdb *postgresDB) GetNewReservations(query models.ReservationQuery) ([]models.Reservation, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
 	reservations := make([]models.Reservation, 0)
-	err := pdb.DB.NewSelect().Model(&reservations).Relation("Room").Where("is_processed=0").Scan(ctx)
+	q := newReservationsFilter(reservationListQuery(pdb.DB, &reservations, query))
+	count, err := q.ScanAndCount(ctx)
 
-	return reservations, err
+	return reservations, count, err
+}
+
+// newReservationsFilter keeps the unprocessed reservations which aren't cancelled
+func newReservationsFilter(q *bun.SelectQuery) *bun.SelectQuery {
+	return q.Where("reservation.is_processed=0").
+		Where("COALESCE(reservation.status, ?)<>?", models.ReservationConfirmed, models.ReservationCancelled)
+}
+
+// exportTimeout is how long an export may take, the client reads it while it is written
+const exportTimeout = 10 * time.Minute
+
+// ExportReservations calls fn for every reservation matching the query, pages aside, with its balance
+// and what it was charged. Only unprocessed reservations which aren't cancelled are passed when onlyNew is set.
+// Rows are read one at a time, so the whole set is never kept in memory
+func (pdb *postgresDB) ExportReservations(query models.ReservationQuery, onlyNew bool,
+	fn func(res models.Reservation) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), exportTimeout)
+	defer cancel()
+
+	rooms := make([]models.Room, 0)
+	err := pdb.DB.NewSelect().Model(&rooms).Scan(ctx)
+	if err != nil {
+		return err
+	}
+	roomByID := make(map[int]*models.Room, len(rooms))
+	for i := range rooms {
+		roomByID[rooms[i].ID] = &rooms[i]
+	}
+
+	query.Page, query.PerPage = 0, 0
+	q := pdb.DB.NewSelect().Model((*models.Reservation)(nil)).
+		ColumnExpr("reservation.*").
+		ColumnExpr("(?) AS balance", balanceQuery(pdb.DB)).
+		ColumnExpr("(?) AS charged", chargedQuery(pdb.DB)).
+		Join("LEFT JOIN rooms AS room ON room.id = reservation.room_id")
+	q = filterReservations(pdb.DB, q, query)
+	if onlyNew {
+		q = newReservationsFilter(q)
+	}
+	rows, err := q.Rows(ctx)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var res models.Reservation
+		err = pdb.DB.ScanRow(ctx, rows, &res)
+		if err != nil {
+			return err
+		}
+		res.Room = roomByID[res.RoomID]
+		err = fn(res)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// chargedQuery sums what the reservation selected by the outer query was charged, less discounts
+func chargedQuery(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().Model((*models.FolioEntry)(nil)).
+		ColumnExpr("COALESCE(SUM(folio_entry.amount), 0)").
+		Where("folio_entry.reservation_id = reservation.id").
+		Where("folio_entry.entry_type NOT IN (?)", bun.In([]string{models.FolioPayment, models.FolioRefund}))
 }
 
 // GetReservationByID search for reservation by id
@@ -157,29 +379,101 @@ func (pdb *postgresDB) GetReservationByID(id int) (*models.Reservation, error) {
 	defer cancel()
 
 	reservation := new(models.Reservation)
-	err := pdb.DB.NewSelect().Model(reservation).Relation("Room").Where("reservation.id=?", id).Scan(ctx)
+	err := pdb.DB.NewSelect().Model(reservation).
+		Relation("Room").
+		Relation("RatePlan").
+		Relation("CancellationPolicy").
+		Relation("Payments", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("payment.id")
+		}).
+		Relation("FolioEntries", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("folio_entry.id")
+		}).
+		Relation("FolioEntries.User").
+		Relation("Taxes", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_tax.id")
+		}).
+		Relation("AddOns", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_add_on.id")
+		}).
+		Relation("Notes", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_note.created_at DESC", "reservation_note.id DESC")
+		}).
+		Relation("Notes.User").
+		Relation("Tags", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_tag.tag")
+		}).
+		Relation("CheckedInUser").
+		Relation("CheckedOutUser").
+		Relation("Guest").
+		Where("reservation.id=?", id).Scan(ctx)
 
 	return reservation, err
 }
 
+// GetReservationsByConfirmationCode returns reservations booked under the code, a group shares one code
+func (pdb *postgresDB) GetReservationsByConfirmationCode(code string) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0)
+	err := pdb.DB.NewSelect().Model(&reservations).
+		ColumnExpr("reservation.*").
+		ColumnExpr("(?) AS balance", balanceQuery(pdb.DB)).
+		Relation("Room").
+		Where("reservation.confirmation_code=?", code).
+		Order("reservation.start_date", "reservation.id").Scan(ctx)
+
+	return reservations, err
+}
+
+// GetReservationsByIDs returns the reservations with the ids with what it takes to price and cancel them,
+// ids without a reservation are skipped
+func (pdb *postgresDB) GetReservationsByIDs(ids []int) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0, len(ids))
+	if len(ids) == 0 {
+		return reservations, nil
+	}
+	err := pdb.DB.NewSelect().Model(&reservations).
+		Relation("Room").
+		Relation("CancellationPolicy").
+		Relation("Taxes").
+		Relation("AddOns").
+		Where("reservation.id IN (?)", bun.In(ids)).
+		Order("reservation.id").Scan(ctx)
+
+	return reservations, err
+}
+
 // UpdateReservation updates reservation
 func (pdb *postgresDB) UpdateReservation(ur models.Reservation) error {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
-	_, err := pdb.DB.NewUpdate().Model(&ur).
-		Column("first_name", "last_name", "email", "phone").
-		WherePK().Exec(ctx)
-	return err
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&ur).
+			Column("first_name", "last_name", "email", "phone").
+			WherePK().Exec(ctx)
+		return err
+	})
 }
 
-// DeleteReservation deletes reservation
+// DeleteReservationByID moves reservation to the trash, its room restrictions go with it and free the room
 func (pdb *postgresDB) DeleteReservationByID(id int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
-	_, err := pdb.DB.NewDelete().Table("reservations").Where("id=?", id).Exec(ctx)
-	return err
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*models.Reservation)(nil)).Where("id=?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*models.RoomRestriction)(nil)).Where("reservation_id=?", id).Exec(ctx)
+		return err
+	})
 }
 
 // UpdateReservationProcessed updates is_processed field in reservation
@@ -191,8 +485,10 @@ func (pdb *postgresDB) UpdateReservationProcessed(id, processed int) error {
 		ID:          id,
 		IsProcessed: processed,
 	}
-	_, err := pdb.DB.NewUpdate().Model(&modelToUpdate).WherePK().Column("is_processed").Exec(ctx)
-	return err
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&modelToUpdate).WherePK().Column("is_processed").Exec(ctx)
+		return err
+	})
 }
 
 func (pdb *postgresDB) GetAllRooms() ([]models.Room, error) {
@@ -215,7 +511,30 @@ func (pdb *postgresDB) GetRoomRestrictionsByRoomIdWithinDates(roomID int, start,
 		Where("room_restriction.room_id=?", roomID).
 		Where("room_restriction.start_date>=?", start).
 		Where("room_restriction.end_date<?", end).
-		Relation("Reservation").Scan(ctx)
+		Where("room_restriction.restriction_id<>?", models.RestrictionHold).
+		Relation("Reservation").
+		Relation("Reservation.Notes", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_note.created_at DESC", "reservation_note.id DESC")
+		}).
+		Relation("Reservation.Tags", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation_tag.tag")
+		}).Scan(ctx)
+
+	return roomRestrictions, err
+}
+
+// GetActiveRoomRestrictionsWithinDates returns restrictions of all rooms overlapping dates, expired holds excluded
+func (pdb *postgresDB) GetActiveRoomRestrictionsWithinDates(start, end time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var roomRestrictions []models.RoomRestriction
+
+	err := pdb.DB.NewSelect().Model(&roomRestrictions).
+		Where("room_restriction.end_date>?", start).
+		Where("room_restriction.start_date<?", end).
+		Where("(room_restriction.expires_at IS NULL OR room_restriction.expires_at>?)", time.Now()).
+		Scan(ctx)
 
 	return roomRestrictions, err
 }
@@ -231,14 +550,2320 @@ func (pdb *postgresDB) AddSingleDayRoomRestriction(roomID, restrictionID int, st
 		RestrictionID: restrictionID,
 	}
 	var newID int
-	err := pdb.DB.NewInsert().Model(&restriction).Returning("id").Scan(ctx, &newID)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(&restriction).Returning("id").Scan(ctx, &newID)
+	})
 	return newID, err
 }
 
+// DeleteRoomRestrictionByID moves room restriction to the trash
 func (pdb *postgresDB) DeleteRoomRestrictionByID(id int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
 	defer cancel()
 
-	_, err := pdb.DB.NewDelete().Table("room_restrictions").Where("id=?", id).Exec(ctx)
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*models.RoomRestriction)(nil)).Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// InsertReservationGroup inserts a group with all its reservations and room restrictions in one transaction,
+// so nothing is saved if any of the rooms is already taken
+func (pdb *postgresDB) InsertReservationGroup(group *models.ReservationGroup, reservations []models.Reservation) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewInsert().Model(group).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		group.ID = newID
+
+		for i := range reservations {
+			res := &reservations[i]
+			res.GroupID = newID
+			res.ConfirmationCode = group.ConfirmationCode
+			err = guestForReservationInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+			err = tx.NewInsert().Model(res).Returning("id").Scan(ctx, &res.ID)
+			if err != nil {
+				return err
+			}
+
+			err = bookRoomInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+
+			err = chargeStayInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return newID, err
+}
+
+// GetReservationGroupByID search for reservation group by id together with its reservations
+func (pdb *postgresDB) GetReservationGroupByID(id int) (*models.ReservationGroup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	group := new(models.ReservationGroup)
+	err := pdb.DB.NewSelect().Model(group).
+		Relation("Reservations", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation.start_date")
+		}).
+		Relation("Reservations.Room").
+		Where("reservation_group.id=?", id).Scan(ctx)
+
+	return group, err
+}
+
+// UpdateReservationGroup updates contact of a group and of all its reservations
+func (pdb *postgresDB) UpdateReservationGroup(group models.ReservationGroup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&group).
+			Column("first_name", "last_name", "email", "phone").
+			WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("first_name=?", group.FirstName).
+			Set("last_name=?", group.LastName).
+			Set("email=?", group.Email).
+			Set("phone=?", group.Phone).
+			Where("group_id=?", group.ID).Exec(ctx)
+		return err
+	})
+}
+
+// UpdateReservationGroupProcessed updates is_processed field in group and all its reservations
+func (pdb *postgresDB) UpdateReservationGroupProcessed(id, processed int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		modelToUpdate := models.ReservationGroup{
+			ID:          id,
+			IsProcessed: processed,
+		}
+		_, err := tx.NewUpdate().Model(&modelToUpdate).WherePK().Column("is_processed").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("is_processed=?", processed).
+			Where("group_id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// DeleteReservationGroupByID moves reservation group to the trash, its reservations and room restrictions go with it
+func (pdb *postgresDB) DeleteReservationGroupByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*models.ReservationGroup)(nil)).Where("id=?", id).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		reservations := tx.NewSelect().Model((*models.Reservation)(nil)).Column("id").Where("group_id=?", id)
+		_, err = tx.NewDelete().Model((*models.RoomRestriction)(nil)).Where("reservation_id IN (?)", reservations).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Model((*models.Reservation)(nil)).Where("group_id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// bookRoomInTx turns the guest's hold into the reservation's room restriction. If the hold is gone already
+// the room is booked only if it is still free. The room row is locked so concurrent bookings wait for each other
+func bookRoomInTx(ctx context.Context, tx bun.Tx, res *models.Reservation) error {
+	_, err := tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", res.RoomID).For("UPDATE").Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	if res.HoldID != 0 {
+		result, err := tx.NewUpdate().Model((*models.RoomRestriction)(nil)).
+			Set("restriction_id=?", models.RestrictionReservation).
+			Set("reservation_id=?", res.ID).
+			Set("expires_at=NULL").
+			Where("id=?", res.HoldID).
+			Where("restriction_id=?", models.RestrictionHold).
+			Where("room_id=?", res.RoomID).
+			Exec(ctx)
+		if err != nil {
+			return err
+		}
+		converted, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if converted > 0 {
+			return nil
+		}
+	}
+
+	numberRows, err := tx.NewSelect().
+		Table("room_restrictions").
+		Where("deleted_at IS NULL").
+		Where("room_id = ?", res.RoomID).
+		Where("end_date>?", res.StartDate).
+		Where("start_date<?", res.EndDate).
+		Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+		Count(ctx)
+	if err != nil {
+		return err
+	}
+	if numberRows > 0 {
+		return repository.ErrRoomNotAvailable
+	}
+
+	rmrs := models.RoomRestriction{
+		StartDate:     res.StartDate,
+		EndDate:       res.EndDate,
+		RoomID:        res.RoomID,
+		ReservationID: res.ID,
+		RestrictionID: models.RestrictionReservation,
+	}
+	_, err = tx.NewInsert().Model(&rmrs).Exec(ctx)
+	return err
+}
+
+// InsertReservationWithHold inserts a reservation and converts the guest's hold into its room restriction
+func (pdb *postgresDB) InsertReservationWithHold(res *models.Reservation) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := guestForReservationInTx(ctx, tx, res)
+		if err != nil {
+			return err
+		}
+		err = tx.NewInsert().Model(res).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		res.ID = newID
+		err = bookRoomInTx(ctx, tx, res)
+		if err != nil {
+			return err
+		}
+		return chargeStayInTx(ctx, tx, res)
+	})
+	return newID, err
+}
+
+// importTimeout is how long an import may take, files have up to a few thousand rows
+const importTimeout = time.Minute
+
+// ImportReservations books the reservations with their room nights in one transaction.
+// Nothing is saved if one of the rooms is taken on the dates
+func (pdb *postgresDB) ImportReservations(reservations []models.Reservation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		for i := range reservations {
+			res := &reservations[i]
+			err := guestForReservationInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+			err = tx.NewInsert().Model(res).Returning("id").Scan(ctx, &res.ID)
+			if err != nil {
+				return err
+			}
+			err = bookRoomInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+			err = chargeStayInTx(ctx, tx, res)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ImportBlocks inserts the room blocks in one transaction. Nothing is saved if one of the rooms is taken on the dates
+func (pdb *postgresDB) ImportBlocks(blocks []models.RoomRestriction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), importTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		for i := range blocks {
+			block := &blocks[i]
+			_, err := tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", block.RoomID).For("UPDATE").Exec(ctx)
+			if err != nil {
+				return err
+			}
+			numberRows, err := tx.NewSelect().
+				Table("room_restrictions").
+				Where("deleted_at IS NULL").
+				Where("room_id = ?", block.RoomID).
+				Where("end_date>?", block.StartDate).
+				Where("start_date<?", block.EndDate).
+				Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+				Count(ctx)
+			if err != nil {
+				return err
+			}
+			if numberRows > 0 {
+				return repository.ErrRoomNotAvailable
+			}
+			err = tx.NewInsert().Model(block).Returning("id").Scan(ctx, &block.ID)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// HoldRoom places a temporary hold on a room if nobody else has it on the hold's dates
+func (pdb *postgresDB) HoldRoom(hold *models.RoomRestriction) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", hold.RoomID).For("UPDATE").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		numberRows, err := tx.NewSelect().
+			Table("room_restrictions").
+			Where("deleted_at IS NULL").
+			Where("room_id = ?", hold.RoomID).
+			Where("end_date>?", hold.StartDate).
+			Where("start_date<?", hold.EndDate).
+			Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+			Count(ctx)
+		if err != nil {
+			return err
+		}
+		if numberRows > 0 {
+			return repository.ErrRoomNotAvailable
+		}
+		hold.RestrictionID = models.RestrictionHold
+		return tx.NewInsert().Model(hold).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// ExtendRoomHolds moves expiration of still active holds
+func (pdb *postgresDB) ExtendRoomHolds(ids []int, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	_, err := pdb.DB.NewUpdate().Model((*models.RoomRestriction)(nil)).
+		Set("expires_at=?", expiresAt).
+		Where("id IN (?)", bun.In(ids)).
+		Where("restriction_id=?", models.RestrictionHold).
+		Where("expires_at>?", time.Now()).
+		Exec(ctx)
+	return err
+}
+
+// ReleaseRoomHold deletes a hold, reservations and blocks are never touched
+func (pdb *postgresDB) ReleaseRoomHold(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	_, err := pdb.DB.NewDelete().Table("room_restrictions").
+		Where("id=?", id).
+		Where("restriction_id=?", models.RestrictionHold).
+		Exec(ctx)
+	return err
+}
+
+// DeleteExpiredRoomHolds deletes all expired holds and returns how many were released
+func (pdb *postgresDB) DeleteExpiredRoomHolds() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	result, err := pdb.DB.NewDelete().Table("room_restrictions").
+		Where("restriction_id=?", models.RestrictionHold).
+		Where("expires_at<=?", time.Now()).
+		Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	released, err := result.RowsAffected()
+	return int(released), err
+}
+
+// InsertWaitlistEntry inserts a waitlist entry
+func (pdb *postgresDB) InsertWaitlistEntry(entry *models.WaitlistEntry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(entry).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// GetWaitlistEntryByID search for waitlist entry by id
+func (pdb *postgresDB) GetWaitlistEntryByID(id int) (*models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entry := new(models.WaitlistEntry)
+	err := pdb.DB.NewSelect().Model(entry).Relation("Room").Where("waitlist_entry.id=?", id).Scan(ctx)
+
+	return entry, err
+}
+
+// GetAllWaitlistEntries returns the whole waitlist in the order guests joined it
+func (pdb *postgresDB) GetAllWaitlistEntries() ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entries := make([]models.WaitlistEntry, 0)
+	err := pdb.DB.NewSelect().Model(&entries).Relation("Room").
+		Order("waitlist_entry.created_at", "waitlist_entry.id").Scan(ctx)
+
+	return entries, err
+}
+
+// GetWaitingEntriesForRoom returns not yet notified entries overlapping dates which the room can serve,
+// oldest first
+func (pdb *postgresDB) GetWaitingEntriesForRoom(roomID int, start, end time.Time) ([]models.WaitlistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entries := make([]models.WaitlistEntry, 0)
+	err := pdb.DB.NewSelect().Model(&entries).
+		Where("waitlist_entry.notified_at IS NULL").
+		Where("(waitlist_entry.room_id IS NULL OR waitlist_entry.room_id=?)", roomID).
+		Where("waitlist_entry.end_date>?", start).
+		Where("waitlist_entry.start_date<?", end).
+		Order("waitlist_entry.created_at", "waitlist_entry.id").Scan(ctx)
+
+	return entries, err
+}
+
+// MarkWaitlistEntryNotified stores the time the guest was emailed about a free room
+func (pdb *postgresDB) MarkWaitlistEntryNotified(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	modelToUpdate := models.WaitlistEntry{
+		ID:         id,
+		NotifiedAt: time.Now(),
+	}
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&modelToUpdate).WherePK().Column("notified_at").Exec(ctx)
+		return err
+	})
+}
+
+// DeleteWaitlistEntryByID deletes waitlist entry
+func (pdb *postgresDB) DeleteWaitlistEntryByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("waitlist_entries").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// CancelReservation marks reservation as cancelled and frees its room. Room nights, taxes and add-ons
+// charged to the folio are reversed and the fee is charged instead
+func (pdb *postgresDB) CancelReservation(id, fee int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return cancelReservationInTx(ctx, tx, id, fee)
+	})
+}
+
+// cancelReservationInTx cancels the reservation as CancelReservation does
+func cancelReservationInTx(ctx context.Context, tx bun.Tx, id, fee int) error {
+	result, err := tx.NewUpdate().Model((*models.Reservation)(nil)).
+		Set("status=?", models.ReservationCancelled).
+		Set("cancelled_at=?", time.Now()).
+		Set("cancellation_fee=?", fee).
+		Where("id=?", id).
+		Where("status<>?", models.ReservationCancelled).Exec(ctx)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrReservationCancelled
+	}
+	_, err = tx.NewDelete().Table("room_restrictions").Where("reservation_id=?", id).Exec(ctx)
+	if err != nil {
+		return err
+	}
+
+	roomCharges, err := folioTotalInTx(ctx, tx, id, models.FolioRoom)
+	if err != nil {
+		return err
+	}
+	taxCharges, err := folioTotalInTx(ctx, tx, id, models.FolioTax)
+	if err != nil {
+		return err
+	}
+	addOnCharges, err := folioTotalInTx(ctx, tx, id, models.FolioAddOn)
+	if err != nil {
+		return err
+	}
+	entries := make([]models.FolioEntry, 0, 4)
+	if roomCharges != 0 {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: id,
+			EntryType:     models.FolioRoom,
+			Description:   "Room nights cancelled",
+			Amount:        -roomCharges,
+		})
+	}
+	if taxCharges != 0 {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: id,
+			EntryType:     models.FolioTax,
+			Description:   "Taxes cancelled",
+			Amount:        -taxCharges,
+		})
+	}
+	if addOnCharges != 0 {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: id,
+			EntryType:     models.FolioAddOn,
+			Description:   "Add-ons cancelled",
+			Amount:        -addOnCharges,
+		})
+	}
+	if fee > 0 {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: id,
+			EntryType:     models.FolioCancellationFee,
+			Description:   "Cancellation fee",
+			Amount:        fee,
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err = tx.NewInsert().Model(&entries).Exec(ctx)
 	return err
 }
+
+// BulkUpdateReservations makes the change to the reservations in one transaction. Reservations the change
+// can't be made to, like cancelled ones which can't be processed or no-shows whose room is taken since,
+// are left as they are and returned with the reason, any other error saves nothing
+func (pdb *postgresDB) BulkUpdateReservations(ids []int, change models.BulkChange) (map[int]error, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var failed map[int]error
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		failed = make(map[int]error)
+		found := make([]int, 0, len(ids))
+		err := tx.NewSelect().Model((*models.Reservation)(nil)).Column("id").
+			Where("id IN (?)", bun.In(ids)).
+			Order("id").For("UPDATE").Scan(ctx, &found)
+		if err != nil {
+			return err
+		}
+		exists := make(map[int]bool, len(found))
+		for _, id := range found {
+			exists[id] = true
+		}
+		for _, id := range ids {
+			if !exists[id] {
+				failed[id] = repository.ErrReservationNotFound
+			}
+		}
+		if len(found) == 0 {
+			return nil
+		}
+
+		switch change.Action {
+		case models.BulkProcess:
+			processed := make([]int, 0, len(found))
+			err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+				Set("is_processed=1").
+				Where("id IN (?)", bun.In(found)).
+				Where("status<>?", models.ReservationCancelled).
+				Where("deleted_at IS NULL").
+				Returning("id").Scan(ctx, &processed)
+			if err != nil {
+				return err
+			}
+			done := make(map[int]bool, len(processed))
+			for _, id := range processed {
+				done[id] = true
+			}
+			for _, id := range found {
+				if !done[id] {
+					failed[id] = repository.ErrReservationCancelled
+				}
+			}
+			return nil
+		case models.BulkConfirm:
+			var reservations []models.Reservation
+			err = tx.NewSelect().Model(&reservations).
+				Column("id", "room_id", "status", "start_date", "end_date").
+				Where("id IN (?)", bun.In(found)).
+				Order("id").Scan(ctx)
+			if err != nil {
+				return err
+			}
+			for i := range reservations {
+				res := &reservations[i]
+				switch res.Status {
+				case models.ReservationCancelled:
+					failed[res.ID] = repository.ErrReservationCancelled
+					continue
+				case models.ReservationNoShow:
+					// the no-show sweep freed the room, the guest gets it back only if nobody took it since
+					err = bookRoomInTx(ctx, tx, res)
+					if errors.Is(err, repository.ErrRoomNotAvailable) {
+						failed[res.ID] = err
+						continue
+					}
+					if err != nil {
+						return err
+					}
+				}
+				_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+					Set("status=?", models.ReservationConfirmed).
+					Set("is_processed=1").
+					Where("id=?", res.ID).
+					Exec(ctx)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		case models.BulkCancel:
+			for _, id := range found {
+				err = cancelReservationInTx(ctx, tx, id, change.Fees[id])
+				if errors.Is(err, repository.ErrReservationCancelled) {
+					failed[id] = err
+					continue
+				}
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		case models.BulkTag:
+			tags := make([]models.ReservationTag, len(found))
+			for i, id := range found {
+				tags[i] = models.ReservationTag{ReservationID: id, Tag: change.Tag}
+			}
+			_, err = tx.NewInsert().Model(&tags).On("CONFLICT DO NOTHING").Exec(ctx)
+			return err
+		default:
+			return fmt.Errorf("unknown bulk action %q", change.Action)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return failed, nil
+}
+
+// folioTotalInTx sums folio entries of the type posted to the reservation
+func folioTotalInTx(ctx context.Context, tx bun.Tx, reservationID int, entryType string) (int, error) {
+	var total int
+	err := tx.NewSelect().Model((*models.FolioEntry)(nil)).
+		ColumnExpr("COALESCE(SUM(amount), 0)").
+		Where("reservation_id=?", reservationID).
+		Where("entry_type=?", entryType).Scan(ctx, &total)
+	return total, err
+}
+
+// MoveReservation changes dates and room of a booked reservation. The room has to be free on the new dates
+// apart from the reservation itself, its room restriction moves along with it. Taxes and add-ons are worked out
+// again and the folio gets the difference in room nights, taxes and add-ons, posted on behalf of the user.
+// Cancelled, no-show and checked out reservations can't be moved
+func (pdb *postgresDB) MoveReservation(res models.Reservation, userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		old := new(models.Reservation)
+		err := tx.NewSelect().Model(old).Where("id=?", res.ID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		switch {
+		case old.Status == models.ReservationCancelled:
+			return repository.ErrReservationCancelled
+		case old.Status == models.ReservationNoShow:
+			return repository.ErrReservationNoShow
+		case !old.CheckedOutAt.IsZero():
+			return repository.ErrAlreadyCheckedOut
+		}
+
+		_, err = tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", res.RoomID).For("UPDATE").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		numberRows, err := tx.NewSelect().
+			Table("room_restrictions").
+			Where("deleted_at IS NULL").
+			Where("room_id = ?", res.RoomID).
+			Where("end_date>?", res.StartDate).
+			Where("start_date<?", res.EndDate).
+			Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+			Where("(reservation_id IS NULL OR reservation_id<>?)", res.ID).
+			Count(ctx)
+		if err != nil {
+			return err
+		}
+		if numberRows > 0 {
+			return repository.ErrRoomNotAvailable
+		}
+
+		_, err = tx.NewUpdate().Model(&res).
+			Column("start_date", "end_date", "room_id", "rate_plan_id", "nightly_rate", "cancellation_policy_id").
+			WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		result, err := tx.NewUpdate().Model((*models.RoomRestriction)(nil)).
+			Set("start_date=?", res.StartDate).
+			Set("end_date=?", res.EndDate).
+			Set("room_id=?", res.RoomID).
+			Where("reservation_id=?", res.ID).
+			Where("restriction_id=?", models.RestrictionReservation).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if moved == 0 {
+			rmrs := models.RoomRestriction{
+				StartDate:     res.StartDate,
+				EndDate:       res.EndDate,
+				RoomID:        res.RoomID,
+				ReservationID: res.ID,
+				RestrictionID: models.RestrictionReservation,
+			}
+			_, err = tx.NewInsert().Model(&rmrs).Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.NewDelete().Model((*models.ReservationTax)(nil)).Where("reservation_id=?", res.ID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		taxes := make([]models.Tax, 0)
+		err = tx.NewSelect().Model(&taxes).Order("id").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		res.Taxes = pricing.ApplyTaxes(res, taxes)
+		if len(res.Taxes) > 0 {
+			_, err = tx.NewInsert().Model(&res.Taxes).Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		res.AddOns, err = repriceAddOnsInTx(ctx, tx, *old, res)
+		if err != nil {
+			return err
+		}
+
+		roomCharges, err := folioTotalInTx(ctx, tx, res.ID, models.FolioRoom)
+		if err != nil {
+			return err
+		}
+		taxCharges, err := folioTotalInTx(ctx, tx, res.ID, models.FolioTax)
+		if err != nil {
+			return err
+		}
+		addOnCharges, err := folioTotalInTx(ctx, tx, res.ID, models.FolioAddOn)
+		if err != nil {
+			return err
+		}
+		entries := make([]models.FolioEntry, 0, 3)
+		if entry := pricing.RoomCharge(res); entry.Amount != roomCharges {
+			entry.Description = "Stay changed to " + entry.Description
+			entry.Amount -= roomCharges
+			entry.UserID = userID
+			entries = append(entries, entry)
+		}
+		newTaxes := 0
+		for _, entry := range pricing.TaxCharges(res) {
+			newTaxes += entry.Amount
+		}
+		if newTaxes != taxCharges {
+			entries = append(entries, models.FolioEntry{
+				ReservationID: res.ID,
+				EntryType:     models.FolioTax,
+				Description:   "Taxes recalculated for the new stay",
+				Amount:        newTaxes - taxCharges,
+				UserID:        userID,
+			})
+		}
+		if newAddOns := pricing.AddOnsTotal(res); newAddOns != addOnCharges {
+			entries = append(entries, models.FolioEntry{
+				ReservationID: res.ID,
+				EntryType:     models.FolioAddOn,
+				Description:   "Add-ons recalculated for the new stay",
+				Amount:        newAddOns - addOnCharges,
+				UserID:        userID,
+			})
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+		_, err = tx.NewInsert().Model(&entries).Exec(ctx)
+		return err
+	})
+}
+
+// repriceAddOnsInTx charges the add-ons sold with the stay of old for the stay of res and returns them.
+// Items of add-ons which are gone keep their amount, as nothing tells how they were charged
+func repriceAddOnsInTx(ctx context.Context, tx bun.Tx, old, res models.Reservation) ([]models.ReservationAddOn, error) {
+	items := make([]models.ReservationAddOn, 0)
+	err := tx.NewSelect().Model(&items).Where("reservation_id=?", res.ID).Order("id").Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, item := range items {
+		if item.AddOnID == 0 {
+			continue
+		}
+		addOn := new(models.AddOn)
+		err = tx.NewSelect().Model(addOn).Where("id=?", item.AddOnID).Scan(ctx)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		items[i] = pricing.RepriceAddOn(old, res, *addOn, item)
+		if items[i].Amount == item.Amount {
+			continue
+		}
+		_, err = tx.NewUpdate().Model(&items[i]).Column("amount").WherePK().Exec(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+// GetFrontDeskReservations returns confirmed reservations the front desk has to deal with on the day: guests due
+// by the day who haven't come yet and guests who are in
+func (pdb *postgresDB) GetFrontDeskReservations(day time.Time) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0)
+	err := pdb.DB.NewSelect().Model(&reservations).
+		ColumnExpr("reservation.*").
+		ColumnExpr("(?) AS balance", balanceQuery(pdb.DB)).
+		Relation("Room").
+		Where("reservation.status=?", models.ReservationConfirmed).
+		Where("reservation.checked_out_at IS NULL").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("reservation.checked_in_at IS NOT NULL").
+				WhereOr("reservation.start_date<=? AND reservation.end_date>?", day, day)
+		}).
+		Order("reservation.start_date", "reservation.id").Scan(ctx)
+
+	return reservations, err
+}
+
+// GetReportReservations returns reservations with a night from the night of from to the night of to,
+// cancelled ones included
+func (pdb *postgresDB) GetReportReservations(from, to time.Time) ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0)
+	err := pdb.DB.NewSelect().Model(&reservations).
+		Where("start_date<=?", to).
+		Where("end_date>?", from).
+		Order("start_date", "id").Scan(ctx)
+
+	return reservations, err
+}
+
+// GetRoomClosures returns owner blocks and out of order periods with a night from the night of from
+// to the night of to, rooms can't be sold on their nights
+func (pdb *postgresDB) GetRoomClosures(from, to time.Time) ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	restrictions := make([]models.RoomRestriction, 0)
+	err := pdb.DB.NewSelect().Model(&restrictions).
+		Where("restriction_id IN (?)", bun.In([]int{models.RestrictionOwnerBlock, models.RestrictionOutOfOrder})).
+		Where("start_date<=?", to).
+		Where("end_date>?", from).
+		Order("start_date", "id").Scan(ctx)
+
+	return restrictions, err
+}
+
+// lockStayInTx locks the reservation for a change of its stay and checks it is still confirmed
+func lockStayInTx(ctx context.Context, tx bun.Tx, id int) (*models.Reservation, error) {
+	res := new(models.Reservation)
+	err := tx.NewSelect().Model(res).Where("id=?", id).For("UPDATE").Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch res.Status {
+	case models.ReservationCancelled:
+		return nil, repository.ErrReservationCancelled
+	case models.ReservationNoShow:
+		return nil, repository.ErrReservationNoShow
+	}
+	return res, nil
+}
+
+// CheckInReservation records the guest came at the time and was checked in by the user
+func (pdb *postgresDB) CheckInReservation(id, userID int, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		res, err := lockStayInTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if !res.CheckedInAt.IsZero() {
+			return repository.ErrAlreadyCheckedIn
+		}
+		res.CheckedInAt = at
+		res.CheckedInBy = userID
+		_, err = tx.NewUpdate().Model(res).Column("checked_in_at", "checked_in_by").WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// CheckOutReservation records the guest left at the time and was checked out by the user. When the guest
+// leaves before the departure day the room is freed from that day on
+func (pdb *postgresDB) CheckOutReservation(id, userID int, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		res, err := lockStayInTx(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if res.CheckedInAt.IsZero() {
+			return repository.ErrNotCheckedIn
+		}
+		if !res.CheckedOutAt.IsZero() {
+			return repository.ErrAlreadyCheckedOut
+		}
+		res.CheckedOutAt = at
+		res.CheckedOutBy = userID
+		_, err = tx.NewUpdate().Model(res).Column("checked_out_at", "checked_out_by").WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		room := models.Room{
+			ID:                    res.RoomID,
+			HousekeepingStatus:    models.HousekeepingDirty,
+			HousekeepingUpdatedAt: at,
+			HousekeepingUpdatedBy: userID,
+		}
+		_, err = tx.NewUpdate().Model(&room).
+			Column("housekeeping_status", "housekeeping_updated_at", "housekeeping_updated_by").
+			WherePK().
+			Where("housekeeping_status<>?", models.HousekeepingOutOfOrder).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		left := frontdesk.DateOf(at)
+		if !left.Before(res.EndDate) {
+			return nil
+		}
+		if !left.After(res.StartDate) {
+			left = res.StartDate.AddDate(0, 0, 1)
+		}
+		_, err = tx.NewUpdate().Model((*models.RoomRestriction)(nil)).
+			Set("end_date=?", left).
+			Where("reservation_id=?", id).
+			Where("restriction_id=?", models.RestrictionReservation).Exec(ctx)
+		return err
+	})
+}
+
+// MarkNoShows marks confirmed reservations arriving on the day whose guests haven't checked in as no-shows
+// and frees their rooms. Earlier stays are left alone, see frontdesk.NoShow. Returns how many were marked
+func (pdb *postgresDB) MarkNoShows(day time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var ids []int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		ids = nil
+		var arrivals []models.Reservation
+		err := tx.NewSelect().Model(&arrivals).
+			Column("id", "status", "start_date", "end_date", "checked_in_at", "checked_out_at").
+			Where("status=?", models.ReservationConfirmed).
+			Where("checked_in_at IS NULL").
+			Where("start_date=?", day).
+			For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		for _, res := range arrivals {
+			if frontdesk.NoShow(res, day) {
+				ids = append(ids, res.ID)
+			}
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("status=?", models.ReservationNoShow).
+			Where("id IN (?)", bun.In(ids)).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewDelete().Table("room_restrictions").Where("reservation_id IN (?)", bun.In(ids)).Exec(ctx)
+		return err
+	})
+	return len(ids), err
+}
+
+// GetRoomsWithRates returns all rooms with their cancellation policies and rate plans
+func (pdb *postgresDB) GetRoomsWithRates() ([]models.Room, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var rooms []models.Room
+	err := pdb.DB.NewSelect().Model(&rooms).
+		Relation("CancellationPolicy").
+		Relation("RatePlans", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("rate_plan.price")
+		}).
+		Relation("RatePlans.CancellationPolicy").
+		Order("room.id").Scan(ctx)
+	return rooms, err
+}
+
+// UpdateRoomRates updates price and cancellation policy of a room
+func (pdb *postgresDB) UpdateRoomRates(room models.Room) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&room).
+			Column("price", "cancellation_policy_id").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// UpdateHousekeepingStatus sets the housekeeping status of the room. A room cleaned or inspected has its
+// open housekeeping tasks done by the user
+func (pdb *postgresDB) UpdateHousekeepingStatus(roomID int, status string, userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		room := models.Room{
+			ID:                    roomID,
+			HousekeepingStatus:    status,
+			HousekeepingUpdatedAt: time.Now(),
+			HousekeepingUpdatedBy: userID,
+		}
+		result, err := tx.NewUpdate().Model(&room).
+			Column("housekeeping_status", "housekeeping_updated_at", "housekeeping_updated_by").
+			WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return sql.ErrNoRows
+		}
+		if status != models.HousekeepingClean && status != models.HousekeepingInspected {
+			return nil
+		}
+		done := models.HousekeepingTask{CompletedAt: room.HousekeepingUpdatedAt, CompletedBy: userID}
+		_, err = tx.NewUpdate().Model(&done).
+			Column("completed_at", "completed_by").
+			Where("room_id=?", roomID).
+			Where("completed_at IS NULL").Exec(ctx)
+		return err
+	})
+}
+
+// GenerateHousekeepingTasks adds the day's tasks: a full clean of rooms guests leave on the day and a tidy of
+// rooms guests stay on in. Tasks already there are kept, so it is safe to run more than once a day.
+// Returns how many tasks were added
+func (pdb *postgresDB) GenerateHousekeepingTasks(day time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0)
+	err := pdb.DB.NewSelect().Model(&reservations).
+		Where("status=?", models.ReservationConfirmed).
+		Where("start_date<?", day).
+		Where("end_date>=?", day).
+		Where("(checked_out_at IS NULL OR checked_out_at>=?)", day).
+		Order("end_date").Scan(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(reservations) == 0 {
+		return 0, nil
+	}
+
+	// a room with a departure and a stay-over on the same day needs the full clean, departures come first
+	tasks := make([]models.HousekeepingTask, 0, len(reservations))
+	seen := make(map[int]bool)
+	for _, res := range reservations {
+		if seen[res.RoomID] {
+			continue
+		}
+		seen[res.RoomID] = true
+		task := models.HousekeepingTask{
+			RoomID:        res.RoomID,
+			TaskDate:      day,
+			TaskType:      models.TaskStayOver,
+			ReservationID: res.ID,
+		}
+		if res.EndDate.Equal(day) {
+			task.TaskType = models.TaskDeparture
+		}
+		tasks = append(tasks, task)
+	}
+
+	result, err := pdb.DB.NewInsert().Model(&tasks).
+		On("CONFLICT (room_id, task_date) DO NOTHING").
+		Returning("NULL").Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	added, err := result.RowsAffected()
+	return int(added), err
+}
+
+// GetHousekeepingTasks returns tasks of the day with their rooms and guests
+func (pdb *postgresDB) GetHousekeepingTasks(day time.Time) ([]models.HousekeepingTask, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tasks := make([]models.HousekeepingTask, 0)
+	err := pdb.DB.NewSelect().Model(&tasks).
+		Relation("Room").
+		Relation("Reservation").
+		Where("housekeeping_task.task_date=?", day).
+		Order("housekeeping_task.room_id").Scan(ctx)
+	return tasks, err
+}
+
+// InsertOutOfOrderPeriod takes the room out of order for the period and blocks it with a room restriction,
+// if nothing else is booked on the room then
+func (pdb *postgresDB) InsertOutOfOrderPeriod(period *models.OutOfOrderPeriod) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", period.RoomID).For("UPDATE").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		numberRows, err := tx.NewSelect().
+			Table("room_restrictions").
+			Where("deleted_at IS NULL").
+			Where("room_id = ?", period.RoomID).
+			Where("end_date>?", period.StartDate).
+			Where("start_date<?", period.EndDate).
+			Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+			Count(ctx)
+		if err != nil {
+			return err
+		}
+		if numberRows > 0 {
+			return repository.ErrRoomNotAvailable
+		}
+
+		err = tx.NewInsert().Model(period).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		rmrs := models.RoomRestriction{
+			StartDate:     period.StartDate,
+			EndDate:       period.EndDate,
+			RoomID:        period.RoomID,
+			RestrictionID: models.RestrictionOutOfOrder,
+			OutOfOrderID:  newID,
+		}
+		_, err = tx.NewInsert().Model(&rmrs).Exec(ctx)
+		return err
+	})
+	return newID, err
+}
+
+// GetOutOfOrderPeriodByID returns the out of order period
+func (pdb *postgresDB) GetOutOfOrderPeriodByID(id int) (*models.OutOfOrderPeriod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	period := new(models.OutOfOrderPeriod)
+	err := pdb.DB.NewSelect().Model(period).Where("id=?", id).Scan(ctx)
+	return period, err
+}
+
+// GetOutOfOrderPeriods returns out of order periods which are not over by the day, with their rooms
+func (pdb *postgresDB) GetOutOfOrderPeriods(from time.Time) ([]models.OutOfOrderPeriod, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	periods := make([]models.OutOfOrderPeriod, 0)
+	err := pdb.DB.NewSelect().Model(&periods).
+		Relation("Room").
+		Where("out_of_order_period.end_date>?", from).
+		Order("out_of_order_period.start_date", "out_of_order_period.id").Scan(ctx)
+	return periods, err
+}
+
+// DeleteOutOfOrderPeriodByID deletes the out of order period, its room restriction goes with it
+func (pdb *postgresDB) DeleteOutOfOrderPeriodByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("out_of_order_periods").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// MarkOutOfOrderRooms sets the out of order status on rooms with an out of order period on the day.
+// Rooms are not put back in service when the period ends, housekeeping does it after checking the room.
+// Returns how many rooms were marked
+func (pdb *postgresDB) MarkOutOfOrderRooms(day time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	periods := pdb.DB.NewSelect().
+		Table("out_of_order_periods").
+		Column("room_id").
+		Where("start_date<=?", day).
+		Where("end_date>?", day)
+	result, err := pdb.DB.NewUpdate().Model((*models.Room)(nil)).
+		Set("housekeeping_status=?", models.HousekeepingOutOfOrder).
+		Set("housekeeping_updated_at=?", time.Now()).
+		Set("housekeeping_updated_by=NULL").
+		Where("id IN (?)", periods).
+		Where("housekeeping_status<>?", models.HousekeepingOutOfOrder).Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := result.RowsAffected()
+	return int(marked), err
+}
+
+// guestForReservationInTx links a new reservation to the guest's profile. Guests are known by email, or by
+// phone and last name when they leave no email. A guest booking for the first time gets a new profile
+func guestForReservationInTx(ctx context.Context, tx bun.Tx, res *models.Reservation) error {
+	if res.GuestID != 0 {
+		return nil
+	}
+
+	email := strings.TrimSpace(res.Email)
+	phone := strings.TrimSpace(res.Phone)
+	if email != "" || phone != "" {
+		guest := new(models.Guest)
+		q := tx.NewSelect().Model(guest).Order("guest.id").Limit(1)
+		if email != "" {
+			q.Where("lower(guest.email)=lower(?)", email)
+		} else {
+			q.Where("guest.phone=?", phone).Where("lower(guest.last_name)=lower(?)", strings.TrimSpace(res.LastName))
+		}
+		err := q.Scan(ctx)
+		if err == nil {
+			res.GuestID = guest.ID
+			if guest.Phone == "" && phone != "" {
+				guest.Phone = phone
+				_, err = tx.NewUpdate().Model(guest).Column("phone").WherePK().Exec(ctx)
+			}
+			return err
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	guest := models.Guest{
+		FirstName: strings.TrimSpace(res.FirstName),
+		LastName:  strings.TrimSpace(res.LastName),
+		Email:     email,
+		Phone:     phone,
+	}
+	return tx.NewInsert().Model(&guest).Returning("id").Scan(ctx, &res.GuestID)
+}
+
+// guestStaysQuery counts confirmed stays of the guest selected by the outer query
+func guestStaysQuery(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().Model((*models.Reservation)(nil)).
+		ColumnExpr("count(*)").
+		Where("reservation.guest_id = guest.id").
+		Where("reservation.status = ?", models.ReservationConfirmed)
+}
+
+// lifetimeValueQuery sums everything the guest selected by the outer query was charged, less discounts
+func lifetimeValueQuery(db bun.IDB) *bun.SelectQuery {
+	return db.NewSelect().Model((*models.FolioEntry)(nil)).
+		ColumnExpr("COALESCE(SUM(folio_entry.amount), 0)").
+		Join("JOIN reservations AS reservation ON reservation.id = folio_entry.reservation_id").
+		Where("reservation.guest_id = guest.id").
+		Where("reservation.deleted_at IS NULL").
+		Where("folio_entry.entry_type NOT IN (?)", bun.In([]string{models.FolioPayment, models.FolioRefund}))
+}
+
+// GetAllGuests returns all guests with their stays and lifetime value
+func (pdb *postgresDB) GetAllGuests() ([]models.Guest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	guests := make([]models.Guest, 0)
+	err := pdb.DB.NewSelect().Model(&guests).
+		ColumnExpr("guest.*").
+		ColumnExpr("(?) AS stays", guestStaysQuery(pdb.DB)).
+		ColumnExpr("(?) AS lifetime_value", lifetimeValueQuery(pdb.DB)).
+		Order("guest.last_name", "guest.first_name", "guest.id").Scan(ctx)
+	return guests, err
+}
+
+// GetGuestByID returns the guest with the stay history, latest stays first
+func (pdb *postgresDB) GetGuestByID(id int) (*models.Guest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	guest := new(models.Guest)
+	err := pdb.DB.NewSelect().Model(guest).
+		ColumnExpr("guest.*").
+		ColumnExpr("(?) AS stays", guestStaysQuery(pdb.DB)).
+		ColumnExpr("(?) AS lifetime_value", lifetimeValueQuery(pdb.DB)).
+		Relation("Reservations", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("reservation.start_date DESC", "reservation.id DESC")
+		}).
+		Relation("Reservations.Room").
+		Relation("Reservations.Taxes").
+		Relation("Reservations.AddOns").
+		Relation("Merges", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Order("guest_merge.created_at DESC", "guest_merge.id DESC")
+		}).
+		Relation("Merges.User").
+		Where("guest.id=?", id).Scan(ctx)
+	return guest, err
+}
+
+// UpdateGuest updates contact details, preferences, notes and the VIP flag of the guest
+func (pdb *postgresDB) UpdateGuest(guest models.Guest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&guest).
+			Column("first_name", "last_name", "email", "phone", "preferences", "notes", "vip").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// InsertReservationNote inserts an internal note on a reservation
+func (pdb *postgresDB) InsertReservationNote(note *models.ReservationNote) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(note).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// AddReservationTag tags a reservation, adding a tag it already has does nothing
+func (pdb *postgresDB) AddReservationTag(reservationID int, tag string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewInsert().Model(&models.ReservationTag{ReservationID: reservationID, Tag: tag}).
+			On("CONFLICT DO NOTHING").Exec(ctx)
+		return err
+	})
+}
+
+// RemoveReservationTag removes the tag from a reservation
+func (pdb *postgresDB) RemoveReservationTag(reservationID int, tag string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Model((*models.ReservationTag)(nil)).
+			Where("reservation_id=?", reservationID).
+			Where("tag=?", tag).Exec(ctx)
+		return err
+	})
+}
+
+// GetAllTags returns every tag in use, in alphabetical order
+func (pdb *postgresDB) GetAllTags() ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	tags := make([]string, 0)
+	err := pdb.DB.NewSelect().Model((*models.ReservationTag)(nil)).
+		Distinct().
+		Column("tag").
+		Order("tag").Scan(ctx, &tags)
+	return tags, err
+}
+
+// MergeGuests merges the duplicate profile mergedID into survivor. The survivor gets the details picked by the
+// admin, the reservations and earlier merges of the duplicate move over to it, the merge is recorded and the
+// duplicate is deleted
+func (pdb *postgresDB) MergeGuests(survivor models.Guest, mergedID int, userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		merged := new(models.Guest)
+		err := tx.NewSelect().Model(merged).Where("guest.id=?", mergedID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.NewUpdate().Model(&survivor).
+			Column("first_name", "last_name", "email", "phone", "preferences", "notes", "vip").
+			WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return sql.ErrNoRows
+		}
+
+		result, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("guest_id=?", survivor.ID).
+			Where("guest_id=?", mergedID).
+			WhereAllWithDeleted().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewUpdate().Model((*models.GuestMerge)(nil)).
+			Set("guest_id=?", survivor.ID).
+			Where("guest_id=?", mergedID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		merge := models.GuestMerge{
+			GuestID:       survivor.ID,
+			MergedGuestID: mergedID,
+			MergedName:    strings.TrimSpace(merged.FirstName + " " + merged.LastName),
+			MergedEmail:   merged.Email,
+			MergedPhone:   merged.Phone,
+			Reservations:  int(moved),
+			MergedBy:      userID,
+		}
+		_, err = tx.NewInsert().Model(&merge).Returning("NULL").Exec(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.NewDelete().Model(merged).WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// InsertCancellationPolicy inserts a cancellation policy
+func (pdb *postgresDB) InsertCancellationPolicy(policy *models.CancellationPolicy) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(policy).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// GetAllCancellationPolicies returns all cancellation policies
+func (pdb *postgresDB) GetAllCancellationPolicies() ([]models.CancellationPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	policies := make([]models.CancellationPolicy, 0)
+	err := pdb.DB.NewSelect().Model(&policies).Order("id").Scan(ctx)
+	return policies, err
+}
+
+// UpdateCancellationPolicy updates cancellation policy
+func (pdb *postgresDB) UpdateCancellationPolicy(policy models.CancellationPolicy) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&policy).
+			Column("policy_name", "free_days", "fee_type", "fee_percent").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// InsertRatePlan inserts a rate plan
+func (pdb *postgresDB) InsertRatePlan(plan *models.RatePlan) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(plan).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// UpdateRatePlan updates name, price, cancellation policy and deposit of a rate plan
+func (pdb *postgresDB) UpdateRatePlan(plan models.RatePlan) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&plan).
+			Column("plan_name", "price", "cancellation_policy_id", "deposit_type", "deposit_percent").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// DeleteRatePlanByID deletes rate plan, reservations made on it keep their rate
+func (pdb *postgresDB) DeleteRatePlanByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("rate_plans").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// InsertPayment inserts a payment and sets its status as payment status of the reservation,
+// a captured payment is posted to the folio
+func (pdb *postgresDB) InsertPayment(payment *models.Payment) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		err := tx.NewInsert().Model(payment).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("payment_status=?", payment.Status).
+			Where("id=?", payment.ReservationID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return postPaymentInTx(ctx, tx, models.Payment{}, *payment)
+	})
+	return newID, err
+}
+
+// GetPaymentByID search for payment by id
+func (pdb *postgresDB) GetPaymentByID(id int) (*models.Payment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	payment := new(models.Payment)
+	err := pdb.DB.NewSelect().Model(payment).Where("id=?", id).Scan(ctx)
+	return payment, err
+}
+
+// UpdatePayment updates status and refunded amount of a payment together with payment status of the reservation,
+// money which changed hands is posted to the folio
+func (pdb *postgresDB) UpdatePayment(payment models.Payment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		var old models.Payment
+		err := tx.NewSelect().Model(&old).Where("id=?", payment.ID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		payment.ReservationID = old.ReservationID
+		payment.Amount = old.Amount
+		payment.Reference = old.Reference
+
+		_, err = tx.NewUpdate().Model(&payment).
+			Column("status", "refunded").
+			WherePK().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("payment_status=?", payment.Status).
+			Where("id=?", payment.ReservationID).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		return postPaymentInTx(ctx, tx, old, payment)
+	})
+}
+
+// UpdatePaymentStatusByReference sets status of all payments with the gateway reference and of their reservations.
+// A refund returns amount, spread over the payments in order, and a payment is refunded once nothing is left on it.
+// Payments whose money was taken can't be voided. Money which changed hands is posted to the folio
+func (pdb *postgresDB) UpdatePaymentStatusByReference(reference, status string, amount int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		var payments []models.Payment
+		err := tx.NewSelect().Model(&payments).
+			Where("reference=?", reference).
+			Order("id").For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if len(payments) == 0 {
+			return repository.ErrPaymentNotFound
+		}
+
+		for _, old := range payments {
+			if status == models.PaymentVoided && paymentTaken(old.Status) {
+				return repository.ErrPaymentTaken
+			}
+			payment := old
+			payment.Status = status
+			if status == models.PaymentRefunded {
+				refunded := payment.Amount - payment.Refunded
+				if refunded > amount {
+					refunded = amount
+				}
+				if refunded < 0 {
+					refunded = 0
+				}
+				amount -= refunded
+				payment.Refunded += refunded
+				if payment.Refunded < payment.Amount {
+					payment.Status = old.Status
+				}
+			}
+			_, err = tx.NewUpdate().Model(&payment).
+				Column("status", "refunded").
+				WherePK().Exec(ctx)
+			if err != nil {
+				return err
+			}
+			_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+				Set("payment_status=?", payment.Status).
+				Where("id=?", payment.ReservationID).Exec(ctx)
+			if err != nil {
+				return err
+			}
+			err = postPaymentInTx(ctx, tx, old, payment)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// chargeStayInTx works out taxes of a new reservation from the taxes in effect, saves its add-ons and
+// charges room nights, taxes and add-ons to its folio
+func chargeStayInTx(ctx context.Context, tx bun.Tx, res *models.Reservation) error {
+	taxes := make([]models.Tax, 0)
+	err := tx.NewSelect().Model(&taxes).Order("id").Scan(ctx)
+	if err != nil {
+		return err
+	}
+	res.Taxes = pricing.ApplyTaxes(*res, taxes)
+	if len(res.Taxes) > 0 {
+		_, err = tx.NewInsert().Model(&res.Taxes).Exec(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	entries := pricing.TaxCharges(*res)
+	if len(res.AddOns) > 0 {
+		for i := range res.AddOns {
+			res.AddOns[i].ReservationID = res.ID
+			err = addOnAvailableInTx(ctx, tx, *res, res.AddOns[i])
+			if err != nil {
+				return err
+			}
+		}
+		_, err = tx.NewInsert().Model(&res.AddOns).Exec(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range res.AddOns {
+			entries = append(entries, pricing.AddOnCharge(item))
+		}
+	}
+	if entry := pricing.RoomCharge(*res); entry.Amount != 0 {
+		entries = append([]models.FolioEntry{entry}, entries...)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err = tx.NewInsert().Model(&entries).Exec(ctx)
+	return err
+}
+
+// addOnAvailableInTx checks a limited add-on is not sold out on any night of the reservation's stay once
+// the item is added. The add-on is locked, so concurrent bookings can't both take the last one
+func addOnAvailableInTx(ctx context.Context, tx bun.Tx, res models.Reservation, item models.ReservationAddOn) error {
+	if item.AddOnID == 0 {
+		return nil
+	}
+	addOn := new(models.AddOn)
+	err := tx.NewSelect().Model(addOn).Where("id=?", item.AddOnID).For("UPDATE").Scan(ctx)
+	if err != nil {
+		return err
+	}
+	if addOn.DailyLimit == 0 {
+		return nil
+	}
+
+	nightly := tx.NewSelect().
+		TableExpr("generate_series(?::date, ?::date - 1, interval '1 day') AS night", res.StartDate, res.EndDate).
+		Join("JOIN reservations AS r ON r.start_date <= night AND r.end_date > night").
+		Join("JOIN reservation_add_ons AS ra ON ra.reservation_id = r.id").
+		ColumnExpr("SUM(ra.quantity) AS booked").
+		Where("ra.add_on_id=?", item.AddOnID).
+		Where("r.status=?", models.ReservationConfirmed).
+		Where("r.deleted_at IS NULL").
+		Group("night")
+	var booked int
+	err = tx.NewSelect().TableExpr("(?) AS nightly", nightly).
+		ColumnExpr("COALESCE(MAX(booked), 0)").Scan(ctx, &booked)
+	if err != nil {
+		return err
+	}
+	if booked+item.Quantity > addOn.DailyLimit {
+		return repository.ErrAddOnNotAvailable
+	}
+	return nil
+}
+
+// postPaymentInTx posts to the folio money which changed hands when the payment went from old to payment
+func postPaymentInTx(ctx context.Context, tx bun.Tx, old, payment models.Payment) error {
+	entries := make([]models.FolioEntry, 0, 2)
+	if !paymentTaken(old.Status) && paymentTaken(payment.Status) {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: payment.ReservationID,
+			EntryType:     models.FolioPayment,
+			Description:   "Payment " + payment.Reference,
+			Amount:        -payment.Amount,
+		})
+	}
+	if refunded := payment.Refunded - old.Refunded; refunded > 0 {
+		entries = append(entries, models.FolioEntry{
+			ReservationID: payment.ReservationID,
+			EntryType:     models.FolioRefund,
+			Description:   "Refund " + payment.Reference,
+			Amount:        refunded,
+		})
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	_, err := tx.NewInsert().Model(&entries).Exec(ctx)
+	return err
+}
+
+// paymentTaken tells if money of a payment in the status has been taken from the guest
+func paymentTaken(status string) bool {
+	return status == models.PaymentCaptured || status == models.PaymentRefunded
+}
+
+// InsertFolioEntry appends an entry to the folio of a reservation
+func (pdb *postgresDB) InsertFolioEntry(entry *models.FolioEntry) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(entry).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// invoicePrefixes are put in front of the sequence number of each kind of document
+var invoicePrefixes = map[string]string{
+	models.DocumentInvoice: "INV-",
+	models.DocumentReceipt: "RCT-",
+}
+
+// IssueInvoice returns the document of the kind issued for the reservation, taking the next number
+// from the sequence the first time it is issued
+func (pdb *postgresDB) IssueInvoice(reservationID int, kind string) (*models.Invoice, error) {
+	prefix, ok := invoicePrefixes[kind]
+	if !ok {
+		return nil, errors.New("unknown document kind " + kind)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	invoice := new(models.Invoice)
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		// concurrent requests for the same reservation wait here instead of numbering two documents
+		_, err := tx.NewSelect().Model((*models.Reservation)(nil)).Column("id").
+			Where("id=?", reservationID).For("UPDATE").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		err = tx.NewSelect().Model(invoice).
+			Where("reservation_id=?", reservationID).Where("kind=?", kind).Scan(ctx)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		invoice.ReservationID = reservationID
+		invoice.Kind = kind
+		return tx.NewInsert().Model(invoice).
+			Value("number", "? || lpad(nextval('invoice_number_seq')::text, 6, '0')", prefix).
+			Returning("*").Scan(ctx)
+	})
+	return invoice, err
+}
+
+// GetAllTaxes returns all tax definitions
+func (pdb *postgresDB) GetAllTaxes() ([]models.Tax, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	taxes := make([]models.Tax, 0)
+	err := pdb.DB.NewSelect().Model(&taxes).Order("id").Scan(ctx)
+	return taxes, err
+}
+
+// InsertTax inserts a tax definition
+func (pdb *postgresDB) InsertTax(tax *models.Tax) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(tax).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// UpdateTax updates a tax definition, reservations keep taxes worked out at booking
+func (pdb *postgresDB) UpdateTax(tax models.Tax) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&tax).
+			Column("name", "tax_type", "rate", "amount", "basis", "inclusive", "valid_from", "valid_to").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// DeleteTaxByID deletes a tax definition
+func (pdb *postgresDB) DeleteTaxByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("taxes").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// GetAllAddOns returns the add-ons catalog
+func (pdb *postgresDB) GetAllAddOns() ([]models.AddOn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	addOns := make([]models.AddOn, 0)
+	err := pdb.DB.NewSelect().Model(&addOns).Order("id").Scan(ctx)
+	return addOns, err
+}
+
+// GetAddOnByID search for add-on by id
+func (pdb *postgresDB) GetAddOnByID(id int) (*models.AddOn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	addOn := new(models.AddOn)
+	err := pdb.DB.NewSelect().Model(addOn).Where("id=?", id).Scan(ctx)
+	return addOn, err
+}
+
+// InsertAddOn inserts an add-on into the catalog
+func (pdb *postgresDB) InsertAddOn(addOn *models.AddOn) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(addOn).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// UpdateAddOn updates an add-on, reservations keep add-ons as they were sold
+func (pdb *postgresDB) UpdateAddOn(addOn models.AddOn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&addOn).
+			Column("name", "description", "price", "basis", "daily_limit").
+			WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// DeleteAddOnByID deletes an add-on from the catalog
+func (pdb *postgresDB) DeleteAddOnByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("add_ons").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// AddReservationAddOn sells an add-on with a booked reservation and charges it to the folio on behalf of the user
+func (pdb *postgresDB) AddReservationAddOn(item *models.ReservationAddOn, userID int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		res := new(models.Reservation)
+		err := tx.NewSelect().Model(res).Where("id=?", item.ReservationID).For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if res.Status == models.ReservationCancelled {
+			return repository.ErrReservationCancelled
+		}
+		err = addOnAvailableInTx(ctx, tx, *res, *item)
+		if err != nil {
+			return err
+		}
+		err = tx.NewInsert().Model(item).Returning("id").Scan(ctx, &newID)
+		if err != nil {
+			return err
+		}
+		entry := pricing.AddOnCharge(*item)
+		entry.UserID = userID
+		_, err = tx.NewInsert().Model(&entry).Exec(ctx)
+		return err
+	})
+	return newID, err
+}
+
+// RemoveReservationAddOn takes an add-on off the reservation and reverses its charge on behalf of the user
+func (pdb *postgresDB) RemoveReservationAddOn(reservationID, itemID, userID int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		item := new(models.ReservationAddOn)
+		err := tx.NewDelete().Model(item).
+			Where("id=?", itemID).
+			Where("reservation_id=?", reservationID).
+			Returning("*").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		entry := pricing.AddOnCharge(*item)
+		entry.Description += " removed"
+		entry.Amount = -entry.Amount
+		entry.UserID = userID
+		_, err = tx.NewInsert().Model(&entry).Exec(ctx)
+		return err
+	})
+}
+
+// GetAllCurrencies returns the exchange rate table
+func (pdb *postgresDB) GetAllCurrencies() ([]models.Currency, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	currencies := make([]models.Currency, 0)
+	err := pdb.DB.NewSelect().Model(&currencies).Order("code").Scan(ctx)
+	return currencies, err
+}
+
+// InsertCurrency inserts an exchange rate
+func (pdb *postgresDB) InsertCurrency(currency *models.Currency) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var newID int
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		return tx.NewInsert().Model(currency).Returning("id").Scan(ctx, &newID)
+	})
+	return newID, err
+}
+
+// UpdateCurrency updates an exchange rate
+func (pdb *postgresDB) UpdateCurrency(currency models.Currency) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewUpdate().Model(&currency).Column("code", "symbol", "rate").WherePK().Exec(ctx)
+		return err
+	})
+}
+
+// DeleteCurrencyByID deletes an exchange rate
+func (pdb *postgresDB) DeleteCurrencyByID(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		_, err := tx.NewDelete().Table("currencies").Where("id=?", id).Exec(ctx)
+		return err
+	})
+}
+
+// auditLogLimit is how many entries the audit log shows at most
+const auditLogLimit = 500
+
+// historyKeys are the columns which link rows to the entity they belong to,
+// such as notes and blocks of a reservation
+var historyKeys = map[string]string{
+	"reservations": "reservation_id",
+	"rooms":        "room_id",
+	"guests":       "guest_id",
+}
+
+// SearchAuditLog returns the latest audit entries matching the search
+func (pdb *postgresDB) SearchAuditLog(search models.AuditSearch) ([]models.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entries := make([]models.AuditEntry, 0)
+	query := pdb.DB.NewSelect().Model(&entries).
+		Relation("User")
+	if search.Query != "" {
+		pattern := "%" + likeEscaper.Replace(search.Query) + "%"
+		users := pdb.DB.NewSelect().
+			Table("users").
+			Column("id").
+			Where("first_name || ' ' || last_name ILIKE ?", pattern).
+			WhereOr("email ILIKE ?", pattern)
+		query = query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("audit_entry.entity_id=?", search.Query).
+				WhereOr("audit_entry.request ILIKE ?", pattern).
+				WhereOr("audit_entry.request_id=?", search.Query).
+				WhereOr("audit_entry.ip ILIKE ?", pattern).
+				WhereOr("audit_entry.before::text ILIKE ?", pattern).
+				WhereOr("audit_entry.after::text ILIKE ?", pattern).
+				WhereOr("audit_entry.user_id IN (?)", users)
+		})
+	}
+	if search.Entity != "" {
+		query = query.Where("audit_entry.entity=?", search.Entity)
+	}
+	if !search.From.IsZero() {
+		query = query.Where("audit_entry.occurred_at>=?", search.From)
+	}
+	if !search.To.IsZero() {
+		query = query.Where("audit_entry.occurred_at<?", search.To.AddDate(0, 0, 1))
+	}
+	err := query.
+		Order("audit_entry.occurred_at DESC", "audit_entry.id DESC").
+		Limit(auditLogLimit).
+		Scan(ctx)
+	return entries, err
+}
+
+// GetEntityHistory returns the audit entries of an entity and of the rows which belong to it, latest first
+func (pdb *postgresDB) GetEntityHistory(entity string, id int) ([]models.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	entityID := strconv.Itoa(id)
+	entries := make([]models.AuditEntry, 0)
+	err := pdb.DB.NewSelect().Model(&entries).
+		Relation("User").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			q = q.WhereGroup(" OR ", func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Where("audit_entry.entity=?", entity).
+					Where("audit_entry.entity_id=?", entityID)
+			})
+			if key, ok := historyKeys[entity]; ok {
+				q = q.WhereOr("COALESCE(audit_entry.after, audit_entry.before) ->> ?=?", key, entityID)
+			}
+			return q
+		}).
+		Order("audit_entry.occurred_at DESC", "audit_entry.id DESC").
+		Scan(ctx)
+	return entries, err
+}
+
+// GetDeletedReservations returns reservations in the trash, latest deleted first
+func (pdb *postgresDB) GetDeletedReservations() ([]models.Reservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	reservations := make([]models.Reservation, 0)
+	err := pdb.DB.NewSelect().Model(&reservations).
+		Relation("Room").
+		WhereDeleted().
+		Order("reservation.deleted_at DESC").
+		Scan(ctx)
+	return reservations, err
+}
+
+// GetDeletedRoomRestrictions returns blocks in the trash, latest deleted first. Room restrictions of
+// reservations are left out, they come back with their reservations
+func (pdb *postgresDB) GetDeletedRoomRestrictions() ([]models.RoomRestriction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	restrictions := make([]models.RoomRestriction, 0)
+	err := pdb.DB.NewSelect().Model(&restrictions).
+		Relation("Room").
+		Relation("Restriction").
+		WhereDeleted().
+		Where("room_restriction.reservation_id IS NULL").
+		Order("room_restriction.deleted_at DESC").
+		Scan(ctx)
+	return restrictions, err
+}
+
+// restoreRoomRestrictionsInTx takes room restrictions out of the trash, unless their rooms got taken
+// on the dates in the meantime
+func restoreRoomRestrictionsInTx(ctx context.Context, tx bun.Tx, restrictions []models.RoomRestriction) error {
+	if len(restrictions) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(restrictions))
+	for _, rr := range restrictions {
+		_, err := tx.NewSelect().Model((*models.Room)(nil)).Where("id=?", rr.RoomID).For("UPDATE").Exec(ctx)
+		if err != nil {
+			return err
+		}
+		numberRows, err := tx.NewSelect().
+			Table("room_restrictions").
+			Where("deleted_at IS NULL").
+			Where("room_id = ?", rr.RoomID).
+			Where("end_date>?", rr.StartDate).
+			Where("start_date<?", rr.EndDate).
+			Where("(expires_at IS NULL OR expires_at>?)", time.Now()).
+			Count(ctx)
+		if err != nil {
+			return err
+		}
+		if numberRows > 0 {
+			return repository.ErrRoomNotAvailable
+		}
+		ids = append(ids, rr.ID)
+	}
+	_, err := tx.NewUpdate().Model((*models.RoomRestriction)(nil)).
+		Set("deleted_at=NULL").
+		Where("id IN (?)", bun.In(ids)).
+		WhereDeleted().Exec(ctx)
+	return err
+}
+
+// RestoreReservation takes reservation out of the trash with its room restrictions and its group.
+// ErrRoomNotAvailable is returned when the room was booked on its dates in the meantime
+func (pdb *postgresDB) RestoreReservation(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		res := new(models.Reservation)
+		err := tx.NewSelect().Model(res).Where("id=?", id).WhereDeleted().For("UPDATE").Scan(ctx)
+		if err != nil {
+			return err
+		}
+		restrictions := make([]models.RoomRestriction, 0)
+		err = tx.NewSelect().Model(&restrictions).Where("reservation_id=?", id).WhereDeleted().Scan(ctx)
+		if err != nil {
+			return err
+		}
+		err = restoreRoomRestrictionsInTx(ctx, tx, restrictions)
+		if err != nil {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.Reservation)(nil)).
+			Set("deleted_at=NULL").
+			Where("id=?", id).
+			WhereDeleted().Exec(ctx)
+		if err != nil || res.GroupID == 0 {
+			return err
+		}
+		_, err = tx.NewUpdate().Model((*models.ReservationGroup)(nil)).
+			Set("deleted_at=NULL").
+			Where("id=?", res.GroupID).
+			WhereDeleted().Exec(ctx)
+		return err
+	})
+}
+
+// RestoreRoomRestriction takes block out of the trash.
+// ErrRoomNotAvailable is returned when the room was booked on its dates in the meantime
+func (pdb *postgresDB) RestoreRoomRestriction(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		restrictions := make([]models.RoomRestriction, 0)
+		err := tx.NewSelect().Model(&restrictions).
+			Where("id=?", id).
+			Where("reservation_id IS NULL").
+			WhereDeleted().Scan(ctx)
+		if err != nil {
+			return err
+		}
+		if len(restrictions) == 0 {
+			return sql.ErrNoRows
+		}
+		return restoreRoomRestrictionsInTx(ctx, tx, restrictions)
+	})
+}
+
+// purgeEmptyGroupsInTx deletes groups in the trash which have no reservations left
+func purgeEmptyGroupsInTx(ctx context.Context, tx bun.Tx) error {
+	reservations := tx.NewSelect().
+		Table("reservations").
+		Where("reservations.group_id = reservation_group.id")
+	_, err := tx.NewDelete().Model((*models.ReservationGroup)(nil)).
+		Where("NOT EXISTS (?)", reservations).
+		WhereDeleted().
+		ForceDelete().Exec(ctx)
+	return err
+}
+
+// PurgeReservation deletes reservation in the trash for good, with everything that belongs to it
+func (pdb *postgresDB) PurgeReservation(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		result, err := tx.NewDelete().Model((*models.Reservation)(nil)).
+			Where("id=?", id).
+			WhereDeleted().
+			ForceDelete().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		purged, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if purged == 0 {
+			return sql.ErrNoRows
+		}
+		return purgeEmptyGroupsInTx(ctx, tx)
+	})
+}
+
+// PurgeRoomRestriction deletes block in the trash for good
+func (pdb *postgresDB) PurgeRoomRestriction(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	return pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		result, err := tx.NewDelete().Model((*models.RoomRestriction)(nil)).
+			Where("id=?", id).
+			Where("reservation_id IS NULL").
+			WhereDeleted().
+			ForceDelete().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		purged, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if purged == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// PurgeTrash deletes for good everything moved to the trash before the time and returns
+// how many reservations and blocks were purged
+func (pdb *postgresDB) PurgeTrash(before time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	var purged int64
+	err := pdb.audited(ctx, func(ctx context.Context, tx bun.Tx) error {
+		result, err := tx.NewDelete().Model((*models.Reservation)(nil)).
+			Where("deleted_at<?", before).
+			WhereDeleted().
+			ForceDelete().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		purged, err = result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		result, err = tx.NewDelete().Model((*models.RoomRestriction)(nil)).
+			Where("deleted_at<?", before).
+			WhereDeleted().
+			ForceDelete().Exec(ctx)
+		if err != nil {
+			return err
+		}
+		blocks, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		purged += blocks
+		return purgeEmptyGroupsInTx(ctx, tx)
+	})
+	return int(purged), err
+}
@@ -10,6 +10,7 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/porky256/course-project/internal/models"
+	repository "github.com/porky256/course-project/internal/repository"
 )
 
 // MockDatabaseRepo is a mock of DatabaseRepo interface.
@@ -35,6 +36,35 @@ func (m *MockDatabaseRepo) EXPECT() *MockDatabaseRepoMockRecorder {
 	return m.recorder
 }
 
+// AddReservationAddOn mocks base method.
+func (m *MockDatabaseRepo) AddReservationAddOn(item *models.ReservationAddOn, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReservationAddOn", item, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddReservationAddOn indicates an expected call of AddReservationAddOn.
+func (mr *MockDatabaseRepoMockRecorder) AddReservationAddOn(item, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReservationAddOn", reflect.TypeOf((*MockDatabaseRepo)(nil).AddReservationAddOn), item, userID)
+}
+
+// AddReservationTag mocks base method.
+func (m *MockDatabaseRepo) AddReservationTag(reservationID int, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddReservationTag", reservationID, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddReservationTag indicates an expected call of AddReservationTag.
+func (mr *MockDatabaseRepoMockRecorder) AddReservationTag(reservationID, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddReservationTag", reflect.TypeOf((*MockDatabaseRepo)(nil).AddReservationTag), reservationID, tag)
+}
+
 // AddSingleDayRoomRestriction mocks base method.
 func (m *MockDatabaseRepo) AddSingleDayRoomRestriction(roomID, restrictionID int, start time.Time) (int, error) {
 	m.ctrl.T.Helper()
@@ -81,6 +111,134 @@ func (mr *MockDatabaseRepoMockRecorder) AvailabilityOfAllRooms(start, end interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AvailabilityOfAllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).AvailabilityOfAllRooms), start, end)
 }
 
+// BulkUpdateReservations mocks base method.
+func (m *MockDatabaseRepo) BulkUpdateReservations(ids []int, change models.BulkChange) (map[int]error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BulkUpdateReservations", ids, change)
+	ret0, _ := ret[0].(map[int]error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BulkUpdateReservations indicates an expected call of BulkUpdateReservations.
+func (mr *MockDatabaseRepoMockRecorder) BulkUpdateReservations(ids, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BulkUpdateReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).BulkUpdateReservations), ids, change)
+}
+
+// CancelReservation mocks base method.
+func (m *MockDatabaseRepo) CancelReservation(id, fee int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelReservation", id, fee)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelReservation indicates an expected call of CancelReservation.
+func (mr *MockDatabaseRepoMockRecorder) CancelReservation(id, fee interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).CancelReservation), id, fee)
+}
+
+// CheckInReservation mocks base method.
+func (m *MockDatabaseRepo) CheckInReservation(id, userID int, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckInReservation", id, userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckInReservation indicates an expected call of CheckInReservation.
+func (mr *MockDatabaseRepoMockRecorder) CheckInReservation(id, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckInReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).CheckInReservation), id, userID, at)
+}
+
+// CheckOutReservation mocks base method.
+func (m *MockDatabaseRepo) CheckOutReservation(id, userID int, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckOutReservation", id, userID, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckOutReservation indicates an expected call of CheckOutReservation.
+func (mr *MockDatabaseRepoMockRecorder) CheckOutReservation(id, userID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckOutReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).CheckOutReservation), id, userID, at)
+}
+
+// DeleteAddOnByID mocks base method.
+func (m *MockDatabaseRepo) DeleteAddOnByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAddOnByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAddOnByID indicates an expected call of DeleteAddOnByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteAddOnByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAddOnByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteAddOnByID), id)
+}
+
+// DeleteCurrencyByID mocks base method.
+func (m *MockDatabaseRepo) DeleteCurrencyByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCurrencyByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCurrencyByID indicates an expected call of DeleteCurrencyByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteCurrencyByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCurrencyByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteCurrencyByID), id)
+}
+
+// DeleteExpiredRoomHolds mocks base method.
+func (m *MockDatabaseRepo) DeleteExpiredRoomHolds() (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRoomHolds")
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRoomHolds indicates an expected call of DeleteExpiredRoomHolds.
+func (mr *MockDatabaseRepoMockRecorder) DeleteExpiredRoomHolds() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRoomHolds", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteExpiredRoomHolds))
+}
+
+// DeleteOutOfOrderPeriodByID mocks base method.
+func (m *MockDatabaseRepo) DeleteOutOfOrderPeriodByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOutOfOrderPeriodByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOutOfOrderPeriodByID indicates an expected call of DeleteOutOfOrderPeriodByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteOutOfOrderPeriodByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOutOfOrderPeriodByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteOutOfOrderPeriodByID), id)
+}
+
+// DeleteRatePlanByID mocks base method.
+func (m *MockDatabaseRepo) DeleteRatePlanByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRatePlanByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRatePlanByID indicates an expected call of DeleteRatePlanByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteRatePlanByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRatePlanByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteRatePlanByID), id)
+}
+
 // DeleteReservationByID mocks base method.
 func (m *MockDatabaseRepo) DeleteReservationByID(id int) error {
 	m.ctrl.T.Helper()
@@ -95,6 +253,20 @@ func (mr *MockDatabaseRepoMockRecorder) DeleteReservationByID(id interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReservationByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteReservationByID), id)
 }
 
+// DeleteReservationGroupByID mocks base method.
+func (m *MockDatabaseRepo) DeleteReservationGroupByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteReservationGroupByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteReservationGroupByID indicates an expected call of DeleteReservationGroupByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteReservationGroupByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteReservationGroupByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteReservationGroupByID), id)
+}
+
 // DeleteRoomRestrictionByID mocks base method.
 func (m *MockDatabaseRepo) DeleteRoomRestrictionByID(id int) error {
 	m.ctrl.T.Helper()
@@ -109,225 +281,1308 @@ func (mr *MockDatabaseRepoMockRecorder) DeleteRoomRestrictionByID(id interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRoomRestrictionByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteRoomRestrictionByID), id)
 }
 
-// GetAllReservations mocks base method.
-func (m *MockDatabaseRepo) GetAllReservations() ([]models.Reservation, error) {
+// DeleteTaxByID mocks base method.
+func (m *MockDatabaseRepo) DeleteTaxByID(id int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllReservations")
-	ret0, _ := ret[0].([]models.Reservation)
+	ret := m.ctrl.Call(m, "DeleteTaxByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTaxByID indicates an expected call of DeleteTaxByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteTaxByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTaxByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteTaxByID), id)
+}
+
+// DeleteWaitlistEntryByID mocks base method.
+func (m *MockDatabaseRepo) DeleteWaitlistEntryByID(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWaitlistEntryByID", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWaitlistEntryByID indicates an expected call of DeleteWaitlistEntryByID.
+func (mr *MockDatabaseRepoMockRecorder) DeleteWaitlistEntryByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWaitlistEntryByID", reflect.TypeOf((*MockDatabaseRepo)(nil).DeleteWaitlistEntryByID), id)
+}
+
+// ExportReservations mocks base method.
+func (m *MockDatabaseRepo) ExportReservations(query models.ReservationQuery, onlyNew bool, fn func(models.Reservation) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportReservations", query, onlyNew, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportReservations indicates an expected call of ExportReservations.
+func (mr *MockDatabaseRepoMockRecorder) ExportReservations(query, onlyNew, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).ExportReservations), query, onlyNew, fn)
+}
+
+// ExtendRoomHolds mocks base method.
+func (m *MockDatabaseRepo) ExtendRoomHolds(ids []int, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExtendRoomHolds", ids, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExtendRoomHolds indicates an expected call of ExtendRoomHolds.
+func (mr *MockDatabaseRepoMockRecorder) ExtendRoomHolds(ids, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtendRoomHolds", reflect.TypeOf((*MockDatabaseRepo)(nil).ExtendRoomHolds), ids, expiresAt)
+}
+
+// GenerateHousekeepingTasks mocks base method.
+func (m *MockDatabaseRepo) GenerateHousekeepingTasks(day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateHousekeepingTasks", day)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllReservations indicates an expected call of GetAllReservations.
-func (mr *MockDatabaseRepoMockRecorder) GetAllReservations() *gomock.Call {
+// GenerateHousekeepingTasks indicates an expected call of GenerateHousekeepingTasks.
+func (mr *MockDatabaseRepoMockRecorder) GenerateHousekeepingTasks(day interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllReservations))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateHousekeepingTasks", reflect.TypeOf((*MockDatabaseRepo)(nil).GenerateHousekeepingTasks), day)
 }
 
-// GetAllRooms mocks base method.
-func (m *MockDatabaseRepo) GetAllRooms() ([]models.Room, error) {
+// GetActiveRoomRestrictionsWithinDates mocks base method.
+func (m *MockDatabaseRepo) GetActiveRoomRestrictionsWithinDates(start, end time.Time) ([]models.RoomRestriction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllRooms")
-	ret0, _ := ret[0].([]models.Room)
+	ret := m.ctrl.Call(m, "GetActiveRoomRestrictionsWithinDates", start, end)
+	ret0, _ := ret[0].([]models.RoomRestriction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllRooms indicates an expected call of GetAllRooms.
-func (mr *MockDatabaseRepoMockRecorder) GetAllRooms() *gomock.Call {
+// GetActiveRoomRestrictionsWithinDates indicates an expected call of GetActiveRoomRestrictionsWithinDates.
+func (mr *MockDatabaseRepoMockRecorder) GetActiveRoomRestrictionsWithinDates(start, end interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllRooms))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRoomRestrictionsWithinDates", reflect.TypeOf((*MockDatabaseRepo)(nil).GetActiveRoomRestrictionsWithinDates), start, end)
 }
 
-// GetNewReservations mocks base method.
-func (m *MockDatabaseRepo) GetNewReservations() ([]models.Reservation, error) {
+// GetAddOnByID mocks base method.
+func (m *MockDatabaseRepo) GetAddOnByID(id int) (*models.AddOn, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetNewReservations")
-	ret0, _ := ret[0].([]models.Reservation)
+	ret := m.ctrl.Call(m, "GetAddOnByID", id)
+	ret0, _ := ret[0].(*models.AddOn)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetNewReservations indicates an expected call of GetNewReservations.
-func (mr *MockDatabaseRepoMockRecorder) GetNewReservations() *gomock.Call {
+// GetAddOnByID indicates an expected call of GetAddOnByID.
+func (mr *MockDatabaseRepoMockRecorder) GetAddOnByID(id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetNewReservations))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAddOnByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAddOnByID), id)
 }
 
-// GetReservationByID mocks base method.
-func (m *MockDatabaseRepo) GetReservationByID(id int) (*models.Reservation, error) {
+// GetAllAddOns mocks base method.
+func (m *MockDatabaseRepo) GetAllAddOns() ([]models.AddOn, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetReservationByID", id)
-	ret0, _ := ret[0].(*models.Reservation)
+	ret := m.ctrl.Call(m, "GetAllAddOns")
+	ret0, _ := ret[0].([]models.AddOn)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetReservationByID indicates an expected call of GetReservationByID.
-func (mr *MockDatabaseRepoMockRecorder) GetReservationByID(id interface{}) *gomock.Call {
+// GetAllAddOns indicates an expected call of GetAllAddOns.
+func (mr *MockDatabaseRepoMockRecorder) GetAllAddOns() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllAddOns", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllAddOns))
 }
 
-// GetRoomByID mocks base method.
-func (m *MockDatabaseRepo) GetRoomByID(id int) (*models.Room, error) {
+// GetAllCancellationPolicies mocks base method.
+func (m *MockDatabaseRepo) GetAllCancellationPolicies() ([]models.CancellationPolicy, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRoomByID", id)
-	ret0, _ := ret[0].(*models.Room)
+	ret := m.ctrl.Call(m, "GetAllCancellationPolicies")
+	ret0, _ := ret[0].([]models.CancellationPolicy)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRoomByID indicates an expected call of GetRoomByID.
-func (mr *MockDatabaseRepoMockRecorder) GetRoomByID(id interface{}) *gomock.Call {
+// GetAllCancellationPolicies indicates an expected call of GetAllCancellationPolicies.
+func (mr *MockDatabaseRepoMockRecorder) GetAllCancellationPolicies() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCancellationPolicies", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllCancellationPolicies))
 }
 
-// GetRoomRestrictionsByRoomIdWithinDates mocks base method.
-func (m *MockDatabaseRepo) GetRoomRestrictionsByRoomIdWithinDates(roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
+// GetAllCurrencies mocks base method.
+func (m *MockDatabaseRepo) GetAllCurrencies() ([]models.Currency, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRoomRestrictionsByRoomIdWithinDates", roomID, start, end)
-	ret0, _ := ret[0].([]models.RoomRestriction)
+	ret := m.ctrl.Call(m, "GetAllCurrencies")
+	ret0, _ := ret[0].([]models.Currency)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRoomRestrictionsByRoomIdWithinDates indicates an expected call of GetRoomRestrictionsByRoomIdWithinDates.
-func (mr *MockDatabaseRepoMockRecorder) GetRoomRestrictionsByRoomIdWithinDates(roomID, start, end interface{}) *gomock.Call {
+// GetAllCurrencies indicates an expected call of GetAllCurrencies.
+func (mr *MockDatabaseRepoMockRecorder) GetAllCurrencies() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomRestrictionsByRoomIdWithinDates", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomRestrictionsByRoomIdWithinDates), roomID, start, end)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCurrencies", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllCurrencies))
 }
 
-// GetUserByID mocks base method.
-func (m *MockDatabaseRepo) GetUserByID(id int) (*models.User, error) {
+// GetAllGuests mocks base method.
+func (m *MockDatabaseRepo) GetAllGuests() ([]models.Guest, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByID", id)
-	ret0, _ := ret[0].(*models.User)
+	ret := m.ctrl.Call(m, "GetAllGuests")
+	ret0, _ := ret[0].([]models.Guest)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByID indicates an expected call of GetUserByID.
-func (mr *MockDatabaseRepoMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+// GetAllGuests indicates an expected call of GetAllGuests.
+func (mr *MockDatabaseRepoMockRecorder) GetAllGuests() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetUserByID), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllGuests", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllGuests))
 }
 
-// InsertReservation mocks base method.
-func (m *MockDatabaseRepo) InsertReservation(res *models.Reservation) (int, error) {
+// GetAllReservations mocks base method.
+func (m *MockDatabaseRepo) GetAllReservations(query models.ReservationQuery) ([]models.Reservation, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertReservation", res)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetAllReservations", query)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAllReservations indicates an expected call of GetAllReservations.
+func (mr *MockDatabaseRepoMockRecorder) GetAllReservations(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllReservations), query)
+}
+
+// GetAllRooms mocks base method.
+func (m *MockDatabaseRepo) GetAllRooms() ([]models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllRooms")
+	ret0, _ := ret[0].([]models.Room)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertReservation indicates an expected call of InsertReservation.
-func (mr *MockDatabaseRepoMockRecorder) InsertReservation(res interface{}) *gomock.Call {
+// GetAllRooms indicates an expected call of GetAllRooms.
+func (mr *MockDatabaseRepoMockRecorder) GetAllRooms() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservation), res)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllRooms))
 }
 
-// InsertRestriction mocks base method.
-func (m *MockDatabaseRepo) InsertRestriction(res *models.Restriction) (int, error) {
+// GetAllTags mocks base method.
+func (m *MockDatabaseRepo) GetAllTags() ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertRestriction", res)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetAllTags")
+	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertRestriction indicates an expected call of InsertRestriction.
-func (mr *MockDatabaseRepoMockRecorder) InsertRestriction(res interface{}) *gomock.Call {
+// GetAllTags indicates an expected call of GetAllTags.
+func (mr *MockDatabaseRepoMockRecorder) GetAllTags() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRestriction), res)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTags", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllTags))
 }
 
-// InsertRoom mocks base method.
-func (m *MockDatabaseRepo) InsertRoom(room *models.Room) (int, error) {
+// GetAllTaxes mocks base method.
+func (m *MockDatabaseRepo) GetAllTaxes() ([]models.Tax, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertRoom", room)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetAllTaxes")
+	ret0, _ := ret[0].([]models.Tax)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertRoom indicates an expected call of InsertRoom.
-func (mr *MockDatabaseRepoMockRecorder) InsertRoom(room interface{}) *gomock.Call {
+// GetAllTaxes indicates an expected call of GetAllTaxes.
+func (mr *MockDatabaseRepoMockRecorder) GetAllTaxes() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoom), room)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTaxes", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllTaxes))
 }
 
-// InsertRoomRestriction mocks base method.
-func (m *MockDatabaseRepo) InsertRoomRestriction(rmres *models.RoomRestriction) (int, error) {
+// GetAllWaitlistEntries mocks base method.
+func (m *MockDatabaseRepo) GetAllWaitlistEntries() ([]models.WaitlistEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertRoomRestriction", rmres)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetAllWaitlistEntries")
+	ret0, _ := ret[0].([]models.WaitlistEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertRoomRestriction indicates an expected call of InsertRoomRestriction.
-func (mr *MockDatabaseRepoMockRecorder) InsertRoomRestriction(rmres interface{}) *gomock.Call {
+// GetAllWaitlistEntries indicates an expected call of GetAllWaitlistEntries.
+func (mr *MockDatabaseRepoMockRecorder) GetAllWaitlistEntries() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoomRestriction), rmres)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllWaitlistEntries", reflect.TypeOf((*MockDatabaseRepo)(nil).GetAllWaitlistEntries))
 }
 
-// InsertUser mocks base method.
-func (m *MockDatabaseRepo) InsertUser(user *models.User) (int, error) {
+// GetDeletedReservations mocks base method.
+func (m *MockDatabaseRepo) GetDeletedReservations() ([]models.Reservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "InsertUser", user)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetDeletedReservations")
+	ret0, _ := ret[0].([]models.Reservation)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// InsertUser indicates an expected call of InsertUser.
-func (mr *MockDatabaseRepoMockRecorder) InsertUser(user interface{}) *gomock.Call {
+// GetDeletedReservations indicates an expected call of GetDeletedReservations.
+func (mr *MockDatabaseRepoMockRecorder) GetDeletedReservations() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertUser), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetDeletedReservations))
 }
 
-// LookForAvailabilityOfRoom mocks base method.
-func (m *MockDatabaseRepo) LookForAvailabilityOfRoom(start, end time.Time, roomID int) (bool, error) {
+// GetDeletedRoomRestrictions mocks base method.
+func (m *MockDatabaseRepo) GetDeletedRoomRestrictions() ([]models.RoomRestriction, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LookForAvailabilityOfRoom", start, end, roomID)
-	ret0, _ := ret[0].(bool)
+	ret := m.ctrl.Call(m, "GetDeletedRoomRestrictions")
+	ret0, _ := ret[0].([]models.RoomRestriction)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// LookForAvailabilityOfRoom indicates an expected call of LookForAvailabilityOfRoom.
-func (mr *MockDatabaseRepoMockRecorder) LookForAvailabilityOfRoom(start, end, roomID interface{}) *gomock.Call {
+// GetDeletedRoomRestrictions indicates an expected call of GetDeletedRoomRestrictions.
+func (mr *MockDatabaseRepoMockRecorder) GetDeletedRoomRestrictions() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookForAvailabilityOfRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).LookForAvailabilityOfRoom), start, end, roomID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedRoomRestrictions", reflect.TypeOf((*MockDatabaseRepo)(nil).GetDeletedRoomRestrictions))
 }
 
-// UpdateReservation mocks base method.
-func (m *MockDatabaseRepo) UpdateReservation(ur models.Reservation) error {
+// GetEntityHistory mocks base method.
+func (m *MockDatabaseRepo) GetEntityHistory(entity string, id int) ([]models.AuditEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReservation", ur)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetEntityHistory", entity, id)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateReservation indicates an expected call of UpdateReservation.
-func (mr *MockDatabaseRepoMockRecorder) UpdateReservation(ur interface{}) *gomock.Call {
+// GetEntityHistory indicates an expected call of GetEntityHistory.
+func (mr *MockDatabaseRepoMockRecorder) GetEntityHistory(entity, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservation), ur)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEntityHistory", reflect.TypeOf((*MockDatabaseRepo)(nil).GetEntityHistory), entity, id)
 }
 
-// UpdateReservationProcessed mocks base method.
-func (m *MockDatabaseRepo) UpdateReservationProcessed(id, processed int) error {
+// GetFrontDeskReservations mocks base method.
+func (m *MockDatabaseRepo) GetFrontDeskReservations(day time.Time) ([]models.Reservation, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateReservationProcessed", id, processed)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetFrontDeskReservations", day)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// UpdateReservationProcessed indicates an expected call of UpdateReservationProcessed.
-func (mr *MockDatabaseRepoMockRecorder) UpdateReservationProcessed(id, processed interface{}) *gomock.Call {
+// GetFrontDeskReservations indicates an expected call of GetFrontDeskReservations.
+func (mr *MockDatabaseRepoMockRecorder) GetFrontDeskReservations(day interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservationProcessed", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservationProcessed), id, processed)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFrontDeskReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetFrontDeskReservations), day)
+}
+
+// GetGuestByID mocks base method.
+func (m *MockDatabaseRepo) GetGuestByID(id int) (*models.Guest, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetGuestByID", id)
+	ret0, _ := ret[0].(*models.Guest)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetGuestByID indicates an expected call of GetGuestByID.
+func (mr *MockDatabaseRepoMockRecorder) GetGuestByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGuestByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetGuestByID), id)
+}
+
+// GetHousekeepingTasks mocks base method.
+func (m *MockDatabaseRepo) GetHousekeepingTasks(day time.Time) ([]models.HousekeepingTask, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHousekeepingTasks", day)
+	ret0, _ := ret[0].([]models.HousekeepingTask)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHousekeepingTasks indicates an expected call of GetHousekeepingTasks.
+func (mr *MockDatabaseRepoMockRecorder) GetHousekeepingTasks(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHousekeepingTasks", reflect.TypeOf((*MockDatabaseRepo)(nil).GetHousekeepingTasks), day)
+}
+
+// GetNewReservations mocks base method.
+func (m *MockDatabaseRepo) GetNewReservations(query models.ReservationQuery) ([]models.Reservation, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNewReservations", query)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetNewReservations indicates an expected call of GetNewReservations.
+func (mr *MockDatabaseRepoMockRecorder) GetNewReservations(query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNewReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetNewReservations), query)
+}
+
+// GetOutOfOrderPeriodByID mocks base method.
+func (m *MockDatabaseRepo) GetOutOfOrderPeriodByID(id int) (*models.OutOfOrderPeriod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutOfOrderPeriodByID", id)
+	ret0, _ := ret[0].(*models.OutOfOrderPeriod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutOfOrderPeriodByID indicates an expected call of GetOutOfOrderPeriodByID.
+func (mr *MockDatabaseRepoMockRecorder) GetOutOfOrderPeriodByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutOfOrderPeriodByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetOutOfOrderPeriodByID), id)
+}
+
+// GetOutOfOrderPeriods mocks base method.
+func (m *MockDatabaseRepo) GetOutOfOrderPeriods(from time.Time) ([]models.OutOfOrderPeriod, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutOfOrderPeriods", from)
+	ret0, _ := ret[0].([]models.OutOfOrderPeriod)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutOfOrderPeriods indicates an expected call of GetOutOfOrderPeriods.
+func (mr *MockDatabaseRepoMockRecorder) GetOutOfOrderPeriods(from interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutOfOrderPeriods", reflect.TypeOf((*MockDatabaseRepo)(nil).GetOutOfOrderPeriods), from)
+}
+
+// GetPaymentByID mocks base method.
+func (m *MockDatabaseRepo) GetPaymentByID(id int) (*models.Payment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentByID", id)
+	ret0, _ := ret[0].(*models.Payment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentByID indicates an expected call of GetPaymentByID.
+func (mr *MockDatabaseRepoMockRecorder) GetPaymentByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetPaymentByID), id)
+}
+
+// GetReportReservations mocks base method.
+func (m *MockDatabaseRepo) GetReportReservations(from, to time.Time) ([]models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportReservations", from, to)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportReservations indicates an expected call of GetReportReservations.
+func (mr *MockDatabaseRepoMockRecorder) GetReportReservations(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReportReservations), from, to)
+}
+
+// GetReservationByID mocks base method.
+func (m *MockDatabaseRepo) GetReservationByID(id int) (*models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservationByID", id)
+	ret0, _ := ret[0].(*models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservationByID indicates an expected call of GetReservationByID.
+func (mr *MockDatabaseRepoMockRecorder) GetReservationByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationByID), id)
+}
+
+// GetReservationGroupByID mocks base method.
+func (m *MockDatabaseRepo) GetReservationGroupByID(id int) (*models.ReservationGroup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservationGroupByID", id)
+	ret0, _ := ret[0].(*models.ReservationGroup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservationGroupByID indicates an expected call of GetReservationGroupByID.
+func (mr *MockDatabaseRepoMockRecorder) GetReservationGroupByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationGroupByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationGroupByID), id)
+}
+
+// GetReservationsByConfirmationCode mocks base method.
+func (m *MockDatabaseRepo) GetReservationsByConfirmationCode(code string) ([]models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservationsByConfirmationCode", code)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservationsByConfirmationCode indicates an expected call of GetReservationsByConfirmationCode.
+func (mr *MockDatabaseRepoMockRecorder) GetReservationsByConfirmationCode(code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationsByConfirmationCode", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationsByConfirmationCode), code)
+}
+
+// GetReservationsByIDs mocks base method.
+func (m *MockDatabaseRepo) GetReservationsByIDs(ids []int) ([]models.Reservation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReservationsByIDs", ids)
+	ret0, _ := ret[0].([]models.Reservation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReservationsByIDs indicates an expected call of GetReservationsByIDs.
+func (mr *MockDatabaseRepoMockRecorder) GetReservationsByIDs(ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReservationsByIDs", reflect.TypeOf((*MockDatabaseRepo)(nil).GetReservationsByIDs), ids)
+}
+
+// GetRoomByID mocks base method.
+func (m *MockDatabaseRepo) GetRoomByID(id int) (*models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomByID", id)
+	ret0, _ := ret[0].(*models.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomByID indicates an expected call of GetRoomByID.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomByID), id)
+}
+
+// GetRoomClosures mocks base method.
+func (m *MockDatabaseRepo) GetRoomClosures(from, to time.Time) ([]models.RoomRestriction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomClosures", from, to)
+	ret0, _ := ret[0].([]models.RoomRestriction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomClosures indicates an expected call of GetRoomClosures.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomClosures(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomClosures", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomClosures), from, to)
+}
+
+// GetRoomRestrictionsByRoomIdWithinDates mocks base method.
+func (m *MockDatabaseRepo) GetRoomRestrictionsByRoomIdWithinDates(roomID int, start, end time.Time) ([]models.RoomRestriction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomRestrictionsByRoomIdWithinDates", roomID, start, end)
+	ret0, _ := ret[0].([]models.RoomRestriction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomRestrictionsByRoomIdWithinDates indicates an expected call of GetRoomRestrictionsByRoomIdWithinDates.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomRestrictionsByRoomIdWithinDates(roomID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomRestrictionsByRoomIdWithinDates", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomRestrictionsByRoomIdWithinDates), roomID, start, end)
+}
+
+// GetRoomsWithRates mocks base method.
+func (m *MockDatabaseRepo) GetRoomsWithRates() ([]models.Room, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoomsWithRates")
+	ret0, _ := ret[0].([]models.Room)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoomsWithRates indicates an expected call of GetRoomsWithRates.
+func (mr *MockDatabaseRepoMockRecorder) GetRoomsWithRates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoomsWithRates", reflect.TypeOf((*MockDatabaseRepo)(nil).GetRoomsWithRates))
+}
+
+// GetUserByID mocks base method.
+func (m *MockDatabaseRepo) GetUserByID(id int) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", id)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockDatabaseRepoMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetUserByID), id)
+}
+
+// GetWaitingEntriesForRoom mocks base method.
+func (m *MockDatabaseRepo) GetWaitingEntriesForRoom(roomID int, start, end time.Time) ([]models.WaitlistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWaitingEntriesForRoom", roomID, start, end)
+	ret0, _ := ret[0].([]models.WaitlistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWaitingEntriesForRoom indicates an expected call of GetWaitingEntriesForRoom.
+func (mr *MockDatabaseRepoMockRecorder) GetWaitingEntriesForRoom(roomID, start, end interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWaitingEntriesForRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).GetWaitingEntriesForRoom), roomID, start, end)
+}
+
+// GetWaitlistEntryByID mocks base method.
+func (m *MockDatabaseRepo) GetWaitlistEntryByID(id int) (*models.WaitlistEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWaitlistEntryByID", id)
+	ret0, _ := ret[0].(*models.WaitlistEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWaitlistEntryByID indicates an expected call of GetWaitlistEntryByID.
+func (mr *MockDatabaseRepoMockRecorder) GetWaitlistEntryByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWaitlistEntryByID", reflect.TypeOf((*MockDatabaseRepo)(nil).GetWaitlistEntryByID), id)
+}
+
+// HoldRoom mocks base method.
+func (m *MockDatabaseRepo) HoldRoom(hold *models.RoomRestriction) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HoldRoom", hold)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HoldRoom indicates an expected call of HoldRoom.
+func (mr *MockDatabaseRepoMockRecorder) HoldRoom(hold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HoldRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).HoldRoom), hold)
+}
+
+// ImportBlocks mocks base method.
+func (m *MockDatabaseRepo) ImportBlocks(blocks []models.RoomRestriction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportBlocks", blocks)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportBlocks indicates an expected call of ImportBlocks.
+func (mr *MockDatabaseRepoMockRecorder) ImportBlocks(blocks interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportBlocks", reflect.TypeOf((*MockDatabaseRepo)(nil).ImportBlocks), blocks)
+}
+
+// ImportReservations mocks base method.
+func (m *MockDatabaseRepo) ImportReservations(reservations []models.Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportReservations", reservations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportReservations indicates an expected call of ImportReservations.
+func (mr *MockDatabaseRepoMockRecorder) ImportReservations(reservations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportReservations", reflect.TypeOf((*MockDatabaseRepo)(nil).ImportReservations), reservations)
+}
+
+// InsertAddOn mocks base method.
+func (m *MockDatabaseRepo) InsertAddOn(addOn *models.AddOn) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAddOn", addOn)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAddOn indicates an expected call of InsertAddOn.
+func (mr *MockDatabaseRepoMockRecorder) InsertAddOn(addOn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAddOn", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertAddOn), addOn)
+}
+
+// InsertCancellationPolicy mocks base method.
+func (m *MockDatabaseRepo) InsertCancellationPolicy(policy *models.CancellationPolicy) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCancellationPolicy", policy)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCancellationPolicy indicates an expected call of InsertCancellationPolicy.
+func (mr *MockDatabaseRepoMockRecorder) InsertCancellationPolicy(policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCancellationPolicy", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertCancellationPolicy), policy)
+}
+
+// InsertCurrency mocks base method.
+func (m *MockDatabaseRepo) InsertCurrency(currency *models.Currency) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCurrency", currency)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertCurrency indicates an expected call of InsertCurrency.
+func (mr *MockDatabaseRepoMockRecorder) InsertCurrency(currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCurrency", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertCurrency), currency)
+}
+
+// InsertFolioEntry mocks base method.
+func (m *MockDatabaseRepo) InsertFolioEntry(entry *models.FolioEntry) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertFolioEntry", entry)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertFolioEntry indicates an expected call of InsertFolioEntry.
+func (mr *MockDatabaseRepoMockRecorder) InsertFolioEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertFolioEntry", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertFolioEntry), entry)
+}
+
+// InsertOutOfOrderPeriod mocks base method.
+func (m *MockDatabaseRepo) InsertOutOfOrderPeriod(period *models.OutOfOrderPeriod) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOutOfOrderPeriod", period)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOutOfOrderPeriod indicates an expected call of InsertOutOfOrderPeriod.
+func (mr *MockDatabaseRepoMockRecorder) InsertOutOfOrderPeriod(period interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOutOfOrderPeriod", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertOutOfOrderPeriod), period)
+}
+
+// InsertPayment mocks base method.
+func (m *MockDatabaseRepo) InsertPayment(payment *models.Payment) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPayment", payment)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPayment indicates an expected call of InsertPayment.
+func (mr *MockDatabaseRepoMockRecorder) InsertPayment(payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPayment", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertPayment), payment)
+}
+
+// InsertRatePlan mocks base method.
+func (m *MockDatabaseRepo) InsertRatePlan(plan *models.RatePlan) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRatePlan", plan)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRatePlan indicates an expected call of InsertRatePlan.
+func (mr *MockDatabaseRepoMockRecorder) InsertRatePlan(plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRatePlan", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRatePlan), plan)
+}
+
+// InsertReservation mocks base method.
+func (m *MockDatabaseRepo) InsertReservation(res *models.Reservation) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReservation", res)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReservation indicates an expected call of InsertReservation.
+func (mr *MockDatabaseRepoMockRecorder) InsertReservation(res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservation), res)
+}
+
+// InsertReservationGroup mocks base method.
+func (m *MockDatabaseRepo) InsertReservationGroup(group *models.ReservationGroup, reservations []models.Reservation) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReservationGroup", group, reservations)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReservationGroup indicates an expected call of InsertReservationGroup.
+func (mr *MockDatabaseRepoMockRecorder) InsertReservationGroup(group, reservations interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservationGroup", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservationGroup), group, reservations)
+}
+
+// InsertReservationNote mocks base method.
+func (m *MockDatabaseRepo) InsertReservationNote(note *models.ReservationNote) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReservationNote", note)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReservationNote indicates an expected call of InsertReservationNote.
+func (mr *MockDatabaseRepoMockRecorder) InsertReservationNote(note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservationNote", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservationNote), note)
+}
+
+// InsertReservationWithHold mocks base method.
+func (m *MockDatabaseRepo) InsertReservationWithHold(res *models.Reservation) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReservationWithHold", res)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReservationWithHold indicates an expected call of InsertReservationWithHold.
+func (mr *MockDatabaseRepoMockRecorder) InsertReservationWithHold(res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReservationWithHold", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertReservationWithHold), res)
+}
+
+// InsertRestriction mocks base method.
+func (m *MockDatabaseRepo) InsertRestriction(res *models.Restriction) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRestriction", res)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRestriction indicates an expected call of InsertRestriction.
+func (mr *MockDatabaseRepoMockRecorder) InsertRestriction(res interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRestriction), res)
+}
+
+// InsertRoom mocks base method.
+func (m *MockDatabaseRepo) InsertRoom(room *models.Room) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRoom", room)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRoom indicates an expected call of InsertRoom.
+func (mr *MockDatabaseRepoMockRecorder) InsertRoom(room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoom), room)
+}
+
+// InsertRoomRestriction mocks base method.
+func (m *MockDatabaseRepo) InsertRoomRestriction(rmres *models.RoomRestriction) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRoomRestriction", rmres)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertRoomRestriction indicates an expected call of InsertRoomRestriction.
+func (mr *MockDatabaseRepoMockRecorder) InsertRoomRestriction(rmres interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertRoomRestriction), rmres)
+}
+
+// InsertTax mocks base method.
+func (m *MockDatabaseRepo) InsertTax(tax *models.Tax) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertTax", tax)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertTax indicates an expected call of InsertTax.
+func (mr *MockDatabaseRepoMockRecorder) InsertTax(tax interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertTax", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertTax), tax)
+}
+
+// InsertUser mocks base method.
+func (m *MockDatabaseRepo) InsertUser(user *models.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUser", user)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUser indicates an expected call of InsertUser.
+func (mr *MockDatabaseRepoMockRecorder) InsertUser(user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertUser), user)
+}
+
+// InsertWaitlistEntry mocks base method.
+func (m *MockDatabaseRepo) InsertWaitlistEntry(entry *models.WaitlistEntry) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWaitlistEntry", entry)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWaitlistEntry indicates an expected call of InsertWaitlistEntry.
+func (mr *MockDatabaseRepoMockRecorder) InsertWaitlistEntry(entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWaitlistEntry", reflect.TypeOf((*MockDatabaseRepo)(nil).InsertWaitlistEntry), entry)
+}
+
+// IssueInvoice mocks base method.
+func (m *MockDatabaseRepo) IssueInvoice(reservationID int, kind string) (*models.Invoice, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueInvoice", reservationID, kind)
+	ret0, _ := ret[0].(*models.Invoice)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueInvoice indicates an expected call of IssueInvoice.
+func (mr *MockDatabaseRepoMockRecorder) IssueInvoice(reservationID, kind interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueInvoice", reflect.TypeOf((*MockDatabaseRepo)(nil).IssueInvoice), reservationID, kind)
+}
+
+// LookForAvailabilityOfRoom mocks base method.
+func (m *MockDatabaseRepo) LookForAvailabilityOfRoom(start, end time.Time, roomID int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookForAvailabilityOfRoom", start, end, roomID)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookForAvailabilityOfRoom indicates an expected call of LookForAvailabilityOfRoom.
+func (mr *MockDatabaseRepoMockRecorder) LookForAvailabilityOfRoom(start, end, roomID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookForAvailabilityOfRoom", reflect.TypeOf((*MockDatabaseRepo)(nil).LookForAvailabilityOfRoom), start, end, roomID)
+}
+
+// MarkNoShows mocks base method.
+func (m *MockDatabaseRepo) MarkNoShows(day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNoShows", day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNoShows indicates an expected call of MarkNoShows.
+func (mr *MockDatabaseRepoMockRecorder) MarkNoShows(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNoShows", reflect.TypeOf((*MockDatabaseRepo)(nil).MarkNoShows), day)
+}
+
+// MarkOutOfOrderRooms mocks base method.
+func (m *MockDatabaseRepo) MarkOutOfOrderRooms(day time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutOfOrderRooms", day)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkOutOfOrderRooms indicates an expected call of MarkOutOfOrderRooms.
+func (mr *MockDatabaseRepoMockRecorder) MarkOutOfOrderRooms(day interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutOfOrderRooms", reflect.TypeOf((*MockDatabaseRepo)(nil).MarkOutOfOrderRooms), day)
+}
+
+// MarkWaitlistEntryNotified mocks base method.
+func (m *MockDatabaseRepo) MarkWaitlistEntryNotified(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkWaitlistEntryNotified", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkWaitlistEntryNotified indicates an expected call of MarkWaitlistEntryNotified.
+func (mr *MockDatabaseRepoMockRecorder) MarkWaitlistEntryNotified(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkWaitlistEntryNotified", reflect.TypeOf((*MockDatabaseRepo)(nil).MarkWaitlistEntryNotified), id)
+}
+
+// MergeGuests mocks base method.
+func (m *MockDatabaseRepo) MergeGuests(survivor models.Guest, mergedID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MergeGuests", survivor, mergedID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MergeGuests indicates an expected call of MergeGuests.
+func (mr *MockDatabaseRepoMockRecorder) MergeGuests(survivor, mergedID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MergeGuests", reflect.TypeOf((*MockDatabaseRepo)(nil).MergeGuests), survivor, mergedID, userID)
+}
+
+// MoveReservation mocks base method.
+func (m *MockDatabaseRepo) MoveReservation(res models.Reservation, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveReservation", res, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MoveReservation indicates an expected call of MoveReservation.
+func (mr *MockDatabaseRepoMockRecorder) MoveReservation(res, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).MoveReservation), res, userID)
+}
+
+// PurgeReservation mocks base method.
+func (m *MockDatabaseRepo) PurgeReservation(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeReservation", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeReservation indicates an expected call of PurgeReservation.
+func (mr *MockDatabaseRepoMockRecorder) PurgeReservation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).PurgeReservation), id)
+}
+
+// PurgeRoomRestriction mocks base method.
+func (m *MockDatabaseRepo) PurgeRoomRestriction(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeRoomRestriction", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeRoomRestriction indicates an expected call of PurgeRoomRestriction.
+func (mr *MockDatabaseRepoMockRecorder) PurgeRoomRestriction(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).PurgeRoomRestriction), id)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockDatabaseRepo) PurgeTrash(before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockDatabaseRepoMockRecorder) PurgeTrash(before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockDatabaseRepo)(nil).PurgeTrash), before)
+}
+
+// ReleaseRoomHold mocks base method.
+func (m *MockDatabaseRepo) ReleaseRoomHold(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRoomHold", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRoomHold indicates an expected call of ReleaseRoomHold.
+func (mr *MockDatabaseRepoMockRecorder) ReleaseRoomHold(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRoomHold", reflect.TypeOf((*MockDatabaseRepo)(nil).ReleaseRoomHold), id)
+}
+
+// RemoveReservationAddOn mocks base method.
+func (m *MockDatabaseRepo) RemoveReservationAddOn(reservationID, itemID, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReservationAddOn", reservationID, itemID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReservationAddOn indicates an expected call of RemoveReservationAddOn.
+func (mr *MockDatabaseRepoMockRecorder) RemoveReservationAddOn(reservationID, itemID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReservationAddOn", reflect.TypeOf((*MockDatabaseRepo)(nil).RemoveReservationAddOn), reservationID, itemID, userID)
+}
+
+// RemoveReservationTag mocks base method.
+func (m *MockDatabaseRepo) RemoveReservationTag(reservationID int, tag string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveReservationTag", reservationID, tag)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveReservationTag indicates an expected call of RemoveReservationTag.
+func (mr *MockDatabaseRepoMockRecorder) RemoveReservationTag(reservationID, tag interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReservationTag", reflect.TypeOf((*MockDatabaseRepo)(nil).RemoveReservationTag), reservationID, tag)
+}
+
+// RestoreReservation mocks base method.
+func (m *MockDatabaseRepo) RestoreReservation(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreReservation", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreReservation indicates an expected call of RestoreReservation.
+func (mr *MockDatabaseRepoMockRecorder) RestoreReservation(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).RestoreReservation), id)
+}
+
+// RestoreRoomRestriction mocks base method.
+func (m *MockDatabaseRepo) RestoreRoomRestriction(id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRoomRestriction", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRoomRestriction indicates an expected call of RestoreRoomRestriction.
+func (mr *MockDatabaseRepoMockRecorder) RestoreRoomRestriction(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRoomRestriction", reflect.TypeOf((*MockDatabaseRepo)(nil).RestoreRoomRestriction), id)
+}
+
+// SearchAuditLog mocks base method.
+func (m *MockDatabaseRepo) SearchAuditLog(search models.AuditSearch) ([]models.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAuditLog", search)
+	ret0, _ := ret[0].([]models.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAuditLog indicates an expected call of SearchAuditLog.
+func (mr *MockDatabaseRepoMockRecorder) SearchAuditLog(search interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAuditLog", reflect.TypeOf((*MockDatabaseRepo)(nil).SearchAuditLog), search)
+}
+
+// UpdateAddOn mocks base method.
+func (m *MockDatabaseRepo) UpdateAddOn(addOn models.AddOn) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAddOn", addOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateAddOn indicates an expected call of UpdateAddOn.
+func (mr *MockDatabaseRepoMockRecorder) UpdateAddOn(addOn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAddOn", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateAddOn), addOn)
+}
+
+// UpdateCancellationPolicy mocks base method.
+func (m *MockDatabaseRepo) UpdateCancellationPolicy(policy models.CancellationPolicy) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCancellationPolicy", policy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCancellationPolicy indicates an expected call of UpdateCancellationPolicy.
+func (mr *MockDatabaseRepoMockRecorder) UpdateCancellationPolicy(policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCancellationPolicy", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateCancellationPolicy), policy)
+}
+
+// UpdateCurrency mocks base method.
+func (m *MockDatabaseRepo) UpdateCurrency(currency models.Currency) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCurrency", currency)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateCurrency indicates an expected call of UpdateCurrency.
+func (mr *MockDatabaseRepoMockRecorder) UpdateCurrency(currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCurrency", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateCurrency), currency)
+}
+
+// UpdateGuest mocks base method.
+func (m *MockDatabaseRepo) UpdateGuest(guest models.Guest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateGuest", guest)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateGuest indicates an expected call of UpdateGuest.
+func (mr *MockDatabaseRepoMockRecorder) UpdateGuest(guest interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateGuest", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateGuest), guest)
+}
+
+// UpdateHousekeepingStatus mocks base method.
+func (m *MockDatabaseRepo) UpdateHousekeepingStatus(roomID int, status string, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHousekeepingStatus", roomID, status, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHousekeepingStatus indicates an expected call of UpdateHousekeepingStatus.
+func (mr *MockDatabaseRepoMockRecorder) UpdateHousekeepingStatus(roomID, status, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHousekeepingStatus", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateHousekeepingStatus), roomID, status, userID)
+}
+
+// UpdatePayment mocks base method.
+func (m *MockDatabaseRepo) UpdatePayment(payment models.Payment) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePayment", payment)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePayment indicates an expected call of UpdatePayment.
+func (mr *MockDatabaseRepoMockRecorder) UpdatePayment(payment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePayment", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdatePayment), payment)
+}
+
+// UpdatePaymentStatusByReference mocks base method.
+func (m *MockDatabaseRepo) UpdatePaymentStatusByReference(reference, status string, amount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePaymentStatusByReference", reference, status, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePaymentStatusByReference indicates an expected call of UpdatePaymentStatusByReference.
+func (mr *MockDatabaseRepoMockRecorder) UpdatePaymentStatusByReference(reference, status, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatusByReference", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdatePaymentStatusByReference), reference, status, amount)
+}
+
+// UpdateRatePlan mocks base method.
+func (m *MockDatabaseRepo) UpdateRatePlan(plan models.RatePlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRatePlan", plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRatePlan indicates an expected call of UpdateRatePlan.
+func (mr *MockDatabaseRepoMockRecorder) UpdateRatePlan(plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRatePlan", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateRatePlan), plan)
+}
+
+// UpdateReservation mocks base method.
+func (m *MockDatabaseRepo) UpdateReservation(ur models.Reservation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservation", ur)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservation indicates an expected call of UpdateReservation.
+func (mr *MockDatabaseRepoMockRecorder) UpdateReservation(ur interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservation", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservation), ur)
+}
+
+// UpdateReservationGroup mocks base method.
+func (m *MockDatabaseRepo) UpdateReservationGroup(group models.ReservationGroup) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservationGroup", group)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservationGroup indicates an expected call of UpdateReservationGroup.
+func (mr *MockDatabaseRepoMockRecorder) UpdateReservationGroup(group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservationGroup", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservationGroup), group)
+}
+
+// UpdateReservationGroupProcessed mocks base method.
+func (m *MockDatabaseRepo) UpdateReservationGroupProcessed(id, processed int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservationGroupProcessed", id, processed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservationGroupProcessed indicates an expected call of UpdateReservationGroupProcessed.
+func (mr *MockDatabaseRepoMockRecorder) UpdateReservationGroupProcessed(id, processed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservationGroupProcessed", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservationGroupProcessed), id, processed)
+}
+
+// UpdateReservationProcessed mocks base method.
+func (m *MockDatabaseRepo) UpdateReservationProcessed(id, processed int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReservationProcessed", id, processed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReservationProcessed indicates an expected call of UpdateReservationProcessed.
+func (mr *MockDatabaseRepoMockRecorder) UpdateReservationProcessed(id, processed interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReservationProcessed", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateReservationProcessed), id, processed)
+}
+
+// UpdateRoomRates mocks base method.
+func (m *MockDatabaseRepo) UpdateRoomRates(room models.Room) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRoomRates", room)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRoomRates indicates an expected call of UpdateRoomRates.
+func (mr *MockDatabaseRepoMockRecorder) UpdateRoomRates(room interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRoomRates", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateRoomRates), room)
+}
+
+// UpdateTax mocks base method.
+func (m *MockDatabaseRepo) UpdateTax(tax models.Tax) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTax", tax)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTax indicates an expected call of UpdateTax.
+func (mr *MockDatabaseRepoMockRecorder) UpdateTax(tax interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTax", reflect.TypeOf((*MockDatabaseRepo)(nil).UpdateTax), tax)
+}
+
+// WithActor mocks base method.
+func (m *MockDatabaseRepo) WithActor(actor models.Actor) repository.DatabaseRepo {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithActor", actor)
+	ret0, _ := ret[0].(repository.DatabaseRepo)
+	return ret0
+}
+
+// WithActor indicates an expected call of WithActor.
+func (mr *MockDatabaseRepoMockRecorder) WithActor(actor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithActor", reflect.TypeOf((*MockDatabaseRepo)(nil).WithActor), actor)
 }
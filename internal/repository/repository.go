@@ -1,24 +1,131 @@
 package repository
 
 import (
+	"errors"
 	"github.com/porky256/course-project/internal/models"
 	"time"
 )
 
+// ErrRoomNotAvailable is returned when a room is already taken on requested dates
+var ErrRoomNotAvailable = errors.New("room is not available on requested dates")
+
+// ErrReservationCancelled is returned when cancelling a reservation which is cancelled already
+var ErrReservationCancelled = errors.New("reservation is already cancelled")
+
+// ErrReservationNoShow is returned when checking in a guest who was marked as a no-show
+var ErrReservationNoShow = errors.New("reservation is marked as a no-show")
+
+// ErrAlreadyCheckedIn is returned when checking in a guest twice
+var ErrAlreadyCheckedIn = errors.New("guest is already checked in")
+
+// ErrNotCheckedIn is returned when checking out a guest who hasn't checked in
+var ErrNotCheckedIn = errors.New("guest is not checked in")
+
+// ErrAlreadyCheckedOut is returned when checking out a guest twice
+var ErrAlreadyCheckedOut = errors.New("guest is already checked out")
+
+// ErrAddOnNotAvailable is returned when a limited add-on is sold out on a night of the stay
+var ErrAddOnNotAvailable = errors.New("add-on is not available on requested dates")
+
+// ErrReservationNotFound is returned for a reservation which doesn't exist or is in the trash
+var ErrReservationNotFound = errors.New("reservation is not found")
+
+// ErrPaymentNotFound is returned when there is no payment with the gateway reference
+var ErrPaymentNotFound = errors.New("payment is not found")
+
+// ErrPaymentTaken is returned when a payment whose money was taken is voided, it has to be refunded instead
+var ErrPaymentTaken = errors.New("payment is captured and can't be voided")
+
 type DatabaseRepo interface {
+	// WithActor returns the repository which records the actor in the audit log with every change
+	WithActor(actor models.Actor) DatabaseRepo
+
 	InsertReservation(res *models.Reservation) (int, error)
 	GetReservationByID(id int) (*models.Reservation, error)
-	GetAllReservations() ([]models.Reservation, error)
-	GetNewReservations() ([]models.Reservation, error)
+	GetReservationsByConfirmationCode(code string) ([]models.Reservation, error)
+	GetReservationsByIDs(ids []int) ([]models.Reservation, error)
+	GetAllReservations(query models.ReservationQuery) ([]models.Reservation, int, error)
+	GetNewReservations(query models.ReservationQuery) ([]models.Reservation, int, error)
+	ExportReservations(query models.ReservationQuery, onlyNew bool, fn func(res models.Reservation) error) error
 	UpdateReservation(ur models.Reservation) error
 	UpdateReservationProcessed(id, processed int) error
 	DeleteReservationByID(id int) error
+	CancelReservation(id, fee int) error
+	BulkUpdateReservations(ids []int, change models.BulkChange) (map[int]error, error)
+	MoveReservation(res models.Reservation, userID int) error
+	GetFrontDeskReservations(day time.Time) ([]models.Reservation, error)
+	CheckInReservation(id, userID int, at time.Time) error
+	CheckOutReservation(id, userID int, at time.Time) error
+	MarkNoShows(day time.Time) (int, error)
+	GetReportReservations(from, to time.Time) ([]models.Reservation, error)
+	GetRoomClosures(from, to time.Time) ([]models.RoomRestriction, error)
+
+	GetAllGuests() ([]models.Guest, error)
+	GetGuestByID(id int) (*models.Guest, error)
+	UpdateGuest(guest models.Guest) error
+	MergeGuests(survivor models.Guest, mergedID int, userID int) error
+
+	InsertReservationNote(note *models.ReservationNote) (int, error)
+	AddReservationTag(reservationID int, tag string) error
+	RemoveReservationTag(reservationID int, tag string) error
+	GetAllTags() ([]string, error)
+
+	InsertReservationGroup(group *models.ReservationGroup, reservations []models.Reservation) (int, error)
+	GetReservationGroupByID(id int) (*models.ReservationGroup, error)
+	UpdateReservationGroup(group models.ReservationGroup) error
+	UpdateReservationGroupProcessed(id, processed int) error
+	DeleteReservationGroupByID(id int) error
 
 	InsertRoom(room *models.Room) (int, error)
 	GetRoomByID(id int) (*models.Room, error)
 	GetAllRooms() ([]models.Room, error)
 	LookForAvailabilityOfRoom(start, end time.Time, roomID int) (bool, error)
 	AvailabilityOfAllRooms(start, end time.Time) ([]models.Room, error)
+	GetRoomsWithRates() ([]models.Room, error)
+	UpdateRoomRates(room models.Room) error
+
+	UpdateHousekeepingStatus(roomID int, status string, userID int) error
+	GenerateHousekeepingTasks(day time.Time) (int, error)
+	GetHousekeepingTasks(day time.Time) ([]models.HousekeepingTask, error)
+	InsertOutOfOrderPeriod(period *models.OutOfOrderPeriod) (int, error)
+	GetOutOfOrderPeriodByID(id int) (*models.OutOfOrderPeriod, error)
+	GetOutOfOrderPeriods(from time.Time) ([]models.OutOfOrderPeriod, error)
+	DeleteOutOfOrderPeriodByID(id int) error
+	MarkOutOfOrderRooms(day time.Time) (int, error)
+
+	InsertCancellationPolicy(policy *models.CancellationPolicy) (int, error)
+	GetAllCancellationPolicies() ([]models.CancellationPolicy, error)
+	UpdateCancellationPolicy(policy models.CancellationPolicy) error
+
+	InsertRatePlan(plan *models.RatePlan) (int, error)
+	UpdateRatePlan(plan models.RatePlan) error
+	DeleteRatePlanByID(id int) error
+
+	GetAllTaxes() ([]models.Tax, error)
+	InsertTax(tax *models.Tax) (int, error)
+	UpdateTax(tax models.Tax) error
+	DeleteTaxByID(id int) error
+
+	GetAllAddOns() ([]models.AddOn, error)
+	GetAddOnByID(id int) (*models.AddOn, error)
+	InsertAddOn(addOn *models.AddOn) (int, error)
+	UpdateAddOn(addOn models.AddOn) error
+	DeleteAddOnByID(id int) error
+	AddReservationAddOn(item *models.ReservationAddOn, userID int) (int, error)
+	RemoveReservationAddOn(reservationID, itemID, userID int) error
+
+	GetAllCurrencies() ([]models.Currency, error)
+	InsertCurrency(currency *models.Currency) (int, error)
+	UpdateCurrency(currency models.Currency) error
+	DeleteCurrencyByID(id int) error
+
+	InsertPayment(payment *models.Payment) (int, error)
+	GetPaymentByID(id int) (*models.Payment, error)
+	UpdatePayment(payment models.Payment) error
+	UpdatePaymentStatusByReference(reference, status string, amount int) error
+
+	InsertFolioEntry(entry *models.FolioEntry) (int, error)
+	IssueInvoice(reservationID int, kind string) (*models.Invoice, error)
 
 	InsertUser(user *models.User) (int, error)
 	GetUserByID(id int) (*models.User, error)
@@ -26,9 +133,35 @@ type DatabaseRepo interface {
 	InsertRestriction(res *models.Restriction) (int, error)
 
 	InsertRoomRestriction(rmres *models.RoomRestriction) (int, error)
+	ImportReservations(reservations []models.Reservation) error
+	ImportBlocks(blocks []models.RoomRestriction) error
+	InsertReservationWithHold(res *models.Reservation) (int, error)
+	HoldRoom(hold *models.RoomRestriction) (int, error)
+	ExtendRoomHolds(ids []int, expiresAt time.Time) error
+	ReleaseRoomHold(id int) error
+	DeleteExpiredRoomHolds() (int, error)
 	AddSingleDayRoomRestriction(roomID, restrictionID int, start time.Time) (int, error)
 	GetRoomRestrictionsByRoomIdWithinDates(roomID int, start, end time.Time) ([]models.RoomRestriction, error)
+	GetActiveRoomRestrictionsWithinDates(start, end time.Time) ([]models.RoomRestriction, error)
 	DeleteRoomRestrictionByID(id int) error
 
+	InsertWaitlistEntry(entry *models.WaitlistEntry) (int, error)
+	GetWaitlistEntryByID(id int) (*models.WaitlistEntry, error)
+	GetAllWaitlistEntries() ([]models.WaitlistEntry, error)
+	GetWaitingEntriesForRoom(roomID int, start, end time.Time) ([]models.WaitlistEntry, error)
+	MarkWaitlistEntryNotified(id int) error
+	DeleteWaitlistEntryByID(id int) error
+
+	GetDeletedReservations() ([]models.Reservation, error)
+	GetDeletedRoomRestrictions() ([]models.RoomRestriction, error)
+	RestoreReservation(id int) error
+	RestoreRoomRestriction(id int) error
+	PurgeReservation(id int) error
+	PurgeRoomRestriction(id int) error
+	PurgeTrash(before time.Time) (int, error)
+
 	Authenticate(email, passwordSample string) (int, string, error)
+
+	SearchAuditLog(search models.AuditSearch) ([]models.AuditEntry, error)
+	GetEntityHistory(entity string, id int) ([]models.AuditEntry, error)
 }
@@ -191,8 +191,17 @@ function singleRoomChooseDates(c) {
                                 showConfirmButton: false,
                             })
                         } else {
-                            attention.error({
-                                msg: "Sorry, this room is not available on this dates >:("
+                            attention.custom({
+                                msg: '<p>Sorry, this room is not available on this dates >:(</p>' +
+                                    '<p><a href="/waitlist?room_id=' +
+                                    data.room_id +
+                                    '&start=' +
+                                    data.start_date +
+                                    '&end=' +
+                                    data.end_date +
+                                    '" class="btn btn-primary">Join the waitlist</a></p>',
+                                icon: 'error',
+                                showConfirmButton: false,
                             })
                         }
                     })